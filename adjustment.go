@@ -0,0 +1,364 @@
+package walleter
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// AdjustmentBusinessModule the business module of the commands of approved adjustments. Commands of this
+// module, carrying a CommandApproval or deducting assets are rejected with ErrApprovalRequired unless
+// ApproveAdjustment issues them.
+const AdjustmentBusinessModule = "Adjustment"
+
+// AdjustmentStatus the state of an AdjustmentRequest, pending until an operator reviews it.
+type AdjustmentStatus string
+
+const (
+	AdjustmentPending  AdjustmentStatus = "pending"
+	AdjustmentApproved AdjustmentStatus = "approved"
+	AdjustmentRejected AdjustmentStatus = "rejected"
+)
+
+// Adjustment a manual correction of a wallet proposed by an operator. Positive amounts are credited as income,
+// negative ones are deducted, a debit beyond the balance fails the approval of the whole adjustment.
+type Adjustment struct {
+	AccountId uint64
+	Operator  string
+	Reason    string
+	Tokens    map[ERC20TokenEnum]float64
+	Items     map[uint64]int64
+}
+
+// AdjustmentAmounts the amounts of an adjustment, by token symbol and by ERC1155 id; negative amounts are debits.
+type AdjustmentAmounts struct {
+	Tokens map[string]float64 `json:"tokens,omitempty"`
+	Items  map[uint64]int64   `json:"items,omitempty"`
+}
+
+func (AdjustmentAmounts) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (a AdjustmentAmounts) Value() (driver.Value, error) {
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *AdjustmentAmounts) Scan(input interface{}) error {
+	return scanJSON(input, a)
+}
+
+// AdjustmentRequest an adjustment waiting for, or having received, the review of a second operator.
+// Only an approval by an operator other than the proposer applies it.
+type AdjustmentRequest struct {
+	gorm.Model `swagger-ignore:"true"`
+	AccountId  uint64            `json:"account_id" gorm:"not null;index"`
+	Amounts    AdjustmentAmounts `json:"amounts"`
+	Reason     string            `json:"reason" gorm:"type:varchar(255);not null"`
+	ProposedBy string            `json:"proposed_by" gorm:"type:varchar(64);not null"`
+	Status     AdjustmentStatus  `json:"status" gorm:"type:varchar(16);not null;index"`
+	ReviewedBy string            `json:"reviewed_by" gorm:"type:varchar(64)"`
+	ReviewNote string            `json:"review_note" gorm:"type:varchar(255)"`
+	ReviewedAt *time.Time        `json:"reviewed_at"`
+}
+
+// CommandApproval the approval a command was issued under, recorded in its logs.
+type CommandApproval struct {
+	RequestId  uint   `json:"request_id"`
+	ProposedBy string `json:"proposed_by"`
+	ApprovedBy string `json:"approved_by"`
+	Reason     string `json:"reason"`
+}
+
+func (CommandApproval) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (a CommandApproval) Value() (driver.Value, error) {
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *CommandApproval) Scan(input interface{}) error {
+	return scanJSON(input, a)
+}
+
+// ProposeAdjustment records adjustment as a pending AdjustmentRequest, nothing changes in the wallet until
+// another operator approves it.
+func (s *Walleter) ProposeAdjustment(ctx context.Context, adjustment Adjustment) (AdjustmentRequest, error) {
+	if err := checkOperatorAction(adjustment.Operator, adjustment.Reason); err != nil {
+		return AdjustmentRequest{}, err
+	}
+	amounts, err := adjustment.amounts()
+	if err != nil {
+		return AdjustmentRequest{}, err
+	}
+
+	var request AdjustmentRequest
+	err = s.repo.WithContext(ctx).Transaction(func(tx Repository) (err error) {
+		adjustments, err := storeOf[AdjustmentStore](tx)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.GetWallet(adjustment.AccountId); err != nil {
+			return err
+		}
+		request, err = adjustments.InsertAdjustmentRequest(AdjustmentRequest{
+			AccountId:  adjustment.AccountId,
+			Amounts:    amounts,
+			Reason:     adjustment.Reason,
+			ProposedBy: adjustment.Operator,
+			Status:     AdjustmentPending,
+		})
+		if err != nil {
+			return err
+		}
+		return insertAuditLog(tx, request.auditLog(AuditProposeAdjustment, adjustment.Operator, adjustment.Reason))
+	})
+	return request, err
+}
+
+// ApproveAdjustment applies the pending request id on behalf of approver, who cannot be its proposer.
+// The commands of the request carry a CommandApproval and apply to frozen accounts as well.
+// The request stays pending when a command is rejected, e.g. for a debit beyond the balance.
+func (s *Walleter) ApproveAdjustment(ctx context.Context, id uint, approver string, note string) (Wallet, error) {
+	if strings.TrimSpace(approver) == "" {
+		return Wallet{}, fmt.Errorf("%w: operator is required", ErrInvalidOperatorAction)
+	}
+
+	repo := s.repo.WithContext(ctx)
+	var accountId uint64
+	err := repo.Transaction(func(tx Repository) error {
+		request, err := s.reviewAdjustment(tx, id, approver, note, AdjustmentApproved)
+		if err != nil {
+			return err
+		}
+		accountId = request.AccountId
+
+		approval := &CommandApproval{RequestId: request.ID, ProposedBy: request.ProposedBy, ApprovedBy: approver, Reason: request.Reason}
+		for _, command := range request.Amounts.commands(request.AccountId) {
+			command.Approval = approval
+			if _, err := s.handleCommand(tx, command, true); err != nil {
+				return err
+			}
+		}
+		return insertAuditLog(tx, request.auditLog(AuditApproveAdjustment, approver, reviewReason(note, request)))
+	})
+	if err != nil {
+		return Wallet{}, err
+	}
+	return repo.GetWallet(accountId)
+}
+
+// RejectAdjustment closes the pending request id without applying it. Its proposer may reject it, e.g. to withdraw it.
+func (s *Walleter) RejectAdjustment(ctx context.Context, id uint, reviewer string, note string) (AdjustmentRequest, error) {
+	if err := checkOperatorAction(reviewer, note); err != nil {
+		return AdjustmentRequest{}, err
+	}
+	var request AdjustmentRequest
+	err := s.repo.WithContext(ctx).Transaction(func(tx Repository) (err error) {
+		request, err = s.reviewAdjustment(tx, id, reviewer, note, AdjustmentRejected)
+		if err != nil {
+			return err
+		}
+		return insertAuditLog(tx, request.auditLog(AuditRejectAdjustment, reviewer, note))
+	})
+	return request, err
+}
+
+// GetAdjustmentRequest returns the request id, gorm.ErrRecordNotFound when it does not exist.
+func (s *Walleter) GetAdjustmentRequest(id uint) (AdjustmentRequest, error) {
+	adjustments, err := storeOf[AdjustmentStore](s.repo)
+	if err != nil {
+		return AdjustmentRequest{}, err
+	}
+	return adjustments.GetAdjustmentRequest(id)
+}
+
+// ListAdjustmentRequests returns the requests with status, every request when empty, oldest first.
+func (s *Walleter) ListAdjustmentRequests(status AdjustmentStatus) ([]AdjustmentRequest, error) {
+	adjustments, err := storeOf[AdjustmentStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return adjustments.ListAdjustmentRequests(status)
+}
+
+// reviewAdjustment moves the pending request id to status. Another reviewer getting there first is
+// reported as ErrAdjustmentNotPending.
+func (s *Walleter) reviewAdjustment(tx Repository, id uint, reviewer string, note string, status AdjustmentStatus) (AdjustmentRequest, error) {
+	adjustments, err := storeOf[AdjustmentStore](tx)
+	if err != nil {
+		return AdjustmentRequest{}, err
+	}
+	request, err := adjustments.GetAdjustmentRequest(id)
+	if err != nil {
+		return AdjustmentRequest{}, err
+	}
+	if request.Status != AdjustmentPending {
+		return AdjustmentRequest{}, fmt.Errorf("%w: request %d is %s", ErrAdjustmentNotPending, id, request.Status)
+	}
+	if status == AdjustmentApproved && strings.EqualFold(strings.TrimSpace(request.ProposedBy), strings.TrimSpace(reviewer)) {
+		return AdjustmentRequest{}, fmt.Errorf("%w: request %d", ErrSelfApproval, id)
+	}
+
+	now := time.Now()
+	request.Status = status
+	request.ReviewedBy = reviewer
+	request.ReviewNote = note
+	request.ReviewedAt = &now
+	reviewed, err := adjustments.ReviewAdjustmentRequest(request)
+	if err != nil {
+		return AdjustmentRequest{}, err
+	}
+	if !reviewed {
+		return AdjustmentRequest{}, fmt.Errorf("%w: request %d was reviewed meanwhile", ErrAdjustmentNotPending, id)
+	}
+	return request, nil
+}
+
+func (request AdjustmentRequest) auditLog(action AuditAction, operator string, reason string) AuditLog {
+	return AuditLog{Operator: operator, Action: action, AccountId: request.AccountId, Reason: reason, Detail: request.Amounts}
+}
+
+func reviewReason(note string, request AdjustmentRequest) string {
+	if strings.TrimSpace(note) != "" {
+		return note
+	}
+	return request.Reason
+}
+
+// amounts validates the adjustment, leaving zero amounts out.
+func (adjustment Adjustment) amounts() (AdjustmentAmounts, error) {
+	amounts := AdjustmentAmounts{Tokens: map[string]float64{}, Items: map[uint64]int64{}}
+	for token, value := range adjustment.Tokens {
+		if token.String() == "unknown" || math.IsNaN(value) || math.IsInf(value, 0) {
+			return AdjustmentAmounts{}, fmt.Errorf("%w: invalid amount of token %d", ErrInvalidOperatorAction, token)
+		}
+		if value != 0 {
+			amounts.Tokens[token.String()] = value
+		}
+	}
+	for id, value := range adjustment.Items {
+		if value != 0 {
+			amounts.Items[id] = value
+		}
+	}
+	if len(amounts.Tokens) == 0 && len(amounts.Items) == 0 {
+		return AdjustmentAmounts{}, fmt.Errorf("%w: nothing to adjust", ErrInvalidOperatorAction)
+	}
+	return amounts, nil
+}
+
+// commands splits the amounts into an income and a withdrawal per asset type.
+func (a AdjustmentAmounts) commands(accountId uint64) []WalletCommand {
+	credits, debits := map[ERC20TokenEnum]float64{}, map[ERC20TokenEnum]float64{}
+	for symbol, value := range a.Tokens {
+		token, ok := erc20TokenOf(symbol)
+		switch {
+		case !ok:
+			continue
+		case value > 0:
+			credits[token] = value
+		case value < 0:
+			debits[token] = -value
+		}
+	}
+
+	var ids []uint64
+	for id := range a.Items {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var creditIds, creditValues, debitIds, debitValues []uint64
+	for _, id := range ids {
+		value := a.Items[id]
+		switch {
+		case value > 0:
+			creditIds, creditValues = append(creditIds, id), append(creditValues, uint64(value))
+		case value < 0:
+			debitIds, debitValues = append(debitIds, id), append(debitValues, uint64(-value))
+		}
+	}
+
+	var commands []WalletCommand
+	if len(credits) > 0 {
+		commands = append(commands, NewERC20WalletCommand(accountId, Income, AdjustmentBusinessModule, InGame, credits, nil))
+	}
+	if len(debits) > 0 {
+		commands = append(commands, NewERC20WalletCommand(accountId, Deduct, AdjustmentBusinessModule, InGame, debits, nil))
+	}
+	if len(creditIds) > 0 {
+		commands = append(commands, NewERC1155WalletCommand(accountId, Income, AdjustmentBusinessModule, InGame, creditIds, creditValues, nil))
+	}
+	if len(debitIds) > 0 {
+		commands = append(commands, NewERC1155WalletCommand(accountId, Deduct, AdjustmentBusinessModule, InGame, debitIds, debitValues, nil))
+	}
+	return commands
+}
+
+// checkApproval rejects adjustment commands issued outside of an approved AdjustmentRequest. Callers of
+// ExecuteCommand may fill in any business module and approval, so only operator commands, which
+// ApproveAdjustment issues with the approval of the request, pass.
+func checkApproval(command WalletCommand, byOperator bool) error {
+	adjustment := command.BusinessModule == AdjustmentBusinessModule || command.Approval != nil || command.ActionType == Deduct
+	if adjustment && (!byOperator || command.Approval == nil) {
+		return newWalletError(ErrApprovalRequired, command.AccountId)
+	}
+	return nil
+}
+
+// erc20TokenOf the token of symbol, as stored in wallets and adjustments.
+func erc20TokenOf(symbol string) (ERC20TokenEnum, bool) {
+	for token := ETH; token <= FISHX; token++ {
+		if token.String() == symbol {
+			return token, true
+		}
+	}
+	return 0, false
+}
+
+type adjustmentRequestDAO struct{}
+
+var adjustmentDAO = &adjustmentRequestDAO{}
+
+func (dao adjustmentRequestDAO) insertRequest(db *gorm.DB, request AdjustmentRequest) (AdjustmentRequest, error) {
+	err := db.Create(&request).Error
+	return request, err
+}
+
+func (dao adjustmentRequestDAO) getRequest(db *gorm.DB, id uint) (request AdjustmentRequest, err error) {
+	err = db.First(&request, id).Error
+	return request, err
+}
+
+// reviewRequest saves the review of request if it is still pending, reporting whether it was.
+func (dao adjustmentRequestDAO) reviewRequest(db *gorm.DB, request AdjustmentRequest) (bool, error) {
+	result := db.Model(&AdjustmentRequest{}).
+		Where("id = ? AND status = ?", request.ID, AdjustmentPending).
+		Updates(map[string]interface{}{
+			"status":      request.Status,
+			"reviewed_by": request.ReviewedBy,
+			"review_note": request.ReviewNote,
+			"reviewed_at": request.ReviewedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (dao adjustmentRequestDAO) listRequests(db *gorm.DB, status AdjustmentStatus) (result []AdjustmentRequest, err error) {
+	query := db.Order("id")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err = query.Find(&result).Error
+	return result, err
+}
@@ -0,0 +1,175 @@
+package walleter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// AccountFreeze marks a frozen account: commands on it are rejected with ErrAccountFrozen until it is
+// unfrozen. Approved adjustments still apply, so that the wallet of a frozen account can be corrected.
+type AccountFreeze struct {
+	gorm.Model `swagger-ignore:"true"`
+	AccountId  uint64 `json:"account_id" gorm:"uniqueIndex;not null"`
+	Operator   string `json:"operator" gorm:"type:varchar(64);not null"`
+	Reason     string `json:"reason" gorm:"type:varchar(255);not null"`
+}
+
+// AuditAction an operator action recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditFreeze            AuditAction = "freeze"
+	AuditUnfreeze          AuditAction = "unfreeze"
+	AuditProposeAdjustment AuditAction = "propose_adjustment"
+	AuditApproveAdjustment AuditAction = "approve_adjustment"
+	AuditRejectAdjustment  AuditAction = "reject_adjustment"
+	AuditApproveWithdrawal AuditAction = "approve_withdrawal"
+	AuditRejectWithdrawal  AuditAction = "reject_withdrawal"
+)
+
+// AuditLog records an operator action on an account, in the same transaction as the action itself.
+type AuditLog struct {
+	gorm.Model `swagger-ignore:"true"`
+	Operator   string            `json:"operator" gorm:"type:varchar(64);not null;index"`
+	Action     AuditAction       `json:"action" gorm:"type:varchar(32);not null"`
+	AccountId  uint64            `json:"account_id" gorm:"not null;index"`
+	Reason     string            `json:"reason" gorm:"type:varchar(255);not null"`
+	Detail     AdjustmentAmounts `json:"detail"`
+}
+
+// FreezeAccount freezes the account of accountId, see AccountFreeze. An account already frozen keeps its first freeze.
+func (s *Walleter) FreezeAccount(ctx context.Context, accountId uint64, operator string, reason string) (AccountFreeze, error) {
+	if err := checkOperatorAction(operator, reason); err != nil {
+		return AccountFreeze{}, err
+	}
+	var freeze AccountFreeze
+	err := s.repo.WithContext(ctx).Transaction(func(tx Repository) (err error) {
+		admin, err := storeOf[AdminStore](tx)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.GetWallet(accountId); err != nil {
+			return err
+		}
+		freeze, err = admin.GetAccountFreeze(accountId)
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		freeze, err = admin.CreateAccountFreeze(AccountFreeze{AccountId: accountId, Operator: operator, Reason: reason})
+		if err != nil {
+			return err
+		}
+		_, err = admin.InsertAuditLog(AuditLog{Operator: operator, Action: AuditFreeze, AccountId: accountId, Reason: reason})
+		return err
+	})
+	return freeze, err
+}
+
+// UnfreezeAccount lifts the freeze of accountId, gorm.ErrRecordNotFound means it was not frozen.
+func (s *Walleter) UnfreezeAccount(ctx context.Context, accountId uint64, operator string, reason string) error {
+	if err := checkOperatorAction(operator, reason); err != nil {
+		return err
+	}
+	return s.repo.WithContext(ctx).Transaction(func(tx Repository) error {
+		admin, err := storeOf[AdminStore](tx)
+		if err != nil {
+			return err
+		}
+		if _, err := admin.GetAccountFreeze(accountId); err != nil {
+			return err
+		}
+		if err := admin.DeleteAccountFreeze(accountId); err != nil {
+			return err
+		}
+		_, err = admin.InsertAuditLog(AuditLog{Operator: operator, Action: AuditUnfreeze, AccountId: accountId, Reason: reason})
+		return err
+	})
+}
+
+// GetAccountFreeze returns the freeze of accountId, gorm.ErrRecordNotFound when it is not frozen.
+func (s *Walleter) GetAccountFreeze(accountId uint64) (AccountFreeze, error) {
+	admin, err := storeOf[AdminStore](s.repo)
+	if err != nil {
+		return AccountFreeze{}, err
+	}
+	return admin.GetAccountFreeze(accountId)
+}
+
+// ListAuditLogs returns the operator actions on accountId, oldest first.
+func (s *Walleter) ListAuditLogs(accountId uint64) ([]AuditLog, error) {
+	admin, err := storeOf[AdminStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return admin.ListAuditLogs(accountId)
+}
+
+// insertAuditLog records log in the AdminStore of tx.
+func insertAuditLog(tx Repository, log AuditLog) error {
+	admin, err := storeOf[AdminStore](tx)
+	if err != nil {
+		return err
+	}
+	_, err = admin.InsertAuditLog(log)
+	return err
+}
+
+// checkNotFrozen rejects commands on a frozen account. A Repository without AdminStore has no frozen accounts.
+func checkNotFrozen(repo Repository, accountId uint64) error {
+	admin, ok := repo.(AdminStore)
+	if !ok {
+		return nil
+	}
+	_, err := admin.GetAccountFreeze(accountId)
+	if err == nil {
+		return newWalletError(ErrAccountFrozen, accountId)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// checkOperatorAction operator actions are only accepted along with who takes them and why.
+func checkOperatorAction(operator string, reason string) error {
+	if strings.TrimSpace(operator) == "" {
+		return fmt.Errorf("%w: operator is required", ErrInvalidOperatorAction)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: reason is required", ErrInvalidOperatorAction)
+	}
+	return nil
+}
+
+type adminDAO struct{}
+
+var accountAdminDAO = &adminDAO{}
+
+func (dao adminDAO) getAccountFreeze(db *gorm.DB, accountId uint64) (freeze AccountFreeze, err error) {
+	err = db.Where("account_id = ?", accountId).First(&freeze).Error
+	return freeze, err
+}
+
+func (dao adminDAO) createAccountFreeze(db *gorm.DB, freeze AccountFreeze) (AccountFreeze, error) {
+	err := db.Create(&freeze).Error
+	return freeze, err
+}
+
+// deleteAccountFreeze removes the row for good, the unique account_id would otherwise block the next freeze.
+func (dao adminDAO) deleteAccountFreeze(db *gorm.DB, accountId uint64) error {
+	return db.Unscoped().Where("account_id = ?", accountId).Delete(&AccountFreeze{}).Error
+}
+
+func (dao adminDAO) insertAuditLog(db *gorm.DB, log AuditLog) (AuditLog, error) {
+	err := db.Create(&log).Error
+	return log, err
+}
+
+func (dao adminDAO) listAuditLogs(db *gorm.DB, accountId uint64) (result []AuditLog, err error) {
+	err = db.Where("account_id = ?", accountId).Order("id").Find(&result).Error
+	return result, err
+}
@@ -0,0 +1,690 @@
+// Command walleteradmin lets operators inspect and correct wallets without writing SQL.
+//
+//	walleteradmin -dialect mysql -dsn 'user:pass@tcp(db:3306)/walleter?parseTime=True' -charger 1 <command> [flags] [args]
+//
+// Commands:
+//
+//	wallet <account_id>                               show a wallet with its decoded ERC1155 holdings
+//	logs [-limit n] [-cursor c] [-asc] <account_id>   list the logs of an account, newest first
+//	verify [-all] [account_id...]                     verify check signs
+//	reconcile [account_id...]                         check balances against their totals and history
+//	freeze -reason r <account_id>                     reject the commands of an account
+//	unfreeze -reason r <account_id>                   accept the commands of an account again
+//	propose -reason r [-token SYMBOL=±amount]... [-item ID=±amount]... <account_id>
+//	                                                  propose to credit or debit a wallet by hand
+//	approve [-note n] <request_id>                    apply an adjustment proposed by another operator
+//	reject -note n <request_id>                       close an adjustment without applying it
+//	requests [-status s]                              list adjustment requests, pending ones by default
+//	withdrawals [-status s]                           list held withdrawals, pending ones by default
+//	approve-withdrawal <hold_id>                      approve a held withdrawal
+//	reject-withdrawal -reason r <hold_id>             release the funds of a held withdrawal
+//	expire-withdrawals                                release the held withdrawals whose timeout passed
+//	audit <account_id>                                list the operator actions on an account
+//	flags [account_id]                                list the accounts flagged by reverted chain deposits
+//	addresses -chain c <account_id>                   list the deposit addresses of an account, retired ones included
+//	webhook endpoints                                 list the webhook endpoints
+//	webhook deliveries [-status s] [-limit n] <endpoint_id>
+//	                                                  list the latest deliveries of an endpoint
+//	webhook replay <delivery_id>                      send a delivery again, whatever its status
+//	webhook replay-events -since t <endpoint_id>      queue the events created since t (RFC 3339) for an endpoint again
+//
+// Freezes, unfreezes, adjustment reviews and withdrawal approvals are recorded in the audit log under -operator,
+// the OS user by default. An adjustment only applies once approved by an operator other than the one who
+// proposed it, a held withdrawal once approved by as many distinct operators as its hold requires.
+// -o json prints JSON for scripting instead of tables. The schema must be up to date, the tool never migrates it.
+// The exit status is 1 on errors and 2 when verify or reconcile find issues.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// errIssuesFound makes the exit status 2, after the issues were printed.
+var errIssuesFound = errors.New("issues found")
+
+type admin struct {
+	db       *gorm.DB
+	w        *walleter.Walleter
+	out      output
+	operator string
+	timeout  time.Duration
+}
+
+func main() {
+	global := flag.NewFlagSet("walleteradmin", flag.ExitOnError)
+	dialect := global.String("dialect", envOr("WALLETER_DIALECT", "mysql"), "database driver: mysql or postgres, $WALLETER_DIALECT")
+	dsn := global.String("dsn", os.Getenv("WALLETER_DSN"), "data source name, $WALLETER_DSN")
+	charger := global.Uint64("charger", 0, "fee charger account id the services run with")
+	format := global.String("o", "table", "output format: table or json")
+	operator := global.String("operator", currentUser(), "operator recorded in the audit log")
+	timeout := global.Duration("timeout", time.Minute, "timeout of every command")
+	global.Usage = func() {
+		fmt.Fprintln(global.Output(), "usage: walleteradmin [flags] wallet|logs|verify|reconcile|freeze|unfreeze|propose|approve|reject|requests|withdrawals|approve-withdrawal|reject-withdrawal|expire-withdrawals|audit|flags|addresses|webhook [flags] [args]")
+		global.PrintDefaults()
+	}
+	_ = global.Parse(os.Args[1:])
+	if global.NArg() == 0 {
+		global.Usage()
+		os.Exit(1)
+	}
+
+	out, err := newOutput(*format)
+	if err == nil {
+		var a *admin
+		a, err = connect(*dialect, *dsn, *charger)
+		if err == nil {
+			a.out, a.operator, a.timeout = out, *operator, *timeout
+			err = a.run(global.Arg(0), global.Args()[1:])
+		}
+	}
+	switch {
+	case errors.Is(err, errIssuesFound):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "walleteradmin:", err)
+		os.Exit(1)
+	}
+}
+
+// connect opens the database and checks that its schema is the one of this release.
+func connect(dialect string, dsn string, charger uint64) (*admin, error) {
+	if dsn == "" {
+		return nil, errors.New("-dsn is required")
+	}
+	// a wrong id would create a wallet for an account which is not the fee charger
+	if charger == 0 {
+		return nil, errors.New("-charger is required")
+	}
+	var dialector gorm.Dialector
+	switch dialect {
+	case "mysql":
+		dialector = mysql.Open(dsn)
+	case "postgres":
+		dialector = postgres.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported dialect %q", dialect)
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, err
+	}
+	pending, err := walleter.PendingMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return nil, fmt.Errorf("schema is %d migrations behind, migrate it before using this tool", len(pending))
+	}
+	return &admin{db: db, w: walleter.New(db, charger, walleter.WithoutAutoMigrate())}, nil
+}
+
+func (a *admin) run(command string, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	switch command {
+	case "wallet":
+		return a.showWallet(args)
+	case "logs":
+		return a.listLogs(args)
+	case "verify":
+		return a.verify(args)
+	case "reconcile":
+		return a.reconcile(args)
+	case "freeze":
+		return a.freeze(ctx, args)
+	case "unfreeze":
+		return a.unfreeze(ctx, args)
+	case "propose":
+		return a.propose(ctx, args)
+	case "approve":
+		return a.approve(ctx, args)
+	case "reject":
+		return a.reject(ctx, args)
+	case "requests":
+		return a.requests(args)
+	case "withdrawals":
+		return a.withdrawals(args)
+	case "approve-withdrawal":
+		return a.approveWithdrawal(ctx, args)
+	case "reject-withdrawal":
+		return a.rejectWithdrawal(ctx, args)
+	case "expire-withdrawals":
+		return a.expireWithdrawals(ctx)
+	case "audit":
+		return a.audit(args)
+	case "flags":
+		return a.flags(args)
+	case "addresses":
+		return a.addresses(args)
+	case "webhook":
+		return a.webhook(args)
+	}
+	return fmt.Errorf("unknown command %q", command)
+}
+
+func (a *admin) showWallet(args []string) error {
+	flags := flag.NewFlagSet("wallet", flag.ExitOnError)
+	_ = flags.Parse(args)
+	accountId, err := accountIdArg(flags)
+	if err != nil {
+		return err
+	}
+	wallet, err := a.w.GetWalletByAccountId(accountId)
+	if err != nil {
+		return fmt.Errorf("wallet %d: %w", accountId, err)
+	}
+	freeze, err := a.w.GetAccountFreeze(accountId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	view := walletView{Wallet: wallet, Items: wallet.ERC1155TokenData.Amounts(), Valid: walleter.VerifyWallet(wallet) == nil}
+	if err == nil {
+		view.Freeze = &freeze
+	}
+	return a.out.wallet(view)
+}
+
+func (a *admin) listLogs(args []string) error {
+	flags := flag.NewFlagSet("logs", flag.ExitOnError)
+	limit := flags.Int("limit", 20, "logs per page, at most 500")
+	cursor := flags.String("cursor", "", "next cursor printed by the previous page")
+	ascending := flags.Bool("asc", false, "oldest logs first")
+	_ = flags.Parse(args)
+	accountId, err := accountIdArg(flags)
+	if err != nil {
+		return err
+	}
+	page, err := a.w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, Cursor: *cursor, Limit: *limit, Ascending: *ascending})
+	if err != nil {
+		return err
+	}
+	return a.out.logs(page)
+}
+
+func (a *admin) verify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	all := flags.Bool("all", false, "verify every wallet")
+	_ = flags.Parse(args)
+	accountIds, err := accountIdArgs(flags)
+	if err != nil {
+		return err
+	}
+	if *all == (len(accountIds) > 0) {
+		return errors.New("verify needs either account ids or -all")
+	}
+
+	var results []verifyResult
+	check := func(wallet walleter.Wallet) {
+		result := verifyResult{AccountId: wallet.AccountId, Valid: true}
+		if err := walleter.VerifyWallet(wallet); err != nil {
+			result.Valid, result.Error = false, err.Error()
+		}
+		results = append(results, result)
+	}
+	if *all {
+		var wallets []walleter.Wallet
+		err = a.db.Preload("ERC20TokenData").Preload("ERC1155TokenData").
+			FindInBatches(&wallets, 200, func(tx *gorm.DB, batch int) error {
+				for _, wallet := range wallets {
+					check(wallet)
+				}
+				return nil
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	for _, accountId := range accountIds {
+		wallet, err := a.w.GetWalletByAccountId(accountId)
+		if err != nil {
+			return fmt.Errorf("wallet %d: %w", accountId, err)
+		}
+		check(wallet)
+	}
+
+	if err := a.out.verify(results); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if !result.Valid {
+			return errIssuesFound
+		}
+	}
+	return nil
+}
+
+func (a *admin) reconcile(args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	_ = flags.Parse(args)
+	accountIds, err := accountIdArgs(flags)
+	if err != nil {
+		return err
+	}
+	report, err := a.w.Reconcile(accountIds)
+	if err != nil {
+		return err
+	}
+	if err := a.out.reconciliation(report); err != nil {
+		return err
+	}
+	if len(report.Issues) > 0 {
+		return errIssuesFound
+	}
+	return nil
+}
+
+func (a *admin) freeze(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("freeze", flag.ExitOnError)
+	reason := flags.String("reason", "", "why the account is frozen, required")
+	_ = flags.Parse(args)
+	accountId, err := accountIdArg(flags)
+	if err != nil {
+		return err
+	}
+	freeze, err := a.w.FreezeAccount(ctx, accountId, a.operator, *reason)
+	if err != nil {
+		return err
+	}
+	return a.out.freeze(freeze)
+}
+
+func (a *admin) unfreeze(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("unfreeze", flag.ExitOnError)
+	reason := flags.String("reason", "", "why the account is unfrozen, required")
+	_ = flags.Parse(args)
+	accountId, err := accountIdArg(flags)
+	if err != nil {
+		return err
+	}
+	err = a.w.UnfreezeAccount(ctx, accountId, a.operator, *reason)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("account %d is not frozen", accountId)
+	}
+	if err != nil {
+		return err
+	}
+	return a.out.message(fmt.Sprintf("account %d unfrozen", accountId))
+}
+
+func (a *admin) propose(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("propose", flag.ExitOnError)
+	reason := flags.String("reason", "", "why the wallet is adjusted, required")
+	var tokens, items amountFlags
+	flags.Var(&tokens, "token", "SYMBOL=amount, negative amounts debit, repeatable")
+	flags.Var(&items, "item", "ID=amount, negative amounts debit, repeatable")
+	_ = flags.Parse(args)
+	accountId, err := accountIdArg(flags)
+	if err != nil {
+		return err
+	}
+
+	adjustment := walleter.Adjustment{
+		AccountId: accountId,
+		Operator:  a.operator,
+		Reason:    *reason,
+		Tokens:    map[walleter.ERC20TokenEnum]float64{},
+		Items:     map[uint64]int64{},
+	}
+	for _, amount := range tokens {
+		token, ok := parseToken(amount.key)
+		if !ok {
+			return fmt.Errorf("unknown token %q", amount.key)
+		}
+		value, err := strconv.ParseFloat(amount.value, 64)
+		if err != nil {
+			return fmt.Errorf("token %s: %w", amount.key, err)
+		}
+		adjustment.Tokens[token] += value
+	}
+	for _, amount := range items {
+		id, err := strconv.ParseUint(amount.key, 10, 64)
+		if err != nil {
+			return fmt.Errorf("item %s: %w", amount.key, err)
+		}
+		value, err := strconv.ParseInt(amount.value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("item %s: %w", amount.key, err)
+		}
+		adjustment.Items[id] += value
+	}
+
+	request, err := a.w.ProposeAdjustment(ctx, adjustment)
+	if err != nil {
+		return err
+	}
+	return a.out.requests([]walleter.AdjustmentRequest{request})
+}
+
+func (a *admin) approve(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("approve", flag.ExitOnError)
+	note := flags.String("note", "", "note recorded with the approval")
+	_ = flags.Parse(args)
+	id, err := requestIdArg(flags)
+	if err != nil {
+		return err
+	}
+	wallet, err := a.w.ApproveAdjustment(ctx, id, a.operator, *note)
+	if err != nil {
+		return err
+	}
+	return a.out.wallet(walletView{Wallet: wallet, Items: wallet.ERC1155TokenData.Amounts(), Valid: walleter.VerifyWallet(wallet) == nil})
+}
+
+func (a *admin) reject(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reject", flag.ExitOnError)
+	note := flags.String("note", "", "why the adjustment is rejected, required")
+	_ = flags.Parse(args)
+	id, err := requestIdArg(flags)
+	if err != nil {
+		return err
+	}
+	request, err := a.w.RejectAdjustment(ctx, id, a.operator, *note)
+	if err != nil {
+		return err
+	}
+	return a.out.requests([]walleter.AdjustmentRequest{request})
+}
+
+func (a *admin) requests(args []string) error {
+	flags := flag.NewFlagSet("requests", flag.ExitOnError)
+	status := flags.String("status", string(walleter.AdjustmentPending), "pending, approved, rejected or all")
+	_ = flags.Parse(args)
+	filter := walleter.AdjustmentStatus(*status)
+	switch filter {
+	case walleter.AdjustmentPending, walleter.AdjustmentApproved, walleter.AdjustmentRejected:
+	case "all":
+		filter = ""
+	default:
+		return fmt.Errorf("unknown status %q", *status)
+	}
+	requests, err := a.w.ListAdjustmentRequests(filter)
+	if err != nil {
+		return err
+	}
+	return a.out.requests(requests)
+}
+
+func (a *admin) withdrawals(args []string) error {
+	flags := flag.NewFlagSet("withdrawals", flag.ExitOnError)
+	status := flags.String("status", string(walleter.WithdrawalPending), "pending, approved, rejected, expired or all")
+	_ = flags.Parse(args)
+	filter := walleter.WithdrawalHoldStatus(*status)
+	switch filter {
+	case walleter.WithdrawalPending, walleter.WithdrawalApproved, walleter.WithdrawalRejected, walleter.WithdrawalExpired:
+	case "all":
+		filter = ""
+	default:
+		return fmt.Errorf("unknown status %q", *status)
+	}
+	holds, err := a.w.ListWithdrawalHolds(filter)
+	if err != nil {
+		return err
+	}
+	return a.out.withdrawals(holds)
+}
+
+func (a *admin) approveWithdrawal(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("approve-withdrawal", flag.ExitOnError)
+	_ = flags.Parse(args)
+	id, err := holdIdArg(flags)
+	if err != nil {
+		return err
+	}
+	hold, wallet, err := a.w.ApproveWithdrawal(ctx, id, a.operator)
+	if err != nil {
+		return err
+	}
+	if hold.Status == walleter.WithdrawalPending {
+		return a.out.withdrawals([]walleter.WithdrawalHold{hold})
+	}
+	return a.out.wallet(walletView{Wallet: wallet, Items: wallet.ERC1155TokenData.Amounts(), Valid: walleter.VerifyWallet(wallet) == nil})
+}
+
+func (a *admin) rejectWithdrawal(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reject-withdrawal", flag.ExitOnError)
+	reason := flags.String("reason", "", "why the withdrawal is rejected, required")
+	_ = flags.Parse(args)
+	id, err := holdIdArg(flags)
+	if err != nil {
+		return err
+	}
+	hold, err := a.w.RejectWithdrawal(ctx, id, a.operator, *reason)
+	if err != nil {
+		return err
+	}
+	return a.out.withdrawals([]walleter.WithdrawalHold{hold})
+}
+
+func (a *admin) expireWithdrawals(ctx context.Context) error {
+	expired, err := a.w.ExpireWithdrawals(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.message(fmt.Sprintf("%d withdrawals expired", expired))
+}
+
+func (a *admin) audit(args []string) error {
+	flags := flag.NewFlagSet("audit", flag.ExitOnError)
+	_ = flags.Parse(args)
+	accountId, err := accountIdArg(flags)
+	if err != nil {
+		return err
+	}
+	logs, err := a.w.ListAuditLogs(accountId)
+	if err != nil {
+		return err
+	}
+	return a.out.audit(logs)
+}
+
+func (a *admin) flags(args []string) error {
+	flags := flag.NewFlagSet("flags", flag.ExitOnError)
+	_ = flags.Parse(args)
+	if flags.NArg() > 1 {
+		return errors.New("flags takes at most one account id")
+	}
+	accountIds, err := accountIdArgs(flags)
+	if err != nil {
+		return err
+	}
+	var accountId uint64
+	if len(accountIds) == 1 {
+		accountId = accountIds[0]
+	}
+	accountFlags, err := a.w.ListAccountFlags(accountId)
+	if err != nil {
+		return err
+	}
+	return a.out.flags(accountFlags)
+}
+
+func (a *admin) addresses(args []string) error {
+	flags := flag.NewFlagSet("addresses", flag.ExitOnError)
+	chainName := flags.String("chain", "", "ethereum, goerli_testnet, bsc or bsc_testnet, required")
+	_ = flags.Parse(args)
+	accountId, err := accountIdArg(flags)
+	if err != nil {
+		return err
+	}
+	for _, chain := range []walleter.CommandSourceType{walleter.Ethereum, walleter.GoerliTestnet, walleter.BSC, walleter.BSCTestnet} {
+		if chain.String() == *chainName {
+			addresses, err := a.w.ListDepositAddresses(accountId, chain)
+			if err != nil {
+				return err
+			}
+			return a.out.addresses(addresses)
+		}
+	}
+	return fmt.Errorf("unsupported chain %q", *chainName)
+}
+
+func (a *admin) webhook(args []string) error {
+	if len(args) == 0 {
+		return errors.New("webhook needs endpoints, deliveries, replay or replay-events")
+	}
+	webhooks := a.w.NewWebhookService(walleter.WebhookConfig{})
+	switch args[0] {
+	case "endpoints":
+		endpoints, err := webhooks.ListEndpoints()
+		if err != nil {
+			return err
+		}
+		return a.out.endpoints(endpoints)
+	case "deliveries":
+		flags := flag.NewFlagSet("webhook deliveries", flag.ExitOnError)
+		status := flags.String("status", "", "pending, delivered or dead, all by default")
+		limit := flags.Int("limit", 20, "latest deliveries listed")
+		_ = flags.Parse(args[1:])
+		switch *status {
+		case "", walleter.OutboxPending.String(), walleter.OutboxDelivered.String(), walleter.OutboxDeadLetter.String():
+		default:
+			return fmt.Errorf("unknown status %q", *status)
+		}
+		id, err := idArg(flags, "endpoint")
+		if err != nil {
+			return err
+		}
+		deliveries, err := webhooks.ListDeliveries(id, *status, *limit)
+		if err != nil {
+			return err
+		}
+		return a.out.deliveries(deliveries)
+	case "replay":
+		flags := flag.NewFlagSet("webhook replay", flag.ExitOnError)
+		_ = flags.Parse(args[1:])
+		id, err := idArg(flags, "delivery")
+		if err != nil {
+			return err
+		}
+		err = webhooks.ReplayDelivery(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("delivery %d not found", id)
+		}
+		if err != nil {
+			return err
+		}
+		return a.out.message(fmt.Sprintf("delivery %d queued again", id))
+	case "replay-events":
+		flags := flag.NewFlagSet("webhook replay-events", flag.ExitOnError)
+		since := flags.String("since", "", "RFC 3339 time of the oldest event replayed, required")
+		_ = flags.Parse(args[1:])
+		id, err := idArg(flags, "endpoint")
+		if err != nil {
+			return err
+		}
+		from, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			return fmt.Errorf("invalid -since %q: %w", *since, err)
+		}
+		replayed, err := webhooks.ReplayEvents(id, from)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("endpoint %d not found", id)
+		}
+		if err != nil {
+			return err
+		}
+		return a.out.message(fmt.Sprintf("%d deliveries queued", replayed))
+	}
+	return fmt.Errorf("unknown webhook command %q", args[0])
+}
+
+// amountFlags repeated KEY=amount flags, kept as text until the key tells how to parse the amount.
+type amountFlags []struct{ key, value string }
+
+func (f *amountFlags) String() string {
+	var parts []string
+	for _, amount := range *f {
+		parts = append(parts, amount.key+"="+amount.value)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f *amountFlags) Set(value string) error {
+	key, amount, ok := strings.Cut(value, "=")
+	if !ok || key == "" || amount == "" {
+		return fmt.Errorf("%q is not KEY=amount", value)
+	}
+	*f = append(*f, struct{ key, value string }{key, amount})
+	return nil
+}
+
+func accountIdArg(flags *flag.FlagSet) (uint64, error) {
+	if flags.NArg() != 1 {
+		return 0, fmt.Errorf("%s needs one account id", flags.Name())
+	}
+	accountIds, err := accountIdArgs(flags)
+	if err != nil {
+		return 0, err
+	}
+	return accountIds[0], nil
+}
+
+func requestIdArg(flags *flag.FlagSet) (uint, error) {
+	return idArg(flags, "request")
+}
+
+func holdIdArg(flags *flag.FlagSet) (uint, error) {
+	return idArg(flags, "hold")
+}
+
+// idArg the single record id argument of flags, named kind in errors.
+func idArg(flags *flag.FlagSet, kind string) (uint, error) {
+	if flags.NArg() != 1 {
+		return 0, fmt.Errorf("%s needs one %s id", flags.Name(), kind)
+	}
+	id, err := strconv.ParseUint(flags.Arg(0), 10, 0)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid %s id %q", kind, flags.Arg(0))
+	}
+	return uint(id), nil
+}
+
+func accountIdArgs(flags *flag.FlagSet) ([]uint64, error) {
+	var result []uint64
+	for _, arg := range flags.Args() {
+		accountId, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil || accountId == 0 {
+			return nil, fmt.Errorf("invalid account id %q", arg)
+		}
+		result = append(result, accountId)
+	}
+	return result, nil
+}
+
+func parseToken(symbol string) (walleter.ERC20TokenEnum, bool) {
+	for token := walleter.ETH; token <= walleter.FISHX; token++ {
+		if strings.EqualFold(token.String(), symbol) {
+			return token, true
+		}
+	}
+	return 0, false
+}
+
+func currentUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
+
+func envOr(name string, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+// walletView a wallet as shown to operators.
+type walletView struct {
+	Wallet walleter.Wallet         `json:"wallet"`
+	Items  []walleter.ItemAmount   `json:"items"`
+	Valid  bool                    `json:"check_sign_valid"`
+	Freeze *walleter.AccountFreeze `json:"freeze,omitempty"`
+}
+
+type verifyResult struct {
+	AccountId uint64 `json:"account_id"`
+	Valid     bool   `json:"valid"`
+	Error     string `json:"error,omitempty"`
+}
+
+// output prints the results of the commands, as tables for people or JSON for scripts.
+type output interface {
+	wallet(view walletView) error
+	logs(page walleter.WalletLogPage) error
+	verify(results []verifyResult) error
+	reconciliation(report walleter.ReconciliationReport) error
+	freeze(freeze walleter.AccountFreeze) error
+	audit(logs []walleter.AuditLog) error
+	requests(requests []walleter.AdjustmentRequest) error
+	withdrawals(holds []walleter.WithdrawalHold) error
+	flags(flags []walleter.AccountFlag) error
+	addresses(addresses []walleter.DepositAddress) error
+	endpoints(endpoints []walleter.WebhookEndpoint) error
+	deliveries(deliveries []walleter.WebhookDelivery) error
+	message(text string) error
+}
+
+func newOutput(format string) (output, error) {
+	switch format {
+	case "table":
+		return tableOutput{out: os.Stdout}, nil
+	case "json":
+		return jsonOutput{out: os.Stdout}, nil
+	}
+	return nil, fmt.Errorf("unsupported output format %q", format)
+}
+
+type jsonOutput struct {
+	out io.Writer
+}
+
+func (o jsonOutput) write(v interface{}) error {
+	encoder := json.NewEncoder(o.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (o jsonOutput) wallet(view walletView) error               { return o.write(view) }
+func (o jsonOutput) logs(page walleter.WalletLogPage) error     { return o.write(page) }
+func (o jsonOutput) verify(results []verifyResult) error        { return o.write(results) }
+func (o jsonOutput) freeze(freeze walleter.AccountFreeze) error { return o.write(freeze) }
+func (o jsonOutput) audit(logs []walleter.AuditLog) error       { return o.write(logs) }
+
+func (o jsonOutput) requests(requests []walleter.AdjustmentRequest) error {
+	return o.write(requests)
+}
+
+func (o jsonOutput) withdrawals(holds []walleter.WithdrawalHold) error {
+	return o.write(holds)
+}
+
+func (o jsonOutput) flags(flags []walleter.AccountFlag) error {
+	return o.write(flags)
+}
+
+func (o jsonOutput) addresses(addresses []walleter.DepositAddress) error {
+	return o.write(addresses)
+}
+
+func (o jsonOutput) endpoints(endpoints []walleter.WebhookEndpoint) error {
+	return o.write(endpoints)
+}
+
+func (o jsonOutput) deliveries(deliveries []walleter.WebhookDelivery) error {
+	return o.write(deliveries)
+}
+
+func (o jsonOutput) reconciliation(report walleter.ReconciliationReport) error {
+	return o.write(report)
+}
+
+func (o jsonOutput) message(text string) error {
+	return o.write(map[string]string{"message": text})
+}
+
+type tableOutput struct {
+	out io.Writer
+}
+
+// table prints rows under header, aligned in columns.
+func (o tableOutput) table(header []string, rows [][]string) error {
+	writer := tabwriter.NewWriter(o.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+func (o tableOutput) wallet(view walletView) error {
+	status := "active"
+	if view.Freeze != nil {
+		status = fmt.Sprintf("frozen by %s at %s: %s", view.Freeze.Operator, formatTime(view.Freeze.CreatedAt), view.Freeze.Reason)
+	}
+	checkSign := "valid"
+	if !view.Valid {
+		checkSign = "INVALID"
+	}
+	fmt.Fprintf(o.out, "account %d, %s, check sign %s\n\n", view.Wallet.AccountId, status, checkSign)
+
+	var rows [][]string
+	for _, token := range view.Wallet.ERC20TokenData {
+		rows = append(rows, []string{token.Token, formatAmount(token.Balance), formatAmount(token.Locked), formatAmount(token.TotalIncome),
+			formatAmount(token.TotalSpend), formatAmount(token.TotalDeposit), formatAmount(token.TotalWithdraw), formatAmount(token.TotalFee)})
+	}
+	if err := o.table([]string{"TOKEN", "BALANCE", "LOCKED", "INCOME", "SPEND", "DEPOSIT", "WITHDRAW", "FEE"}, rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(o.out)
+	rows = nil
+	for _, item := range view.Items {
+		rows = append(rows, []string{fmt.Sprint(item.Id), fmt.Sprint(item.Amount)})
+	}
+	return o.table([]string{"ITEM", "AMOUNT"}, rows)
+}
+
+func (o tableOutput) logs(page walleter.WalletLogPage) error {
+	var rows [][]string
+	for _, entry := range page.Entries {
+		var amounts []string
+		for _, token := range entry.Tokens {
+			amounts = append(amounts, fmt.Sprintf("%s %s", formatAmount(token.Amount), token.Token))
+		}
+		for _, item := range entry.Items {
+			amounts = append(amounts, fmt.Sprintf("%d x#%d", item.Amount, item.Id))
+		}
+		var fees []string
+		for _, fee := range entry.Fees {
+			fees = append(fees, fmt.Sprintf("%s %s", formatAmount(fee.Amount), fee.Token))
+		}
+		status := entry.Status
+		if entry.FailureDetail != nil {
+			status += ": " + string(entry.FailureDetail.Code)
+		}
+		rows = append(rows, []string{fmt.Sprint(entry.Id), formatTime(entry.CreatedAt), entry.AssetType.String(), entry.ActionType,
+			entry.BusinessModule, entry.Source, status, strings.Join(amounts, ", "), strings.Join(fees, ", ")})
+	}
+	err := o.table([]string{"ID", "CREATED", "ASSET", "ACTION", "MODULE", "SOURCE", "STATUS", "AMOUNTS", "FEES"}, rows)
+	if err != nil || page.NextCursor == "" {
+		return err
+	}
+	_, err = fmt.Fprintf(o.out, "\nnext page: -cursor %s\n", page.NextCursor)
+	return err
+}
+
+func (o tableOutput) verify(results []verifyResult) error {
+	var rows [][]string
+	invalid := 0
+	for _, result := range results {
+		if result.Valid {
+			continue
+		}
+		invalid++
+		rows = append(rows, []string{fmt.Sprint(result.AccountId), result.Error})
+	}
+	if invalid > 0 {
+		if err := o.table([]string{"ACCOUNT", "ERROR"}, rows); err != nil {
+			return err
+		}
+		fmt.Fprintln(o.out)
+	}
+	_, err := fmt.Fprintf(o.out, "%d wallets verified, %d invalid\n", len(results), invalid)
+	return err
+}
+
+func (o tableOutput) reconciliation(report walleter.ReconciliationReport) error {
+	if len(report.Issues) > 0 {
+		var rows [][]string
+		for _, issue := range report.Issues {
+			asset := issue.Token
+			if issue.ItemId != 0 {
+				asset = fmt.Sprintf("#%d", issue.ItemId)
+			}
+			rows = append(rows, []string{fmt.Sprint(issue.AccountId), string(issue.Kind), asset,
+				formatAmount(issue.Expected), formatAmount(issue.Actual), issue.Message})
+		}
+		if err := o.table([]string{"ACCOUNT", "KIND", "ASSET", "EXPECTED", "ACTUAL", "MESSAGE"}, rows); err != nil {
+			return err
+		}
+		fmt.Fprintln(o.out)
+	}
+	_, err := fmt.Fprintf(o.out, "%d wallets reconciled, %d issues\n", report.Wallets, len(report.Issues))
+	return err
+}
+
+func (o tableOutput) freeze(freeze walleter.AccountFreeze) error {
+	_, err := fmt.Fprintf(o.out, "account %d frozen by %s at %s: %s\n", freeze.AccountId, freeze.Operator,
+		formatTime(freeze.CreatedAt), freeze.Reason)
+	return err
+}
+
+func (o tableOutput) audit(logs []walleter.AuditLog) error {
+	var rows [][]string
+	for _, log := range logs {
+		rows = append(rows, []string{fmt.Sprint(log.ID), formatTime(log.CreatedAt), log.Operator, string(log.Action),
+			formatAdjustment(log.Detail), log.Reason})
+	}
+	return o.table([]string{"ID", "AT", "OPERATOR", "ACTION", "AMOUNTS", "REASON"}, rows)
+}
+
+func (o tableOutput) requests(requests []walleter.AdjustmentRequest) error {
+	var rows [][]string
+	for _, request := range requests {
+		review := ""
+		if request.ReviewedAt != nil {
+			review = fmt.Sprintf("%s at %s", request.ReviewedBy, formatTime(*request.ReviewedAt))
+			if request.ReviewNote != "" {
+				review += ": " + request.ReviewNote
+			}
+		}
+		rows = append(rows, []string{fmt.Sprint(request.ID), fmt.Sprint(request.AccountId), formatTime(request.CreatedAt),
+			request.ProposedBy, string(request.Status), formatAdjustment(request.Amounts), request.Reason, review})
+	}
+	return o.table([]string{"ID", "ACCOUNT", "PROPOSED", "BY", "STATUS", "AMOUNTS", "REASON", "REVIEW"}, rows)
+}
+
+func (o tableOutput) message(text string) error {
+	_, err := fmt.Fprintln(o.out, text)
+	return err
+}
+
+func (o tableOutput) withdrawals(holds []walleter.WithdrawalHold) error {
+	var rows [][]string
+	for _, hold := range holds {
+		expires := "never"
+		if hold.ExpiresAt != nil {
+			expires = formatTime(*hold.ExpiresAt)
+		}
+		resolution := ""
+		if hold.ResolvedAt != nil {
+			resolution = formatTime(*hold.ResolvedAt)
+			if hold.ResolvedBy != "" {
+				resolution = fmt.Sprintf("%s at %s", hold.ResolvedBy, resolution)
+			}
+			if hold.Reason != "" {
+				resolution += ": " + hold.Reason
+			}
+		}
+		rows = append(rows, []string{fmt.Sprint(hold.ID), fmt.Sprint(hold.AccountId), formatTime(hold.CreatedAt),
+			formatHeldAmounts(hold.Amounts), string(hold.Status),
+			fmt.Sprintf("%d/%d", hold.Approvals, hold.RequiredApprovals), expires, resolution})
+	}
+	return o.table([]string{"ID", "ACCOUNT", "HELD", "AMOUNTS", "STATUS", "APPROVALS", "EXPIRES", "RESOLUTION"}, rows)
+}
+
+func (o tableOutput) flags(flags []walleter.AccountFlag) error {
+	var rows [][]string
+	for _, flag := range flags {
+		deposit := ""
+		if flag.DepositId != 0 {
+			deposit = fmt.Sprint(flag.DepositId)
+		}
+		rows = append(rows, []string{fmt.Sprint(flag.ID), fmt.Sprint(flag.AccountId), formatTime(flag.CreatedAt),
+			string(flag.Reason), deposit, formatAdjustment(flag.Shortfall)})
+	}
+	return o.table([]string{"ID", "ACCOUNT", "FLAGGED", "REASON", "DEPOSIT", "SHORTFALL"}, rows)
+}
+
+func (o tableOutput) addresses(addresses []walleter.DepositAddress) error {
+	var rows [][]string
+	for _, address := range addresses {
+		retired := ""
+		if address.RetiredAt != nil {
+			retired = formatTime(*address.RetiredAt)
+		}
+		rows = append(rows, []string{address.Chain, address.Address, formatTime(address.AssignedAt), retired})
+	}
+	return o.table([]string{"CHAIN", "ADDRESS", "ASSIGNED", "RETIRED"}, rows)
+}
+
+func (o tableOutput) endpoints(endpoints []walleter.WebhookEndpoint) error {
+	var rows [][]string
+	for _, endpoint := range endpoints {
+		rows = append(rows, []string{fmt.Sprint(endpoint.ID), endpoint.Name, endpoint.URL, fmt.Sprint(endpoint.Active),
+			strings.Join(endpoint.Filter.EventTypes, ",")})
+	}
+	return o.table([]string{"ID", "NAME", "URL", "ACTIVE", "EVENTS"}, rows)
+}
+
+func (o tableOutput) deliveries(deliveries []walleter.WebhookDelivery) error {
+	var rows [][]string
+	for _, delivery := range deliveries {
+		next := ""
+		if delivery.Status == walleter.OutboxPending.String() {
+			next = formatTime(delivery.NextAttemptAt)
+		}
+		rows = append(rows, []string{fmt.Sprint(delivery.ID), fmt.Sprint(delivery.EventId), delivery.EventType,
+			formatTime(delivery.CreatedAt), delivery.Status, fmt.Sprint(delivery.Attempts), next, delivery.LastError})
+	}
+	return o.table([]string{"ID", "EVENT", "TYPE", "CREATED", "STATUS", "ATTEMPTS", "NEXT ATTEMPT", "LAST ERROR"}, rows)
+}
+
+// formatAdjustment prints signed amounts, sorted so that the output is stable.
+func formatAdjustment(amounts walleter.AdjustmentAmounts) string {
+	var result []string
+	for token, value := range amounts.Tokens {
+		result = append(result, fmt.Sprintf("%+g %s", value, token))
+	}
+	for id, value := range amounts.Items {
+		result = append(result, fmt.Sprintf("%+d x#%d", value, id))
+	}
+	sort.Strings(result)
+	return strings.Join(result, ", ")
+}
+
+func formatHeldAmounts(amounts walleter.HeldAmounts) string {
+	var result []string
+	for token, value := range amounts {
+		result = append(result, fmt.Sprintf("%s %s", formatAmount(value), token))
+	}
+	sort.Strings(result)
+	return strings.Join(result, ", ")
+}
+
+func formatAmount(value float64) string {
+	return fmt.Sprintf("%g", value)
+}
+
+func formatTime(at time.Time) string {
+	return at.UTC().Format(time.RFC3339)
+}
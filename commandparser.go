@@ -42,6 +42,8 @@ func parseCommandToERC20WalletLog(command WalletCommand, w Wallet) ERC20WalletLo
 		Status:         Pending.String(),
 		OriginalWallet: w,
 		Source:         command.CommandSource.String(),
+		Approval:       command.Approval,
+		ChainRef:       command.ChainRef,
 		SettledWallet:  Wallet{},
 	}
 }
@@ -59,6 +61,8 @@ func parseCommandToERC1155WalletLog(command WalletCommand, w Wallet) ERC1155Wall
 		Fees:           erc20TokenCollection{Items: fees},
 		Status:         Pending.String(),
 		Source:         command.CommandSource.String(),
+		Approval:       command.Approval,
+		ChainRef:       command.ChainRef,
 		OriginalWallet: w,
 		SettledWallet:  Wallet{},
 	}
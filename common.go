@@ -8,6 +8,18 @@ const (
 	Other            = 2
 )
 
+func (t AssetType) String() string {
+	switch t {
+	case ERC20AssetType:
+		return "erc20"
+	case ERC1155AssetType:
+		return "erc1155"
+	case Other:
+		return "other"
+	}
+	return "unknown"
+}
+
 // ERC20TokenEnum ERC20 token name currently supported.
 type ERC20TokenEnum int
 
@@ -106,6 +118,14 @@ const (
 
 	// ChargeFee will perform subtraction operation in game database.
 	ChargeFee WalletActionType = 5
+
+	// RevertDeposit takes back a chain deposit whose block was reorganized out. Only the confirmation of chain
+	// deposits issues it, the balance may become negative.
+	RevertDeposit WalletActionType = 6
+
+	// Deduct takes assets out of a wallet for an approved adjustment, counted as spent. Only ApproveAdjustment
+	// issues it.
+	Deduct WalletActionType = 7
 )
 
 func (t WalletActionType) String() string {
@@ -122,6 +142,10 @@ func (t WalletActionType) String() string {
 		return "withdraw"
 	case ChargeFee:
 		return "fee"
+	case RevertDeposit:
+		return "revert_deposit"
+	case Deduct:
+		return "deduct"
 	}
 	return "unknown"
 }
@@ -133,6 +157,8 @@ const (
 	Pending WalletLogStatus = 0
 	Done    WalletLogStatus = 1
 	Failed  WalletLogStatus = 2
+	// Held a withdrawal waiting for approvals, its amounts are locked until it is approved, rejected or expires.
+	Held WalletLogStatus = 3
 )
 
 func (s WalletLogStatus) String() string {
@@ -143,6 +169,8 @@ func (s WalletLogStatus) String() string {
 		return "done"
 	case Failed:
 		return "failed"
+	case Held:
+		return "held"
 	}
 	return "unknown"
 }
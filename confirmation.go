@@ -0,0 +1,353 @@
+package walleter
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DepositStatus the stage of a chain deposit on its way to being irreversible.
+type DepositStatus string
+
+const (
+	// DepositPending waits for the confirmations of its chain, it is not credited yet.
+	DepositPending DepositStatus = "pending"
+	// DepositCredited credited, but still watched for reorganizations until it is final.
+	DepositCredited DepositStatus = "credited"
+	// DepositFinal credited and deep enough in its chain to be considered irreversible.
+	DepositFinal DepositStatus = "final"
+	// DepositDropped its block was reorganized out before the deposit was credited.
+	DepositDropped DepositStatus = "dropped"
+	// DepositReverted its block was reorganized out after the deposit was credited, the credit was taken back.
+	DepositReverted DepositStatus = "reverted"
+)
+
+// ConfirmationPolicy how deep the block of a deposit must be in its chain. Confirmations count the block of
+// the deposit itself: a deposit is credited once Confirmations canonical blocks, its own included, are mined.
+// Credited deposits are watched for reorganizations until Finality confirmations, Confirmations when lower.
+// The zero policy credits deposits as soon as they are ingested and never watches them.
+type ConfirmationPolicy struct {
+	Confirmations uint64
+	Finality      uint64
+}
+
+// status the status of a deposit with confirmations under p.
+func (p ConfirmationPolicy) status(confirmations uint64) DepositStatus {
+	switch {
+	case confirmations < p.Confirmations:
+		return DepositPending
+	case confirmations < p.Finality:
+		return DepositCredited
+	}
+	return DepositFinal
+}
+
+// AccountFlagReason why an account was flagged for review.
+type AccountFlagReason string
+
+// AccountFlagRevertedDeposit a reverted deposit was spent before it was taken back.
+const AccountFlagRevertedDeposit AccountFlagReason = "reverted_deposit"
+
+// AccountFlag marks an account for review. The Shortfall of a reverted deposit is what the wallet lacked when
+// it was taken back: ERC20 balances went negative by the token amounts, the items were not taken back at all.
+type AccountFlag struct {
+	gorm.Model `swagger-ignore:"true"`
+	AccountId  uint64            `json:"account_id" gorm:"not null;index"`
+	Reason     AccountFlagReason `json:"reason" gorm:"type:varchar(32);not null"`
+	DepositId  uint              `json:"deposit_id,omitempty"`
+	Shortfall  AdjustmentAmounts `json:"shortfall"`
+}
+
+// ConfirmationReport what a call of ProcessConfirmations changed.
+type ConfirmationReport struct {
+	Credited  int `json:"credited"`
+	Finalized int `json:"finalized"`
+	Dropped   int `json:"dropped"`
+	Reverted  int `json:"reverted"`
+}
+
+// BlockFeed reports the canonical blocks of chains, e.g. from a node of each chain.
+type BlockFeed interface {
+	// Head returns the number of the latest canonical block of chain.
+	Head(ctx context.Context, chain CommandSourceType) (uint64, error)
+	// BlockHash returns the hash of the canonical block number of chain, empty when there is none.
+	BlockHash(ctx context.Context, chain CommandSourceType, number uint64) (string, error)
+}
+
+// ProcessConfirmations moves the pending and credited deposits along with the chains of feed: deposits are
+// credited once they reach the confirmations of their chain and become final at its finality. A deposit whose
+// block was reorganized out is dropped when it was still pending, and reverted when it was credited: the credit
+// is taken back even if that makes the balance negative, in which case the account is flagged, see AccountFlag.
+// Deposits changed by another instance meanwhile are skipped.
+func (s *Walleter) ProcessConfirmations(ctx context.Context, feed BlockFeed) (ConfirmationReport, error) {
+	var report ConfirmationReport
+	repo := s.repo.WithContext(ctx)
+	store, err := storeOf[ChainDepositStore](repo)
+	if err != nil {
+		return report, err
+	}
+	deposits, err := store.ListIngestedDeposits(0, []DepositStatus{DepositPending, DepositCredited})
+	if err != nil {
+		return report, err
+	}
+
+	heads := map[CommandSourceType]uint64{}
+	for _, deposit := range deposits {
+		chain := deposit.source()
+		head, ok := heads[chain]
+		if !ok {
+			if head, err = feed.Head(ctx, chain); err != nil {
+				return report, err
+			}
+			heads[chain] = head
+		}
+		// the feed has not reached the block yet
+		if head < deposit.BlockNumber {
+			continue
+		}
+		hash, err := feed.BlockHash(ctx, chain, deposit.BlockNumber)
+		if err != nil {
+			return report, err
+		}
+
+		status := s.confirmations[chain].status(head - deposit.BlockNumber + 1)
+		if !strings.EqualFold(hash, deposit.BlockHash) {
+			status = DepositDropped
+			if deposit.Status == DepositCredited {
+				status = DepositReverted
+			}
+		}
+		if status == deposit.Status || status == DepositPending {
+			continue
+		}
+		changed, err := s.advanceDeposit(repo, deposit, status)
+		if err != nil {
+			return report, err
+		}
+		if !changed {
+			continue
+		}
+		s.logger.Info("chain deposit "+string(status), "chain", deposit.Chain, "tx_hash", deposit.TxHash,
+			"log_index", deposit.LogIndex, "account_id", deposit.AccountId)
+		report.add(deposit.Status, status)
+	}
+	return report, nil
+}
+
+// RunConfirmationWorker calls ProcessConfirmations every interval until ctx is cancelled.
+func (s *Walleter) RunConfirmationWorker(ctx context.Context, feed BlockFeed, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.ProcessConfirmations(ctx, feed); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListIngestedDeposits returns the deposits of accountId with one of statuses, oldest first. Every account
+// is listed when accountId is 0, every status when statuses is empty.
+func (s *Walleter) ListIngestedDeposits(accountId uint64, statuses ...DepositStatus) ([]IngestedDeposit, error) {
+	store, err := storeOf[ChainDepositStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return store.ListIngestedDeposits(accountId, statuses)
+}
+
+// ListAccountFlags returns the flags of accountId, of every account when it is 0, oldest first.
+func (s *Walleter) ListAccountFlags(accountId uint64) ([]AccountFlag, error) {
+	store, err := storeOf[ChainDepositStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return store.ListAccountFlags(accountId)
+}
+
+// advanceDeposit moves deposit to status, crediting or reverting it as needed. It reports false when the
+// deposit was moved by another instance meanwhile.
+func (s *Walleter) advanceDeposit(repo Repository, deposit IngestedDeposit, status DepositStatus) (bool, error) {
+	changed := false
+	err := repo.Transaction(func(tx Repository) error {
+		store, err := storeOf[ChainDepositStore](tx)
+		if err != nil {
+			return err
+		}
+		next := deposit
+		next.Status = status
+		ok, err := store.UpdateIngestedDeposit(next, deposit.Status)
+		if err != nil || !ok {
+			return err
+		}
+		changed = true
+		switch {
+		case status == DepositReverted:
+			return s.revertDeposit(tx, deposit)
+		case deposit.Status == DepositPending && status != DepositDropped:
+			_, err = s.handleCommand(tx, deposit.command(Deposit), true)
+			return err
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// revertDeposit takes the credit of deposit back. ERC20 balances may become negative, while items which are
+// not in the wallet anymore cannot be taken back. Either way the account is flagged with what is missing.
+func (s *Walleter) revertDeposit(tx Repository, deposit IngestedDeposit) error {
+	wallet, err := tx.GetWallet(deposit.AccountId)
+	if err != nil {
+		return err
+	}
+	command := deposit.command(RevertDeposit)
+	shortfall := AdjustmentAmounts{}
+	if deposit.AssetType == ERC1155AssetType {
+		ids := convertStringToUIntArray(wallet.ERC1155TokenData.Ids)
+		values := convertStringToUIntArray(wallet.ERC1155TokenData.Values)
+		var taken ERC1155Command
+		for index, id := range command.ERC1155Command.Ids {
+			value := command.ERC1155Command.Values[index]
+			var held uint64
+			if i := indexOfArray(ids, id); i != -1 {
+				held = values[i]
+			}
+			if held < value {
+				if shortfall.Items == nil {
+					shortfall.Items = map[uint64]int64{}
+				}
+				shortfall.Items[id] = int64(value - held)
+				value = held
+			}
+			if value > 0 {
+				taken.Ids = append(taken.Ids, id)
+				taken.Values = append(taken.Values, value)
+			}
+		}
+		command.ERC1155Command = taken
+	} else {
+		token, _ := erc20TokenOf(deposit.Token)
+		_, tokenWallet := getUserSpecifiedERC20TokenWallet(wallet, token)
+		if missing := math.Min(deposit.Amount, deposit.Amount-tokenWallet.Balance); missing > 0 {
+			shortfall.Tokens = map[string]float64{deposit.Token: missing}
+		}
+	}
+
+	if deposit.AssetType == ERC20AssetType || len(command.ERC1155Command.Ids) > 0 {
+		if _, err := s.handleCommand(tx, command, true); err != nil {
+			return err
+		}
+	}
+	if len(shortfall.Tokens) == 0 && len(shortfall.Items) == 0 {
+		return nil
+	}
+	s.logger.Warn("reverted chain deposit was spent, account flagged", "account_id", deposit.AccountId,
+		"chain", deposit.Chain, "tx_hash", deposit.TxHash, "log_index", deposit.LogIndex)
+	store, err := storeOf[ChainDepositStore](tx)
+	if err != nil {
+		return err
+	}
+	_, err = store.InsertAccountFlag(AccountFlag{
+		AccountId: deposit.AccountId,
+		Reason:    AccountFlagRevertedDeposit,
+		DepositId: deposit.ID,
+		Shortfall: shortfall,
+	})
+	return err
+}
+
+func (report *ConfirmationReport) add(from DepositStatus, to DepositStatus) {
+	switch to {
+	case DepositDropped:
+		report.Dropped++
+	case DepositReverted:
+		report.Reverted++
+	case DepositCredited:
+		report.Credited++
+	case DepositFinal:
+		if from == DepositPending {
+			report.Credited++
+		}
+		report.Finalized++
+	}
+}
+
+// MemoryBlockFeed a BlockFeed kept in memory, meant for tests. SetBlock mines blocks, replacing the hash of
+// a block models a reorganization.
+type MemoryBlockFeed struct {
+	mu     sync.Mutex
+	heads  map[CommandSourceType]uint64
+	hashes map[CommandSourceType]map[uint64]string
+}
+
+// NewMemoryBlockFeed returns a MemoryBlockFeed without blocks.
+func NewMemoryBlockFeed() *MemoryBlockFeed {
+	return &MemoryBlockFeed{heads: map[CommandSourceType]uint64{}, hashes: map[CommandSourceType]map[uint64]string{}}
+}
+
+// SetBlock makes hash the canonical block number of chain, which becomes the head if it is above it.
+func (f *MemoryBlockFeed) SetBlock(chain CommandSourceType, number uint64, hash string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.hashes[chain] == nil {
+		f.hashes[chain] = map[uint64]string{}
+	}
+	f.hashes[chain][number] = hash
+	if number > f.heads[chain] {
+		f.heads[chain] = number
+	}
+}
+
+// SetHead makes number the head of chain, forgetting the blocks above it.
+func (f *MemoryBlockFeed) SetHead(chain CommandSourceType, number uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.heads[chain] = number
+	for block := range f.hashes[chain] {
+		if block > number {
+			delete(f.hashes[chain], block)
+		}
+	}
+}
+
+func (f *MemoryBlockFeed) Head(ctx context.Context, chain CommandSourceType) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.heads[chain], nil
+}
+
+func (f *MemoryBlockFeed) BlockHash(ctx context.Context, chain CommandSourceType, number uint64) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.hashes[chain][number], nil
+}
+
+type accountFlagDAO struct{}
+
+var flagDAO = &accountFlagDAO{}
+
+func (dao accountFlagDAO) insertFlag(db *gorm.DB, flag AccountFlag) (AccountFlag, error) {
+	err := db.Create(&flag).Error
+	return flag, err
+}
+
+func (dao accountFlagDAO) listFlags(db *gorm.DB, accountId uint64) (result []AccountFlag, err error) {
+	query := db.Order("id")
+	if accountId != 0 {
+		query = query.Where("account_id = ?", accountId)
+	}
+	err = query.Find(&result).Error
+	return result, err
+}
@@ -0,0 +1,433 @@
+package walleter
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ChainDepositBusinessModule the business module of the commands crediting chain deposits.
+const ChainDepositBusinessModule = "ChainDeposit"
+
+var (
+	hashPattern    = regexp.MustCompile(`^0x[0-9a-f]{64}$`)
+	addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+)
+
+// ChainDeposit a transfer to a deposit address observed on chain by an indexer. Chain, TxHash and LogIndex
+// identify it: a deposit reported again with the same identity is credited once.
+// ERC20 deposits set Token and Amount, ERC1155 deposits set Ids and Values. BlockNumber and BlockHash locate
+// the transfer, they are required on chains with a ConfirmationPolicy.
+type ChainDeposit struct {
+	Chain       CommandSourceType
+	TxHash      string
+	LogIndex    uint
+	BlockNumber uint64
+	BlockHash   string
+	Address     string
+	AssetType   AssetType
+	Token       ERC20TokenEnum
+	Amount      float64
+	Ids         []uint64
+	Values      []uint64
+}
+
+// ChainReference the on-chain transfer a command credits, recorded in its logs.
+type ChainReference struct {
+	Chain    string `json:"chain"`
+	TxHash   string `json:"tx_hash"`
+	LogIndex uint   `json:"log_index"`
+	Address  string `json:"address"`
+}
+
+func (ChainReference) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (r ChainReference) Value() (driver.Value, error) {
+	b, err := json.Marshal(r)
+	return string(b), err
+}
+
+func (r *ChainReference) Scan(input interface{}) error {
+	return scanJSON(input, r)
+}
+
+// IngestedDeposit a chain deposit of an account, its unique index dedupes replays of the indexer.
+// Status tells whether it is credited yet, see ProcessConfirmations.
+type IngestedDeposit struct {
+	gorm.Model  `swagger-ignore:"true"`
+	Chain       string        `json:"chain" gorm:"type:varchar(20);not null;uniqueIndex:idx_ingested_deposit_identity"`
+	TxHash      string        `json:"tx_hash" gorm:"type:varchar(66);not null;uniqueIndex:idx_ingested_deposit_identity"`
+	LogIndex    uint          `json:"log_index" gorm:"not null;uniqueIndex:idx_ingested_deposit_identity"`
+	BlockNumber uint64        `json:"block_number,omitempty" gorm:"not null;default:0"`
+	BlockHash   string        `json:"block_hash,omitempty" gorm:"type:varchar(66)"`
+	Status      DepositStatus `json:"status" gorm:"type:varchar(20);not null;default:final;index"`
+	Address     string        `json:"address" gorm:"type:varchar(42);not null;index"`
+	AccountId   uint64        `json:"account_id" gorm:"not null;index"`
+	AssetType   AssetType     `json:"asset_type" gorm:"not null"`
+	Token       string        `json:"token" gorm:"type:varchar(20)"`
+	Amount      float64       `json:"amount"`
+	Ids         string        `json:"ids"`
+	Values      string        `json:"values"`
+}
+
+// DepositResult the outcome of IngestDeposit. Duplicate deposits were ingested before, Wallet is then
+// the current wallet of the account.
+type DepositResult struct {
+	Deposit   IngestedDeposit `json:"deposit"`
+	Wallet    Wallet          `json:"wallet"`
+	Duplicate bool            `json:"duplicate"`
+}
+
+// DepositAddressResolver maps deposit addresses to accounts. It returns ErrUnknownDepositAddress for an
+// address which belongs to no account.
+type DepositAddressResolver interface {
+	AccountOfDepositAddress(ctx context.Context, chain CommandSourceType, address string) (uint64, error)
+}
+
+// DepositAddressResolverFunc a function used as DepositAddressResolver.
+type DepositAddressResolverFunc func(ctx context.Context, chain CommandSourceType, address string) (uint64, error)
+
+func (f DepositAddressResolverFunc) AccountOfDepositAddress(ctx context.Context, chain CommandSourceType, address string) (uint64, error) {
+	return f(ctx, chain, address)
+}
+
+// IngestDeposit credits deposit to the account of its address, once per on-chain identity: a deposit already
+// ingested is reported as Duplicate without changing the wallet. Chain deposits already happened, so they are
+// credited to frozen accounts as well. The log of the command records the ChainReference of the deposit.
+// On chains with a ConfirmationPolicy the deposit stays pending until ProcessConfirmations credits it.
+// A deposit which was dropped or reverted and is reported again from another block is pending again.
+func (s *Walleter) IngestDeposit(ctx context.Context, deposit ChainDeposit) (DepositResult, error) {
+	record, err := deposit.record()
+	if err != nil {
+		return DepositResult{}, err
+	}
+	record.Status = s.confirmations[deposit.Chain].status(0)
+	if record.Status != DepositFinal && (record.BlockNumber == 0 || record.BlockHash == "") {
+		return DepositResult{}, fmt.Errorf("%w: the block is required on %s", ErrInvalidDeposit, deposit.Chain)
+	}
+	repo := s.repo.WithContext(ctx)
+	store, err := storeOf[ChainDepositStore](repo)
+	if err != nil {
+		return DepositResult{}, err
+	}
+	record.AccountId, err = s.resolveDepositAddress(repo, deposit.Chain, record.Address)
+	if err != nil {
+		return DepositResult{}, err
+	}
+
+	var wallet Wallet
+	err = repo.Transaction(func(tx Repository) error {
+		store, err := storeOf[ChainDepositStore](tx)
+		if err != nil {
+			return err
+		}
+		_, err = store.GetIngestedDeposit(record.Chain, record.TxHash, record.LogIndex)
+		if err == nil {
+			return ErrDuplicateDeposit
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if record, err = store.InsertIngestedDeposit(record); err != nil {
+			return err
+		}
+		wallet, err = s.creditDeposit(tx, record)
+		return err
+	})
+	if !errors.Is(err, ErrDuplicateDeposit) {
+		return DepositResult{Deposit: record, Wallet: wallet}, err
+	}
+
+	existing, err := store.GetIngestedDeposit(record.Chain, record.TxHash, record.LogIndex)
+	if err != nil {
+		return DepositResult{}, err
+	}
+	if !existing.sameTransfer(record) {
+		return DepositResult{}, fmt.Errorf("%w: %s %s:%d was ingested with different content", ErrInvalidDeposit,
+			record.Chain, record.TxHash, record.LogIndex)
+	}
+	if existing.reincludedIn(record) {
+		moved := existing
+		moved.BlockNumber, moved.BlockHash, moved.Status = record.BlockNumber, record.BlockHash, record.Status
+		reincluded := false
+		err = repo.Transaction(func(tx Repository) error {
+			store, err := storeOf[ChainDepositStore](tx)
+			if err != nil {
+				return err
+			}
+			ok, err := store.UpdateIngestedDeposit(moved, existing.Status)
+			if err != nil || !ok {
+				return err
+			}
+			reincluded = true
+			wallet, err = s.creditDeposit(tx, moved)
+			return err
+		})
+		if err != nil {
+			return DepositResult{}, err
+		}
+		if reincluded {
+			return DepositResult{Deposit: moved, Wallet: wallet}, nil
+		}
+		// moved by another instance meanwhile
+		if existing, err = store.GetIngestedDeposit(record.Chain, record.TxHash, record.LogIndex); err != nil {
+			return DepositResult{}, err
+		}
+	}
+	wallet, err = repo.GetWallet(existing.AccountId)
+	return DepositResult{Deposit: existing, Wallet: wallet, Duplicate: true}, err
+}
+
+// DepositSource delivers the deposits observed by an indexer. A deposit may be delivered more than once,
+// e.g. when the indexer replays blocks.
+type DepositSource interface {
+	// NextDeposits waits for deposits until ctx is done. It returns io.EOF once the source is exhausted.
+	NextDeposits(ctx context.Context) ([]ChainDeposit, error)
+}
+
+// RunDepositIngestion ingests the deposits of source until ctx is cancelled or source is exhausted.
+// Deposits which can never be credited, invalid ones or those to unknown addresses, are logged and skipped;
+// other errors stop the ingestion so that the deposits are delivered again once it is restarted.
+func (s *Walleter) RunDepositIngestion(ctx context.Context, source DepositSource) error {
+	for {
+		deposits, err := source.NextDeposits(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, deposit := range deposits {
+			result, err := s.IngestDeposit(ctx, deposit)
+			keyvals := []interface{}{"chain", deposit.Chain.String(), "tx_hash", deposit.TxHash, "log_index", deposit.LogIndex}
+			switch {
+			case errors.Is(err, ErrInvalidDeposit), errors.Is(err, ErrUnknownDepositAddress):
+				s.logger.Warn("chain deposit skipped", append(keyvals, "error", err)...)
+			case err != nil:
+				return err
+			case result.Duplicate:
+				s.logger.Debug("chain deposit already ingested", keyvals...)
+			default:
+				s.logger.Info("chain deposit ingested", append(keyvals, "account_id", result.Deposit.AccountId)...)
+			}
+		}
+	}
+}
+
+// MemoryDepositSource a DepositSource fed by Publish, meant for tests and for replaying deposits by hand.
+type MemoryDepositSource struct {
+	mu      sync.Mutex
+	batches [][]ChainDeposit
+	closed  bool
+	ready   chan struct{}
+}
+
+// NewMemoryDepositSource returns an empty MemoryDepositSource.
+func NewMemoryDepositSource() *MemoryDepositSource {
+	return &MemoryDepositSource{ready: make(chan struct{}, 1)}
+}
+
+// Publish delivers deposits as one batch.
+func (s *MemoryDepositSource) Publish(deposits ...ChainDeposit) {
+	s.mu.Lock()
+	s.batches = append(s.batches, deposits)
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Close exhausts the source once the published batches are delivered.
+func (s *MemoryDepositSource) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *MemoryDepositSource) NextDeposits(ctx context.Context) ([]ChainDeposit, error) {
+	for {
+		s.mu.Lock()
+		if len(s.batches) > 0 {
+			batch := s.batches[0]
+			s.batches = s.batches[1:]
+			s.mu.Unlock()
+			return batch, nil
+		}
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return nil, io.EOF
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.ready:
+		}
+	}
+}
+
+func (s *MemoryDepositSource) notify() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// record validates deposit and normalizes its identity: hashes and addresses are stored lower case.
+func (deposit ChainDeposit) record() (IngestedDeposit, error) {
+	invalid := func(reason string) (IngestedDeposit, error) {
+		return IngestedDeposit{}, fmt.Errorf("%w: %s", ErrInvalidDeposit, reason)
+	}
+	switch deposit.Chain {
+	case Ethereum, GoerliTestnet, BSC, BSCTestnet:
+	default:
+		return invalid(fmt.Sprintf("unsupported chain %s", deposit.Chain))
+	}
+	record := IngestedDeposit{
+		Chain:       deposit.Chain.String(),
+		TxHash:      strings.ToLower(strings.TrimSpace(deposit.TxHash)),
+		LogIndex:    deposit.LogIndex,
+		BlockNumber: deposit.BlockNumber,
+		BlockHash:   strings.ToLower(strings.TrimSpace(deposit.BlockHash)),
+		Address:     strings.ToLower(strings.TrimSpace(deposit.Address)),
+		AssetType:   deposit.AssetType,
+	}
+	if !hashPattern.MatchString(record.TxHash) {
+		return invalid(fmt.Sprintf("malformed tx hash %q", deposit.TxHash))
+	}
+	if record.BlockHash != "" && !hashPattern.MatchString(record.BlockHash) {
+		return invalid(fmt.Sprintf("malformed block hash %q", deposit.BlockHash))
+	}
+	if !addressPattern.MatchString(record.Address) {
+		return invalid(fmt.Sprintf("malformed address %q", deposit.Address))
+	}
+
+	switch deposit.AssetType {
+	case ERC20AssetType:
+		if _, ok := erc20TokenOf(deposit.Token.String()); !ok {
+			return invalid(fmt.Sprintf("unsupported token %d", deposit.Token))
+		}
+		if !(deposit.Amount > 0) {
+			return invalid("amount must be positive")
+		}
+		record.Token = deposit.Token.String()
+		record.Amount = deposit.Amount
+	case ERC1155AssetType:
+		if len(deposit.Ids) == 0 || len(deposit.Ids) != len(deposit.Values) {
+			return invalid("ids and values must pair up")
+		}
+		for _, value := range deposit.Values {
+			if value == 0 {
+				return invalid("values must be positive")
+			}
+		}
+		record.Ids = convertArrayToString(deposit.Ids, ",")
+		record.Values = convertArrayToString(deposit.Values, ",")
+	default:
+		return invalid(fmt.Sprintf("unsupported asset type %s", deposit.AssetType))
+	}
+	return record, nil
+}
+
+// creditDeposit credits d unless it waits for confirmations, returning the wallet of its account.
+func (s *Walleter) creditDeposit(tx Repository, d IngestedDeposit) (Wallet, error) {
+	if d.Status == DepositPending {
+		return tx.GetWallet(d.AccountId)
+	}
+	return s.handleCommand(tx, d.command(Deposit), true)
+}
+
+// command the command of action, Deposit or RevertDeposit, on the assets of the deposit.
+func (d IngestedDeposit) command(action WalletActionType) WalletCommand {
+	var command WalletCommand
+	if d.AssetType == ERC1155AssetType {
+		command = NewERC1155WalletCommand(d.AccountId, action, ChainDepositBusinessModule, d.source(),
+			convertStringToUIntArray(d.Ids), convertStringToUIntArray(d.Values), nil)
+	} else {
+		token, _ := erc20TokenOf(d.Token)
+		command = NewERC20WalletCommand(d.AccountId, action, ChainDepositBusinessModule, d.source(),
+			map[ERC20TokenEnum]float64{token: d.Amount}, nil)
+	}
+	command.ChainRef = &ChainReference{Chain: d.Chain, TxHash: d.TxHash, LogIndex: d.LogIndex, Address: d.Address}
+	return command
+}
+
+// source the CommandSourceType of the chain of d.
+func (d IngestedDeposit) source() CommandSourceType {
+	for source := Ethereum; source <= BSCTestnet; source++ {
+		if source.String() == d.Chain {
+			return source
+		}
+	}
+	return InGame
+}
+
+// sameTransfer reports whether d and other credit the same assets to the same account.
+func (d IngestedDeposit) sameTransfer(other IngestedDeposit) bool {
+	return d.AccountId == other.AccountId && d.AssetType == other.AssetType && d.Token == other.Token &&
+		d.Amount == other.Amount && d.Ids == other.Ids && d.Values == other.Values
+}
+
+// reincludedIn reports whether the transfer of d, which is not credited now, was mined again in the block of other:
+// a reorganization may drop a transaction from one block and include it in another one.
+func (d IngestedDeposit) reincludedIn(other IngestedDeposit) bool {
+	switch d.Status {
+	case DepositPending, DepositDropped, DepositReverted:
+		return other.BlockHash != "" && other.BlockHash != d.BlockHash
+	}
+	return false
+}
+
+type ingestedDepositDAO struct{}
+
+var depositDAO = &ingestedDepositDAO{}
+
+func (dao ingestedDepositDAO) getDeposit(db *gorm.DB, chain string, txHash string, logIndex uint) (deposit IngestedDeposit, err error) {
+	err = db.Where("chain = ? AND tx_hash = ? AND log_index = ?", chain, txHash, logIndex).First(&deposit).Error
+	return deposit, err
+}
+
+func (dao ingestedDepositDAO) insertDeposit(db *gorm.DB, deposit IngestedDeposit) (IngestedDeposit, error) {
+	if err := db.Create(&deposit).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return IngestedDeposit{}, ErrDuplicateDeposit
+		}
+		return IngestedDeposit{}, err
+	}
+	return deposit, nil
+}
+
+// updateDeposit saves the status and block of deposit if its status is still from, reporting whether it was.
+func (dao ingestedDepositDAO) updateDeposit(db *gorm.DB, deposit IngestedDeposit, from DepositStatus) (bool, error) {
+	result := db.Model(&IngestedDeposit{}).
+		Where("id = ? AND status = ?", deposit.ID, from).
+		Updates(map[string]interface{}{
+			"status":       deposit.Status,
+			"block_number": deposit.BlockNumber,
+			"block_hash":   deposit.BlockHash,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (dao ingestedDepositDAO) listDeposits(db *gorm.DB, accountId uint64, statuses []DepositStatus) (result []IngestedDeposit, err error) {
+	query := db.Order("id")
+	if accountId != 0 {
+		query = query.Where("account_id = ?", accountId)
+	}
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+	err = query.Find(&result).Error
+	return result, err
+}
@@ -0,0 +1,293 @@
+package walleter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+	"gorm.io/gorm"
+)
+
+// DepositAddress an address of an account on a chain. Each account has at most one current address per chain,
+// assigning another one retires it. Retired addresses still belong to the account: deposits sent to them
+// are credited to it.
+type DepositAddress struct {
+	gorm.Model `swagger-ignore:"true"`
+	AccountId  uint64     `json:"account_id" gorm:"not null;index:idx_deposit_address_account"`
+	Chain      string     `json:"chain" gorm:"type:varchar(20);not null;index:idx_deposit_address_account;uniqueIndex:idx_deposit_address_identity"`
+	Address    string     `json:"address" gorm:"type:varchar(42);not null;uniqueIndex:idx_deposit_address_identity"`
+	AssignedAt time.Time  `json:"assigned_at" gorm:"not null"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+// ImportedDepositAddress an address, current or retired, of the account-to-address mapping kept elsewhere.
+type ImportedDepositAddress struct {
+	AccountId  uint64
+	Chain      CommandSourceType
+	Address    string
+	AssignedAt time.Time
+	RetiredAt  *time.Time
+}
+
+// AssignDepositAddress makes address the current deposit address of accountId on chain, retiring the previous
+// one. Addresses are stored in their EIP-55 form, mixed case ones must carry a valid checksum. It fails with
+// ErrDepositAddressTaken when address belongs to another account and gorm.ErrRecordNotFound when accountId has
+// no wallet; a retired address of the account becomes current again.
+func (s *Walleter) AssignDepositAddress(ctx context.Context, accountId uint64, chain CommandSourceType, address string) (DepositAddress, error) {
+	address, err := normalizeDepositAddress(chain, address)
+	if err != nil {
+		return DepositAddress{}, err
+	}
+	var result DepositAddress
+	err = s.repo.WithContext(ctx).Transaction(func(tx Repository) error {
+		store, err := storeOf[DepositAddressStore](tx)
+		if err != nil {
+			return err
+		}
+		result, err = assignDepositAddress(store, DepositAddress{AccountId: accountId, Chain: chain.String(), Address: address,
+			AssignedAt: time.Now()})
+		return err
+	})
+	return result, err
+}
+
+// ImportDepositAddresses stores addresses with their history, e.g. to move the mapping kept by another
+// service into walleter. Addresses already imported are skipped, an imported current address retires the
+// current one of its account at its AssignedAt and needs the account to have a wallet. It returns the number
+// of addresses stored; addresses are validated first, so nothing is stored when one of them is invalid or
+// belongs to another account.
+func (s *Walleter) ImportDepositAddresses(ctx context.Context, addresses []ImportedDepositAddress) (int, error) {
+	var records []DepositAddress
+	for _, item := range addresses {
+		address, err := normalizeDepositAddress(item.Chain, item.Address)
+		if err != nil {
+			return 0, err
+		}
+		if item.AccountId == 0 || item.AssignedAt.IsZero() || (item.RetiredAt != nil && item.RetiredAt.Before(item.AssignedAt)) {
+			return 0, fmt.Errorf("%w: %s needs an account and a retirement after its assignment", ErrInvalidDepositAddress, address)
+		}
+		records = append(records, DepositAddress{AccountId: item.AccountId, Chain: item.Chain.String(), Address: address,
+			AssignedAt: item.AssignedAt, RetiredAt: item.RetiredAt})
+	}
+
+	imported := 0
+	err := s.repo.WithContext(ctx).Transaction(func(tx Repository) error {
+		store, err := storeOf[DepositAddressStore](tx)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			existing, err := store.GetDepositAddress(record.Chain, record.Address)
+			if err == nil {
+				if existing.AccountId != record.AccountId {
+					return fmt.Errorf("%w: %s of account %d", ErrDepositAddressTaken, record.Address, existing.AccountId)
+				}
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if record.RetiredAt == nil {
+				if _, err := assignDepositAddress(store, record); err != nil {
+					return err
+				}
+			} else if _, err := store.InsertDepositAddress(record); err != nil {
+				return err
+			}
+			imported++
+		}
+		return nil
+	})
+	return imported, err
+}
+
+// GetDepositAddress returns the current deposit address of accountId on chain, gorm.ErrRecordNotFound when
+// it has none.
+func (s *Walleter) GetDepositAddress(accountId uint64, chain CommandSourceType) (DepositAddress, error) {
+	addresses, err := s.ListDepositAddresses(accountId, chain)
+	if err != nil {
+		return DepositAddress{}, err
+	}
+	for _, address := range addresses {
+		if address.RetiredAt == nil {
+			return address, nil
+		}
+	}
+	return DepositAddress{}, gorm.ErrRecordNotFound
+}
+
+// ListDepositAddresses returns every deposit address accountId had on chain, retired ones included, in the
+// order they were assigned.
+func (s *Walleter) ListDepositAddresses(accountId uint64, chain CommandSourceType) ([]DepositAddress, error) {
+	store, err := storeOf[DepositAddressStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return store.ListDepositAddresses(accountId, chain.String())
+}
+
+// AccountOfDepositAddress returns the account address belongs to on chain, current or retired, and
+// ErrUnknownDepositAddress when it belongs to none. The registry of a Walleter resolves the addresses of
+// deposits unless WithDepositAddressResolver replaces it.
+func (s *Walleter) AccountOfDepositAddress(ctx context.Context, chain CommandSourceType, address string) (uint64, error) {
+	return s.accountOfDepositAddress(s.repo.WithContext(ctx), chain, address)
+}
+
+// resolveDepositAddress returns the account of address on chain with the DepositAddressResolver of s,
+// the registry on repo by default.
+func (s *Walleter) resolveDepositAddress(repo Repository, chain CommandSourceType, address string) (uint64, error) {
+	if s.depositAddresses != nil {
+		return s.depositAddresses.AccountOfDepositAddress(repo.Context(), chain, address)
+	}
+	return s.accountOfDepositAddress(repo, chain, address)
+}
+
+func (s *Walleter) accountOfDepositAddress(repo Repository, chain CommandSourceType, address string) (uint64, error) {
+	normalized, err := normalizeDepositAddress(chain, address)
+	if err != nil {
+		return 0, err
+	}
+	store, err := storeOf[DepositAddressStore](repo)
+	if err != nil {
+		return 0, err
+	}
+	record, err := store.GetDepositAddress(chain.String(), normalized)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("%w: %s on %s", ErrUnknownDepositAddress, normalized, chain)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return record.AccountId, nil
+}
+
+// resolveCommandAccount sets the AccountId of a Deposit command referencing a deposit address instead.
+func (s *Walleter) resolveCommandAccount(repo Repository, command WalletCommand) (WalletCommand, error) {
+	if command.DepositAddress == "" {
+		return command, nil
+	}
+	if command.ActionType != Deposit {
+		return command, newWalletError(ErrActionTypeNotSupport, command.AccountId)
+	}
+	accountId, err := s.resolveDepositAddress(repo, command.CommandSource, command.DepositAddress)
+	if err != nil {
+		return command, err
+	}
+	if command.AccountId != 0 && command.AccountId != accountId {
+		return command, fmt.Errorf("%w: %s belongs to account %d, not %d", ErrDepositAddressTaken,
+			command.DepositAddress, accountId, command.AccountId)
+	}
+	command.AccountId = accountId
+	return command, nil
+}
+
+// assignDepositAddress stores address as the current address of its account, retiring the current one at
+// its AssignedAt. The wallet of the account stays locked until tx ends, so concurrent assignments cannot
+// both find no current address and leave the account with two.
+func assignDepositAddress(tx DepositAddressStore, address DepositAddress) (DepositAddress, error) {
+	if err := tx.LockWallet(address.AccountId); err != nil {
+		return DepositAddress{}, err
+	}
+	existing, err := tx.GetDepositAddress(address.Chain, address.Address)
+	switch {
+	case err == nil && existing.AccountId != address.AccountId:
+		return DepositAddress{}, fmt.Errorf("%w: %s of account %d", ErrDepositAddressTaken, address.Address, existing.AccountId)
+	case err == nil && existing.RetiredAt == nil:
+		return existing, nil
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return DepositAddress{}, err
+	}
+
+	history, err := tx.ListDepositAddresses(address.AccountId, address.Chain)
+	if err != nil {
+		return DepositAddress{}, err
+	}
+	for _, current := range history {
+		if current.RetiredAt != nil {
+			continue
+		}
+		retiredAt := address.AssignedAt
+		current.RetiredAt = &retiredAt
+		if err := tx.UpdateDepositAddress(current); err != nil {
+			return DepositAddress{}, err
+		}
+	}
+	if existing.ID != 0 {
+		existing.AssignedAt, existing.RetiredAt = address.AssignedAt, nil
+		return existing, tx.UpdateDepositAddress(existing)
+	}
+	return tx.InsertDepositAddress(address)
+}
+
+// normalizeDepositAddress validates address and returns its EIP-55 form. Addresses in a single case carry no
+// checksum, mixed case ones must match theirs.
+func normalizeDepositAddress(chain CommandSourceType, address string) (string, error) {
+	switch chain {
+	case Ethereum, GoerliTestnet, BSC, BSCTestnet:
+	default:
+		return "", fmt.Errorf("%w: unsupported chain %s", ErrInvalidDepositAddress, chain)
+	}
+	address = strings.TrimSpace(address)
+	if !addressPattern.MatchString(address) {
+		return "", fmt.Errorf("%w: malformed address %q", ErrInvalidDepositAddress, address)
+	}
+	checksummed := checksumAddress(address)
+	digits := address[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && address != checksummed {
+		return "", fmt.Errorf("%w: checksum of %s does not match, expected %s", ErrInvalidDepositAddress, address, checksummed)
+	}
+	return checksummed, nil
+}
+
+// checksumAddress returns the EIP-55 form of a well formed address: the hex letters are upper case where
+// the nibble of the Keccak-256 hash of the lower case address at the same position is 8 or more.
+func checksumAddress(address string) string {
+	digits := strings.ToLower(address[2:])
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(digits))
+	digest := hash.Sum(nil)
+
+	result := []byte(digits)
+	for i, c := range result {
+		nibble := digest[i/2] >> 4
+		if i%2 == 1 {
+			nibble = digest[i/2] & 0x0f
+		}
+		if c >= 'a' && nibble >= 8 {
+			result[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(result)
+}
+
+type depositAddressDAO struct{}
+
+var addressDAO = &depositAddressDAO{}
+
+func (dao depositAddressDAO) insertAddress(db *gorm.DB, address DepositAddress) (DepositAddress, error) {
+	if err := db.Create(&address).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return DepositAddress{}, fmt.Errorf("%w: %s", ErrDepositAddressTaken, address.Address)
+		}
+		return DepositAddress{}, err
+	}
+	return address, nil
+}
+
+func (dao depositAddressDAO) getAddress(db *gorm.DB, chain string, address string) (result DepositAddress, err error) {
+	err = db.Where("chain = ? AND address = ?", chain, address).First(&result).Error
+	return result, err
+}
+
+func (dao depositAddressDAO) updateAddress(db *gorm.DB, address DepositAddress) error {
+	return db.Model(&DepositAddress{}).Where("id = ?", address.ID).
+		Updates(map[string]interface{}{"assigned_at": address.AssignedAt, "retired_at": address.RetiredAt}).Error
+}
+
+func (dao depositAddressDAO) listAddresses(db *gorm.DB, accountId uint64, chain string) (result []DepositAddress, err error) {
+	err = db.Where("account_id = ? AND chain = ?", accountId, chain).Order("assigned_at").Order("id").Find(&result).Error
+	return result, err
+}
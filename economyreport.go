@@ -0,0 +1,364 @@
+package walleter
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	economyReportDayFormat = "2006-01-02"
+	economyAggregateBatch  = 500
+
+	erc20LogAggregateCursor   = "erc20_wallet_logs"
+	erc1155LogAggregateCursor = "erc1155_wallet_logs"
+)
+
+var errAggregateCursorMoved = errors.New("aggregate cursor moved by another refresh")
+
+// EconomyDailyAggregate daily totals of one token for one action, business module and source.
+// Rows are built from Done logs by RefreshEconomyAggregates, fees are aggregated under the fee token.
+type EconomyDailyAggregate struct {
+	gorm.Model     `swagger-ignore:"true"`
+	Day            string  `json:"day" gorm:"type:varchar(10);not null;uniqueIndex:idx_economy_daily_aggregate"`
+	Token          string  `json:"token" gorm:"type:varchar(32);not null;uniqueIndex:idx_economy_daily_aggregate"`
+	ActionType     string  `json:"action_type" gorm:"type:varchar(64);not null;uniqueIndex:idx_economy_daily_aggregate"`
+	BusinessModule string  `json:"business_module" gorm:"type:varchar(64);not null;uniqueIndex:idx_economy_daily_aggregate"`
+	Source         string  `json:"source" gorm:"type:varchar(20);not null;uniqueIndex:idx_economy_daily_aggregate"`
+	Income         float64 `json:"income"`
+	Spend          float64 `json:"spend"`
+	Deposit        float64 `json:"deposit"`
+	Withdraw       float64 `json:"withdraw"`
+	Fee            float64 `json:"fee"`
+	Count          uint64  `json:"count"`
+}
+
+// EconomyAggregateCursor the last log id of a log table folded into EconomyDailyAggregate.
+type EconomyAggregateCursor struct {
+	Name      string `gorm:"type:varchar(64);primaryKey"`
+	LastLogId uint   `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// EconomyAggregateUnsettled a log the cursor of its table passed while it was Pending or Held. It is folded
+// into EconomyDailyAggregate by the first refresh after it settles.
+type EconomyAggregateUnsettled struct {
+	CursorName string `gorm:"type:varchar(64);primaryKey"`
+	LogId      uint   `gorm:"primaryKey;autoIncrement:false"`
+	CreatedAt  time.Time
+}
+
+func (EconomyAggregateUnsettled) TableName() string {
+	return "economy_aggregate_unsettled"
+}
+
+// ReportDimension a column economy reports can be grouped by.
+type ReportDimension string
+
+const (
+	DayDimension            ReportDimension = "day"
+	TokenDimension          ReportDimension = "token"
+	ActionTypeDimension     ReportDimension = "action_type"
+	BusinessModuleDimension ReportDimension = "business_module"
+	CommandSourceDimension  ReportDimension = "source"
+)
+
+// EconomyReportQuery selects aggregates between From and To (both days inclusive) and groups them by GroupBy.
+type EconomyReportQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy []ReportDimension
+}
+
+// EconomyReportRow one group of an economy report, dimensions which are not grouped are left empty.
+type EconomyReportRow struct {
+	Day            string  `json:"day,omitempty"`
+	Token          string  `json:"token,omitempty"`
+	ActionType     string  `json:"action_type,omitempty"`
+	BusinessModule string  `json:"business_module,omitempty"`
+	Source         string  `json:"source,omitempty"`
+	Income         float64 `json:"income"`
+	Spend          float64 `json:"spend"`
+	Deposit        float64 `json:"deposit"`
+	Withdraw       float64 `json:"withdraw"`
+	Fee            float64 `json:"fee"`
+	Count          uint64  `json:"count"`
+}
+
+// RefreshEconomyAggregates folds the logs settled since the previous refresh into the daily aggregate table.
+// Logs which are still Pending or Held are passed over and remembered, they are folded by the first refresh
+// after they settle, so a stalled command never holds back the aggregates. Concurrent refreshes of one log
+// table are safe, the one losing the race on its cursor does nothing; refreshes of both tables add to the
+// same aggregate rows with atomic upserts.
+func (s *Walleter) RefreshEconomyAggregates() error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+	for {
+		erc20Count, err := refreshAggregatesFrom(db, erc20LogAggregateCursor, func(query *gorm.DB) ([]WalletLogEntry, error) {
+			var logs []ERC20WalletLog
+			err := query.Select("id", "created_at", "business_module", "action_type", "source", "tokens", "fees", "status").
+				Find(&logs).Error
+			var result []WalletLogEntry
+			for _, item := range logs {
+				result = append(result, item.toEntry())
+			}
+			return result, err
+		})
+		if err != nil {
+			return err
+		}
+		erc1155Count, err := refreshAggregatesFrom(db, erc1155LogAggregateCursor, func(query *gorm.DB) ([]WalletLogEntry, error) {
+			var logs []ERC1155WalletLog
+			err := query.Select("id", "created_at", "business_module", "action_type", "source", "ids", "values", "fees", "status").
+				Find(&logs).Error
+			var result []WalletLogEntry
+			for _, item := range logs {
+				result = append(result, item.toEntry())
+			}
+			return result, err
+		})
+		if err != nil {
+			return err
+		}
+		if erc20Count < economyAggregateBatch && erc1155Count < economyAggregateBatch {
+			return nil
+		}
+	}
+}
+
+// QueryEconomyReport sums the daily aggregates of the query range grouped by the requested dimensions.
+func (s *Walleter) QueryEconomyReport(query EconomyReportQuery) ([]EconomyReportRow, error) {
+	var columns []string
+	for _, dimension := range query.GroupBy {
+		switch dimension {
+		case DayDimension, TokenDimension, ActionTypeDimension, BusinessModuleDimension, CommandSourceDimension:
+			columns = append(columns, string(dimension))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownReportDimension, dimension)
+		}
+	}
+
+	selects := append([]string{}, columns...)
+	selects = append(selects,
+		"SUM(income) AS income", "SUM(spend) AS spend", "SUM(deposit) AS deposit",
+		"SUM(withdraw) AS withdraw", "SUM(fee) AS fee", "SUM(count) AS count",
+	)
+	db, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+	db = db.Model(&EconomyDailyAggregate{}).Select(strings.Join(selects, ", "))
+	if !query.From.IsZero() {
+		db = db.Where("day >= ?", query.From.UTC().Format(economyReportDayFormat))
+	}
+	if !query.To.IsZero() {
+		db = db.Where("day <= ?", query.To.UTC().Format(economyReportDayFormat))
+	}
+	if len(columns) > 0 {
+		db = db.Group(strings.Join(columns, ", ")).Order(strings.Join(columns, ", "))
+	}
+
+	var rows []EconomyReportRow
+	err = db.Scan(&rows).Error
+	return rows, err
+}
+
+type aggregateKey struct {
+	day            string
+	token          string
+	actionType     string
+	businessModule string
+	source         string
+}
+
+func (k aggregateKey) less(other aggregateKey) bool {
+	if k.day != other.day {
+		return k.day < other.day
+	}
+	if k.token != other.token {
+		return k.token < other.token
+	}
+	if k.actionType != other.actionType {
+		return k.actionType < other.actionType
+	}
+	if k.businessModule != other.businessModule {
+		return k.businessModule < other.businessModule
+	}
+	return k.source < other.source
+}
+
+// refreshAggregatesFrom folds the logs of one table which settled since they were passed over and one batch of
+// logs after the cursor into the aggregates, and returns how many logs of the batch were read. load finds the
+// logs of query.
+func refreshAggregatesFrom(db *gorm.DB, cursorName string, load func(query *gorm.DB) ([]WalletLogEntry, error)) (int, error) {
+	count := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cursor := EconomyAggregateCursor{Name: cursorName}
+		if err := tx.FirstOrCreate(&cursor, EconomyAggregateCursor{Name: cursorName}).Error; err != nil {
+			return err
+		}
+		deltas := map[aggregateKey]*EconomyDailyAggregate{}
+
+		var unsettledIds []uint
+		err := tx.Model(&EconomyAggregateUnsettled{}).Where("cursor_name = ?", cursorName).Order("log_id").
+			Pluck("log_id", &unsettledIds).Error
+		if err != nil {
+			return err
+		}
+		if len(unsettledIds) > 0 {
+			unsettled, err := load(tx.Where("id IN ?", unsettledIds).Order("id"))
+			if err != nil {
+				return err
+			}
+			for _, entry := range unsettled {
+				if !logSettled(entry.Status) {
+					continue
+				}
+				result := tx.Where("cursor_name = ? AND log_id = ?", cursorName, entry.Id).Delete(&EconomyAggregateUnsettled{})
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return errAggregateCursorMoved
+				}
+				if entry.Status == Done.String() {
+					addEntryToAggregates(deltas, entry)
+				}
+			}
+		}
+
+		entries, err := load(tx.Where("id > ?", cursor.LastLogId).Order("id").Limit(economyAggregateBatch))
+		if err != nil {
+			return err
+		}
+		count = len(entries)
+		lastLogId := cursor.LastLogId
+		for _, entry := range entries {
+			lastLogId = entry.Id
+			if !logSettled(entry.Status) {
+				err := tx.Create(&EconomyAggregateUnsettled{CursorName: cursorName, LogId: entry.Id}).Error
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if entry.Status == Done.String() {
+				addEntryToAggregates(deltas, entry)
+			}
+		}
+		if lastLogId != cursor.LastLogId {
+			result := tx.Model(&EconomyAggregateCursor{}).
+				Where("name = ? AND last_log_id = ?", cursorName, cursor.LastLogId).
+				Updates(map[string]interface{}{"last_log_id": lastLogId, "updated_at": time.Now()})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errAggregateCursorMoved
+			}
+		}
+
+		// rows are upserted in key order, so that refreshes of both tables lock them in the same order
+		keys := make([]aggregateKey, 0, len(deltas))
+		for key := range deltas {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].less(keys[j])
+		})
+		for _, key := range keys {
+			if err := addToAggregate(tx, key, *deltas[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errAggregateCursorMoved) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// addToAggregate adds delta to the aggregate row of key in one statement, inserting the row when it does not exist.
+func addToAggregate(tx *gorm.DB, key aggregateKey, delta EconomyDailyAggregate) error {
+	row := delta
+	row.Day = key.day
+	row.Token = key.token
+	row.ActionType = key.actionType
+	row.BusinessModule = key.businessModule
+	row.Source = key.source
+	add := func(column string, value interface{}) clause.Expr {
+		return gorm.Expr("economy_daily_aggregates."+column+" + ?", value)
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}, {Name: "token"}, {Name: "action_type"}, {Name: "business_module"}, {Name: "source"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"income":     add("income", delta.Income),
+			"spend":      add("spend", delta.Spend),
+			"deposit":    add("deposit", delta.Deposit),
+			"withdraw":   add("withdraw", delta.Withdraw),
+			"fee":        add("fee", delta.Fee),
+			"count":      add("count", delta.Count),
+			"updated_at": time.Now(),
+		}),
+	}).Create(&row).Error
+}
+
+// logSettled reports whether a log of status reached its final status, Pending and Held logs may still become Done.
+func logSettled(status string) bool {
+	return status != Pending.String() && status != Held.String()
+}
+
+func addEntryToAggregates(deltas map[aggregateKey]*EconomyDailyAggregate, entry WalletLogEntry) {
+	if entry.ActionType == Initialize.String() {
+		return
+	}
+	day := entry.CreatedAt.UTC().Format(economyReportDayFormat)
+	get := func(token string) *EconomyDailyAggregate {
+		key := aggregateKey{day, token, entry.ActionType, entry.BusinessModule, entry.Source}
+		if deltas[key] == nil {
+			deltas[key] = &EconomyDailyAggregate{}
+		}
+		return deltas[key]
+	}
+	add := func(row *EconomyDailyAggregate, amount float64) {
+		switch entry.ActionType {
+		case Income.String():
+			row.Income += amount
+		case Spend.String(), Deduct.String():
+			row.Spend += amount
+		case Deposit.String():
+			row.Deposit += amount
+		case RevertDeposit.String():
+			row.Deposit -= amount
+		case Withdraw.String():
+			row.Withdraw += amount
+		case ChargeFee.String():
+			row.Fee += amount
+		}
+		row.Count++
+	}
+
+	for _, token := range entry.Tokens {
+		add(get(token.Token), token.Amount)
+	}
+	for _, item := range entry.Items {
+		add(get(erc1155AggregateToken(item.Id)), float64(item.Amount))
+	}
+	for _, fee := range entry.Fees {
+		row := get(fee.Token)
+		row.Fee += fee.Amount
+	}
+}
+
+// erc1155AggregateToken the token name under which an ERC1155 id is aggregated.
+func erc1155AggregateToken(id uint64) string {
+	return fmt.Sprintf("ERC1155#%d", id)
+}
@@ -1,46 +1,109 @@
 package walleter
 
-import (
-	"gorm.io/gorm"
-)
+import "errors"
 
-func handleERC1155Command(db *gorm.DB, command WalletCommand) (Wallet, error) {
+func handleERC1155Command(repo Repository, command WalletCommand) (Wallet, error) {
 	if len(command.ERC1155Command.Values) != len(command.ERC1155Command.Ids) {
-		return Wallet{}, ErrIncorrectERC1155Param
+		return Wallet{}, newWalletError(ErrIncorrectERC1155Param, command.AccountId)
 	}
 	logService := newWalletLogService()
 	validator := newWalletValidator()
 
-	userWallet, err := walletDAO.getWallet(db, command.AccountId)
+	stepRepo, span := startSpan(repo, "walleter.load_wallet", command)
+	userWallet, err := stepRepo.GetWallet(command.AccountId)
+	endSpan(span, err)
 	if err != nil {
 		return Wallet{}, err
 	}
 
 	// 1. Verify that the user's current wallet status is normal
+	_, span = startSpan(repo, "walleter.validate_wallet", command)
 	result, err := validator.validateWallet(userWallet)
+	if errors.Is(err, ErrIncorrectCheckSign) {
+		err = newWalletError(err, command.AccountId)
+	}
+	endSpan(span, err)
 	if err != nil || !result {
 		return Wallet{}, err
 	}
 
 	// 2.Insert a log message
-	erc1155Log, err := logService.insertNewERC1155WalletLog(db, command, userWallet)
+	stepRepo, span = startSpan(repo, "walleter.insert_log", command)
+	erc1155Log, err := logService.insertNewERC1155WalletLog(stepRepo, command, userWallet)
+	endSpan(span, err)
 	if err != nil {
 		return Wallet{}, err
 	}
 
+	// 3 ~ 6. Charge fees, change user assets and generate new verification information.
+	// All or nothing: a failed step undoes the ones before, userWallet keeps the original state for the log.
+	var settledWallet Wallet
+	err = repo.Transaction(func(tx Repository) (err error) {
+		settledWallet, err = applyERC1155Command(tx, command, copyWallet(userWallet))
+		return err
+	})
+	if err != nil {
+		stepRepo, span = startSpan(repo, "walleter.fail_log", command)
+		_, logErr := logService.failedERC1155WalletLog(stepRepo, erc1155Log, userWallet, err)
+		endSpan(span, logErr)
+		return Wallet{}, err
+	}
+
+	// 8. Update log information
+	stepRepo, span = startSpan(repo, "walleter.update_log", command)
+	_, err = newWalletLogService().updateERC1155WalletLog(stepRepo, erc1155Log, Done, settledWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	// 9. Publish the change through the outbox
+	stepRepo, span = startSpan(repo, "walleter.publish_event", command)
+	err = newWalletEventService().publishWalletChanged(stepRepo, command, erc1155Log.ID, settledWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	stepRepo, span = startSpan(repo, "walleter.reload_wallet", command)
+	wallet, err := stepRepo.GetWallet(command.AccountId)
+	endSpan(span, err)
+	return wallet, err
+}
+
+// applyERC1155Command performs the changes of command on userWallet and stores them, returning the settled wallet.
+func applyERC1155Command(repo Repository, command WalletCommand, userWallet Wallet) (Wallet, error) {
 	// 3. Whether to charge a fee
-	for _, fee := range command.FeeCommands {
-		if fee.Value <= 0 {
-			continue
-		}
-		userWallet, err = newFeeChargerService().chargeFee(db, fee, userWallet)
-		if err != nil {
-			logService.updateERC1155WalletLog(db, erc1155Log, Failed, userWallet)
-			return Wallet{}, err
-		}
+	stepRepo, span := startSpan(repo, "walleter.charge_fees", command)
+	userWallet, err := chargeCommandFees(stepRepo, command, userWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
 	}
 
 	// 5. Make changes to user assets
+	stepRepo, span = startSpan(repo, "walleter.change_erc1155_assets", command)
+	userWallet, err = changeERC1155Assets(stepRepo, command, userWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	// 6. Generate new verification information
+	stepRepo, span = startSpan(repo, "walleter.update_check_sign", command)
+	userWallet, err = updateCheckSign(stepRepo, userWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+	return userWallet, nil
+}
+
+// changeERC1155Assets applies the item amounts of command to userWallet, rewriting its ids and values.
+func changeERC1155Assets(repo Repository, command WalletCommand, userWallet Wallet) (Wallet, error) {
+	historyService := newBalanceHistoryService()
+
+	var err error
 	ids := convertStringToUIntArray(userWallet.ERC1155TokenData.Ids)
 	values := convertStringToUIntArray(userWallet.ERC1155TokenData.Values)
 	switch command.ActionType {
@@ -57,50 +120,41 @@ func handleERC1155Command(db *gorm.DB, command WalletCommand) (Wallet, error) {
 
 			userWallet.ERC1155TokenData.Ids = convertArrayToString(ids, ",")
 			userWallet.ERC1155TokenData.Values = convertArrayToString(values, ",")
-			err = walletDAO.updateERC1155WalletData(db, userWallet.ERC1155TokenData)
+			err = repo.UpdateERC1155TokenWallet(userWallet.ERC1155TokenData)
+			if err != nil {
+				return Wallet{}, err
+			}
+			err = historyService.recordERC1155Balance(repo, command.AccountId, id, values[indexOfArray(ids, id)], int64(value))
 			if err != nil {
 				return Wallet{}, err
 			}
 		}
-	case Withdraw, Spend:
+	case Withdraw, Spend, RevertDeposit, Deduct:
 		for index, id := range command.ERC1155Command.Ids {
 			value := command.ERC1155Command.Values[index]
 			i := indexOfArray(ids, id)
 			if i == -1 {
-				return Wallet{}, ErrNoEnoughNFT
+				return Wallet{}, newWalletError(ErrNoEnoughNFT, command.AccountId).withItem(id, value, 0)
 			} else {
 				if values[i] < value {
-					return Wallet{}, ErrNoEnoughNFT
+					return Wallet{}, newWalletError(ErrNoEnoughNFT, command.AccountId).withItem(id, value, values[i])
 				}
 				values[i] = values[i] - value
 			}
 
 			userWallet.ERC1155TokenData.Ids = convertArrayToString(ids, ",")
 			userWallet.ERC1155TokenData.Values = convertArrayToString(values, ",")
-			err = walletDAO.updateERC1155WalletData(db, userWallet.ERC1155TokenData)
+			err = repo.UpdateERC1155TokenWallet(userWallet.ERC1155TokenData)
+			if err != nil {
+				return Wallet{}, err
+			}
+			err = historyService.recordERC1155Balance(repo, command.AccountId, id, values[i], -int64(value))
 			if err != nil {
 				return Wallet{}, err
 			}
 		}
 	default:
-		return Wallet{}, ErrActionTypeNotSupport
-	}
-
-	// 6. Generate new verification information
-	newCheckSign, err := validator.generateNewSignHash(userWallet)
-	if err != nil {
-		return Wallet{}, err
-	}
-	userWallet.CheckSign = newCheckSign
-	err = walletDAO.updateWalletCheckSign(db, userWallet)
-	if err != nil {
-		return Wallet{}, err
-	}
-
-	// 8. Update log information
-	_, err = newWalletLogService().updateERC1155WalletLog(db, erc1155Log, Done, userWallet)
-	if err != nil {
-		return Wallet{}, err
+		return Wallet{}, newWalletError(ErrActionTypeNotSupport, command.AccountId)
 	}
-	return walletDAO.getWallet(db, command.AccountId)
+	return userWallet, nil
 }
@@ -1,52 +1,122 @@
 package walleter
 
-import (
-	"gorm.io/gorm"
-)
+import "errors"
 
 // This function doesn't contain Transaction, so if we should wrap this function in a Transaction out of this function.
-func handleERC20Command(db *gorm.DB, command WalletCommand) (Wallet, error) {
+func handleERC20Command(repo Repository, command WalletCommand) (Wallet, error) {
 	logService := newWalletLogService()
 	validator := newWalletValidator()
 
-	userWallet, err := walletDAO.getWallet(db, command.AccountId)
+	stepRepo, span := startSpan(repo, "walleter.load_wallet", command)
+	userWallet, err := stepRepo.GetWallet(command.AccountId)
+	endSpan(span, err)
 	if err != nil {
 		return Wallet{}, err
 	}
 
 	// 1. Verify that the user's current wallet status is normal
+	_, span = startSpan(repo, "walleter.validate_wallet", command)
 	result, err := validator.validateWallet(userWallet)
+	if errors.Is(err, ErrIncorrectCheckSign) {
+		err = newWalletError(err, command.AccountId)
+	}
+	endSpan(span, err)
 	if err != nil || !result {
 		return Wallet{}, err
 	}
 
 	// 2.Insert a log message
-	erc20Log, err := logService.insertNewERC20WalletLog(db, command, userWallet)
+	stepRepo, span = startSpan(repo, "walleter.insert_log", command)
+	erc20Log, err := logService.insertNewERC20WalletLog(stepRepo, command, userWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	// 3 ~ 6. Charge fees, change user assets and generate new verification information.
+	// All or nothing: a failed step undoes the ones before, userWallet keeps the original state for the log.
+	var settledWallet Wallet
+	err = repo.Transaction(func(tx Repository) (err error) {
+		settledWallet, err = applyERC20Command(tx, command, copyWallet(userWallet))
+		return err
+	})
 	if err != nil {
+		stepRepo, span = startSpan(repo, "walleter.fail_log", command)
+		_, logErr := logService.failedERC20WalletLog(stepRepo, erc20Log, userWallet, err)
+		endSpan(span, logErr)
 		return Wallet{}, err
 	}
 
+	// 8. Update log information
+	stepRepo, span = startSpan(repo, "walleter.update_log", command)
+	_, err = newWalletLogService().updateERC20WalletLog(stepRepo, erc20Log, Done, settledWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	// 9. Publish the change through the outbox
+	stepRepo, span = startSpan(repo, "walleter.publish_event", command)
+	err = newWalletEventService().publishWalletChanged(stepRepo, command, erc20Log.ID, settledWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	stepRepo, span = startSpan(repo, "walleter.reload_wallet", command)
+	wallet, err := stepRepo.GetWallet(command.AccountId)
+	endSpan(span, err)
+	return wallet, err
+}
+
+// applyERC20Command performs the changes of command on userWallet and stores them, returning the settled wallet.
+func applyERC20Command(repo Repository, command WalletCommand, userWallet Wallet) (Wallet, error) {
 	// 3. Whether to charge a fee
-	for _, fee := range command.FeeCommands {
-		if fee.Value <= 0 {
-			continue
-		}
-		userWallet, err = newFeeChargerService().chargeFee(db, fee, userWallet)
-		if err != nil {
-			logService.updateERC20WalletLog(db, erc20Log, Failed, userWallet)
-			return Wallet{}, err
-		}
+	stepRepo, span := startSpan(repo, "walleter.charge_fees", command)
+	userWallet, err := chargeCommandFees(stepRepo, command, userWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
 	}
 
 	// 4. Make changes to user assets
+	stepRepo, span = startSpan(repo, "walleter.change_erc20_assets", command)
+	userWallet, err = changeERC20Assets(stepRepo, command, userWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	// 6. Generate new verification information
+	stepRepo, span = startSpan(repo, "walleter.update_check_sign", command)
+	userWallet, err = updateCheckSign(stepRepo, userWallet)
+	endSpan(span, err)
+	if err != nil {
+		return Wallet{}, err
+	}
+	return userWallet, nil
+}
+
+// changeERC20Assets applies the token amounts of command to userWallet.
+func changeERC20Assets(repo Repository, command WalletCommand, userWallet Wallet) (Wallet, error) {
+	historyService := newBalanceHistoryService()
+
+	var err error
 	switch command.ActionType {
 	case Deposit:
 		for _, token := range command.ERC20Commands {
 			index, userERC20TokenWallet := getUserSpecifiedERC20TokenWallet(userWallet, token.Token)
+			if index == -1 {
+				return Wallet{}, newWalletError(ErrCannotFindERC20Wallet, command.AccountId).withToken(token.Token.String(), token.Value, 0)
+			}
 			userERC20TokenWallet.Balance += token.Value
 			userERC20TokenWallet.TotalDeposit += token.Value
 			userWallet.ERC20TokenData[index] = userERC20TokenWallet
-			err = walletDAO.updateERC20WalletData(db, userERC20TokenWallet)
+			err = repo.UpdateERC20TokenWallet(userERC20TokenWallet)
+			if err != nil {
+				return Wallet{}, err
+			}
+			err = historyService.recordERC20Balance(repo, userERC20TokenWallet, token.Value)
 			if err != nil {
 				return Wallet{}, err
 			}
@@ -54,13 +124,62 @@ func handleERC20Command(db *gorm.DB, command WalletCommand) (Wallet, error) {
 	case Withdraw:
 		for _, token := range command.ERC20Commands {
 			index, userERC20TokenWallet := getUserSpecifiedERC20TokenWallet(userWallet, token.Token)
+			if index == -1 {
+				return Wallet{}, newWalletError(ErrCannotFindERC20Wallet, command.AccountId).withToken(token.Token.String(), token.Value, 0)
+			}
 			if userERC20TokenWallet.Balance < token.Value {
-				return Wallet{}, ErrNoEnoughERC20Balance
+				return Wallet{}, newWalletError(ErrNoEnoughERC20Balance, command.AccountId).
+					withToken(token.Token.String(), token.Value, userERC20TokenWallet.Balance)
 			}
 			userERC20TokenWallet.Balance -= token.Value
 			userERC20TokenWallet.TotalWithdraw += token.Value
 			userWallet.ERC20TokenData[index] = userERC20TokenWallet
-			err = walletDAO.updateERC20WalletData(db, userERC20TokenWallet)
+			err = repo.UpdateERC20TokenWallet(userERC20TokenWallet)
+			if err != nil {
+				return Wallet{}, err
+			}
+			err = historyService.recordERC20Balance(repo, userERC20TokenWallet, -token.Value)
+			if err != nil {
+				return Wallet{}, err
+			}
+		}
+	case RevertDeposit:
+		for _, token := range command.ERC20Commands {
+			index, userERC20TokenWallet := getUserSpecifiedERC20TokenWallet(userWallet, token.Token)
+			if index == -1 {
+				return Wallet{}, newWalletError(ErrCannotFindERC20Wallet, command.AccountId).withToken(token.Token.String(), token.Value, 0)
+			}
+			// the deposit is gone from chain, so it is taken back even if it was spent meanwhile
+			userERC20TokenWallet.Balance -= token.Value
+			userERC20TokenWallet.TotalDeposit -= token.Value
+			userWallet.ERC20TokenData[index] = userERC20TokenWallet
+			err = repo.UpdateERC20TokenWallet(userERC20TokenWallet)
+			if err != nil {
+				return Wallet{}, err
+			}
+			err = historyService.recordERC20Balance(repo, userERC20TokenWallet, -token.Value)
+			if err != nil {
+				return Wallet{}, err
+			}
+		}
+	case Deduct:
+		for _, token := range command.ERC20Commands {
+			index, userERC20TokenWallet := getUserSpecifiedERC20TokenWallet(userWallet, token.Token)
+			if index == -1 {
+				return Wallet{}, newWalletError(ErrCannotFindERC20Wallet, command.AccountId).withToken(token.Token.String(), token.Value, 0)
+			}
+			if userERC20TokenWallet.Balance < token.Value {
+				return Wallet{}, newWalletError(ErrNoEnoughERC20Balance, command.AccountId).
+					withToken(token.Token.String(), token.Value, userERC20TokenWallet.Balance)
+			}
+			userERC20TokenWallet.Balance -= token.Value
+			userERC20TokenWallet.TotalSpend += token.Value
+			userWallet.ERC20TokenData[index] = userERC20TokenWallet
+			err = repo.UpdateERC20TokenWallet(userERC20TokenWallet)
+			if err != nil {
+				return Wallet{}, err
+			}
+			err = historyService.recordERC20Balance(repo, userERC20TokenWallet, -token.Value)
 			if err != nil {
 				return Wallet{}, err
 			}
@@ -68,40 +187,30 @@ func handleERC20Command(db *gorm.DB, command WalletCommand) (Wallet, error) {
 	case Income:
 		for _, token := range command.ERC20Commands {
 			index, userERC20TokenWallet := getUserSpecifiedERC20TokenWallet(userWallet, token.Token)
+			if index == -1 {
+				return Wallet{}, newWalletError(ErrCannotFindERC20Wallet, command.AccountId).withToken(token.Token.String(), token.Value, 0)
+			}
 			userERC20TokenWallet.Balance += token.Value
 			userERC20TokenWallet.TotalIncome += token.Value
 			userWallet.ERC20TokenData[index] = userERC20TokenWallet
-			err = walletDAO.updateERC20WalletData(db, userERC20TokenWallet)
+			err = repo.UpdateERC20TokenWallet(userERC20TokenWallet)
+			if err != nil {
+				return Wallet{}, err
+			}
+			err = historyService.recordERC20Balance(repo, userERC20TokenWallet, token.Value)
 			if err != nil {
 				return Wallet{}, err
 			}
 		}
 	case Spend, ChargeFee:
 		for _, token := range command.ERC20Commands {
-			userWallet, err := newFeeChargerService().chargeFee(db, token, userWallet)
+			userWallet, err = newFeeChargerService().chargeFee(repo, token, userWallet)
 			if err != nil {
-				return userWallet, err
+				return Wallet{}, err
 			}
 		}
 	default:
-		return Wallet{}, ErrActionTypeNotSupport
-	}
-
-	// 6. Generate new verification information
-	newCheckSign, err := validator.generateNewSignHash(userWallet)
-	if err != nil {
-		return Wallet{}, err
-	}
-	userWallet.CheckSign = newCheckSign
-	err = walletDAO.updateWalletCheckSign(db, userWallet)
-	if err != nil {
-		return Wallet{}, err
-	}
-
-	// 8. Update log information
-	_, err = newWalletLogService().updateERC20WalletLog(db, erc20Log, Done, userWallet)
-	if err != nil {
-		return Wallet{}, err
+		return Wallet{}, newWalletError(ErrActionTypeNotSupport, command.AccountId)
 	}
-	return walletDAO.getWallet(db, command.AccountId)
+	return userWallet, nil
 }
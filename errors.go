@@ -1,15 +1,218 @@
 package walleter
 
-import "errors"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
 
 var (
-	ErrIncorrectAssetType    = errors.New("incorrect asset type in command")
-	ErrIncorrectERC1155Param = errors.New("incorrect erc1155 parameters")
-	ErrIncorrectCheckSign    = errors.New("check sign is invalid")
-	ErrNoEnoughNFT           = errors.New("insufficient nft balance")
-	ErrNoEnoughERC20Balance  = errors.New("insufficient balance")
-	ErrNoEnoughBalanceForFee = errors.New("insufficient balance for fee")
-	ErrAssetTypeNotSupport   = errors.New("not support current asset type")
-	ErrActionTypeNotSupport  = errors.New("not support action type")
-	ErrCannotFindERC20Wallet = errors.New("cannot find erc20 wallet")
+	ErrIncorrectAssetType     = errors.New("incorrect asset type in command")
+	ErrIncorrectERC1155Param  = errors.New("incorrect erc1155 parameters")
+	ErrIncorrectCheckSign     = errors.New("check sign is invalid")
+	ErrNoEnoughNFT            = errors.New("insufficient nft balance")
+	ErrNoEnoughERC20Balance   = errors.New("insufficient balance")
+	ErrNoEnoughBalanceForFee  = errors.New("insufficient balance for fee")
+	ErrAssetTypeNotSupport    = errors.New("not support current asset type")
+	ErrActionTypeNotSupport   = errors.New("not support action type")
+	ErrCannotFindERC20Wallet  = errors.New("cannot find erc20 wallet")
+	ErrInvalidCursor          = errors.New("invalid pagination cursor")
+	ErrUnknownReportDimension = errors.New("unknown report dimension")
+	ErrUnknownMigration       = errors.New("unknown schema migration")
+	ErrUnsupportedDialect     = errors.New("no schema migrations for this database dialect")
+	ErrAccountFrozen          = errors.New("account is frozen")
+	ErrInvalidOperatorAction  = errors.New("invalid operator action")
+	ErrApprovalRequired       = errors.New("command requires an approved adjustment")
+	ErrSelfApproval           = errors.New("operators cannot approve their own adjustments")
+	ErrAdjustmentNotPending   = errors.New("adjustment request is not pending")
+	ErrWithdrawalNotPending   = errors.New("withdrawal is not held")
+	ErrDuplicateApproval      = errors.New("operator already approved this withdrawal")
+	ErrWithdrawalRejected     = errors.New("withdrawal rejected by an operator")
+	ErrWithdrawalExpired      = errors.New("withdrawal approval timed out")
+	ErrWithdrawalHeld         = errors.New("withdrawal held for approval")
+	ErrInvalidDeposit         = errors.New("invalid chain deposit")
+	ErrUnknownDepositAddress  = errors.New("unknown deposit address")
+	ErrDuplicateDeposit       = errors.New("chain deposit already ingested")
+	ErrInvalidDepositAddress  = errors.New("invalid deposit address")
+	ErrDepositAddressTaken    = errors.New("deposit address belongs to another account")
+	ErrDatabaseRequired       = errors.New("operation needs a walleter storing into a gorm database")
+	ErrCommandInterrupted     = errors.New("command interrupted before its log settled")
+	ErrStatementNotSupported  = errors.New("no statement for this account")
+	ErrStoreNotSupported      = errors.New("repository does not implement the store of this feature")
+)
+
+// ErrorCode stable identifier of a wallet failure, safe to persist and to match on across services.
+type ErrorCode string
+
+const (
+	CodeIncorrectAssetType    ErrorCode = "incorrect_asset_type"
+	CodeIncorrectERC1155Param ErrorCode = "incorrect_erc1155_param"
+	CodeIncorrectCheckSign    ErrorCode = "incorrect_check_sign"
+	CodeInsufficientNFT       ErrorCode = "insufficient_nft"
+	CodeInsufficientBalance   ErrorCode = "insufficient_balance"
+	CodeInsufficientFee       ErrorCode = "insufficient_balance_for_fee"
+	CodeAssetTypeNotSupport   ErrorCode = "asset_type_not_supported"
+	CodeActionTypeNotSupport  ErrorCode = "action_type_not_supported"
+	CodeERC20WalletNotFound   ErrorCode = "erc20_wallet_not_found"
+	CodeAccountFrozen         ErrorCode = "account_frozen"
+	CodeApprovalRequired      ErrorCode = "approval_required"
+	CodeWithdrawalRejected    ErrorCode = "withdrawal_rejected"
+	CodeWithdrawalExpired     ErrorCode = "withdrawal_expired"
+	CodeWithdrawalHeld        ErrorCode = "withdrawal_held"
+	CodeInterrupted           ErrorCode = "interrupted"
+	CodeInvalidCursor         ErrorCode = "invalid_cursor"
+	CodeUnknownDimension      ErrorCode = "unknown_report_dimension"
+	CodeInvalidOperatorAction ErrorCode = "invalid_operator_action"
+	CodeSelfApproval          ErrorCode = "self_approval"
+	CodeAdjustmentNotPending  ErrorCode = "adjustment_not_pending"
+	CodeWithdrawalNotPending  ErrorCode = "withdrawal_not_pending"
+	CodeDuplicateApproval     ErrorCode = "duplicate_approval"
+	CodeInvalidDeposit        ErrorCode = "invalid_deposit"
+	CodeDuplicateDeposit      ErrorCode = "duplicate_deposit"
+	CodeInvalidDepositAddress ErrorCode = "invalid_deposit_address"
+	CodeUnknownDepositAddress ErrorCode = "unknown_deposit_address"
+	CodeDepositAddressTaken   ErrorCode = "deposit_address_taken"
+	CodeStatementNotSupported ErrorCode = "statement_not_supported"
+	CodeInternal              ErrorCode = "internal"
 )
+
+var errorCodes = map[error]ErrorCode{
+	ErrIncorrectAssetType:     CodeIncorrectAssetType,
+	ErrIncorrectERC1155Param:  CodeIncorrectERC1155Param,
+	ErrIncorrectCheckSign:     CodeIncorrectCheckSign,
+	ErrNoEnoughNFT:            CodeInsufficientNFT,
+	ErrNoEnoughERC20Balance:   CodeInsufficientBalance,
+	ErrNoEnoughBalanceForFee:  CodeInsufficientFee,
+	ErrAssetTypeNotSupport:    CodeAssetTypeNotSupport,
+	ErrActionTypeNotSupport:   CodeActionTypeNotSupport,
+	ErrCannotFindERC20Wallet:  CodeERC20WalletNotFound,
+	ErrAccountFrozen:          CodeAccountFrozen,
+	ErrApprovalRequired:       CodeApprovalRequired,
+	ErrWithdrawalRejected:     CodeWithdrawalRejected,
+	ErrWithdrawalExpired:      CodeWithdrawalExpired,
+	ErrWithdrawalHeld:         CodeWithdrawalHeld,
+	ErrCommandInterrupted:     CodeInterrupted,
+	ErrInvalidCursor:          CodeInvalidCursor,
+	ErrUnknownReportDimension: CodeUnknownDimension,
+	ErrInvalidOperatorAction:  CodeInvalidOperatorAction,
+	ErrSelfApproval:           CodeSelfApproval,
+	ErrAdjustmentNotPending:   CodeAdjustmentNotPending,
+	ErrWithdrawalNotPending:   CodeWithdrawalNotPending,
+	ErrDuplicateApproval:      CodeDuplicateApproval,
+	ErrInvalidDeposit:         CodeInvalidDeposit,
+	ErrDuplicateDeposit:       CodeDuplicateDeposit,
+	ErrInvalidDepositAddress:  CodeInvalidDepositAddress,
+	ErrUnknownDepositAddress:  CodeUnknownDepositAddress,
+	ErrDepositAddressTaken:    CodeDepositAddressTaken,
+	ErrStatementNotSupported:  CodeStatementNotSupported,
+}
+
+// WalletError describes why a command failed. It wraps one of the sentinel errors above,
+// so errors.Is(err, ErrNoEnoughERC20Balance) keeps working, and is stored in the failure detail of logs.
+type WalletError struct {
+	Code      ErrorCode `json:"code"`
+	AccountId uint64    `json:"account_id"`
+	Token     string    `json:"token,omitempty"`
+	ItemId    uint64    `json:"item_id,omitempty"`
+	Requested float64   `json:"requested,omitempty"`
+	Available float64   `json:"available,omitempty"`
+	Message   string    `json:"message"`
+
+	err error
+}
+
+func newWalletError(err error, accountId uint64) *WalletError {
+	return &WalletError{
+		Code:      ErrorCodeOf(err),
+		AccountId: accountId,
+		Message:   err.Error(),
+		err:       err,
+	}
+}
+
+func (e *WalletError) withToken(token string, requested, available float64) *WalletError {
+	e.Token = token
+	e.Requested = requested
+	e.Available = available
+	return e
+}
+
+func (e *WalletError) withItem(itemId uint64, requested, available uint64) *WalletError {
+	e.ItemId = itemId
+	e.Requested = float64(requested)
+	e.Available = float64(available)
+	return e
+}
+
+func (e *WalletError) Error() string {
+	var details []string
+	if e.AccountId != 0 {
+		details = append(details, fmt.Sprintf("account %d", e.AccountId))
+	}
+	if e.Token != "" {
+		details = append(details, fmt.Sprintf("token %s", e.Token))
+	}
+	if e.ItemId != 0 {
+		details = append(details, fmt.Sprintf("item %d", e.ItemId))
+	}
+	if e.Requested != 0 || e.Available != 0 {
+		details = append(details, fmt.Sprintf("requested %v, available %v", e.Requested, e.Available))
+	}
+	if len(details) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(details, ", "))
+}
+
+func (e *WalletError) Unwrap() error {
+	return e.err
+}
+
+func (e WalletError) Value() (driver.Value, error) {
+	b, err := json.Marshal(e)
+	return string(b), err
+}
+
+func (WalletError) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (e *WalletError) Scan(input interface{}) error {
+	if err := scanJSON(input, e); err != nil {
+		return err
+	}
+	for sentinel, code := range errorCodes {
+		if code == e.Code {
+			e.err = sentinel
+		}
+	}
+	return nil
+}
+
+// ErrorCodeOf returns the stable code of err, CodeInternal for errors not raised by the wallet rules.
+func ErrorCodeOf(err error) ErrorCode {
+	var walletErr *WalletError
+	if errors.As(err, &walletErr) {
+		return walletErr.Code
+	}
+	for sentinel, code := range errorCodes {
+		if errors.Is(err, sentinel) {
+			return code
+		}
+	}
+	return CodeInternal
+}
+
+// failureDetailOf converts any error of a command into the detail persisted in logs.
+func failureDetailOf(err error, accountId uint64) *WalletError {
+	var walletErr *WalletError
+	if errors.As(err, &walletErr) {
+		return walletErr
+	}
+	return newWalletError(err, accountId)
+}
@@ -1,9 +1,5 @@
 package walleter
 
-import (
-	"gorm.io/gorm"
-)
-
 // fee charging service.
 type feeChargerService struct{}
 
@@ -11,36 +7,50 @@ func newFeeChargerService() *feeChargerService {
 	return &feeChargerService{}
 }
 
-func (*feeChargerService) chargeFee(db *gorm.DB, token ERC20Command, userWallet Wallet) (Wallet, error) {
+func (*feeChargerService) chargeFee(repo Repository, token ERC20Command, userWallet Wallet) (Wallet, error) {
 	// get fee charger account.
-	feeChargerWallet, err := walletDAO.getWallet(db, feeChargerAccountId)
+	feeChargerWallet, err := repo.GetWallet(feeChargerAccountId)
 	if err != nil {
 		return userWallet, err
 	}
 
 	index, userERC20TokenWallet := getUserSpecifiedERC20TokenWallet(userWallet, token.Token)
 	if index == -1 || userERC20TokenWallet.Balance < token.Value {
-		return userWallet, ErrNoEnoughBalanceForFee
+		return userWallet, newWalletError(ErrNoEnoughBalanceForFee, userWallet.AccountId).
+			withToken(token.Token.String(), token.Value, userERC20TokenWallet.Balance)
 	}
 
 	userERC20TokenWallet.Balance -= token.Value
 	userERC20TokenWallet.TotalFee += token.Value
 	userWallet.ERC20TokenData[index] = userERC20TokenWallet
-	err = walletDAO.updateERC20WalletData(db, userWallet.ERC20TokenData[index])
+	err = repo.UpdateERC20TokenWallet(userWallet.ERC20TokenData[index])
+	if err != nil {
+		return userWallet, err
+	}
+	err = newBalanceHistoryService().recordERC20Balance(repo, userERC20TokenWallet, -token.Value)
 	if err != nil {
 		return userWallet, err
 	}
 
 	index, feeChargerERC20TokenWallet := getUserSpecifiedERC20TokenWallet(feeChargerWallet, token.Token)
 	if index == -1 {
-		return feeChargerWallet, ErrCannotFindERC20Wallet
+		return feeChargerWallet, newWalletError(ErrCannotFindERC20Wallet, feeChargerAccountId).withToken(token.Token.String(), token.Value, 0)
 	}
 	feeChargerERC20TokenWallet.Balance += token.Value
 	feeChargerERC20TokenWallet.TotalIncome += token.Value
 	feeChargerWallet.ERC20TokenData[index] = feeChargerERC20TokenWallet
 
 	// update fee charger account
-	err = walletDAO.updateERC20WalletData(db, feeChargerERC20TokenWallet)
+	err = repo.UpdateERC20TokenWallet(feeChargerERC20TokenWallet)
+	if err != nil {
+		return userWallet, err
+	}
+	err = newBalanceHistoryService().recordERC20Balance(repo, feeChargerERC20TokenWallet, token.Value)
+	if err != nil {
+		return userWallet, err
+	}
+	// keep the check sign of the fee charger account valid, otherwise its own commands get rejected
+	_, err = updateCheckSign(repo, feeChargerWallet)
 	return userWallet, err
 }
 
@@ -53,3 +63,18 @@ func getUserSpecifiedERC20TokenWallet(wallet Wallet, tokenType ERC20TokenEnum) (
 	}
 	return -1, ERC20TokenWallet{}
 }
+
+// chargeCommandFees charges every fee of command from userWallet.
+func chargeCommandFees(repo Repository, command WalletCommand, userWallet Wallet) (Wallet, error) {
+	var err error
+	for _, fee := range command.FeeCommands {
+		if fee.Value <= 0 {
+			continue
+		}
+		userWallet, err = newFeeChargerService().chargeFee(repo, fee, userWallet)
+		if err != nil {
+			return Wallet{}, err
+		}
+	}
+	return userWallet, nil
+}
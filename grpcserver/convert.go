@@ -0,0 +1,187 @@
+package grpcserver
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nami-land/walleter"
+	"github.com/nami-land/walleter/walleterpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func commandFromProto(command *walleterpb.WalletCommand) walleter.WalletCommand {
+	return walleter.WalletCommand{
+		AccountId:      command.GetAccountId(),
+		AssetType:      walleter.AssetType(command.GetAssetType()),
+		ActionType:     walleter.WalletActionType(command.GetActionType()),
+		ERC20Commands:  erc20CommandsFromProto(command.GetErc20Commands()),
+		ERC1155Command: walleter.ERC1155Command{Ids: command.GetErc1155Command().GetIds(), Values: command.GetErc1155Command().GetValues()},
+		FeeCommands:    erc20CommandsFromProto(command.GetFeeCommands()),
+		BusinessModule: command.GetBusinessModule(),
+		CommandSource:  walleter.CommandSourceType(command.GetCommandSource()),
+	}
+}
+
+func erc20CommandsFromProto(commands []*walleterpb.ERC20Command) []walleter.ERC20Command {
+	var result []walleter.ERC20Command
+	for _, command := range commands {
+		result = append(result, walleter.ERC20Command{
+			Token:   walleter.ERC20TokenEnum(command.GetToken()),
+			Value:   command.GetValue(),
+			Decimal: command.GetDecimal(),
+		})
+	}
+	return result
+}
+
+func walletToProto(wallet walleter.Wallet) *walleterpb.Wallet {
+	result := &walleterpb.Wallet{
+		AccountId: wallet.AccountId,
+		Erc1155TokenData: &walleterpb.ERC1155TokenWallet{
+			Ids:    parseUints(wallet.ERC1155TokenData.Ids),
+			Values: parseUints(wallet.ERC1155TokenData.Values),
+		},
+		CheckSign: wallet.CheckSign,
+		CreatedAt: timestamppb.New(wallet.CreatedAt),
+		UpdatedAt: timestamppb.New(wallet.UpdatedAt),
+	}
+	for _, token := range wallet.ERC20TokenData {
+		result.Erc20TokenData = append(result.Erc20TokenData, &walleterpb.ERC20TokenWallet{
+			Token:         token.Token,
+			Balance:       token.Balance,
+			Decimal:       token.Decimal,
+			TotalIncome:   token.TotalIncome,
+			TotalSpend:    token.TotalSpend,
+			TotalDeposit:  token.TotalDeposit,
+			TotalWithdraw: token.TotalWithdraw,
+			TotalFee:      token.TotalFee,
+			Locked:        token.Locked,
+		})
+	}
+	return result
+}
+
+func snapshotToProto(snapshot walleter.WalletSnapshot) *walleterpb.GetWalletAtResponse {
+	return &walleterpb.GetWalletAtResponse{
+		AccountId:       snapshot.AccountId,
+		At:              timestamppb.New(snapshot.At),
+		Erc20Balances:   snapshot.ERC20Balances,
+		Erc1155Balances: snapshot.ERC1155Balances,
+	}
+}
+
+func erc20HistoryToProto(history walleter.ERC20BalanceHistory) *walleterpb.ERC20BalanceHistory {
+	return &walleterpb.ERC20BalanceHistory{
+		AccountId: history.AccountId,
+		Token:     history.Token,
+		Balance:   history.Balance,
+		Change:    history.Change,
+		CreatedAt: timestamppb.New(history.CreatedAt),
+	}
+}
+
+func erc1155HistoryToProto(history walleter.ERC1155BalanceHistory) *walleterpb.ERC1155BalanceHistory {
+	return &walleterpb.ERC1155BalanceHistory{
+		AccountId: history.AccountId,
+		TokenId:   history.TokenId,
+		Balance:   history.Balance,
+		Change:    history.Change,
+		CreatedAt: timestamppb.New(history.CreatedAt),
+	}
+}
+
+func logQueryFromProto(request *walleterpb.ListWalletLogsRequest) walleter.LogQuery {
+	query := walleter.LogQuery{
+		AccountId:      request.GetAccountId(),
+		BusinessModule: request.GetBusinessModule(),
+		TokenIds:       request.GetTokenIds(),
+		Cursor:         request.GetCursor(),
+		Limit:          int(request.GetLimit()),
+		Ascending:      request.GetAscending(),
+	}
+	for _, assetType := range request.GetAssetTypes() {
+		query.AssetTypes = append(query.AssetTypes, walleter.AssetType(assetType))
+	}
+	for _, actionType := range request.GetActionTypes() {
+		query.ActionTypes = append(query.ActionTypes, walleter.WalletActionType(actionType))
+	}
+	for _, source := range request.GetSources() {
+		query.CommandSources = append(query.CommandSources, walleter.CommandSourceType(source))
+	}
+	for _, logStatus := range request.GetStatuses() {
+		query.Statuses = append(query.Statuses, walleter.WalletLogStatus(logStatus))
+	}
+	for _, token := range request.GetTokens() {
+		query.Tokens = append(query.Tokens, walleter.ERC20TokenEnum(token))
+	}
+	if request.GetFrom() != nil {
+		query.From = request.GetFrom().AsTime()
+	}
+	if request.GetTo() != nil {
+		query.To = request.GetTo().AsTime()
+	}
+	return query
+}
+
+func walletLogToProto(entry walleter.WalletLogEntry) *walleterpb.WalletLog {
+	result := &walleterpb.WalletLog{
+		Id:             uint64(entry.Id),
+		AssetType:      walleterpb.AssetType(entry.AssetType),
+		AccountId:      entry.AccountId,
+		BusinessModule: entry.BusinessModule,
+		ActionType:     entry.ActionType,
+		Source:         entry.Source,
+		Status:         entry.Status,
+		Tokens:         tokenAmountsToProto(entry.Tokens),
+		Fees:           tokenAmountsToProto(entry.Fees),
+		CreatedAt:      timestamppb.New(entry.CreatedAt),
+		UpdatedAt:      timestamppb.New(entry.UpdatedAt),
+	}
+	for _, item := range entry.Items {
+		result.Items = append(result.Items, &walleterpb.ItemAmount{Id: item.Id, Amount: item.Amount})
+	}
+	if entry.FailureDetail != nil {
+		result.FailureCode = string(entry.FailureDetail.Code)
+		result.FailureMessage = entry.FailureDetail.Message
+	}
+	return result
+}
+
+func tokenAmountsToProto(amounts []walleter.TokenAmount) []*walleterpb.TokenAmount {
+	var result []*walleterpb.TokenAmount
+	for _, amount := range amounts {
+		result = append(result, &walleterpb.TokenAmount{Token: amount.Token, Amount: amount.Amount, Decimal: amount.Decimal})
+	}
+	return result
+}
+
+// periodOf the bounds of a history query, from defaults to the beginning and to defaults to now.
+func periodOf(from, to *timestamppb.Timestamp) (time.Time, time.Time, error) {
+	start, end := time.Unix(0, 0), time.Now()
+	if from != nil {
+		start = from.AsTime()
+	}
+	if to != nil {
+		end = to.AsTime()
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, status.Error(codes.InvalidArgument, "to is before from")
+	}
+	return start, end, nil
+}
+
+// parseUints parses the comma separated ids or values of an ERC1155 wallet.
+func parseUints(value string) []uint64 {
+	if value == "" {
+		return nil
+	}
+	var result []uint64
+	for _, item := range strings.Split(value, ",") {
+		number, _ := strconv.ParseUint(item, 10, 64)
+		result = append(result, number)
+	}
+	return result
+}
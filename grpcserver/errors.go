@@ -0,0 +1,98 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nami-land/walleter"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// errorDomain the domain of the google.rpc.ErrorInfo attached to the statuses of rejected commands.
+const errorDomain = "walleter"
+
+// grpcCodes the gRPC code of every walleter error code, CodeInternal and unknown codes map to codes.Internal.
+var grpcCodes = map[walleter.ErrorCode]codes.Code{
+	walleter.CodeIncorrectAssetType:    codes.InvalidArgument,
+	walleter.CodeIncorrectERC1155Param: codes.InvalidArgument,
+	walleter.CodeAssetTypeNotSupport:   codes.InvalidArgument,
+	walleter.CodeActionTypeNotSupport:  codes.InvalidArgument,
+	walleter.CodeInsufficientNFT:       codes.FailedPrecondition,
+	walleter.CodeInsufficientBalance:   codes.FailedPrecondition,
+	walleter.CodeInsufficientFee:       codes.FailedPrecondition,
+	walleter.CodeERC20WalletNotFound:   codes.FailedPrecondition,
+	walleter.CodeAccountFrozen:         codes.FailedPrecondition,
+	walleter.CodeWithdrawalRejected:    codes.FailedPrecondition,
+	walleter.CodeWithdrawalExpired:     codes.FailedPrecondition,
+	walleter.CodeAdjustmentNotPending:  codes.FailedPrecondition,
+	walleter.CodeWithdrawalNotPending:  codes.FailedPrecondition,
+	walleter.CodeInvalidCursor:         codes.InvalidArgument,
+	walleter.CodeUnknownDimension:      codes.InvalidArgument,
+	walleter.CodeInvalidOperatorAction: codes.InvalidArgument,
+	walleter.CodeInvalidDeposit:        codes.InvalidArgument,
+	walleter.CodeInvalidDepositAddress: codes.InvalidArgument,
+	walleter.CodeStatementNotSupported: codes.InvalidArgument,
+	walleter.CodeUnknownDepositAddress: codes.NotFound,
+	walleter.CodeDuplicateDeposit:      codes.AlreadyExists,
+	walleter.CodeDuplicateApproval:     codes.AlreadyExists,
+	walleter.CodeDepositAddressTaken:   codes.AlreadyExists,
+	walleter.CodeApprovalRequired:      codes.PermissionDenied,
+	walleter.CodeSelfApproval:          codes.PermissionDenied,
+	walleter.CodeInterrupted:           codes.Aborted,
+	walleter.CodeIncorrectCheckSign:    codes.DataLoss,
+}
+
+// statusOf converts err into a gRPC status error. Wallet errors carry their code as ErrorInfo reason,
+// along with the account, token or item and the amounts involved.
+func statusOf(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Error(codes.NotFound, "wallet not found")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	code := walleter.ErrorCodeOf(err)
+	grpcCode, ok := grpcCodes[code]
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	info := &errdetails.ErrorInfo{Reason: string(code), Domain: errorDomain, Metadata: map[string]string{}}
+	var walletErr *walleter.WalletError
+	if errors.As(err, &walletErr) {
+		info.Metadata["account_id"] = fmt.Sprint(walletErr.AccountId)
+		if walletErr.Token != "" {
+			info.Metadata["token"] = walletErr.Token
+		}
+		if walletErr.ItemId != 0 {
+			info.Metadata["item_id"] = fmt.Sprint(walletErr.ItemId)
+		}
+		if walletErr.Requested != 0 || walletErr.Available != 0 {
+			info.Metadata["requested"] = fmt.Sprint(walletErr.Requested)
+			info.Metadata["available"] = fmt.Sprint(walletErr.Available)
+		}
+	}
+	result, detailErr := status.New(grpcCode, err.Error()).WithDetails(info)
+	if detailErr != nil {
+		return status.Error(grpcCode, err.Error())
+	}
+	return result.Err()
+}
+
+// ErrorCodeOf returns the walleter error code carried by a status error returned by the service,
+// walleter.CodeInternal when it carries none.
+func ErrorCodeOf(err error) walleter.ErrorCode {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == errorDomain {
+			return walleter.ErrorCode(info.GetReason())
+		}
+	}
+	return walleter.CodeInternal
+}
@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/nami-land/walleter"
+	"github.com/nami-land/walleter/walleterpb"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthServer reports SERVING while the database of the Walleter answers, for the whole server
+// and for the wallet service.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	w *walleter.Walleter
+}
+
+func newHealthServer(w *walleter.Walleter) *healthServer {
+	return &healthServer{w: w}
+}
+
+func (h *healthServer) Check(ctx context.Context, request *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if service := request.GetService(); service != "" && service != walleterpb.WalletService_ServiceDesc.ServiceName {
+		return nil, status.Errorf(codes.NotFound, "unknown service %s", service)
+	}
+	if err := h.w.Ping(ctx); err != nil {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
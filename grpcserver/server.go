@@ -0,0 +1,119 @@
+// Package grpcserver serves a Walleter over gRPC, see proto/walleter/v1/walleter.proto.
+// Clients use the generated walleterpb.NewWalletServiceClient.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nami-land/walleter"
+	"github.com/nami-land/walleter/walleterpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements walleterpb.WalletServiceServer on a Walleter created by walleter.New,
+// the history queries read the database directly.
+type Server struct {
+	walleterpb.UnimplementedWalletServiceServer
+
+	w *walleter.Walleter
+}
+
+// NewServer returns the gRPC service of w.
+func NewServer(w *walleter.Walleter) *Server {
+	return &Server{w: w}
+}
+
+// Register adds the wallet service of w and the standard health service to server.
+func Register(server *grpc.Server, w *walleter.Walleter) {
+	walleterpb.RegisterWalletServiceServer(server, NewServer(w))
+	healthpb.RegisterHealthServer(server, newHealthServer(w))
+}
+
+func (s *Server) HandleWalletCommand(ctx context.Context, request *walleterpb.HandleWalletCommandRequest) (*walleterpb.HandleWalletCommandResponse, error) {
+	if request.GetCommand() == nil {
+		return nil, status.Error(codes.InvalidArgument, "command is required")
+	}
+	wallet, err := s.w.ExecuteCommand(ctx, commandFromProto(request.GetCommand()))
+	var held *walleter.WithdrawalHeldError
+	if errors.As(err, &held) {
+		return &walleterpb.HandleWalletCommandResponse{
+			Wallet:           walletToProto(wallet),
+			Status:           walleterpb.WalletLogStatus_WALLET_LOG_STATUS_HELD,
+			WithdrawalHoldId: uint64(held.HoldId),
+		}, nil
+	}
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	return &walleterpb.HandleWalletCommandResponse{
+		Wallet: walletToProto(wallet),
+		Status: walleterpb.WalletLogStatus_WALLET_LOG_STATUS_DONE,
+	}, nil
+}
+
+func (s *Server) GetWallet(ctx context.Context, request *walleterpb.GetWalletRequest) (*walleterpb.GetWalletResponse, error) {
+	wallet, err := s.w.GetWalletByAccountId(request.GetAccountId())
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	return &walleterpb.GetWalletResponse{Wallet: walletToProto(wallet)}, nil
+}
+
+func (s *Server) GetWalletAt(ctx context.Context, request *walleterpb.GetWalletAtRequest) (*walleterpb.GetWalletAtResponse, error) {
+	if request.GetAt() == nil {
+		return nil, status.Error(codes.InvalidArgument, "at is required")
+	}
+	snapshot, err := s.w.GetWalletAt(request.GetAccountId(), request.GetAt().AsTime())
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	return snapshotToProto(snapshot), nil
+}
+
+func (s *Server) GetERC20BalanceHistory(ctx context.Context, request *walleterpb.GetERC20BalanceHistoryRequest) (*walleterpb.GetERC20BalanceHistoryResponse, error) {
+	from, to, err := periodOf(request.GetFrom(), request.GetTo())
+	if err != nil {
+		return nil, err
+	}
+	histories, err := s.w.GetERC20BalanceHistory(request.GetAccountId(), walleter.ERC20TokenEnum(request.GetToken()), from, to)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	response := &walleterpb.GetERC20BalanceHistoryResponse{}
+	for _, history := range histories {
+		response.Histories = append(response.Histories, erc20HistoryToProto(history))
+	}
+	return response, nil
+}
+
+func (s *Server) GetERC1155BalanceHistory(ctx context.Context, request *walleterpb.GetERC1155BalanceHistoryRequest) (*walleterpb.GetERC1155BalanceHistoryResponse, error) {
+	from, to, err := periodOf(request.GetFrom(), request.GetTo())
+	if err != nil {
+		return nil, err
+	}
+	histories, err := s.w.GetERC1155BalanceHistory(request.GetAccountId(), request.GetTokenId(), from, to)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	response := &walleterpb.GetERC1155BalanceHistoryResponse{}
+	for _, history := range histories {
+		response.Histories = append(response.Histories, erc1155HistoryToProto(history))
+	}
+	return response, nil
+}
+
+func (s *Server) ListWalletLogs(ctx context.Context, request *walleterpb.ListWalletLogsRequest) (*walleterpb.ListWalletLogsResponse, error) {
+	page, err := s.w.ListWalletLogs(logQueryFromProto(request))
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	response := &walleterpb.ListWalletLogsResponse{NextCursor: page.NextCursor}
+	for _, entry := range page.Entries {
+		response.Logs = append(response.Logs, walletLogToProto(entry))
+	}
+	return response, nil
+}
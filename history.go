@@ -0,0 +1,214 @@
+package walleter
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ERC20BalanceHistory records the balance of one ERC20 token of an account right after it changed.
+// Point-in-time queries read this table instead of decoding the wallet blobs stored in logs.
+type ERC20BalanceHistory struct {
+	gorm.Model `swagger-ignore:"true"`
+	AccountId  uint64  `json:"account_id" gorm:"not null;index:idx_erc20_balance_history"`
+	Token      string  `json:"token" gorm:"type:varchar(20);not null;index:idx_erc20_balance_history"`
+	Balance    float64 `json:"balance"`
+	Change     float64 `json:"change"`
+}
+
+// ERC1155BalanceHistory records the amount of one ERC1155 id held by an account right after it changed.
+type ERC1155BalanceHistory struct {
+	gorm.Model `swagger-ignore:"true"`
+	AccountId  uint64 `json:"account_id" gorm:"not null;index:idx_erc1155_balance_history"`
+	TokenId    uint64 `json:"token_id" gorm:"not null;index:idx_erc1155_balance_history"`
+	Balance    uint64 `json:"balance"`
+	Change     int64  `json:"change"`
+}
+
+// WalletSnapshot the balances of a wallet at a given moment.
+type WalletSnapshot struct {
+	AccountId       uint64             `json:"account_id"`
+	At              time.Time          `json:"at"`
+	ERC20Balances   map[string]float64 `json:"erc_20_balances"`
+	ERC1155Balances map[uint64]uint64  `json:"erc_1155_balances"`
+}
+
+// GetWalletAt returns what the wallet of accountId held at the given moment.
+// A token without any recorded change before `at` held what its first later change started from,
+// or its current balance when it never changed since. Balances which predate the balance history
+// tables are those the wallet held when they were introduced.
+func (s *Walleter) GetWalletAt(accountId uint64, at time.Time) (WalletSnapshot, error) {
+	histories, err := storeOf[BalanceHistoryStore](s.repo)
+	if err != nil {
+		return WalletSnapshot{}, err
+	}
+	wallet, err := s.repo.GetWallet(accountId)
+	if err != nil {
+		return WalletSnapshot{}, err
+	}
+	if wallet.CreatedAt.After(at) {
+		return WalletSnapshot{}, gorm.ErrRecordNotFound
+	}
+
+	snapshot := WalletSnapshot{
+		AccountId:       accountId,
+		At:              at,
+		ERC20Balances:   map[string]float64{},
+		ERC1155Balances: map[uint64]uint64{},
+	}
+	for _, token := range wallet.ERC20TokenData {
+		snapshot.ERC20Balances[token.Token] = token.Balance
+	}
+	erc1155Balances := map[uint64]uint64{}
+	for _, item := range wallet.ERC1155TokenData.Amounts() {
+		erc1155Balances[item.Id] = item.Amount
+	}
+
+	erc20Later, err := histories.GetEarliestERC20BalanceHistories(accountId, at)
+	if err != nil {
+		return WalletSnapshot{}, err
+	}
+	for _, item := range erc20Later {
+		snapshot.ERC20Balances[item.Token] = item.Balance - item.Change
+	}
+	erc20Histories, err := histories.GetLatestERC20BalanceHistories(accountId, at)
+	if err != nil {
+		return WalletSnapshot{}, err
+	}
+	for _, item := range erc20Histories {
+		snapshot.ERC20Balances[item.Token] = item.Balance
+	}
+
+	erc1155Later, err := histories.GetEarliestERC1155BalanceHistories(accountId, at)
+	if err != nil {
+		return WalletSnapshot{}, err
+	}
+	for _, item := range erc1155Later {
+		erc1155Balances[item.TokenId] = uint64(int64(item.Balance) - item.Change)
+	}
+	erc1155Histories, err := histories.GetLatestERC1155BalanceHistories(accountId, at)
+	if err != nil {
+		return WalletSnapshot{}, err
+	}
+	for _, item := range erc1155Histories {
+		erc1155Balances[item.TokenId] = item.Balance
+	}
+	for id, balance := range erc1155Balances {
+		if balance > 0 {
+			snapshot.ERC1155Balances[id] = balance
+		}
+	}
+	return snapshot, nil
+}
+
+// GetERC20BalanceHistory returns every recorded balance change of token for accountId within [from, to], oldest first.
+func (s *Walleter) GetERC20BalanceHistory(accountId uint64, token ERC20TokenEnum, from, to time.Time) ([]ERC20BalanceHistory, error) {
+	histories, err := storeOf[BalanceHistoryStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return histories.ListERC20BalanceHistories(accountId, token.String(), from, to)
+}
+
+// GetERC1155BalanceHistory returns every recorded change of ERC1155 tokenId for accountId within [from, to], oldest first.
+func (s *Walleter) GetERC1155BalanceHistory(accountId uint64, tokenId uint64, from, to time.Time) ([]ERC1155BalanceHistory, error) {
+	histories, err := storeOf[BalanceHistoryStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return histories.ListERC1155BalanceHistories(accountId, tokenId, from, to)
+}
+
+type balanceHistoryDAO struct{}
+
+var historyDAO = &balanceHistoryDAO{}
+
+func (dao balanceHistoryDAO) insertERC20History(db *gorm.DB, history ERC20BalanceHistory) error {
+	return db.Create(&history).Error
+}
+
+func (dao balanceHistoryDAO) insertERC1155History(db *gorm.DB, history ERC1155BalanceHistory) error {
+	return db.Create(&history).Error
+}
+
+// getLatestERC20Histories returns the last history row of each token recorded no later than `at`.
+func (dao balanceHistoryDAO) getLatestERC20Histories(db *gorm.DB, accountId uint64, at time.Time) (result []ERC20BalanceHistory, err error) {
+	latestIds := db.Model(&ERC20BalanceHistory{}).
+		Select("MAX(id)").
+		Where("account_id = ? AND created_at <= ?", accountId, at).
+		Group("token")
+	err = db.Where("id IN (?)", latestIds).Find(&result).Error
+	return result, err
+}
+
+// getLatestERC1155Histories returns the last history row of each ERC1155 id recorded no later than `at`.
+func (dao balanceHistoryDAO) getLatestERC1155Histories(db *gorm.DB, accountId uint64, at time.Time) (result []ERC1155BalanceHistory, err error) {
+	latestIds := db.Model(&ERC1155BalanceHistory{}).
+		Select("MAX(id)").
+		Where("account_id = ? AND created_at <= ?", accountId, at).
+		Group("token_id")
+	err = db.Where("id IN (?)", latestIds).Find(&result).Error
+	return result, err
+}
+
+// getEarliestERC20Histories returns the first history row of each token recorded after `at`.
+func (dao balanceHistoryDAO) getEarliestERC20Histories(db *gorm.DB, accountId uint64, at time.Time) (result []ERC20BalanceHistory, err error) {
+	earliestIds := db.Model(&ERC20BalanceHistory{}).
+		Select("MIN(id)").
+		Where("account_id = ? AND created_at > ?", accountId, at).
+		Group("token")
+	err = db.Where("id IN (?)", earliestIds).Order("id").Find(&result).Error
+	return result, err
+}
+
+// getEarliestERC1155Histories returns the first history row of each ERC1155 id recorded after `at`.
+func (dao balanceHistoryDAO) getEarliestERC1155Histories(db *gorm.DB, accountId uint64, at time.Time) (result []ERC1155BalanceHistory, err error) {
+	earliestIds := db.Model(&ERC1155BalanceHistory{}).
+		Select("MIN(id)").
+		Where("account_id = ? AND created_at > ?", accountId, at).
+		Group("token_id")
+	err = db.Where("id IN (?)", earliestIds).Order("id").Find(&result).Error
+	return result, err
+}
+
+func (dao balanceHistoryDAO) getERC20Histories(db *gorm.DB, accountId uint64, token string, from, to time.Time) (result []ERC20BalanceHistory, err error) {
+	err = db.Where("account_id = ? AND token = ? AND created_at BETWEEN ? AND ?", accountId, token, from, to).
+		Order("id").
+		Find(&result).Error
+	return result, err
+}
+
+func (dao balanceHistoryDAO) getERC1155Histories(db *gorm.DB, accountId uint64, tokenId uint64, from, to time.Time) (result []ERC1155BalanceHistory, err error) {
+	err = db.Where("account_id = ? AND token_id = ? AND created_at BETWEEN ? AND ?", accountId, tokenId, from, to).
+		Order("id").
+		Find(&result).Error
+	return result, err
+}
+
+// /----------------------------
+// Balance history service
+type balanceHistoryService struct{}
+
+func newBalanceHistoryService() *balanceHistoryService {
+	return &balanceHistoryService{}
+}
+
+// recordERC20Balance append the settled balance of an erc20 token wallet.
+func (receiver *balanceHistoryService) recordERC20Balance(repo Repository, tokenWallet ERC20TokenWallet, change float64) error {
+	return repo.InsertERC20BalanceHistory(ERC20BalanceHistory{
+		AccountId: tokenWallet.AccountId,
+		Token:     tokenWallet.Token,
+		Balance:   tokenWallet.Balance,
+		Change:    change,
+	})
+}
+
+// recordERC1155Balance append the settled amount of an erc1155 id.
+func (receiver *balanceHistoryService) recordERC1155Balance(repo Repository, accountId uint64, tokenId uint64, balance uint64, change int64) error {
+	return repo.InsertERC1155BalanceHistory(ERC1155BalanceHistory{
+		AccountId: accountId,
+		TokenId:   tokenId,
+		Balance:   balance,
+		Change:    change,
+	})
+}
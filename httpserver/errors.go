@@ -0,0 +1,66 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+// statusCodes the HTTP status of every walleter error code, CodeInternal and unknown codes map to 500.
+var statusCodes = map[walleter.ErrorCode]int{
+	walleter.CodeIncorrectAssetType:    http.StatusBadRequest,
+	walleter.CodeIncorrectERC1155Param: http.StatusBadRequest,
+	walleter.CodeAssetTypeNotSupport:   http.StatusBadRequest,
+	walleter.CodeActionTypeNotSupport:  http.StatusBadRequest,
+	walleter.CodeInsufficientNFT:       http.StatusUnprocessableEntity,
+	walleter.CodeInsufficientBalance:   http.StatusUnprocessableEntity,
+	walleter.CodeInsufficientFee:       http.StatusUnprocessableEntity,
+	walleter.CodeERC20WalletNotFound:   http.StatusUnprocessableEntity,
+	walleter.CodeWithdrawalRejected:    http.StatusUnprocessableEntity,
+	walleter.CodeWithdrawalExpired:     http.StatusUnprocessableEntity,
+	walleter.CodeInvalidCursor:         http.StatusBadRequest,
+	walleter.CodeUnknownDimension:      http.StatusBadRequest,
+	walleter.CodeInvalidOperatorAction: http.StatusBadRequest,
+	walleter.CodeInvalidDeposit:        http.StatusBadRequest,
+	walleter.CodeInvalidDepositAddress: http.StatusBadRequest,
+	walleter.CodeStatementNotSupported: http.StatusBadRequest,
+	walleter.CodeUnknownDepositAddress: http.StatusNotFound,
+	walleter.CodeAccountFrozen:         http.StatusConflict,
+	walleter.CodeAdjustmentNotPending:  http.StatusConflict,
+	walleter.CodeWithdrawalNotPending:  http.StatusConflict,
+	walleter.CodeDuplicateDeposit:      http.StatusConflict,
+	walleter.CodeDuplicateApproval:     http.StatusConflict,
+	walleter.CodeDepositAddressTaken:   http.StatusConflict,
+	walleter.CodeInterrupted:           http.StatusConflict,
+	walleter.CodeApprovalRequired:      http.StatusForbidden,
+	walleter.CodeSelfApproval:          http.StatusForbidden,
+}
+
+// writeWalletError answers err. Rejected commands carry their WalletError as detail, other failures
+// are not described to clients: an invalid check sign or a database error is for the logs.
+func writeWalletError(rw http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		writeError(rw, http.StatusNotFound, ErrorResponse{Code: "not_found", Message: "wallet not found"})
+		return
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		writeError(rw, http.StatusServiceUnavailable, ErrorResponse{Code: "unavailable", Message: err.Error()})
+		return
+	}
+
+	code := walleter.ErrorCodeOf(err)
+	statusCode, ok := statusCodes[code]
+	if !ok {
+		writeError(rw, http.StatusInternalServerError, ErrorResponse{Code: string(walleter.CodeInternal), Message: "internal error"})
+		return
+	}
+	response := ErrorResponse{Code: string(code), Message: err.Error()}
+	var walletErr *walleter.WalletError
+	if errors.As(err, &walletErr) {
+		response.Detail = walletErr
+	}
+	writeError(rw, statusCode, response)
+}
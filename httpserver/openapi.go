@@ -0,0 +1,247 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+// openAPIVersion the version of the API described by OpenAPI, bumped along with the /v1 prefix.
+const openAPIVersion = "1.0.0"
+
+var (
+	openAPIOnce     sync.Once
+	openAPIDocument []byte
+)
+
+// OpenAPI returns the OpenAPI 3.0 document of the API. Schemas are generated from the request and
+// response types: fields are named by their json tag, fields without omitempty are required,
+// `enum` tags list the accepted values and fields tagged swagger-ignore are left out.
+func OpenAPI() []byte {
+	openAPIOnce.Do(func() {
+		document, err := json.MarshalIndent(newOpenAPIGenerator().document(), "", "  ")
+		if err != nil {
+			panic("generate openapi document failed: " + err.Error())
+		}
+		openAPIDocument = document
+	})
+	return openAPIDocument
+}
+
+type object = map[string]interface{}
+
+type openAPIGenerator struct {
+	schemas object
+}
+
+func newOpenAPIGenerator() *openAPIGenerator {
+	return &openAPIGenerator{schemas: object{}}
+}
+
+func (g *openAPIGenerator) document() object {
+	accountId := object{"name": "account_id", "in": "path", "required": true,
+		"schema": object{"type": "integer", "format": "uint64", "minimum": 1}}
+	wallet := g.response("the wallet", walleter.Wallet{})
+	invalid := g.response("the request is invalid", ErrorResponse{})
+	notFound := g.response("the wallet does not exist", ErrorResponse{})
+	internal := g.response("the request failed", ErrorResponse{})
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "walleter",
+			"version": openAPIVersion,
+		},
+		"paths": object{
+			"/v1/wallets": object{
+				"post": object{
+					"operationId": "createWallet",
+					"summary":     "Create the wallet of an account, an existing wallet is returned as it is",
+					"requestBody": g.requestBody(CreateWalletRequest{}),
+					"responses": object{
+						"200": g.response("the wallet already existed", walleter.Wallet{}),
+						"201": g.response("the wallet was created", walleter.Wallet{}),
+						"400": invalid,
+						"500": internal,
+					},
+				},
+			},
+			"/v1/wallets/{account_id}": object{
+				"get": object{
+					"operationId": "getWallet",
+					"summary":     "Read the wallet of an account",
+					"parameters":  []interface{}{accountId},
+					"responses":   object{"200": wallet, "400": invalid, "404": notFound, "500": internal},
+				},
+			},
+			"/v1/wallets/{account_id}/commands": object{
+				"post": object{
+					"operationId": "executeCommand",
+					"summary":     "Execute a command on the wallet of an account",
+					"parameters":  []interface{}{accountId},
+					"requestBody": g.requestBody(CommandRequest{}),
+					"responses": object{
+						"200": wallet,
+						"202": g.response("the withdrawal is held for approval", HeldWithdrawalResponse{}),
+						"400": invalid,
+						"403": g.response("adjustments need an approved request", ErrorResponse{}),
+						"404": notFound,
+						"409": g.response("the account is frozen", ErrorResponse{}),
+						"422": g.response("the wallet cannot afford the command", ErrorResponse{}),
+						"500": internal,
+					},
+				},
+			},
+			"/v1/wallets/{account_id}/logs": object{
+				"get": object{
+					"operationId": "listWalletLogs",
+					"summary":     "List the logs of the wallet of an account, newest first unless order is asc",
+					"parameters": []interface{}{
+						accountId,
+						queryParameter("asset_type", enumSchema(assetTypes), true),
+						queryParameter("action_type", enumSchema(logActionTypes), true),
+						queryParameter("status", enumSchema(statuses), true),
+						queryParameter("source", enumSchema(sources), true),
+						queryParameter("token", enumSchema(tokens), true),
+						queryParameter("token_id", object{"type": "integer", "format": "uint64"}, true),
+						queryParameter("business_module", object{"type": "string"}, false),
+						queryParameter("from", object{"type": "string", "format": "date-time"}, false),
+						queryParameter("to", object{"type": "string", "format": "date-time"}, false),
+						queryParameter("cursor", object{"type": "string"}, false),
+						queryParameter("limit", object{"type": "integer", "minimum": 1}, false),
+						queryParameter("order", object{"type": "string", "enum": []string{"asc", "desc"}}, false),
+					},
+					"responses": object{
+						"200": g.response("a page of logs", walleter.WalletLogPage{}),
+						"400": invalid,
+						"500": internal,
+					},
+				},
+			},
+		},
+		"components": object{"schemas": g.schemas},
+	}
+}
+
+func (g *openAPIGenerator) requestBody(v interface{}) object {
+	return object{
+		"required": true,
+		"content":  object{"application/json": object{"schema": g.schemaOf(reflect.TypeOf(v))}},
+	}
+}
+
+func (g *openAPIGenerator) response(description string, v interface{}) object {
+	return object{
+		"description": description,
+		"content":     object{"application/json": object{"schema": g.schemaOf(reflect.TypeOf(v))}},
+	}
+}
+
+// queryParameter a query parameter, repeated ones filter on any of their values.
+func queryParameter(name string, schema object, repeated bool) object {
+	if repeated {
+		schema = object{"type": "array", "items": schema}
+	}
+	return object{"name": name, "in": "query", "schema": schema, "explode": true}
+}
+
+func enumSchema[T interface{ String() string }](values []T) object {
+	var names []string
+	for _, value := range values {
+		names = append(names, value.String())
+	}
+	return object{"type": "string", "enum": names}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf the schema of t, structs are added to the components and referenced.
+func (g *openAPIGenerator) schemaOf(t reflect.Type) object {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return object{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		name := t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// registered first, so that recursive types reference it instead of recursing
+			g.schemas[name] = nil
+			properties, required := object{}, []string{}
+			g.addFields(t, properties, &required)
+			schema := object{"type": "object", "properties": properties}
+			if len(required) > 0 {
+				schema["required"] = required
+			}
+			g.schemas[name] = schema
+		}
+		return object{"$ref": "#/components/schemas/" + name}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return object{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return object{"type": "integer", "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer", "format": t.Kind().String(), "minimum": 0}
+	case reflect.Float32:
+		return object{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return object{"type": "number", "format": "double"}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return object{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	}
+	return object{}
+}
+
+// addFields adds the JSON fields of struct t, including those of embedded structs, to properties.
+func (g *openAPIGenerator) addFields(t reflect.Type, properties object, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("swagger-ignore") == "true" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := g.schemaOf(field.Type)
+		if enum := field.Tag.Get("enum"); enum != "" {
+			schema = object{"type": "string", "enum": strings.Split(enum, ",")}
+		}
+		properties[name] = schema
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+}
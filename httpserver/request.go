@@ -0,0 +1,199 @@
+package httpserver
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+// maxBusinessModuleLength the size of the business_module column of logs.
+const maxBusinessModuleLength = 64
+
+var (
+	tokens      = []walleter.ERC20TokenEnum{walleter.ETH, walleter.BNB, walleter.USDT, walleter.USDC, walleter.BUSD, walleter.NAMIX, walleter.FISHX}
+	assetTypes  = []walleter.AssetType{walleter.ERC20AssetType, walleter.ERC1155AssetType}
+	actionTypes = []walleter.WalletActionType{walleter.Income, walleter.Spend, walleter.Deposit, walleter.Withdraw, walleter.ChargeFee}
+	sources     = []walleter.CommandSourceType{walleter.InGame, walleter.Ethereum, walleter.GoerliTestnet, walleter.BSC, walleter.BSCTestnet}
+	statuses    = []walleter.WalletLogStatus{walleter.Pending, walleter.Done, walleter.Failed, walleter.Held}
+
+	// logActionTypes the action types of logs, commands of the API never initialize wallets, revert deposits or deduct.
+	logActionTypes = []walleter.WalletActionType{walleter.Initialize, walleter.Income, walleter.Spend, walleter.Deposit,
+		walleter.Withdraw, walleter.ChargeFee, walleter.RevertDeposit, walleter.Deduct}
+)
+
+// command validates the request and converts it into the command of accountId, otherwise returns
+// what is wrong with each invalid field.
+func (request CommandRequest) command(accountId uint64) (walleter.WalletCommand, map[string]string) {
+	fields := map[string]string{}
+
+	assetType, ok := parseName(request.AssetType, assetTypes)
+	if !ok {
+		fields["asset_type"] = "must be erc20 or erc1155"
+	}
+	actionType, ok := parseName(request.ActionType, actionTypes)
+	if !ok {
+		fields["action_type"] = "must be income, spend, deposit, withdraw or fee"
+	} else if assetType == walleter.ERC1155AssetType && actionType == walleter.ChargeFee {
+		fields["action_type"] = "fee is not supported for erc1155"
+	}
+	source := walleter.InGame
+	if request.Source != "" {
+		if source, ok = parseName(request.Source, sources); !ok {
+			fields["source"] = "unknown source"
+		}
+	}
+	if strings.TrimSpace(request.BusinessModule) == "" {
+		fields["business_module"] = "is required"
+	} else if len(request.BusinessModule) > maxBusinessModuleLength {
+		fields["business_module"] = fmt.Sprintf("must be at most %d characters", maxBusinessModuleLength)
+	}
+	fees := tokenAmounts("fees", request.Fees, fields)
+
+	if assetType == walleter.ERC1155AssetType {
+		if len(request.Items) == 0 {
+			fields["items"] = "is required for erc1155 commands"
+		}
+		if len(request.Tokens) > 0 {
+			fields["tokens"] = "is not allowed for erc1155 commands"
+		}
+		var ids, values []uint64
+		seen := map[uint64]bool{}
+		for index, item := range request.Items {
+			field := fmt.Sprintf("items[%d]", index)
+			switch {
+			case item.Amount == 0:
+				fields[field] = "amount must be positive"
+			case seen[item.Id]:
+				fields[field] = fmt.Sprintf("id %d is repeated", item.Id)
+			}
+			seen[item.Id] = true
+			ids = append(ids, item.Id)
+			values = append(values, item.Amount)
+		}
+		return walleter.NewERC1155WalletCommand(accountId, actionType, request.BusinessModule, source, ids, values, fees), fields
+	}
+
+	if len(request.Tokens) == 0 {
+		fields["tokens"] = "is required for erc20 commands"
+	}
+	if len(request.Items) > 0 {
+		fields["items"] = "is not allowed for erc20 commands"
+	}
+	tokens := tokenAmounts("tokens", request.Tokens, fields)
+	return walleter.NewERC20WalletCommand(accountId, actionType, request.BusinessModule, source, tokens, fees), fields
+}
+
+// tokenAmounts parses the token amounts of a request, recording invalid ones in fields.
+func tokenAmounts(name string, amounts map[string]float64, fields map[string]string) map[walleter.ERC20TokenEnum]float64 {
+	result := map[walleter.ERC20TokenEnum]float64{}
+	for symbol, amount := range amounts {
+		token, ok := parseToken(symbol)
+		switch {
+		case !ok:
+			fields[name+"."+symbol] = "unknown token"
+		case math.IsNaN(amount) || math.IsInf(amount, 0) || amount <= 0:
+			fields[name+"."+symbol] = "amount must be positive"
+		default:
+			result[token] += amount
+		}
+	}
+	return result
+}
+
+// logQueryOf the log query of accountId described by the query string, otherwise what is wrong with each parameter.
+func logQueryOf(values url.Values, accountId uint64) (walleter.LogQuery, map[string]string) {
+	fields := map[string]string{}
+	query := walleter.LogQuery{
+		AccountId:      accountId,
+		BusinessModule: values.Get("business_module"),
+		Cursor:         values.Get("cursor"),
+	}
+
+	for _, value := range values["asset_type"] {
+		assetType, ok := parseName(value, assetTypes)
+		if !ok {
+			fields["asset_type"] = "must be erc20 or erc1155"
+		}
+		query.AssetTypes = append(query.AssetTypes, assetType)
+	}
+	for _, value := range values["action_type"] {
+		actionType, ok := parseName(value, logActionTypes)
+		if !ok {
+			fields["action_type"] = "unknown action type"
+		}
+		query.ActionTypes = append(query.ActionTypes, actionType)
+	}
+	for _, value := range values["source"] {
+		source, ok := parseName(value, sources)
+		if !ok {
+			fields["source"] = "unknown source"
+		}
+		query.CommandSources = append(query.CommandSources, source)
+	}
+	for _, value := range values["status"] {
+		status, ok := parseName(value, statuses)
+		if !ok {
+			fields["status"] = "must be pending, done, failed or held"
+		}
+		query.Statuses = append(query.Statuses, status)
+	}
+	for _, value := range values["token"] {
+		token, ok := parseToken(value)
+		if !ok {
+			fields["token"] = "unknown token"
+		}
+		query.Tokens = append(query.Tokens, token)
+	}
+	for _, value := range values["token_id"] {
+		tokenId, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			fields["token_id"] = "must be an unsigned integer"
+		}
+		query.TokenIds = append(query.TokenIds, tokenId)
+	}
+
+	for name, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if value := values.Get(name); value != "" {
+			at, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				fields[name] = "must be an RFC 3339 time"
+			}
+			*target = at
+		}
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			fields["limit"] = "must be a positive integer"
+		}
+		query.Limit = limit
+	}
+	switch values.Get("order") {
+	case "", "desc":
+	case "asc":
+		query.Ascending = true
+	default:
+		fields["order"] = "must be asc or desc"
+	}
+	return query, fields
+}
+
+// parseName returns the value of names whose String() is name.
+func parseName[T fmt.Stringer](name string, names []T) (T, bool) {
+	for _, item := range names {
+		if item.String() == name {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func parseToken(symbol string) (walleter.ERC20TokenEnum, bool) {
+	return parseName(strings.ToUpper(symbol), tokens)
+}
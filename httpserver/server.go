@@ -0,0 +1,234 @@
+// Package httpserver serves a Walleter as a JSON HTTP API, described by the OpenAPI document
+// served at /openapi.json. Server is an http.Handler, so it mounts on any mux and runs under httptest.
+//
+//	POST /v1/wallets                       create the wallet of an account
+//	GET  /v1/wallets/{account_id}          read a wallet
+//	POST /v1/wallets/{account_id}/commands execute a command on a wallet, 202 when a withdrawal is held for approval
+//	GET  /v1/wallets/{account_id}/logs     list the logs of a wallet, newest first
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+const maxRequestBytes = 1 << 20
+
+// Server the HTTP API of a Walleter created by walleter.New, listing logs reads the database directly.
+type Server struct {
+	w *walleter.Walleter
+}
+
+// NewServer returns the HTTP API of w.
+func NewServer(w *walleter.Walleter) *Server {
+	return &Server{w: w}
+}
+
+// CreateWalletRequest body of POST /v1/wallets.
+type CreateWalletRequest struct {
+	AccountId uint64 `json:"account_id"`
+}
+
+// CommandRequest body of POST /v1/wallets/{account_id}/commands. ERC20 commands name their amounts
+// in tokens, ERC1155 commands in items; fees are charged in ERC20 tokens for both.
+type CommandRequest struct {
+	AssetType      string             `json:"asset_type" enum:"erc20,erc1155"`
+	ActionType     string             `json:"action_type" enum:"income,spend,deposit,withdraw,fee"`
+	BusinessModule string             `json:"business_module"`
+	Source         string             `json:"source,omitempty" enum:"game,ethereum,goerli_testnet,bsc,bsc_testnet"`
+	Tokens         map[string]float64 `json:"tokens,omitempty"`
+	Items          []ItemRequest      `json:"items,omitempty"`
+	Fees           map[string]float64 `json:"fees,omitempty"`
+}
+
+// ItemRequest an ERC1155 amount of a CommandRequest.
+type ItemRequest struct {
+	Id     uint64 `json:"id"`
+	Amount uint64 `json:"amount"`
+}
+
+// HeldWithdrawalResponse body of a withdrawal held for approval, answered with 202 Accepted. Wallet locks
+// the withdrawn amounts until the hold is approved, rejected or expires.
+type HeldWithdrawalResponse struct {
+	HoldId uint            `json:"hold_id"`
+	Wallet walleter.Wallet `json:"wallet"`
+}
+
+// ErrorResponse body of every failed request. Code is a walleter error code, or invalid_request,
+// not_found and internal; Fields explains invalid_request per field.
+type ErrorResponse struct {
+	Code    string                `json:"code"`
+	Message string                `json:"message"`
+	Fields  map[string]string     `json:"fields,omitempty"`
+	Detail  *walleter.WalletError `json:"detail,omitempty"`
+}
+
+func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "openapi.json" {
+		s.route(rw, r, map[string]http.HandlerFunc{http.MethodGet: s.openAPI})
+		return
+	}
+
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 || segments[0] != "v1" || segments[1] != "wallets" {
+		writeError(rw, http.StatusNotFound, ErrorResponse{Code: "not_found", Message: "no such endpoint"})
+		return
+	}
+	if len(segments) == 2 {
+		s.route(rw, r, map[string]http.HandlerFunc{http.MethodPost: s.createWallet})
+		return
+	}
+
+	accountId, err := strconv.ParseUint(segments[2], 10, 64)
+	if err != nil || accountId == 0 {
+		writeError(rw, http.StatusBadRequest, ErrorResponse{Code: "invalid_request", Message: "invalid account id",
+			Fields: map[string]string{"account_id": "must be a positive integer"}})
+		return
+	}
+	switch {
+	case len(segments) == 3:
+		s.route(rw, r, map[string]http.HandlerFunc{http.MethodGet: func(rw http.ResponseWriter, r *http.Request) {
+			s.getWallet(rw, r, accountId)
+		}})
+	case len(segments) == 4 && segments[3] == "commands":
+		s.route(rw, r, map[string]http.HandlerFunc{http.MethodPost: func(rw http.ResponseWriter, r *http.Request) {
+			s.executeCommand(rw, r, accountId)
+		}})
+	case len(segments) == 4 && segments[3] == "logs":
+		s.route(rw, r, map[string]http.HandlerFunc{http.MethodGet: func(rw http.ResponseWriter, r *http.Request) {
+			s.listLogs(rw, r, accountId)
+		}})
+	default:
+		writeError(rw, http.StatusNotFound, ErrorResponse{Code: "not_found", Message: "no such endpoint"})
+	}
+}
+
+// route calls the handler of the request method, answering 405 for the others.
+func (s *Server) route(rw http.ResponseWriter, r *http.Request, handlers map[string]http.HandlerFunc) {
+	handler, ok := handlers[r.Method]
+	if !ok {
+		for method := range handlers {
+			rw.Header().Add("Allow", method)
+		}
+		writeError(rw, http.StatusMethodNotAllowed, ErrorResponse{Code: "method_not_allowed", Message: r.Method + " is not allowed"})
+		return
+	}
+	handler(rw, r)
+}
+
+func (s *Server) createWallet(rw http.ResponseWriter, r *http.Request) {
+	var request CreateWalletRequest
+	if !decodeBody(rw, r, &request) {
+		return
+	}
+	if request.AccountId == 0 {
+		writeError(rw, http.StatusBadRequest, ErrorResponse{Code: "invalid_request", Message: "invalid request",
+			Fields: map[string]string{"account_id": "must be a positive integer"}})
+		return
+	}
+
+	// an existing wallet is returned as it is
+	statusCode := http.StatusOK
+	if _, err := s.w.GetWalletByAccountId(request.AccountId); errors.Is(err, gorm.ErrRecordNotFound) {
+		statusCode = http.StatusCreated
+	}
+	wallet, err := s.w.ExecuteCommand(r.Context(), walleter.NewInitWalletCommand(request.AccountId))
+	if err != nil {
+		writeWalletError(rw, err)
+		return
+	}
+	writeJSON(rw, statusCode, wallet)
+}
+
+func (s *Server) getWallet(rw http.ResponseWriter, r *http.Request, accountId uint64) {
+	wallet, err := s.w.GetWalletByAccountId(accountId)
+	if err != nil {
+		writeWalletError(rw, err)
+		return
+	}
+	writeJSON(rw, http.StatusOK, wallet)
+}
+
+func (s *Server) executeCommand(rw http.ResponseWriter, r *http.Request, accountId uint64) {
+	var request CommandRequest
+	if !decodeBody(rw, r, &request) {
+		return
+	}
+	command, fields := request.command(accountId)
+	if len(fields) > 0 {
+		writeError(rw, http.StatusBadRequest, ErrorResponse{Code: "invalid_request", Message: "invalid command", Fields: fields})
+		return
+	}
+
+	// commands on a missing wallet are reported as such instead of an internal error
+	if _, err := s.w.GetWalletByAccountId(accountId); err != nil {
+		writeWalletError(rw, err)
+		return
+	}
+	wallet, err := s.w.ExecuteCommand(r.Context(), command)
+	var held *walleter.WithdrawalHeldError
+	if errors.As(err, &held) {
+		writeJSON(rw, http.StatusAccepted, HeldWithdrawalResponse{HoldId: held.HoldId, Wallet: wallet})
+		return
+	}
+	if err != nil {
+		writeWalletError(rw, err)
+		return
+	}
+	writeJSON(rw, http.StatusOK, wallet)
+}
+
+func (s *Server) listLogs(rw http.ResponseWriter, r *http.Request, accountId uint64) {
+	query, fields := logQueryOf(r.URL.Query(), accountId)
+	if len(fields) > 0 {
+		writeError(rw, http.StatusBadRequest, ErrorResponse{Code: "invalid_request", Message: "invalid log query", Fields: fields})
+		return
+	}
+	page, err := s.w.ListWalletLogs(query)
+	if errors.Is(err, walleter.ErrInvalidCursor) {
+		writeError(rw, http.StatusBadRequest, ErrorResponse{Code: "invalid_request", Message: err.Error(),
+			Fields: map[string]string{"cursor": err.Error()}})
+		return
+	}
+	if err != nil {
+		writeWalletError(rw, err)
+		return
+	}
+	if page.Entries == nil {
+		page.Entries = []walleter.WalletLogEntry{}
+	}
+	writeJSON(rw, http.StatusOK, page)
+}
+
+func (s *Server) openAPI(rw http.ResponseWriter, r *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	_, _ = rw.Write(OpenAPI())
+}
+
+// decodeBody decodes the JSON body of r into v, answering 400 and returning false when it is malformed.
+func decodeBody(rw http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(rw, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(rw, http.StatusBadRequest, ErrorResponse{Code: "invalid_request", Message: "malformed body: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+func writeJSON(rw http.ResponseWriter, statusCode int, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(statusCode)
+	_ = json.NewEncoder(rw).Encode(v)
+}
+
+func writeError(rw http.ResponseWriter, statusCode int, response ErrorResponse) {
+	writeJSON(rw, statusCode, response)
+}
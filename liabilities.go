@@ -0,0 +1,181 @@
+package walleter
+
+import (
+	"sort"
+	"time"
+)
+
+// TokenLiability the total balance of an ERC20 token, split by the kind of account holding it.
+type TokenLiability struct {
+	Token      string  `json:"token"`
+	Users      float64 `json:"users"`
+	FeeCharger float64 `json:"fee_charger"`
+	System     float64 `json:"system"`
+	Total      float64 `json:"total"`
+}
+
+// ItemLiability the total amount of an ERC1155 id, split by the kind of account holding it.
+type ItemLiability struct {
+	Id         uint64 `json:"id"`
+	Users      uint64 `json:"users"`
+	FeeCharger uint64 `json:"fee_charger"`
+	System     uint64 `json:"system"`
+	Total      uint64 `json:"total"`
+}
+
+// LiabilityReport what the game owes its players next to what its own accounts hold.
+type LiabilityReport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	ERC20       []TokenLiability `json:"erc_20"`
+	ERC1155     []ItemLiability  `json:"erc_1155"`
+}
+
+// CustodyBalances the assets actually held in custody (hot/cold wallets, contracts), supplied by the caller.
+type CustodyBalances struct {
+	ERC20   map[string]float64
+	ERC1155 map[uint64]uint64
+}
+
+// TokenSolvency compares the custody of an ERC20 token with the balances owed to users.
+type TokenSolvency struct {
+	Token     string  `json:"token"`
+	Custody   float64 `json:"custody"`
+	Owed      float64 `json:"owed"`
+	Ledger    float64 `json:"ledger"`
+	Shortfall float64 `json:"shortfall"`
+	Solvent   bool    `json:"solvent"`
+}
+
+// ItemSolvency compares the custody of an ERC1155 id with the amount owed to users.
+type ItemSolvency struct {
+	Id        uint64 `json:"id"`
+	Custody   uint64 `json:"custody"`
+	Owed      uint64 `json:"owed"`
+	Ledger    uint64 `json:"ledger"`
+	Shortfall uint64 `json:"shortfall"`
+	Solvent   bool   `json:"solvent"`
+}
+
+// SolvencyCheck the result of comparing a LiabilityReport with custody balances.
+type SolvencyCheck struct {
+	Solvent bool            `json:"solvent"`
+	ERC20   []TokenSolvency `json:"erc_20"`
+	ERC1155 []ItemSolvency  `json:"erc_1155"`
+}
+
+// GetLiabilityReport sums balances of every wallet per token and per ERC1155 id, funds locked by held withdrawals
+// included since they are still owed. Balances of the fee charger and of systemAccountIds are reported separately
+// from the balances owed to users.
+func (s *Walleter) GetLiabilityReport(systemAccountIds []uint64) (LiabilityReport, error) {
+	report := LiabilityReport{GeneratedAt: time.Now()}
+	systemAccounts := map[uint64]bool{}
+	for _, id := range systemAccountIds {
+		systemAccounts[id] = true
+	}
+
+	tokens := map[string]*TokenLiability{}
+	items := map[uint64]*ItemLiability{}
+	err := forEachWallet(s.repo, func(wallet Wallet) error {
+		for _, token := range wallet.ERC20TokenData {
+			if tokens[token.Token] == nil {
+				tokens[token.Token] = &TokenLiability{Token: token.Token}
+			}
+			item := tokens[token.Token]
+			balance := token.Balance + token.Locked
+			switch {
+			case wallet.AccountId == feeChargerAccountId:
+				item.FeeCharger += balance
+			case systemAccounts[wallet.AccountId]:
+				item.System += balance
+			default:
+				item.Users += balance
+			}
+			item.Total += balance
+		}
+		for _, amount := range wallet.ERC1155TokenData.Amounts() {
+			if amount.Amount == 0 {
+				continue
+			}
+			if items[amount.Id] == nil {
+				items[amount.Id] = &ItemLiability{Id: amount.Id}
+			}
+			item := items[amount.Id]
+			switch {
+			case wallet.AccountId == feeChargerAccountId:
+				item.FeeCharger += amount.Amount
+			case systemAccounts[wallet.AccountId]:
+				item.System += amount.Amount
+			default:
+				item.Users += amount.Amount
+			}
+			item.Total += amount.Amount
+		}
+		return nil
+	})
+	if err != nil {
+		return LiabilityReport{}, err
+	}
+	for _, item := range tokens {
+		report.ERC20 = append(report.ERC20, *item)
+	}
+	sort.Slice(report.ERC20, func(i, j int) bool { return report.ERC20[i].Token < report.ERC20[j].Token })
+	for _, item := range items {
+		report.ERC1155 = append(report.ERC1155, *item)
+	}
+	sort.Slice(report.ERC1155, func(i, j int) bool { return report.ERC1155[i].Id < report.ERC1155[j].Id })
+	return report, nil
+}
+
+// CheckSolvency compares custody with what is owed to users. A token is solvent when custody covers
+// every user balance, assets held by the fee charger and system accounts belong to the game itself.
+// Tokens present only in custody are reported with nothing owed.
+func (report LiabilityReport) CheckSolvency(custody CustodyBalances) SolvencyCheck {
+	check := SolvencyCheck{Solvent: true}
+
+	seenTokens := map[string]bool{}
+	for _, item := range report.ERC20 {
+		seenTokens[item.Token] = true
+		result := TokenSolvency{
+			Token:   item.Token,
+			Custody: custody.ERC20[item.Token],
+			Owed:    item.Users,
+			Ledger:  item.Total,
+		}
+		if result.Custody < result.Owed {
+			result.Shortfall = result.Owed - result.Custody
+		}
+		result.Solvent = result.Shortfall == 0
+		check.Solvent = check.Solvent && result.Solvent
+		check.ERC20 = append(check.ERC20, result)
+	}
+	for token, amount := range custody.ERC20 {
+		if !seenTokens[token] {
+			check.ERC20 = append(check.ERC20, TokenSolvency{Token: token, Custody: amount, Solvent: true})
+		}
+	}
+	sort.Slice(check.ERC20, func(i, j int) bool { return check.ERC20[i].Token < check.ERC20[j].Token })
+
+	seenItems := map[uint64]bool{}
+	for _, item := range report.ERC1155 {
+		seenItems[item.Id] = true
+		result := ItemSolvency{
+			Id:      item.Id,
+			Custody: custody.ERC1155[item.Id],
+			Owed:    item.Users,
+			Ledger:  item.Total,
+		}
+		if result.Custody < result.Owed {
+			result.Shortfall = result.Owed - result.Custody
+		}
+		result.Solvent = result.Shortfall == 0
+		check.Solvent = check.Solvent && result.Solvent
+		check.ERC1155 = append(check.ERC1155, result)
+	}
+	for id, amount := range custody.ERC1155 {
+		if !seenItems[id] {
+			check.ERC1155 = append(check.ERC1155, ItemSolvency{Id: id, Custody: amount, Solvent: true})
+		}
+	}
+	sort.Slice(check.ERC1155, func(i, j int) bool { return check.ERC1155[i].Id < check.ERC1155[j].Id })
+	return check
+}
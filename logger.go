@@ -0,0 +1,110 @@
+package walleter
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Logger receives the log events of walleter. keyvals are alternating keys and values,
+// keys are strings. Adapters are available for logrus, slog and zap.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// nopLogger discards every event, it is the logger of a Walleter created without WithLogger.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+type logrusLogger struct {
+	logger log.FieldLogger
+}
+
+// NewLogrusLogger adapts a logrus logger or entry.
+func NewLogrusLogger(logger log.FieldLogger) Logger {
+	return &logrusLogger{logger: logger}
+}
+
+func (l *logrusLogger) Debug(msg string, keyvals ...interface{}) {
+	l.logger.WithFields(logrusFields(keyvals)).Debug(msg)
+}
+
+func (l *logrusLogger) Info(msg string, keyvals ...interface{}) {
+	l.logger.WithFields(logrusFields(keyvals)).Info(msg)
+}
+
+func (l *logrusLogger) Warn(msg string, keyvals ...interface{}) {
+	l.logger.WithFields(logrusFields(keyvals)).Warn(msg)
+}
+
+func (l *logrusLogger) Error(msg string, keyvals ...interface{}) {
+	l.logger.WithFields(logrusFields(keyvals)).Error(msg)
+}
+
+func logrusFields(keyvals []interface{}) log.Fields {
+	fields := log.Fields{}
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = "!badkey"
+		}
+		if i+1 < len(keyvals) {
+			fields[key] = keyvals[i+1]
+		} else {
+			fields[key] = "!missing"
+		}
+	}
+	return fields
+}
+
+// commandKeyvals the fields describing command in log events.
+func commandKeyvals(command WalletCommand) []interface{} {
+	return []interface{}{
+		"account_id", command.AccountId,
+		"action_type", command.ActionType.String(),
+		"asset_type", command.AssetType.String(),
+		"business_module", command.BusinessModule,
+		"source", command.CommandSource.String(),
+	}
+}
+
+// logCommand reports the outcome of one HandleWalletCommand call, or of the resolution of a held withdrawal.
+func (s *Walleter) logCommand(command WalletCommand, err error, elapsed time.Duration) {
+	keyvals := append(commandKeyvals(command), "elapsed", elapsed)
+	if held := heldWithdrawalOf(err); held != nil {
+		s.logger.Info("withdrawal held for approval", append(keyvals, "hold_id", held.HoldId)...)
+		return
+	}
+	if err != nil {
+		code := ErrorCodeOf(err)
+		keyvals = append(keyvals, "code", string(code), "error", err.Error())
+		switch code {
+		case CodeIncorrectCheckSign:
+			s.logger.Error("wallet check sign is invalid, wallet data was changed outside walleter", keyvals...)
+		case CodeInternal:
+			s.logger.Error("wallet command failed", keyvals...)
+		default:
+			s.logger.Warn("wallet command rejected", keyvals...)
+		}
+		return
+	}
+
+	for _, fee := range command.FeeCommands {
+		if fee.Value > 0 {
+			s.logger.Info("fee charged",
+				"account_id", command.AccountId,
+				"fee_charger_account_id", feeChargerAccountId,
+				"token", fee.Token.String(),
+				"amount", fee.Value,
+				"business_module", command.BusinessModule)
+		}
+	}
+	s.logger.Info("wallet command finished", keyvals...)
+}
@@ -0,0 +1,33 @@
+//go:build go1.21
+
+package walleter
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger adapts a log/slog logger.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Debug(msg string, keyvals ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelDebug, msg, keyvals...)
+}
+
+func (l *slogLogger) Info(msg string, keyvals ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelInfo, msg, keyvals...)
+}
+
+func (l *slogLogger) Warn(msg string, keyvals ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelWarn, msg, keyvals...)
+}
+
+func (l *slogLogger) Error(msg string, keyvals ...interface{}) {
+	l.logger.Log(context.Background(), slog.LevelError, msg, keyvals...)
+}
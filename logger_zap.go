@@ -0,0 +1,28 @@
+package walleter
+
+import "go.uber.org/zap"
+
+type zapLogger struct {
+	logger *zap.SugaredLogger
+}
+
+// NewZapLogger adapts a zap logger.
+func NewZapLogger(logger *zap.Logger) Logger {
+	return &zapLogger{logger: logger.WithOptions(zap.AddCallerSkip(1)).Sugar()}
+}
+
+func (l *zapLogger) Debug(msg string, keyvals ...interface{}) {
+	l.logger.Debugw(msg, keyvals...)
+}
+
+func (l *zapLogger) Info(msg string, keyvals ...interface{}) {
+	l.logger.Infow(msg, keyvals...)
+}
+
+func (l *zapLogger) Warn(msg string, keyvals ...interface{}) {
+	l.logger.Warnw(msg, keyvals...)
+}
+
+func (l *zapLogger) Error(msg string, keyvals ...interface{}) {
+	l.logger.Errorw(msg, keyvals...)
+}
@@ -0,0 +1,424 @@
+package walleter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLogQueryLimit = 20
+	maxLogQueryLimit     = 500
+	logQueryBatchSize    = 200
+)
+
+// LogQuery filters of wallet log history. Empty slices and zero times mean "no filter".
+type LogQuery struct {
+	AccountId      uint64
+	AssetTypes     []AssetType
+	ActionTypes    []WalletActionType
+	BusinessModule string
+	CommandSources []CommandSourceType
+	Statuses       []WalletLogStatus
+
+	// Tokens matches logs which change or charge fees in any of these ERC20 tokens.
+	Tokens []ERC20TokenEnum
+	// TokenIds matches ERC1155 logs which change any of these ids.
+	TokenIds []uint64
+
+	From time.Time
+	To   time.Time
+
+	// Cursor is the NextCursor of the previous page, empty for the first page.
+	Cursor string
+	// Limit page size, defaults to 20 and is capped at 500.
+	Limit int
+	// Ascending returns the oldest logs first, otherwise newest first.
+	Ascending bool
+}
+
+// TokenAmount a decoded ERC20 amount of a log.
+type TokenAmount struct {
+	Token   string  `json:"token"`
+	Amount  float64 `json:"amount"`
+	Decimal uint64  `json:"decimal"`
+}
+
+// ItemAmount a decoded ERC1155 amount of a log.
+type ItemAmount struct {
+	Id     uint64 `json:"id"`
+	Amount uint64 `json:"amount"`
+}
+
+// WalletLogEntry one decoded row of ERC20WalletLog or ERC1155WalletLog.
+type WalletLogEntry struct {
+	Id             uint             `json:"id"`
+	AssetType      AssetType        `json:"asset_type"`
+	AccountId      uint64           `json:"account_id"`
+	BusinessModule string           `json:"business_module"`
+	ActionType     string           `json:"action_type"`
+	Source         string           `json:"source"`
+	Status         string           `json:"status"`
+	FailureDetail  *WalletError     `json:"failure_detail,omitempty"`
+	Approval       *CommandApproval `json:"approval,omitempty"`
+	ChainRef       *ChainReference  `json:"chain_ref,omitempty"`
+	Tokens         []TokenAmount    `json:"tokens"`
+	Items          []ItemAmount     `json:"items"`
+	Fees           []TokenAmount    `json:"fees"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// WalletLogPage a page of wallet logs, NextCursor is empty when there are no more logs.
+type WalletLogPage struct {
+	Entries    []WalletLogEntry `json:"entries"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+// logCursor remembers the last returned id of each log table.
+type logCursor struct {
+	ERC20   uint `json:"erc20"`
+	ERC1155 uint `json:"erc1155"`
+}
+
+func (c logCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeLogCursor(cursor string) (logCursor, error) {
+	var c logCursor
+	if cursor == "" {
+		return c, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err = json.Unmarshal(b, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// ListWalletLogs returns ERC20 and ERC1155 logs matching query, merged by creation time.
+func (s *Walleter) ListWalletLogs(query LogQuery) (WalletLogPage, error) {
+	cursor, err := decodeLogCursor(query.Cursor)
+	if err != nil {
+		return WalletLogPage{}, err
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLogQueryLimit
+	}
+	if limit > maxLogQueryLimit {
+		limit = maxLogQueryLimit
+	}
+
+	var entries []WalletLogEntry
+	if query.matchesAssetType(ERC20AssetType) {
+		erc20Entries, err := findERC20Entries(s.repo, query, cursor.ERC20, limit+1)
+		if err != nil {
+			return WalletLogPage{}, err
+		}
+		entries = append(entries, erc20Entries...)
+	}
+	if query.matchesAssetType(ERC1155AssetType) {
+		erc1155Entries, err := findERC1155Entries(s.repo, query, cursor.ERC1155, limit+1)
+		if err != nil {
+			return WalletLogPage{}, err
+		}
+		entries = append(entries, erc1155Entries...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt) == query.Ascending
+		}
+		if a.AssetType != b.AssetType {
+			return (a.AssetType < b.AssetType) == query.Ascending
+		}
+		return (a.Id < b.Id) == query.Ascending
+	})
+
+	page := WalletLogPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		for _, entry := range page.Entries {
+			if entry.AssetType == ERC20AssetType {
+				cursor.ERC20 = entry.Id
+			} else {
+				cursor.ERC1155 = entry.Id
+			}
+		}
+		page.NextCursor = cursor.encode()
+	}
+	return page, nil
+}
+
+func (query LogQuery) matchesAssetType(assetType AssetType) bool {
+	if len(query.AssetTypes) == 0 {
+		return true
+	}
+	for _, item := range query.AssetTypes {
+		if item == assetType {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTokens reports whether any of tokens or fees is one of the queried tokens.
+func (query LogQuery) matchesTokens(tokens []TokenAmount, fees []TokenAmount) bool {
+	if len(query.Tokens) == 0 {
+		return true
+	}
+	for _, token := range query.Tokens {
+		for _, item := range tokens {
+			if item.Token == token.String() {
+				return true
+			}
+		}
+		for _, item := range fees {
+			if item.Token == token.String() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (query LogQuery) matchesTokenIds(items []ItemAmount) bool {
+	if len(query.TokenIds) == 0 {
+		return true
+	}
+	for _, item := range items {
+		if indexOfArray(query.TokenIds, item.Id) != -1 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesColumns is scope applied to a log in memory, without the cursor.
+func (query LogQuery) matchesColumns(accountId uint64, businessModule, actionType, source, status string, createdAt time.Time) bool {
+	if query.AccountId != 0 && accountId != query.AccountId {
+		return false
+	}
+	if query.BusinessModule != "" && businessModule != query.BusinessModule {
+		return false
+	}
+	if len(query.ActionTypes) > 0 && !containsName(query.ActionTypes, actionType) {
+		return false
+	}
+	if len(query.CommandSources) > 0 && !containsName(query.CommandSources, source) {
+		return false
+	}
+	if len(query.Statuses) > 0 && !containsName(query.Statuses, status) {
+		return false
+	}
+	if !query.From.IsZero() && createdAt.Before(query.From) {
+		return false
+	}
+	return query.To.IsZero() || !createdAt.After(query.To)
+}
+
+// follows reports whether the log id comes after the cursor afterId in the order of query.
+func (query LogQuery) follows(id uint, afterId uint) bool {
+	if afterId == 0 {
+		return true
+	}
+	if query.Ascending {
+		return id > afterId
+	}
+	return id < afterId
+}
+
+func containsName[T fmt.Stringer](items []T, name string) bool {
+	for _, item := range items {
+		if item.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scope applies the column filters shared by both log tables, the cursor and the ordering.
+func (query LogQuery) scope(afterId uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query.AccountId != 0 {
+			db = db.Where("account_id = ?", query.AccountId)
+		}
+		if query.BusinessModule != "" {
+			db = db.Where("business_module = ?", query.BusinessModule)
+		}
+		if len(query.ActionTypes) > 0 {
+			var actions []string
+			for _, item := range query.ActionTypes {
+				actions = append(actions, item.String())
+			}
+			db = db.Where("action_type IN ?", actions)
+		}
+		if len(query.CommandSources) > 0 {
+			var sources []string
+			for _, item := range query.CommandSources {
+				sources = append(sources, item.String())
+			}
+			db = db.Where("source IN ?", sources)
+		}
+		if len(query.Statuses) > 0 {
+			var statuses []string
+			for _, item := range query.Statuses {
+				statuses = append(statuses, item.String())
+			}
+			db = db.Where("status IN ?", statuses)
+		}
+		if !query.From.IsZero() {
+			db = db.Where("created_at >= ?", query.From)
+		}
+		if !query.To.IsZero() {
+			db = db.Where("created_at <= ?", query.To)
+		}
+		if query.Ascending {
+			if afterId > 0 {
+				db = db.Where("id > ?", afterId)
+			}
+			return db.Order("id")
+		}
+		if afterId > 0 {
+			db = db.Where("id < ?", afterId)
+		}
+		return db.Order("id DESC")
+	}
+}
+
+type walletLogQueryDAO struct{}
+
+var logQueryDAO = &walletLogQueryDAO{}
+
+func (dao walletLogQueryDAO) findERC20Logs(db *gorm.DB, query LogQuery, afterId uint, limit int) (result []ERC20WalletLog, err error) {
+	err = db.Select("id", "created_at", "updated_at", "account_id", "business_module", "action_type", "source", "tokens", "fees", "status", "failure_detail", "approval", "chain_ref").
+		Scopes(query.scope(afterId)).
+		Limit(limit).
+		Find(&result).Error
+	return result, err
+}
+
+func (dao walletLogQueryDAO) findERC1155Logs(db *gorm.DB, query LogQuery, afterId uint, limit int) (result []ERC1155WalletLog, err error) {
+	err = db.Select("id", "created_at", "updated_at", "account_id", "business_module", "action_type", "source", "ids", "values", "fees", "status", "failure_detail", "approval", "chain_ref").
+		Scopes(query.scope(afterId)).
+		Limit(limit).
+		Find(&result).Error
+	return result, err
+}
+
+// findERC20Entries scans ERC20 logs after afterId in batches until limit matching entries are found.
+// Token filters live inside json columns, so they are applied here rather than by the Repository.
+func findERC20Entries(repo Repository, query LogQuery, afterId uint, limit int) ([]WalletLogEntry, error) {
+	if len(query.TokenIds) > 0 {
+		return nil, nil
+	}
+	var result []WalletLogEntry
+	for len(result) < limit {
+		logs, err := repo.FindERC20WalletLogs(query, afterId, logQueryBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range logs {
+			afterId = item.ID
+			entry := item.toEntry()
+			if query.matchesTokens(entry.Tokens, entry.Fees) {
+				result = append(result, entry)
+			}
+		}
+		if len(logs) < logQueryBatchSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+// findERC1155Entries scans ERC1155 logs after afterId in batches until limit matching entries are found.
+func findERC1155Entries(repo Repository, query LogQuery, afterId uint, limit int) ([]WalletLogEntry, error) {
+	var result []WalletLogEntry
+	for len(result) < limit {
+		logs, err := repo.FindERC1155WalletLogs(query, afterId, logQueryBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range logs {
+			afterId = item.ID
+			entry := item.toEntry()
+			if query.matchesTokens(nil, entry.Fees) && query.matchesTokenIds(entry.Items) {
+				result = append(result, entry)
+			}
+		}
+		if len(logs) < logQueryBatchSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (l ERC20WalletLog) toEntry() WalletLogEntry {
+	return WalletLogEntry{
+		Id:             l.ID,
+		AssetType:      ERC20AssetType,
+		AccountId:      l.AccountId,
+		BusinessModule: l.BusinessModule,
+		ActionType:     l.ActionType,
+		Source:         l.Source,
+		Status:         l.Status,
+		FailureDetail:  l.FailureDetail,
+		Approval:       l.Approval,
+		ChainRef:       l.ChainRef,
+		Tokens:         l.Tokens.toTokenAmounts(),
+		Fees:           l.Fees.toTokenAmounts(),
+		CreatedAt:      l.CreatedAt,
+		UpdatedAt:      l.UpdatedAt,
+	}
+}
+
+func (l ERC1155WalletLog) toEntry() WalletLogEntry {
+	var items []ItemAmount
+	ids := convertStringToUIntArray(l.Ids)
+	values := convertStringToUIntArray(l.Values)
+	for index, id := range ids {
+		if index < len(values) {
+			items = append(items, ItemAmount{Id: id, Amount: values[index]})
+		}
+	}
+	return WalletLogEntry{
+		Id:             l.ID,
+		AssetType:      ERC1155AssetType,
+		AccountId:      l.AccountId,
+		BusinessModule: l.BusinessModule,
+		ActionType:     l.ActionType,
+		Source:         l.Source,
+		Status:         l.Status,
+		FailureDetail:  l.FailureDetail,
+		Approval:       l.Approval,
+		ChainRef:       l.ChainRef,
+		Items:          items,
+		Fees:           l.Fees.toTokenAmounts(),
+		CreatedAt:      l.CreatedAt,
+		UpdatedAt:      l.UpdatedAt,
+	}
+}
+
+func (item erc20TokenCollection) toTokenAmounts() []TokenAmount {
+	var result []TokenAmount
+	for _, data := range item.Items {
+		result = append(result, TokenAmount{
+			Token:   data.TokenType,
+			Amount:  data.Amount,
+			Decimal: data.Decimal,
+		})
+	}
+	return result
+}
@@ -0,0 +1,889 @@
+package walleter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryRepository a Repository keeping everything in memory, meant for unit tests of services using
+// walleter without a database. Transactions are serializable: a transaction works on a copy of the data
+// which replaces it on commit, calls from outside the transaction wait until it ends.
+type MemoryRepository struct {
+	mu    *sync.Mutex
+	state *memoryState
+	ctx   context.Context
+}
+
+type memoryState struct {
+	lastId           uint
+	wallets          map[uint64]Wallet
+	erc20Logs        []ERC20WalletLog
+	erc1155Logs      []ERC1155WalletLog
+	erc20Histories   []ERC20BalanceHistory
+	erc1155Histories []ERC1155BalanceHistory
+	outboxEvents     []OutboxEvent
+	freezes          map[uint64]AccountFreeze
+	auditLogs        []AuditLog
+	adjustments      []AdjustmentRequest
+	withdrawalHolds  []WithdrawalHold
+	deposits         []IngestedDeposit
+	flags            []AccountFlag
+	addresses        []DepositAddress
+	approvals        []WithdrawalApproval
+}
+
+// NewMemoryRepository returns an empty MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		mu:    &sync.Mutex{},
+		state: &memoryState{wallets: map[uint64]Wallet{}, freezes: map[uint64]AccountFreeze{}},
+		ctx:   context.Background(),
+	}
+}
+
+func (r *MemoryRepository) WithContext(ctx context.Context) Repository {
+	return &MemoryRepository{mu: r.mu, state: r.state, ctx: ctx}
+}
+
+func (r *MemoryRepository) Context() context.Context {
+	return r.ctx
+}
+
+func (r *MemoryRepository) Transaction(fn func(tx Repository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := r.state.clone()
+	tx := &MemoryRepository{mu: &sync.Mutex{}, state: snapshot, ctx: r.ctx}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	*r.state = *snapshot
+	return nil
+}
+
+func (r *MemoryRepository) GetWallet(accountId uint64) (Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, ok := r.state.wallets[accountId]
+	if !ok {
+		return Wallet{}, gorm.ErrRecordNotFound
+	}
+	return copyWallet(wallet), nil
+}
+
+func (r *MemoryRepository) ListWallets(afterAccountId uint64, limit int) ([]Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []Wallet
+	for accountId, wallet := range r.state.wallets {
+		if accountId > afterAccountId {
+			result = append(result, copyWallet(wallet))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].AccountId < result[j].AccountId })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) CreateWallet(wallet Wallet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.state.wallets[wallet.AccountId]; ok {
+		return nil
+	}
+	now := time.Now()
+	wallet = copyWallet(wallet)
+	wallet.Model = r.state.newModel(now)
+	for index := range wallet.ERC20TokenData {
+		wallet.ERC20TokenData[index].Model = r.state.newModel(now)
+		wallet.ERC20TokenData[index].AccountId = wallet.AccountId
+	}
+	wallet.ERC1155TokenData.Model = r.state.newModel(now)
+	wallet.ERC1155TokenData.AccountId = wallet.AccountId
+	r.state.wallets[wallet.AccountId] = wallet
+	return nil
+}
+
+func (r *MemoryRepository) UpdateERC20TokenWallet(tokenWallet ERC20TokenWallet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, ok := r.state.wallets[tokenWallet.AccountId]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	tokenWallet.UpdatedAt = time.Now()
+	for index, item := range wallet.ERC20TokenData {
+		if item.ID == tokenWallet.ID {
+			wallet.ERC20TokenData[index] = tokenWallet
+			return nil
+		}
+	}
+	tokenWallet.Model = r.state.newModel(tokenWallet.UpdatedAt)
+	wallet.ERC20TokenData = append(wallet.ERC20TokenData, tokenWallet)
+	r.state.wallets[tokenWallet.AccountId] = wallet
+	return nil
+}
+
+func (r *MemoryRepository) UpdateERC1155TokenWallet(tokenWallet ERC1155TokenWallet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, ok := r.state.wallets[tokenWallet.AccountId]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	tokenWallet.UpdatedAt = time.Now()
+	wallet.ERC1155TokenData = tokenWallet
+	r.state.wallets[tokenWallet.AccountId] = wallet
+	return nil
+}
+
+// LockWallet only checks that the wallet exists, transactions on a MemoryRepository already run one at a time.
+func (r *MemoryRepository) LockWallet(accountId uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.state.wallets[accountId]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *MemoryRepository) UpdateWalletCheckSign(accountId uint64, checkSign string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, ok := r.state.wallets[accountId]
+	if !ok {
+		return nil
+	}
+	wallet.CheckSign = checkSign
+	wallet.UpdatedAt = time.Now()
+	r.state.wallets[accountId] = wallet
+	return nil
+}
+
+func (r *MemoryRepository) InsertERC20WalletLog(log ERC20WalletLog) (ERC20WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.Model = r.state.newModel(time.Now())
+	log.OriginalWallet = copyWallet(log.OriginalWallet)
+	log.SettledWallet = copyWallet(log.SettledWallet)
+	r.state.erc20Logs = append(r.state.erc20Logs, log)
+	return log, nil
+}
+
+func (r *MemoryRepository) GetERC20WalletLog(id uint) (ERC20WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range r.state.erc20Logs {
+		if item.ID == id {
+			item.OriginalWallet = copyWallet(item.OriginalWallet)
+			item.SettledWallet = copyWallet(item.SettledWallet)
+			return item, nil
+		}
+	}
+	return ERC20WalletLog{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) UpdateERC20WalletLog(log ERC20WalletLog) (ERC20WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.UpdatedAt = time.Now()
+	log.OriginalWallet = copyWallet(log.OriginalWallet)
+	log.SettledWallet = copyWallet(log.SettledWallet)
+	for index, item := range r.state.erc20Logs {
+		if item.ID == log.ID {
+			r.state.erc20Logs[index] = log
+			return log, nil
+		}
+	}
+	return ERC20WalletLog{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) ListERC20WalletLogs(accountId uint64) ([]ERC20WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []ERC20WalletLog
+	for _, log := range r.state.erc20Logs {
+		if log.AccountId == accountId {
+			log.OriginalWallet = copyWallet(log.OriginalWallet)
+			log.SettledWallet = copyWallet(log.SettledWallet)
+			result = append(result, log)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) InsertERC1155WalletLog(log ERC1155WalletLog) (ERC1155WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.Model = r.state.newModel(time.Now())
+	log.OriginalWallet = copyWallet(log.OriginalWallet)
+	log.SettledWallet = copyWallet(log.SettledWallet)
+	r.state.erc1155Logs = append(r.state.erc1155Logs, log)
+	return log, nil
+}
+
+func (r *MemoryRepository) UpdateERC1155WalletLog(log ERC1155WalletLog) (ERC1155WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.UpdatedAt = time.Now()
+	log.OriginalWallet = copyWallet(log.OriginalWallet)
+	log.SettledWallet = copyWallet(log.SettledWallet)
+	for index, item := range r.state.erc1155Logs {
+		if item.ID == log.ID {
+			r.state.erc1155Logs[index] = log
+			return log, nil
+		}
+	}
+	return ERC1155WalletLog{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) ListERC1155WalletLogs(accountId uint64) ([]ERC1155WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []ERC1155WalletLog
+	for _, log := range r.state.erc1155Logs {
+		if log.AccountId == accountId {
+			log.OriginalWallet = copyWallet(log.OriginalWallet)
+			log.SettledWallet = copyWallet(log.SettledWallet)
+			result = append(result, log)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) GetERC1155WalletLog(id uint) (ERC1155WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range r.state.erc1155Logs {
+		if item.ID == id {
+			item.OriginalWallet = copyWallet(item.OriginalWallet)
+			item.SettledWallet = copyWallet(item.SettledWallet)
+			return item, nil
+		}
+	}
+	return ERC1155WalletLog{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) FindERC20WalletLogs(query LogQuery, afterId uint, limit int) ([]ERC20WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []ERC20WalletLog
+	for index := range r.state.erc20Logs {
+		if !query.Ascending {
+			index = len(r.state.erc20Logs) - 1 - index
+		}
+		log := r.state.erc20Logs[index]
+		if !query.follows(log.ID, afterId) || !query.matchesColumns(log.AccountId, log.BusinessModule, log.ActionType, log.Source, log.Status, log.CreatedAt) {
+			continue
+		}
+		log.OriginalWallet = copyWallet(log.OriginalWallet)
+		log.SettledWallet = copyWallet(log.SettledWallet)
+		if result = append(result, log); len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) FindERC1155WalletLogs(query LogQuery, afterId uint, limit int) ([]ERC1155WalletLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []ERC1155WalletLog
+	for index := range r.state.erc1155Logs {
+		if !query.Ascending {
+			index = len(r.state.erc1155Logs) - 1 - index
+		}
+		log := r.state.erc1155Logs[index]
+		if !query.follows(log.ID, afterId) || !query.matchesColumns(log.AccountId, log.BusinessModule, log.ActionType, log.Source, log.Status, log.CreatedAt) {
+			continue
+		}
+		log.OriginalWallet = copyWallet(log.OriginalWallet)
+		log.SettledWallet = copyWallet(log.SettledWallet)
+		if result = append(result, log); len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) InsertERC20BalanceHistory(history ERC20BalanceHistory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history.Model = r.state.newModel(time.Now())
+	r.state.erc20Histories = append(r.state.erc20Histories, history)
+	return nil
+}
+
+func (r *MemoryRepository) InsertERC1155BalanceHistory(history ERC1155BalanceHistory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history.Model = r.state.newModel(time.Now())
+	r.state.erc1155Histories = append(r.state.erc1155Histories, history)
+	return nil
+}
+
+func (r *MemoryRepository) GetLatestERC20BalanceHistories(accountId uint64, at time.Time) ([]ERC20BalanceHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest := map[string]ERC20BalanceHistory{}
+	for _, history := range r.state.erc20Histories {
+		if history.AccountId == accountId && !history.CreatedAt.After(at) {
+			latest[history.Token] = history
+		}
+	}
+	var result []ERC20BalanceHistory
+	for _, history := range latest {
+		result = append(result, history)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (r *MemoryRepository) GetLatestERC1155BalanceHistories(accountId uint64, at time.Time) ([]ERC1155BalanceHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latest := map[uint64]ERC1155BalanceHistory{}
+	for _, history := range r.state.erc1155Histories {
+		if history.AccountId == accountId && !history.CreatedAt.After(at) {
+			latest[history.TokenId] = history
+		}
+	}
+	var result []ERC1155BalanceHistory
+	for _, history := range latest {
+		result = append(result, history)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (r *MemoryRepository) GetEarliestERC20BalanceHistories(accountId uint64, at time.Time) ([]ERC20BalanceHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	earliest := map[string]ERC20BalanceHistory{}
+	var result []ERC20BalanceHistory
+	for _, history := range r.state.erc20Histories {
+		if _, found := earliest[history.Token]; !found && history.AccountId == accountId && history.CreatedAt.After(at) {
+			earliest[history.Token] = history
+			result = append(result, history)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) GetEarliestERC1155BalanceHistories(accountId uint64, at time.Time) ([]ERC1155BalanceHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	earliest := map[uint64]ERC1155BalanceHistory{}
+	var result []ERC1155BalanceHistory
+	for _, history := range r.state.erc1155Histories {
+		if _, found := earliest[history.TokenId]; !found && history.AccountId == accountId && history.CreatedAt.After(at) {
+			earliest[history.TokenId] = history
+			result = append(result, history)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) ListERC20BalanceHistories(accountId uint64, token string, from, to time.Time) ([]ERC20BalanceHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []ERC20BalanceHistory
+	for _, history := range r.state.erc20Histories {
+		if history.AccountId == accountId && history.Token == token && !history.CreatedAt.Before(from) && !history.CreatedAt.After(to) {
+			result = append(result, history)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) ListERC1155BalanceHistories(accountId uint64, tokenId uint64, from, to time.Time) ([]ERC1155BalanceHistory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []ERC1155BalanceHistory
+	for _, history := range r.state.erc1155Histories {
+		if history.AccountId == accountId && history.TokenId == tokenId && !history.CreatedAt.Before(from) && !history.CreatedAt.After(to) {
+			result = append(result, history)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) InsertOutboxEvent(event OutboxEvent) (OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	event.Model = r.state.newModel(time.Now())
+	r.state.outboxEvents = append(r.state.outboxEvents, event)
+	return event, nil
+}
+
+func (r *MemoryRepository) GetIngestedDeposit(chain string, txHash string, logIndex uint) (IngestedDeposit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, deposit := range r.state.deposits {
+		if deposit.Chain == chain && deposit.TxHash == txHash && deposit.LogIndex == logIndex {
+			return deposit, nil
+		}
+	}
+	return IngestedDeposit{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) InsertIngestedDeposit(deposit IngestedDeposit) (IngestedDeposit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range r.state.deposits {
+		if item.Chain == deposit.Chain && item.TxHash == deposit.TxHash && item.LogIndex == deposit.LogIndex {
+			return IngestedDeposit{}, ErrDuplicateDeposit
+		}
+	}
+	deposit.Model = r.state.newModel(time.Now())
+	r.state.deposits = append(r.state.deposits, deposit)
+	return deposit, nil
+}
+
+func (r *MemoryRepository) UpdateIngestedDeposit(deposit IngestedDeposit, from DepositStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for index, stored := range r.state.deposits {
+		if stored.ID != deposit.ID {
+			continue
+		}
+		if stored.Status != from {
+			return false, nil
+		}
+		stored.Status = deposit.Status
+		stored.BlockNumber = deposit.BlockNumber
+		stored.BlockHash = deposit.BlockHash
+		stored.UpdatedAt = time.Now()
+		r.state.deposits[index] = stored
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *MemoryRepository) ListIngestedDeposits(accountId uint64, statuses []DepositStatus) ([]IngestedDeposit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []IngestedDeposit
+	for _, deposit := range r.state.deposits {
+		if accountId != 0 && deposit.AccountId != accountId {
+			continue
+		}
+		matches := len(statuses) == 0
+		for _, status := range statuses {
+			matches = matches || deposit.Status == status
+		}
+		if matches {
+			result = append(result, deposit)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) InsertDepositAddress(address DepositAddress) (DepositAddress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range r.state.addresses {
+		if item.Chain == address.Chain && item.Address == address.Address {
+			return DepositAddress{}, fmt.Errorf("%w: %s", ErrDepositAddressTaken, address.Address)
+		}
+	}
+	address.Model = r.state.newModel(time.Now())
+	r.state.addresses = append(r.state.addresses, address)
+	return address, nil
+}
+
+func (r *MemoryRepository) GetDepositAddress(chain string, address string) (DepositAddress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range r.state.addresses {
+		if item.Chain == chain && item.Address == address {
+			return item, nil
+		}
+	}
+	return DepositAddress{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) UpdateDepositAddress(address DepositAddress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for index, item := range r.state.addresses {
+		if item.ID == address.ID {
+			item.AssignedAt = address.AssignedAt
+			item.RetiredAt = address.RetiredAt
+			item.UpdatedAt = time.Now()
+			r.state.addresses[index] = item
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) ListDepositAddresses(accountId uint64, chain string) ([]DepositAddress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []DepositAddress
+	for _, item := range r.state.addresses {
+		if item.AccountId == accountId && item.Chain == chain {
+			result = append(result, item)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].AssignedAt.Before(result[j].AssignedAt) })
+	return result, nil
+}
+
+func (r *MemoryRepository) InsertAccountFlag(flag AccountFlag) (AccountFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	flag.Model = r.state.newModel(time.Now())
+	r.state.flags = append(r.state.flags, flag)
+	return flag, nil
+}
+
+func (r *MemoryRepository) ListAccountFlags(accountId uint64) ([]AccountFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []AccountFlag
+	for _, flag := range r.state.flags {
+		if accountId == 0 || flag.AccountId == accountId {
+			result = append(result, flag)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) GetAccountFreeze(accountId uint64) (AccountFreeze, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	freeze, ok := r.state.freezes[accountId]
+	if !ok {
+		return AccountFreeze{}, gorm.ErrRecordNotFound
+	}
+	return freeze, nil
+}
+
+func (r *MemoryRepository) CreateAccountFreeze(freeze AccountFreeze) (AccountFreeze, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	freeze.Model = r.state.newModel(time.Now())
+	r.state.freezes[freeze.AccountId] = freeze
+	return freeze, nil
+}
+
+func (r *MemoryRepository) DeleteAccountFreeze(accountId uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.state.freezes, accountId)
+	return nil
+}
+
+func (r *MemoryRepository) InsertAuditLog(log AuditLog) (AuditLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.Model = r.state.newModel(time.Now())
+	r.state.auditLogs = append(r.state.auditLogs, log)
+	return log, nil
+}
+
+func (r *MemoryRepository) ListAuditLogs(accountId uint64) ([]AuditLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []AuditLog
+	for _, log := range r.state.auditLogs {
+		if log.AccountId == accountId {
+			result = append(result, log)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) InsertAdjustmentRequest(request AdjustmentRequest) (AdjustmentRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request.Model = r.state.newModel(time.Now())
+	r.state.adjustments = append(r.state.adjustments, request)
+	return request, nil
+}
+
+func (r *MemoryRepository) GetAdjustmentRequest(id uint) (AdjustmentRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, request := range r.state.adjustments {
+		if request.ID == id {
+			return request, nil
+		}
+	}
+	return AdjustmentRequest{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) ReviewAdjustmentRequest(request AdjustmentRequest) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for index, stored := range r.state.adjustments {
+		if stored.ID != request.ID {
+			continue
+		}
+		if stored.Status != AdjustmentPending {
+			return false, nil
+		}
+		stored.Status = request.Status
+		stored.ReviewedBy = request.ReviewedBy
+		stored.ReviewNote = request.ReviewNote
+		stored.ReviewedAt = request.ReviewedAt
+		stored.UpdatedAt = time.Now()
+		r.state.adjustments[index] = stored
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *MemoryRepository) ListAdjustmentRequests(status AdjustmentStatus) ([]AdjustmentRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []AdjustmentRequest
+	for _, request := range r.state.adjustments {
+		if status == "" || request.Status == status {
+			result = append(result, request)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) InsertWithdrawalHold(hold WithdrawalHold) (WithdrawalHold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hold.Model = r.state.newModel(time.Now())
+	r.state.withdrawalHolds = append(r.state.withdrawalHolds, hold)
+	return hold, nil
+}
+
+func (r *MemoryRepository) GetWithdrawalHold(id uint) (WithdrawalHold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if index := r.state.withdrawalHoldIndex(id); index != -1 {
+		return r.state.withdrawalHolds[index], nil
+	}
+	return WithdrawalHold{}, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRepository) AddWithdrawalApproval(approval WithdrawalApproval) (WithdrawalHold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range r.state.approvals {
+		if item.HoldId == approval.HoldId && item.Operator == approval.Operator {
+			return WithdrawalHold{}, fmt.Errorf("%w: %s already approved withdrawal %d", ErrDuplicateApproval, approval.Operator, approval.HoldId)
+		}
+	}
+	index := r.state.withdrawalHoldIndex(approval.HoldId)
+	if index == -1 || r.state.withdrawalHolds[index].Status != WithdrawalPending {
+		return WithdrawalHold{}, fmt.Errorf("%w: withdrawal %d was resolved meanwhile", ErrWithdrawalNotPending, approval.HoldId)
+	}
+	approval.Model = r.state.newModel(time.Now())
+	r.state.approvals = append(r.state.approvals, approval)
+	hold := r.state.withdrawalHolds[index]
+	hold.Approvals++
+	hold.UpdatedAt = time.Now()
+	r.state.withdrawalHolds[index] = hold
+	return hold, nil
+}
+
+func (r *MemoryRepository) ResolveWithdrawalHold(hold WithdrawalHold) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := r.state.withdrawalHoldIndex(hold.ID)
+	if index == -1 || r.state.withdrawalHolds[index].Status != WithdrawalPending {
+		return false, nil
+	}
+	stored := r.state.withdrawalHolds[index]
+	stored.Status = hold.Status
+	stored.ResolvedBy = hold.ResolvedBy
+	stored.ResolvedAt = hold.ResolvedAt
+	stored.Reason = hold.Reason
+	stored.UpdatedAt = time.Now()
+	r.state.withdrawalHolds[index] = stored
+	return true, nil
+}
+
+func (r *MemoryRepository) ListWithdrawalHolds(status WithdrawalHoldStatus) ([]WithdrawalHold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []WithdrawalHold
+	for _, hold := range r.state.withdrawalHolds {
+		if status == "" || hold.Status == status {
+			result = append(result, hold)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) ListExpiredWithdrawalHolds(now time.Time) ([]WithdrawalHold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []WithdrawalHold
+	for _, hold := range r.state.withdrawalHolds {
+		if hold.Status == WithdrawalPending && hold.ExpiresAt != nil && !hold.ExpiresAt.After(now) {
+			result = append(result, hold)
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryRepository) ListWithdrawalApprovals(holdId uint) ([]WithdrawalApproval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []WithdrawalApproval
+	for _, approval := range r.state.approvals {
+		if approval.HoldId == holdId {
+			result = append(result, approval)
+		}
+	}
+	return result, nil
+}
+
+// AccountIds returns the accounts having a wallet, in ascending order.
+func (r *MemoryRepository) AccountIds() []uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []uint64
+	for accountId := range r.state.wallets {
+		result = append(result, accountId)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// ERC20BalanceHistories returns every recorded ERC20 balance change of accountId, oldest first.
+func (r *MemoryRepository) ERC20BalanceHistories(accountId uint64) []ERC20BalanceHistory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []ERC20BalanceHistory
+	for _, history := range r.state.erc20Histories {
+		if history.AccountId == accountId {
+			result = append(result, history)
+		}
+	}
+	return result
+}
+
+// ERC1155BalanceHistories returns every recorded ERC1155 amount change of accountId, oldest first.
+func (r *MemoryRepository) ERC1155BalanceHistories(accountId uint64) []ERC1155BalanceHistory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []ERC1155BalanceHistory
+	for _, history := range r.state.erc1155Histories {
+		if history.AccountId == accountId {
+			result = append(result, history)
+		}
+	}
+	return result
+}
+
+// OutboxEvents returns every event published so far, oldest first.
+func (r *MemoryRepository) OutboxEvents() []OutboxEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]OutboxEvent{}, r.state.outboxEvents...)
+}
+
+func (state *memoryState) newModel(now time.Time) gorm.Model {
+	state.lastId++
+	return gorm.Model{ID: state.lastId, CreatedAt: now, UpdatedAt: now}
+}
+
+func (state *memoryState) withdrawalHoldIndex(id uint) int {
+	for index, hold := range state.withdrawalHolds {
+		if hold.ID == id {
+			return index
+		}
+	}
+	return -1
+}
+
+func (state *memoryState) clone() *memoryState {
+	result := &memoryState{
+		lastId:           state.lastId,
+		wallets:          make(map[uint64]Wallet, len(state.wallets)),
+		erc20Logs:        append([]ERC20WalletLog{}, state.erc20Logs...),
+		erc1155Logs:      append([]ERC1155WalletLog{}, state.erc1155Logs...),
+		erc20Histories:   append([]ERC20BalanceHistory{}, state.erc20Histories...),
+		erc1155Histories: append([]ERC1155BalanceHistory{}, state.erc1155Histories...),
+		outboxEvents:     append([]OutboxEvent{}, state.outboxEvents...),
+		freezes:          make(map[uint64]AccountFreeze, len(state.freezes)),
+		auditLogs:        append([]AuditLog{}, state.auditLogs...),
+		adjustments:      append([]AdjustmentRequest{}, state.adjustments...),
+		withdrawalHolds:  append([]WithdrawalHold{}, state.withdrawalHolds...),
+		deposits:         append([]IngestedDeposit{}, state.deposits...),
+		flags:            append([]AccountFlag{}, state.flags...),
+		addresses:        append([]DepositAddress{}, state.addresses...),
+		approvals:        append([]WithdrawalApproval{}, state.approvals...),
+	}
+	for accountId, freeze := range state.freezes {
+		result.freezes[accountId] = freeze
+	}
+	for accountId, wallet := range state.wallets {
+		result.wallets[accountId] = copyWallet(wallet)
+	}
+	return result
+}
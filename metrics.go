@@ -0,0 +1,225 @@
+package walleter
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+const metricsStartedAtKey = "walleter:metrics_started_at"
+
+// Metrics Prometheus collectors of a Walleter. Create it with NewMetrics, register it on a registry
+// with Register and hand it to New with WithMetrics.
+type Metrics struct {
+	commands        *prometheus.CounterVec
+	commandErrors   *prometheus.CounterVec
+	tokenVolume     *prometheus.CounterVec
+	itemVolume      *prometheus.CounterVec
+	feesCollected   *prometheus.CounterVec
+	commandDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	pendingLogs     *prometheus.Desc
+	pendingEvents   *prometheus.Desc
+
+	// db counts the gauges on scrape, outside of walleter statements so scrapes do not show up in queryDuration.
+	db *gorm.DB
+}
+
+// NewMetrics creates the collectors, every metric name is prefixed with namespace when it is not empty.
+func NewMetrics(namespace string) *Metrics {
+	return &Metrics{
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "walleter_commands_total",
+			Help:      "Wallet commands handled, by outcome. Held withdrawals count again when their hold is resolved.",
+		}, []string{"action_type", "asset_type", "business_module", "source", "status"}),
+		commandErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "walleter_command_errors_total",
+			Help:      "Failed wallet commands by error code.",
+		}, []string{"action_type", "asset_type", "code"}),
+		tokenVolume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "walleter_token_volume_total",
+			Help:      "Amount of ERC20 tokens moved by successful commands.",
+		}, []string{"action_type", "business_module", "token"}),
+		itemVolume: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "walleter_item_volume_total",
+			Help:      "Amount of ERC1155 items moved by successful commands.",
+		}, []string{"action_type", "business_module"}),
+		feesCollected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "walleter_fees_collected_total",
+			Help:      "Fees credited to the fee charger account.",
+		}, []string{"business_module", "token"}),
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "walleter_command_duration_seconds",
+			Help:      "Latency of HandleWalletCommand.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"action_type", "asset_type", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "walleter_db_duration_seconds",
+			Help:      "Latency of the database calls issued by walleter.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation", "table"}),
+		pendingLogs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "walleter_pending_logs"),
+			"Wallet logs still in Pending status.",
+			[]string{"asset_type"}, nil),
+		pendingEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "walleter_outbox_events"),
+			"Outbox events not delivered yet, by status.",
+			[]string{"status"}, nil),
+	}
+}
+
+// Register adds every collector to registry.
+func (m *Metrics) Register(registry prometheus.Registerer) error {
+	collectors := []prometheus.Collector{
+		m.commands, m.commandErrors, m.tokenVolume, m.itemVolume, m.feesCollected,
+		m.commandDuration, m.queryDuration, m,
+	}
+	for _, collector := range collectors {
+		if err := registry.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Describe implements prometheus.Collector for the gauges read from the database on scrape.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.pendingLogs
+	ch <- m.pendingEvents
+}
+
+// Collect implements prometheus.Collector, counting Pending logs and undelivered outbox events.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	if m.db == nil {
+		return
+	}
+	var erc20Count, erc1155Count int64
+	if err := m.db.Model(&ERC20WalletLog{}).Where("status = ?", Pending.String()).Count(&erc20Count).Error; err != nil {
+		ch <- prometheus.NewInvalidMetric(m.pendingLogs, err)
+		return
+	}
+	if err := m.db.Model(&ERC1155WalletLog{}).Where("status = ?", Pending.String()).Count(&erc1155Count).Error; err != nil {
+		ch <- prometheus.NewInvalidMetric(m.pendingLogs, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(m.pendingLogs, prometheus.GaugeValue, float64(erc20Count), AssetType(ERC20AssetType).String())
+	ch <- prometheus.MustNewConstMetric(m.pendingLogs, prometheus.GaugeValue, float64(erc1155Count), AssetType(ERC1155AssetType).String())
+
+	for _, status := range []OutboxStatus{OutboxPending, OutboxDeadLetter} {
+		var count int64
+		if err := m.db.Model(&OutboxEvent{}).Where("status = ?", status.String()).Count(&count).Error; err != nil {
+			ch <- prometheus.NewInvalidMetric(m.pendingEvents, err)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(m.pendingEvents, prometheus.GaugeValue, float64(count), status.String())
+	}
+}
+
+// observeCommand records the outcome of one HandleWalletCommand call, or of the resolution of a held withdrawal.
+func (m *Metrics) observeCommand(command WalletCommand, err error, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	status := Done.String()
+	held := heldWithdrawalOf(err) != nil
+	switch {
+	case held:
+		status = Held.String()
+	case err != nil:
+		status = Failed.String()
+	}
+	action := command.ActionType.String()
+	asset := command.AssetType.String()
+
+	m.commands.WithLabelValues(action, asset, command.BusinessModule, command.CommandSource.String(), status).Inc()
+	m.commandDuration.WithLabelValues(action, asset, status).Observe(elapsed.Seconds())
+	// the amounts of a held withdrawal move once its hold is approved, which is observed again
+	if held {
+		return
+	}
+	if err != nil {
+		m.commandErrors.WithLabelValues(action, asset, string(ErrorCodeOf(err))).Inc()
+		return
+	}
+
+	for _, token := range command.ERC20Commands {
+		if token.Value == 0 {
+			continue
+		}
+		m.tokenVolume.WithLabelValues(action, command.BusinessModule, token.Token.String()).Add(token.Value)
+	}
+	for _, value := range command.ERC1155Command.Values {
+		if value == 0 {
+			continue
+		}
+		m.itemVolume.WithLabelValues(action, command.BusinessModule).Add(float64(value))
+	}
+	credited := command.FeeCommands
+	// ERC20 spends and fee charges are credited to the fee charger as well
+	if command.AssetType == ERC20AssetType && (command.ActionType == Spend || command.ActionType == ChargeFee) {
+		credited = append(append([]ERC20Command{}, credited...), command.ERC20Commands...)
+	}
+	for _, fee := range credited {
+		if fee.Value > 0 {
+			m.feesCollected.WithLabelValues(command.BusinessModule, fee.Token.String()).Add(fee.Value)
+		}
+	}
+}
+
+// metricsPlugin measures the latency of every database call of a Walleter created with WithMetrics,
+// which covers each DAO call.
+type metricsPlugin struct{}
+
+func (p *metricsPlugin) Name() string {
+	return "walleter:metrics"
+}
+
+func (p *metricsPlugin) Initialize(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		if instrumentation := instrumentationOf(tx); instrumentation == nil || instrumentation.metrics == nil {
+			return
+		}
+		tx.InstanceSet(metricsStartedAtKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			startedAt, ok := tx.InstanceGet(metricsStartedAtKey)
+			if !ok {
+				return
+			}
+			instrumentationOf(tx).metrics.queryDuration.WithLabelValues(operation, tx.Statement.Table).
+				Observe(time.Since(startedAt.(time.Time)).Seconds())
+		}
+	}
+
+	callback := db.Callback()
+	registrations := []error{
+		callback.Create().Before("gorm:create").Register("walleter:metrics_before_create", before),
+		callback.Create().After("gorm:create").Register("walleter:metrics_after_create", after("create")),
+		callback.Query().Before("gorm:query").Register("walleter:metrics_before_query", before),
+		callback.Query().After("gorm:query").Register("walleter:metrics_after_query", after("query")),
+		callback.Update().Before("gorm:update").Register("walleter:metrics_before_update", before),
+		callback.Update().After("gorm:update").Register("walleter:metrics_after_update", after("update")),
+		callback.Delete().Before("gorm:delete").Register("walleter:metrics_before_delete", before),
+		callback.Delete().After("gorm:delete").Register("walleter:metrics_after_delete", after("delete")),
+		callback.Row().Before("gorm:row").Register("walleter:metrics_before_row", before),
+		callback.Row().After("gorm:row").Register("walleter:metrics_after_row", after("row")),
+		callback.Raw().Before("gorm:raw").Register("walleter:metrics_before_raw", before),
+		callback.Raw().After("gorm:raw").Register("walleter:metrics_after_raw", after("raw")),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
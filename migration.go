@@ -0,0 +1,280 @@
+package walleter
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration one versioned change of the walleter schema. Its SQL is shipped for every supported dialect in
+// migrations/<dialect>/<version>_<name>.up.sql, which applies it, and .down.sql, which reverts it.
+// Both run in a transaction together with the update of the schema_version table. On MySQL DDL statements
+// commit implicitly, so a migration failing half way there keeps its earlier statements: they have to be
+// reverted by hand before the migration is retried. PostgreSQL and SQLite revert them with the transaction.
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// SchemaVersion a row of the schema_version table, one per applied migration.
+type SchemaVersion struct {
+	Version   uint      `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `json:"name" gorm:"type:varchar(128);not null"`
+	AppliedAt time.Time `json:"applied_at" gorm:"not null"`
+}
+
+func (SchemaVersion) TableName() string {
+	return "schema_version"
+}
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLock the name of the lock serializing the migrations of instances starting at the same time.
+const migrationLock = "walleter_schema_migration"
+
+// migrations every migration of walleter, in version order. Versions are never reused or edited
+// once released, a schema or data change always comes as a new migration at the end, with its SQL for every
+// dialect of migrationDialects. The first ones create the tables only if they do not exist, so databases
+// created by the AutoMigrate of earlier releases are adopted with their data.
+var migrations = []Migration{
+	{Version: 1, Name: "create_wallets"},
+	{Version: 2, Name: "create_wallet_logs"},
+	{Version: 3, Name: "create_balance_histories"},
+	{Version: 4, Name: "create_economy_aggregates"},
+	{Version: 5, Name: "create_outbox_events"},
+	{Version: 6, Name: "create_webhooks"},
+	{Version: 7, Name: "create_account_freezes_and_audit_logs"},
+	{Version: 8, Name: "create_adjustment_requests"},
+	{Version: 9, Name: "create_withdrawal_holds"},
+	{Version: 10, Name: "create_ingested_deposits"},
+	{Version: 11, Name: "add_deposit_confirmations"},
+	{Version: 12, Name: "create_deposit_addresses"},
+	{Version: 13, Name: "add_wallet_log_status_indexes"},
+}
+
+// migrationDialects the gorm dialects migrations are written for.
+var migrationDialects = []string{"mysql", "postgres", "sqlite"}
+
+// migrationDialect returns the dialect of db, failing with ErrUnsupportedDialect when no migration is written for it.
+func migrationDialect(db *gorm.DB) (string, error) {
+	name := db.Dialector.Name()
+	for _, dialect := range migrationDialects {
+		if dialect == name {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedDialect, name)
+}
+
+// migrationStatements returns the statements of file in the migrations of dialect. Statements end with a
+// semicolon, lines starting with -- are comments.
+func migrationStatements(dialect string, file string) ([]string, error) {
+	content, err := migrationFiles.ReadFile(path.Join("migrations", dialect, file))
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements, nil
+}
+
+// execMigrationFile runs the statements of file in the migrations of dialect.
+func execMigrationFile(tx *gorm.DB, dialect string, file string) error {
+	statements, err := migrationStatements(dialect, file)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrationTransaction runs fn in a transaction holding the migration lock, so that instances starting at the
+// same time migrate one after the other. SQLite allows a single writer, it needs no lock.
+func migrationTransaction(db *gorm.DB, dialect string, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		switch dialect {
+		case "postgres":
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", migrationLock).Error; err != nil {
+				return err
+			}
+		case "mysql":
+			// a session lock, DDL statements commit the transaction
+			var locked int
+			if err := tx.Raw("SELECT GET_LOCK(?, 60)", migrationLock).Scan(&locked).Error; err != nil {
+				return err
+			}
+			if locked != 1 {
+				return errors.New("timed out waiting for the migration lock")
+			}
+			defer tx.Exec("SELECT RELEASE_LOCK(?)", migrationLock)
+		}
+		return fn(tx)
+	})
+}
+
+// Migrations returns every migration known to this release, in version order.
+func Migrations() []Migration {
+	return append([]Migration{}, migrations...)
+}
+
+// LatestSchemaVersion the version of the schema this release works with.
+func LatestSchemaVersion() uint {
+	return migrations[len(migrations)-1].Version
+}
+
+// AppliedMigrations returns the migrations applied to db, in version order. It only reads db: a database
+// without schema_version table has none applied.
+func AppliedMigrations(db *gorm.DB) ([]SchemaVersion, error) {
+	if !db.Migrator().HasTable(&SchemaVersion{}) {
+		return nil, nil
+	}
+	var result []SchemaVersion
+	err := db.Order("version").Find(&result).Error
+	return result, err
+}
+
+// PendingMigrations returns the migrations not applied to db yet, in the order Migrate applies them.
+// Like AppliedMigrations it only reads db.
+func PendingMigrations(db *gorm.DB) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	var result []Migration
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			result = append(result, migration)
+		}
+	}
+	return result, nil
+}
+
+// Migrate applies every pending migration to db. New calls it unless WithoutAutoMigrate is given.
+func Migrate(db *gorm.DB) error {
+	return MigrateTo(db, LatestSchemaVersion())
+}
+
+// MigrateTo brings db to version: pending migrations up to version are applied in ascending order,
+// applied migrations above version are reverted in descending order. MigrateTo(db, 0) reverts everything.
+func MigrateTo(db *gorm.DB, version uint) error {
+	if version > LatestSchemaVersion() {
+		return fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+	}
+	dialect, err := migrationDialect(db)
+	if err != nil {
+		return err
+	}
+	err = migrationTransaction(db, dialect, func(tx *gorm.DB) error {
+		return execMigrationFile(tx, dialect, "schema_version.sql")
+	})
+	if err != nil {
+		return fmt.Errorf("create schema_version: %w", err)
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	// 1. Revert the applied migrations above version, newest first.
+	var reverts []uint
+	for applyVersion := range applied {
+		if applyVersion > version {
+			reverts = append(reverts, applyVersion)
+		}
+	}
+	sort.Slice(reverts, func(i, j int) bool { return reverts[i] > reverts[j] })
+	for _, revertVersion := range reverts {
+		migration, ok := findMigration(revertVersion)
+		if !ok {
+			return fmt.Errorf("%w: %d is applied but unknown to this release", ErrUnknownMigration, revertVersion)
+		}
+		err := migrationTransaction(db, dialect, func(tx *gorm.DB) error {
+			// another instance reverted it meanwhile
+			if applied, err := migrationApplied(tx, migration.Version); err != nil || !applied {
+				return err
+			}
+			if err := execMigrationFile(tx, dialect, migration.file("down")); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaVersion{}, migration.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("revert migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	// 2. Apply the pending migrations up to version, oldest first.
+	for _, migration := range migrations {
+		if migration.Version > version || applied[migration.Version] {
+			continue
+		}
+		err := migrationTransaction(db, dialect, func(tx *gorm.DB) error {
+			// another instance starting at the same time applied it first
+			if applied, err := migrationApplied(tx, migration.Version); err != nil || applied {
+				return err
+			}
+			if err := execMigrationFile(tx, dialect, migration.file("up")); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaVersion{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+func appliedVersions(db *gorm.DB) (map[uint]bool, error) {
+	applied, err := AppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint]bool, len(applied))
+	for _, item := range applied {
+		result[item.Version] = true
+	}
+	return result, nil
+}
+
+// migrationApplied reports whether the migration version is recorded in schema_version.
+func migrationApplied(tx *gorm.DB, version uint) (bool, error) {
+	var count int64
+	err := tx.Model(&SchemaVersion{}).Where("version = ?", version).Count(&count).Error
+	return count > 0, err
+}
+
+// file the name of the SQL file of m applying it, direction "up", or reverting it, direction "down".
+func (m Migration) file(direction string) string {
+	return fmt.Sprintf("%04d_%s.%s.sql", m.Version, m.Name, direction)
+}
+
+func findMigration(version uint) (Migration, bool) {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
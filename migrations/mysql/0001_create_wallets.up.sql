@@ -0,0 +1,41 @@
+-- The tables may exist already, created by the AutoMigrate of the releases before schema migrations.
+CREATE TABLE IF NOT EXISTS `erc20_token_wallets` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned,
+    `token` varchar(20),
+    `balance` double,
+    `decimal` bigint unsigned,
+    `total_income` double,
+    `total_spend` double,
+    `total_deposit` double,
+    `total_withdraw` double,
+    `total_fee` double,
+    PRIMARY KEY (`id`),
+    INDEX `idx_erc20_token_wallets_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `erc1155_token_wallets` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned,
+    `ids` longtext,
+    `values` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_erc1155_token_wallets_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `wallets` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL UNIQUE,
+    `check_sign` varchar(128) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_wallets_deleted_at` (`deleted_at`)
+);
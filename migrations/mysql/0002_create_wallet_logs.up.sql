@@ -0,0 +1,42 @@
+-- The tables may exist already, created by the AutoMigrate of the releases before schema migrations.
+-- Their logs tables have no failure_detail column yet.
+CREATE TABLE IF NOT EXISTS `erc20_wallet_logs` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned,
+    `business_module` varchar(64) NOT NULL,
+    `action_type` varchar(64) NOT NULL,
+    `source` varchar(20),
+    `tokens` JSON NOT NULL,
+    `fees` JSON,
+    `status` varchar(64) NOT NULL,
+    `original_wallet` JSON NOT NULL,
+    `settled_wallet` JSON NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_erc20_wallet_logs_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `erc1155_wallet_logs` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned,
+    `business_module` varchar(64) NOT NULL,
+    `action_type` varchar(64) NOT NULL,
+    `source` varchar(20),
+    `ids` longtext,
+    `values` longtext,
+    `fees` JSON,
+    `status` varchar(10) NOT NULL,
+    `original_wallet` JSON NOT NULL,
+    `settled_wallet` JSON,
+    PRIMARY KEY (`id`),
+    INDEX `idx_erc1155_wallet_logs_deleted_at` (`deleted_at`)
+);
+
+ALTER TABLE `erc20_wallet_logs` ADD COLUMN `failure_detail` JSON;
+
+ALTER TABLE `erc1155_wallet_logs` ADD COLUMN `failure_detail` JSON;
@@ -0,0 +1,27 @@
+CREATE TABLE `erc20_balance_histories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `token` varchar(20) NOT NULL,
+    `balance` double,
+    `change` double,
+    PRIMARY KEY (`id`),
+    INDEX `idx_erc20_balance_history` (`account_id`,`token`),
+    INDEX `idx_erc20_balance_histories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `erc1155_balance_histories` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `token_id` bigint unsigned NOT NULL,
+    `balance` bigint unsigned,
+    `change` bigint,
+    PRIMARY KEY (`id`),
+    INDEX `idx_erc1155_balance_histories_deleted_at` (`deleted_at`),
+    INDEX `idx_erc1155_balance_history` (`account_id`,`token_id`)
+);
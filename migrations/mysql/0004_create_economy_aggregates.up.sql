@@ -0,0 +1,34 @@
+CREATE TABLE `economy_daily_aggregates` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `day` varchar(10) NOT NULL,
+    `token` varchar(32) NOT NULL,
+    `action_type` varchar(64) NOT NULL,
+    `business_module` varchar(64) NOT NULL,
+    `source` varchar(20) NOT NULL,
+    `income` double,
+    `spend` double,
+    `deposit` double,
+    `withdraw` double,
+    `fee` double,
+    `count` bigint unsigned,
+    PRIMARY KEY (`id`),
+    INDEX `idx_economy_daily_aggregates_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_economy_daily_aggregate` (`day`,`token`,`action_type`,`business_module`,`source`)
+);
+
+CREATE TABLE `economy_aggregate_cursors` (
+    `name` varchar(64),
+    `last_log_id` bigint unsigned NOT NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`name`)
+);
+
+CREATE TABLE `economy_aggregate_unsettled` (
+    `cursor_name` varchar(64),
+    `log_id` bigint unsigned,
+    `created_at` datetime(3) NULL,
+    PRIMARY KEY (`cursor_name`,`log_id`)
+);
@@ -0,0 +1,17 @@
+CREATE TABLE `outbox_events` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `event_type` varchar(32) NOT NULL,
+    `payload` JSON NOT NULL,
+    `status` varchar(10) NOT NULL,
+    `attempts` bigint,
+    `next_attempt_at` datetime(3) NULL,
+    `last_error` varchar(255),
+    PRIMARY KEY (`id`),
+    INDEX `idx_outbox_events_deleted_at` (`deleted_at`),
+    INDEX `idx_outbox_events_account_id` (`account_id`),
+    INDEX `idx_outbox_event_due` (`status`,`next_attempt_at`)
+);
@@ -0,0 +1,34 @@
+CREATE TABLE `webhook_endpoints` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `name` varchar(64) NOT NULL,
+    `url` varchar(512) NOT NULL,
+    `secret` varchar(128) NOT NULL,
+    `filter` JSON,
+    `active` boolean NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_webhook_endpoints_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `webhook_deliveries` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `endpoint_id` bigint unsigned NOT NULL,
+    `event_id` bigint unsigned NOT NULL,
+    `event_type` varchar(32) NOT NULL,
+    `payload` text NOT NULL,
+    `status` varchar(10) NOT NULL,
+    `attempts` bigint,
+    `next_attempt_at` datetime(3) NULL,
+    `last_status_code` bigint,
+    `last_error` varchar(255),
+    `delivered_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_webhook_deliveries_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_webhook_delivery_event` (`endpoint_id`,`event_id`,`event_type`),
+    INDEX `idx_webhook_delivery_due` (`status`,`next_attempt_at`)
+);
@@ -0,0 +1,28 @@
+CREATE TABLE `account_freezes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `operator` varchar(64) NOT NULL,
+    `reason` varchar(255) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_account_freezes_account_id` (`account_id`),
+    INDEX `idx_account_freezes_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE `audit_logs` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `operator` varchar(64) NOT NULL,
+    `action` varchar(32) NOT NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `reason` varchar(255) NOT NULL,
+    `detail` JSON,
+    PRIMARY KEY (`id`),
+    INDEX `idx_audit_logs_deleted_at` (`deleted_at`),
+    INDEX `idx_audit_logs_operator` (`operator`),
+    INDEX `idx_audit_logs_account_id` (`account_id`)
+);
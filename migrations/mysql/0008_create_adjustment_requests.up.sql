@@ -0,0 +1,22 @@
+CREATE TABLE `adjustment_requests` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `amounts` JSON,
+    `reason` varchar(255) NOT NULL,
+    `proposed_by` varchar(64) NOT NULL,
+    `status` varchar(16) NOT NULL,
+    `reviewed_by` varchar(64),
+    `review_note` varchar(255),
+    `reviewed_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_adjustment_requests_deleted_at` (`deleted_at`),
+    INDEX `idx_adjustment_requests_account_id` (`account_id`),
+    INDEX `idx_adjustment_requests_status` (`status`)
+);
+
+ALTER TABLE `erc20_wallet_logs` ADD COLUMN `approval` JSON;
+
+ALTER TABLE `erc1155_wallet_logs` ADD COLUMN `approval` JSON;
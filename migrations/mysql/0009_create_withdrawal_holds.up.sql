@@ -0,0 +1,36 @@
+ALTER TABLE `erc20_token_wallets` ADD COLUMN `locked` double NOT NULL DEFAULT 0;
+
+CREATE TABLE `withdrawal_holds` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `log_id` bigint unsigned NOT NULL,
+    `amounts` JSON,
+    `required_approvals` bigint NOT NULL,
+    `approvals` bigint NOT NULL DEFAULT 0,
+    `status` varchar(16) NOT NULL,
+    `expires_at` datetime(3) NULL,
+    `resolved_by` varchar(64),
+    `resolved_at` datetime(3) NULL,
+    `reason` varchar(255),
+    PRIMARY KEY (`id`),
+    INDEX `idx_withdrawal_holds_status` (`status`),
+    INDEX `idx_withdrawal_holds_expires_at` (`expires_at`),
+    INDEX `idx_withdrawal_holds_deleted_at` (`deleted_at`),
+    INDEX `idx_withdrawal_holds_account_id` (`account_id`),
+    UNIQUE INDEX `idx_withdrawal_holds_log_id` (`log_id`)
+);
+
+CREATE TABLE `withdrawal_approvals` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `hold_id` bigint unsigned NOT NULL,
+    `operator` varchar(64) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_withdrawal_approvals_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_withdrawal_approval_operator` (`hold_id`,`operator`)
+);
@@ -0,0 +1,25 @@
+CREATE TABLE `ingested_deposits` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `chain` varchar(20) NOT NULL,
+    `tx_hash` varchar(66) NOT NULL,
+    `log_index` bigint unsigned NOT NULL,
+    `address` varchar(42) NOT NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `asset_type` bigint NOT NULL,
+    `token` varchar(20),
+    `amount` double,
+    `ids` longtext,
+    `values` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_ingested_deposits_address` (`address`),
+    INDEX `idx_ingested_deposits_account_id` (`account_id`),
+    INDEX `idx_ingested_deposits_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_ingested_deposit_identity` (`chain`,`tx_hash`,`log_index`)
+);
+
+ALTER TABLE `erc20_wallet_logs` ADD COLUMN `chain_ref` JSON;
+
+ALTER TABLE `erc1155_wallet_logs` ADD COLUMN `chain_ref` JSON;
@@ -0,0 +1,21 @@
+ALTER TABLE `ingested_deposits` ADD COLUMN `block_number` bigint unsigned NOT NULL DEFAULT 0;
+
+ALTER TABLE `ingested_deposits` ADD COLUMN `block_hash` varchar(66);
+
+ALTER TABLE `ingested_deposits` ADD COLUMN `status` varchar(20) NOT NULL DEFAULT 'final';
+
+CREATE INDEX `idx_ingested_deposits_status` ON `ingested_deposits`(`status`);
+
+CREATE TABLE `account_flags` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `reason` varchar(32) NOT NULL,
+    `deposit_id` bigint unsigned,
+    `shortfall` JSON,
+    PRIMARY KEY (`id`),
+    INDEX `idx_account_flags_deleted_at` (`deleted_at`),
+    INDEX `idx_account_flags_account_id` (`account_id`)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE `deposit_addresses` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `account_id` bigint unsigned NOT NULL,
+    `chain` varchar(20) NOT NULL,
+    `address` varchar(42) NOT NULL,
+    `assigned_at` datetime(3) NOT NULL,
+    `retired_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_deposit_addresses_deleted_at` (`deleted_at`),
+    INDEX `idx_deposit_address_account` (`account_id`,`chain`),
+    UNIQUE INDEX `idx_deposit_address_identity` (`chain`,`address`)
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS `schema_version` (
+    `version` bigint unsigned,
+    `name` varchar(128) NOT NULL,
+    `applied_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`version`)
+);
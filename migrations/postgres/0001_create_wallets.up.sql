@@ -0,0 +1,44 @@
+-- The tables may exist already, created by the AutoMigrate of the releases before schema migrations.
+CREATE TABLE IF NOT EXISTS "erc20_token_wallets" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint,
+    "token" varchar(20),
+    "balance" decimal,
+    "decimal" bigint,
+    "total_income" decimal,
+    "total_spend" decimal,
+    "total_deposit" decimal,
+    "total_withdraw" decimal,
+    "total_fee" decimal,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_erc20_token_wallets_deleted_at" ON "erc20_token_wallets" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "erc1155_token_wallets" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint,
+    "ids" text,
+    "values" text,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_erc1155_token_wallets_deleted_at" ON "erc1155_token_wallets" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "wallets" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL UNIQUE,
+    "check_sign" varchar(128) NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_wallets_deleted_at" ON "wallets" ("deleted_at");
@@ -0,0 +1,44 @@
+-- The tables may exist already, created by the AutoMigrate of the releases before schema migrations.
+-- Their logs tables have no failure_detail column yet.
+CREATE TABLE IF NOT EXISTS "erc20_wallet_logs" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint,
+    "business_module" varchar(64) NOT NULL,
+    "action_type" varchar(64) NOT NULL,
+    "source" varchar(20),
+    "tokens" JSONB NOT NULL,
+    "fees" JSONB,
+    "status" varchar(64) NOT NULL,
+    "original_wallet" JSONB NOT NULL,
+    "settled_wallet" JSONB NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_erc20_wallet_logs_deleted_at" ON "erc20_wallet_logs" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "erc1155_wallet_logs" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint,
+    "business_module" varchar(64) NOT NULL,
+    "action_type" varchar(64) NOT NULL,
+    "source" varchar(20),
+    "ids" text,
+    "values" text,
+    "fees" JSONB,
+    "status" varchar(10) NOT NULL,
+    "original_wallet" JSONB NOT NULL,
+    "settled_wallet" JSONB,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_erc1155_wallet_logs_deleted_at" ON "erc1155_wallet_logs" ("deleted_at");
+
+ALTER TABLE "erc20_wallet_logs" ADD COLUMN "failure_detail" JSONB;
+
+ALTER TABLE "erc1155_wallet_logs" ADD COLUMN "failure_detail" JSONB;
@@ -0,0 +1,2 @@
+DROP TABLE "erc1155_balance_histories";
+DROP TABLE "erc20_balance_histories";
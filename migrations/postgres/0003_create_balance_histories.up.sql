@@ -0,0 +1,31 @@
+CREATE TABLE "erc20_balance_histories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL,
+    "token" varchar(20) NOT NULL,
+    "balance" decimal,
+    "change" decimal,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_erc20_balance_history" ON "erc20_balance_histories" ("account_id","token");
+
+CREATE INDEX "idx_erc20_balance_histories_deleted_at" ON "erc20_balance_histories" ("deleted_at");
+
+CREATE TABLE "erc1155_balance_histories" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL,
+    "token_id" bigint NOT NULL,
+    "balance" bigint,
+    "change" bigint,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_erc1155_balance_history" ON "erc1155_balance_histories" ("account_id","token_id");
+
+CREATE INDEX "idx_erc1155_balance_histories_deleted_at" ON "erc1155_balance_histories" ("deleted_at");
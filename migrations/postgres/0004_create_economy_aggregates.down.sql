@@ -0,0 +1,3 @@
+DROP TABLE "economy_aggregate_unsettled";
+DROP TABLE "economy_aggregate_cursors";
+DROP TABLE "economy_daily_aggregates";
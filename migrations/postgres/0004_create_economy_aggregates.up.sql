@@ -0,0 +1,36 @@
+CREATE TABLE "economy_daily_aggregates" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "day" varchar(10) NOT NULL,
+    "token" varchar(32) NOT NULL,
+    "action_type" varchar(64) NOT NULL,
+    "business_module" varchar(64) NOT NULL,
+    "source" varchar(20) NOT NULL,
+    "income" decimal,
+    "spend" decimal,
+    "deposit" decimal,
+    "withdraw" decimal,
+    "fee" decimal,
+    "count" bigint,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_economy_daily_aggregates_deleted_at" ON "economy_daily_aggregates" ("deleted_at");
+
+CREATE UNIQUE INDEX "idx_economy_daily_aggregate" ON "economy_daily_aggregates" ("day","token","action_type","business_module","source");
+
+CREATE TABLE "economy_aggregate_cursors" (
+    "name" varchar(64),
+    "last_log_id" bigint NOT NULL,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("name")
+);
+
+CREATE TABLE "economy_aggregate_unsettled" (
+    "cursor_name" varchar(64),
+    "log_id" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("cursor_name","log_id")
+);
@@ -0,0 +1,20 @@
+CREATE TABLE "outbox_events" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL,
+    "event_type" varchar(32) NOT NULL,
+    "payload" JSONB NOT NULL,
+    "status" varchar(10) NOT NULL,
+    "attempts" bigint,
+    "next_attempt_at" timestamptz,
+    "last_error" varchar(255),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_outbox_event_due" ON "outbox_events" ("status","next_attempt_at");
+
+CREATE INDEX "idx_outbox_events_account_id" ON "outbox_events" ("account_id");
+
+CREATE INDEX "idx_outbox_events_deleted_at" ON "outbox_events" ("deleted_at");
@@ -0,0 +1,38 @@
+CREATE TABLE "webhook_endpoints" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" varchar(64) NOT NULL,
+    "url" varchar(512) NOT NULL,
+    "secret" varchar(128) NOT NULL,
+    "filter" JSONB,
+    "active" boolean NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_webhook_endpoints_deleted_at" ON "webhook_endpoints" ("deleted_at");
+
+CREATE TABLE "webhook_deliveries" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "endpoint_id" bigint NOT NULL,
+    "event_id" bigint NOT NULL,
+    "event_type" varchar(32) NOT NULL,
+    "payload" text NOT NULL,
+    "status" varchar(10) NOT NULL,
+    "attempts" bigint,
+    "next_attempt_at" timestamptz,
+    "last_status_code" bigint,
+    "last_error" varchar(255),
+    "delivered_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_webhook_delivery_due" ON "webhook_deliveries" ("status","next_attempt_at");
+
+CREATE UNIQUE INDEX "idx_webhook_delivery_event" ON "webhook_deliveries" ("endpoint_id","event_id","event_type");
+
+CREATE INDEX "idx_webhook_deliveries_deleted_at" ON "webhook_deliveries" ("deleted_at");
@@ -0,0 +1,33 @@
+CREATE TABLE "account_freezes" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL,
+    "operator" varchar(64) NOT NULL,
+    "reason" varchar(255) NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idx_account_freezes_account_id" ON "account_freezes" ("account_id");
+
+CREATE INDEX "idx_account_freezes_deleted_at" ON "account_freezes" ("deleted_at");
+
+CREATE TABLE "audit_logs" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "operator" varchar(64) NOT NULL,
+    "action" varchar(32) NOT NULL,
+    "account_id" bigint NOT NULL,
+    "reason" varchar(255) NOT NULL,
+    "detail" JSONB,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_audit_logs_deleted_at" ON "audit_logs" ("deleted_at");
+
+CREATE INDEX "idx_audit_logs_account_id" ON "audit_logs" ("account_id");
+
+CREATE INDEX "idx_audit_logs_operator" ON "audit_logs" ("operator");
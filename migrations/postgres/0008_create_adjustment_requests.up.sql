@@ -0,0 +1,25 @@
+CREATE TABLE "adjustment_requests" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL,
+    "amounts" JSONB,
+    "reason" varchar(255) NOT NULL,
+    "proposed_by" varchar(64) NOT NULL,
+    "status" varchar(16) NOT NULL,
+    "reviewed_by" varchar(64),
+    "review_note" varchar(255),
+    "reviewed_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_adjustment_requests_account_id" ON "adjustment_requests" ("account_id");
+
+CREATE INDEX "idx_adjustment_requests_deleted_at" ON "adjustment_requests" ("deleted_at");
+
+CREATE INDEX "idx_adjustment_requests_status" ON "adjustment_requests" ("status");
+
+ALTER TABLE "erc20_wallet_logs" ADD COLUMN "approval" JSONB;
+
+ALTER TABLE "erc1155_wallet_logs" ADD COLUMN "approval" JSONB;
@@ -0,0 +1,3 @@
+DROP TABLE "withdrawal_approvals";
+DROP TABLE "withdrawal_holds";
+ALTER TABLE "erc20_token_wallets" DROP COLUMN "locked";
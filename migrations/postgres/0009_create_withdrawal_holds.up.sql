@@ -0,0 +1,43 @@
+ALTER TABLE "erc20_token_wallets" ADD COLUMN "locked" decimal NOT NULL DEFAULT 0;
+
+CREATE TABLE "withdrawal_holds" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL,
+    "log_id" bigint NOT NULL,
+    "amounts" JSONB,
+    "required_approvals" bigint NOT NULL,
+    "approvals" bigint NOT NULL DEFAULT 0,
+    "status" varchar(16) NOT NULL,
+    "expires_at" timestamptz,
+    "resolved_by" varchar(64),
+    "resolved_at" timestamptz,
+    "reason" varchar(255),
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_withdrawal_holds_expires_at" ON "withdrawal_holds" ("expires_at");
+
+CREATE INDEX "idx_withdrawal_holds_status" ON "withdrawal_holds" ("status");
+
+CREATE UNIQUE INDEX "idx_withdrawal_holds_log_id" ON "withdrawal_holds" ("log_id");
+
+CREATE INDEX "idx_withdrawal_holds_account_id" ON "withdrawal_holds" ("account_id");
+
+CREATE INDEX "idx_withdrawal_holds_deleted_at" ON "withdrawal_holds" ("deleted_at");
+
+CREATE TABLE "withdrawal_approvals" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "hold_id" bigint NOT NULL,
+    "operator" varchar(64) NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idx_withdrawal_approval_operator" ON "withdrawal_approvals" ("hold_id","operator");
+
+CREATE INDEX "idx_withdrawal_approvals_deleted_at" ON "withdrawal_approvals" ("deleted_at");
@@ -0,0 +1,3 @@
+ALTER TABLE "erc1155_wallet_logs" DROP COLUMN "chain_ref";
+ALTER TABLE "erc20_wallet_logs" DROP COLUMN "chain_ref";
+DROP TABLE "ingested_deposits";
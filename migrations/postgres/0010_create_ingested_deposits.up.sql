@@ -0,0 +1,29 @@
+CREATE TABLE "ingested_deposits" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "chain" varchar(20) NOT NULL,
+    "tx_hash" varchar(66) NOT NULL,
+    "log_index" bigint NOT NULL,
+    "address" varchar(42) NOT NULL,
+    "account_id" bigint NOT NULL,
+    "asset_type" bigint NOT NULL,
+    "token" varchar(20),
+    "amount" decimal,
+    "ids" text,
+    "values" text,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_ingested_deposits_address" ON "ingested_deposits" ("address");
+
+CREATE UNIQUE INDEX "idx_ingested_deposit_identity" ON "ingested_deposits" ("chain","tx_hash","log_index");
+
+CREATE INDEX "idx_ingested_deposits_deleted_at" ON "ingested_deposits" ("deleted_at");
+
+CREATE INDEX "idx_ingested_deposits_account_id" ON "ingested_deposits" ("account_id");
+
+ALTER TABLE "erc20_wallet_logs" ADD COLUMN "chain_ref" JSONB;
+
+ALTER TABLE "erc1155_wallet_logs" ADD COLUMN "chain_ref" JSONB;
@@ -0,0 +1,23 @@
+ALTER TABLE "ingested_deposits" ADD COLUMN "block_number" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "ingested_deposits" ADD COLUMN "block_hash" varchar(66);
+
+ALTER TABLE "ingested_deposits" ADD COLUMN "status" varchar(20) NOT NULL DEFAULT 'final';
+
+CREATE INDEX "idx_ingested_deposits_status" ON "ingested_deposits" ("status");
+
+CREATE TABLE "account_flags" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL,
+    "reason" varchar(32) NOT NULL,
+    "deposit_id" bigint,
+    "shortfall" JSONB,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "idx_account_flags_account_id" ON "account_flags" ("account_id");
+
+CREATE INDEX "idx_account_flags_deleted_at" ON "account_flags" ("deleted_at");
@@ -0,0 +1,18 @@
+CREATE TABLE "deposit_addresses" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "account_id" bigint NOT NULL,
+    "chain" varchar(20) NOT NULL,
+    "address" varchar(42) NOT NULL,
+    "assigned_at" timestamptz NOT NULL,
+    "retired_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "idx_deposit_address_identity" ON "deposit_addresses" ("chain","address");
+
+CREATE INDEX "idx_deposit_address_account" ON "deposit_addresses" ("account_id","chain");
+
+CREATE INDEX "idx_deposit_addresses_deleted_at" ON "deposit_addresses" ("deleted_at");
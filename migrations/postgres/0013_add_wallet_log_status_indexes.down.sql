@@ -0,0 +1,2 @@
+DROP INDEX "idx_erc1155_wallet_logs_status";
+DROP INDEX "idx_erc20_wallet_logs_status";
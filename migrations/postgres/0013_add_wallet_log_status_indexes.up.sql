@@ -0,0 +1,3 @@
+-- The pending logs gauge counts the Pending logs of both tables on every scrape.
+CREATE INDEX "idx_erc20_wallet_logs_status" ON "erc20_wallet_logs" ("status");
+CREATE INDEX "idx_erc1155_wallet_logs_status" ON "erc1155_wallet_logs" ("status");
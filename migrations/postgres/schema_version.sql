@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS "schema_version" (
+    "version" bigint,
+    "name" varchar(128) NOT NULL,
+    "applied_at" timestamptz NOT NULL,
+    PRIMARY KEY ("version")
+);
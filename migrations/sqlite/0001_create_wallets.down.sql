@@ -0,0 +1,3 @@
+DROP TABLE `wallets`;
+DROP TABLE `erc1155_token_wallets`;
+DROP TABLE `erc20_token_wallets`;
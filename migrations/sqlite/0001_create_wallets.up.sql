@@ -0,0 +1,41 @@
+-- The tables may exist already, created by the AutoMigrate of the releases before schema migrations.
+CREATE TABLE IF NOT EXISTS `erc20_token_wallets` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer,
+    `token` varchar(20),
+    `balance` real,
+    `decimal` integer,
+    `total_income` real,
+    `total_spend` real,
+    `total_deposit` real,
+    `total_withdraw` real,
+    `total_fee` real
+);
+
+CREATE INDEX IF NOT EXISTS `idx_erc20_token_wallets_deleted_at` ON `erc20_token_wallets`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `erc1155_token_wallets` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer,
+    `ids` text,
+    `values` text
+);
+
+CREATE INDEX IF NOT EXISTS `idx_erc1155_token_wallets_deleted_at` ON `erc1155_token_wallets`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `wallets` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL UNIQUE,
+    `check_sign` varchar(128) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `idx_wallets_deleted_at` ON `wallets`(`deleted_at`);
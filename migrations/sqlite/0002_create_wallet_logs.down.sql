@@ -0,0 +1,2 @@
+DROP TABLE `erc1155_wallet_logs`;
+DROP TABLE `erc20_wallet_logs`;
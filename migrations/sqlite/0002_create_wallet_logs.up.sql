@@ -0,0 +1,42 @@
+-- The tables may exist already, created by the AutoMigrate of the releases before schema migrations.
+-- Their logs tables have no failure_detail column yet.
+CREATE TABLE IF NOT EXISTS `erc20_wallet_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer,
+    `business_module` varchar(64) NOT NULL,
+    `action_type` varchar(64) NOT NULL,
+    `source` varchar(20),
+    `tokens` TEXT NOT NULL,
+    `fees` TEXT,
+    `status` varchar(64) NOT NULL,
+    `original_wallet` TEXT NOT NULL,
+    `settled_wallet` TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS `idx_erc20_wallet_logs_deleted_at` ON `erc20_wallet_logs`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `erc1155_wallet_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer,
+    `business_module` varchar(64) NOT NULL,
+    `action_type` varchar(64) NOT NULL,
+    `source` varchar(20),
+    `ids` text,
+    `values` text,
+    `fees` TEXT,
+    `status` varchar(10) NOT NULL,
+    `original_wallet` TEXT NOT NULL,
+    `settled_wallet` TEXT
+);
+
+CREATE INDEX IF NOT EXISTS `idx_erc1155_wallet_logs_deleted_at` ON `erc1155_wallet_logs`(`deleted_at`);
+
+ALTER TABLE `erc20_wallet_logs` ADD COLUMN `failure_detail` TEXT;
+
+ALTER TABLE `erc1155_wallet_logs` ADD COLUMN `failure_detail` TEXT;
@@ -0,0 +1,29 @@
+CREATE TABLE `erc20_balance_histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL,
+    `token` varchar(20) NOT NULL,
+    `balance` real,
+    `change` real
+);
+
+CREATE INDEX `idx_erc20_balance_history` ON `erc20_balance_histories`(`account_id`,`token`);
+
+CREATE INDEX `idx_erc20_balance_histories_deleted_at` ON `erc20_balance_histories`(`deleted_at`);
+
+CREATE TABLE `erc1155_balance_histories` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL,
+    `token_id` integer NOT NULL,
+    `balance` integer,
+    `change` integer
+);
+
+CREATE INDEX `idx_erc1155_balance_history` ON `erc1155_balance_histories`(`account_id`,`token_id`);
+
+CREATE INDEX `idx_erc1155_balance_histories_deleted_at` ON `erc1155_balance_histories`(`deleted_at`);
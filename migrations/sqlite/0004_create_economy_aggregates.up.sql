@@ -0,0 +1,35 @@
+CREATE TABLE `economy_daily_aggregates` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `day` varchar(10) NOT NULL,
+    `token` varchar(32) NOT NULL,
+    `action_type` varchar(64) NOT NULL,
+    `business_module` varchar(64) NOT NULL,
+    `source` varchar(20) NOT NULL,
+    `income` real,
+    `spend` real,
+    `deposit` real,
+    `withdraw` real,
+    `fee` real,
+    `count` integer
+);
+
+CREATE UNIQUE INDEX `idx_economy_daily_aggregate` ON `economy_daily_aggregates`(`day`,`token`,`action_type`,`business_module`,`source`);
+
+CREATE INDEX `idx_economy_daily_aggregates_deleted_at` ON `economy_daily_aggregates`(`deleted_at`);
+
+CREATE TABLE `economy_aggregate_cursors` (
+    `name` varchar(64),
+    `last_log_id` integer NOT NULL,
+    `updated_at` datetime,
+    PRIMARY KEY (`name`)
+);
+
+CREATE TABLE `economy_aggregate_unsettled` (
+    `cursor_name` varchar(64),
+    `log_id` integer,
+    `created_at` datetime,
+    PRIMARY KEY (`cursor_name`,`log_id`)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE `outbox_events` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL,
+    `event_type` varchar(32) NOT NULL,
+    `payload` TEXT NOT NULL,
+    `status` varchar(10) NOT NULL,
+    `attempts` integer,
+    `next_attempt_at` datetime,
+    `last_error` varchar(255)
+);
+
+CREATE INDEX `idx_outbox_event_due` ON `outbox_events`(`status`,`next_attempt_at`);
+
+CREATE INDEX `idx_outbox_events_account_id` ON `outbox_events`(`account_id`);
+
+CREATE INDEX `idx_outbox_events_deleted_at` ON `outbox_events`(`deleted_at`);
@@ -0,0 +1,36 @@
+CREATE TABLE `webhook_endpoints` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `name` varchar(64) NOT NULL,
+    `url` varchar(512) NOT NULL,
+    `secret` varchar(128) NOT NULL,
+    `filter` TEXT,
+    `active` numeric NOT NULL
+);
+
+CREATE INDEX `idx_webhook_endpoints_deleted_at` ON `webhook_endpoints`(`deleted_at`);
+
+CREATE TABLE `webhook_deliveries` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `endpoint_id` integer NOT NULL,
+    `event_id` integer NOT NULL,
+    `event_type` varchar(32) NOT NULL,
+    `payload` text NOT NULL,
+    `status` varchar(10) NOT NULL,
+    `attempts` integer,
+    `next_attempt_at` datetime,
+    `last_status_code` integer,
+    `last_error` varchar(255),
+    `delivered_at` datetime
+);
+
+CREATE INDEX `idx_webhook_delivery_due` ON `webhook_deliveries`(`status`,`next_attempt_at`);
+
+CREATE UNIQUE INDEX `idx_webhook_delivery_event` ON `webhook_deliveries`(`endpoint_id`,`event_id`,`event_type`);
+
+CREATE INDEX `idx_webhook_deliveries_deleted_at` ON `webhook_deliveries`(`deleted_at`);
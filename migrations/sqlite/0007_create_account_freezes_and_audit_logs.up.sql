@@ -0,0 +1,31 @@
+CREATE TABLE `account_freezes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL,
+    `operator` varchar(64) NOT NULL,
+    `reason` varchar(255) NOT NULL
+);
+
+CREATE UNIQUE INDEX `idx_account_freezes_account_id` ON `account_freezes`(`account_id`);
+
+CREATE INDEX `idx_account_freezes_deleted_at` ON `account_freezes`(`deleted_at`);
+
+CREATE TABLE `audit_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `operator` varchar(64) NOT NULL,
+    `action` varchar(32) NOT NULL,
+    `account_id` integer NOT NULL,
+    `reason` varchar(255) NOT NULL,
+    `detail` TEXT
+);
+
+CREATE INDEX `idx_audit_logs_account_id` ON `audit_logs`(`account_id`);
+
+CREATE INDEX `idx_audit_logs_operator` ON `audit_logs`(`operator`);
+
+CREATE INDEX `idx_audit_logs_deleted_at` ON `audit_logs`(`deleted_at`);
@@ -0,0 +1,3 @@
+ALTER TABLE `erc1155_wallet_logs` DROP COLUMN `approval`;
+ALTER TABLE `erc20_wallet_logs` DROP COLUMN `approval`;
+DROP TABLE `adjustment_requests`;
@@ -0,0 +1,24 @@
+CREATE TABLE `adjustment_requests` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL,
+    `amounts` TEXT,
+    `reason` varchar(255) NOT NULL,
+    `proposed_by` varchar(64) NOT NULL,
+    `status` varchar(16) NOT NULL,
+    `reviewed_by` varchar(64),
+    `review_note` varchar(255),
+    `reviewed_at` datetime
+);
+
+CREATE INDEX `idx_adjustment_requests_status` ON `adjustment_requests`(`status`);
+
+CREATE INDEX `idx_adjustment_requests_account_id` ON `adjustment_requests`(`account_id`);
+
+CREATE INDEX `idx_adjustment_requests_deleted_at` ON `adjustment_requests`(`deleted_at`);
+
+ALTER TABLE `erc20_wallet_logs` ADD COLUMN `approval` TEXT;
+
+ALTER TABLE `erc1155_wallet_logs` ADD COLUMN `approval` TEXT;
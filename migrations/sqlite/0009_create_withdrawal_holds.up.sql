@@ -0,0 +1,41 @@
+ALTER TABLE `erc20_token_wallets` ADD COLUMN `locked` real NOT NULL DEFAULT 0;
+
+CREATE TABLE `withdrawal_holds` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL,
+    `log_id` integer NOT NULL,
+    `amounts` TEXT,
+    `required_approvals` integer NOT NULL,
+    `approvals` integer NOT NULL DEFAULT 0,
+    `status` varchar(16) NOT NULL,
+    `expires_at` datetime,
+    `resolved_by` varchar(64),
+    `resolved_at` datetime,
+    `reason` varchar(255)
+);
+
+CREATE INDEX `idx_withdrawal_holds_expires_at` ON `withdrawal_holds`(`expires_at`);
+
+CREATE INDEX `idx_withdrawal_holds_status` ON `withdrawal_holds`(`status`);
+
+CREATE UNIQUE INDEX `idx_withdrawal_holds_log_id` ON `withdrawal_holds`(`log_id`);
+
+CREATE INDEX `idx_withdrawal_holds_account_id` ON `withdrawal_holds`(`account_id`);
+
+CREATE INDEX `idx_withdrawal_holds_deleted_at` ON `withdrawal_holds`(`deleted_at`);
+
+CREATE TABLE `withdrawal_approvals` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `hold_id` integer NOT NULL,
+    `operator` varchar(64) NOT NULL
+);
+
+CREATE UNIQUE INDEX `idx_withdrawal_approval_operator` ON `withdrawal_approvals`(`hold_id`,`operator`);
+
+CREATE INDEX `idx_withdrawal_approvals_deleted_at` ON `withdrawal_approvals`(`deleted_at`);
@@ -0,0 +1,28 @@
+CREATE TABLE `ingested_deposits` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `chain` varchar(20) NOT NULL,
+    `tx_hash` varchar(66) NOT NULL,
+    `log_index` integer NOT NULL,
+    `address` varchar(42) NOT NULL,
+    `account_id` integer NOT NULL,
+    `asset_type` integer NOT NULL,
+    `token` varchar(20),
+    `amount` real,
+    `ids` text,
+    `values` text
+);
+
+CREATE INDEX `idx_ingested_deposits_account_id` ON `ingested_deposits`(`account_id`);
+
+CREATE INDEX `idx_ingested_deposits_address` ON `ingested_deposits`(`address`);
+
+CREATE UNIQUE INDEX `idx_ingested_deposit_identity` ON `ingested_deposits`(`chain`,`tx_hash`,`log_index`);
+
+CREATE INDEX `idx_ingested_deposits_deleted_at` ON `ingested_deposits`(`deleted_at`);
+
+ALTER TABLE `erc20_wallet_logs` ADD COLUMN `chain_ref` TEXT;
+
+ALTER TABLE `erc1155_wallet_logs` ADD COLUMN `chain_ref` TEXT;
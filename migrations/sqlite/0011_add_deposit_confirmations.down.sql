@@ -0,0 +1,5 @@
+DROP TABLE `account_flags`;
+DROP INDEX `idx_ingested_deposits_status`;
+ALTER TABLE `ingested_deposits` DROP COLUMN `status`;
+ALTER TABLE `ingested_deposits` DROP COLUMN `block_hash`;
+ALTER TABLE `ingested_deposits` DROP COLUMN `block_number`;
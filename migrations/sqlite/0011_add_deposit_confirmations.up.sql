@@ -0,0 +1,22 @@
+ALTER TABLE `ingested_deposits` ADD COLUMN `block_number` integer NOT NULL DEFAULT 0;
+
+ALTER TABLE `ingested_deposits` ADD COLUMN `block_hash` varchar(66);
+
+ALTER TABLE `ingested_deposits` ADD COLUMN `status` varchar(20) NOT NULL DEFAULT 'final';
+
+CREATE INDEX `idx_ingested_deposits_status` ON `ingested_deposits`(`status`);
+
+CREATE TABLE `account_flags` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL,
+    `reason` varchar(32) NOT NULL,
+    `deposit_id` integer,
+    `shortfall` TEXT
+);
+
+CREATE INDEX `idx_account_flags_account_id` ON `account_flags`(`account_id`);
+
+CREATE INDEX `idx_account_flags_deleted_at` ON `account_flags`(`deleted_at`);
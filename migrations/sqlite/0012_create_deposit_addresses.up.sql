@@ -0,0 +1,17 @@
+CREATE TABLE `deposit_addresses` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `account_id` integer NOT NULL,
+    `chain` varchar(20) NOT NULL,
+    `address` varchar(42) NOT NULL,
+    `assigned_at` datetime NOT NULL,
+    `retired_at` datetime
+);
+
+CREATE UNIQUE INDEX `idx_deposit_address_identity` ON `deposit_addresses`(`chain`,`address`);
+
+CREATE INDEX `idx_deposit_address_account` ON `deposit_addresses`(`account_id`,`chain`);
+
+CREATE INDEX `idx_deposit_addresses_deleted_at` ON `deposit_addresses`(`deleted_at`);
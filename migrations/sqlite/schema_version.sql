@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS `schema_version` (
+    `version` integer,
+    `name` varchar(128) NOT NULL,
+    `applied_at` datetime NOT NULL,
+    PRIMARY KEY (`version`)
+);
@@ -4,9 +4,12 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"strings"
 
 	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 type Wallet struct {
@@ -23,7 +26,19 @@ func (w Wallet) Value() (driver.Value, error) {
 }
 
 func (w *Wallet) Scan(input interface{}) error {
-	return json.Unmarshal(input.([]byte), w)
+	return scanJSON(input, w)
+}
+
+func (Wallet) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+// copyWallet copies w with its own token rows, so later changes of either do not show in the other.
+func copyWallet(w Wallet) Wallet {
+	if w.ERC20TokenData != nil {
+		w.ERC20TokenData = append([]ERC20TokenWallet{}, w.ERC20TokenData...)
+	}
+	return w
 }
 
 type ERC20TokenWallet struct {
@@ -37,6 +52,8 @@ type ERC20TokenWallet struct {
 	TotalDeposit  float64 `json:"total_deposit"`
 	TotalWithdraw float64 `json:"total_withdraw"`
 	TotalFee      float64 `json:"total_fee"`
+	// Locked the part of the balance held by withdrawals waiting for approval, not included in Balance.
+	Locked float64 `json:"locked,omitempty" gorm:"not null;default:0"`
 }
 
 type ERC1155TokenWallet struct {
@@ -46,6 +63,19 @@ type ERC1155TokenWallet struct {
 	Values     string `json:"values"`
 }
 
+// Amounts decodes the holdings, in the order of Ids. Ids without a matching value are left out.
+func (w ERC1155TokenWallet) Amounts() []ItemAmount {
+	ids := convertStringToUIntArray(w.Ids)
+	values := convertStringToUIntArray(w.Values)
+	var result []ItemAmount
+	for index, id := range ids {
+		if index < len(values) {
+			result = append(result, ItemAmount{Id: id, Amount: values[index]})
+		}
+	}
+	return result
+}
+
 type walletDA0 struct{}
 
 var walletDAO = &walletDA0{}
@@ -60,6 +90,27 @@ func (dao walletDA0) getWallet(db *gorm.DB, accountId uint64) (w Wallet, err err
 	return w, nil
 }
 
+func (dao walletDA0) listWallets(db *gorm.DB, afterAccountId uint64, limit int) (result []Wallet, err error) {
+	err = db.Preload("ERC20TokenData").
+		Preload("ERC1155TokenData").
+		Where("account_id > ?", afterAccountId).
+		Order("account_id").
+		Limit(limit).
+		Find(&result).Error
+	return result, err
+}
+
+// lockWallet selects the wallet row of accountId FOR UPDATE. SQLite has no row locks, its writers are
+// serialized by the database lock instead.
+func (dao walletDA0) lockWallet(db *gorm.DB, accountId uint64) error {
+	query := db.Model(&Wallet{}).Select("id").Where("account_id = ?", accountId)
+	if db.Dialector.Name() != "sqlite" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var wallet Wallet
+	return query.First(&wallet).Error
+}
+
 func (dao walletDA0) updateWallet(db *gorm.DB, newWallet Wallet) error {
 	if err := db.Save(&newWallet).Error; err != nil {
 		return err
@@ -67,10 +118,15 @@ func (dao walletDA0) updateWallet(db *gorm.DB, newWallet Wallet) error {
 	return nil
 }
 
+// createWallet creates wallet unless the account has one already. The insert runs in a nested transaction,
+// inside a transaction of the caller that is a savepoint, so a unique violation only rolls back the insert:
+// PostgreSQL aborts the whole transaction otherwise.
 func (dao walletDA0) createWallet(db *gorm.DB, wallet Wallet) error {
-	var mysqlErr *mysql.MySQLError
-	if err := db.Create(&wallet).Error; err != nil {
-		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&wallet).Error
+	})
+	if err != nil {
+		if isDuplicateKeyError(err) {
 			return nil
 		}
 		return err
@@ -78,6 +134,22 @@ func (dao walletDA0) createWallet(db *gorm.DB, wallet Wallet) error {
 	return nil
 }
 
+// isDuplicateKeyError reports whether err is a unique constraint violation, on MySQL, PostgreSQL or SQLite.
+func isDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062
+	}
+	// pgx and lib/pq errors expose the SQLSTATE, 23505 is unique_violation
+	var sqlStateErr interface{ SQLState() string }
+	if errors.As(err, &sqlStateErr) {
+		return sqlStateErr.SQLState() == "23505"
+	}
+	message := err.Error()
+	return strings.Contains(message, "UNIQUE constraint failed") ||
+		strings.Contains(message, "duplicate key value violates unique constraint")
+}
+
 func (dao walletDA0) updateWalletCheckSign(db *gorm.DB, newWallet Wallet) error {
 	return db.Model(&newWallet).
 		Where("account_id = ?", newWallet.AccountId).
@@ -0,0 +1,63 @@
+package walleter
+
+import "go.opentelemetry.io/otel/trace"
+
+// Option customizes a Walleter created by New.
+type Option func(*Walleter)
+
+// WithMetrics records command and database metrics into m, see NewMetrics.
+func WithMetrics(m *Metrics) Option {
+	return func(s *Walleter) {
+		s.metrics = m
+	}
+}
+
+// WithTracerProvider traces every command, its steps and its database calls with provider.
+// Spans are children of the span in the context of the db passed to HandleWalletCommand.
+// Without it New registers no tracing callbacks on db.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(s *Walleter) {
+		s.tracer = provider.Tracer(tracerName)
+		s.traced = true
+	}
+}
+
+// WithLogger sends the log events of walleter to logger, nothing is logged by default.
+func WithLogger(logger Logger) Option {
+	return func(s *Walleter) {
+		s.logger = logger
+	}
+}
+
+// WithWithdrawalPolicy holds the large withdrawals described by policy until operators approve them,
+// see ApproveWithdrawal. Commands of held withdrawals return a WithdrawalHeldError carrying the hold id.
+// Without it every withdrawal is performed right away.
+func WithWithdrawalPolicy(policy WithdrawalPolicy) Option {
+	return func(s *Walleter) {
+		s.withdrawalPolicy = policy
+	}
+}
+
+// WithDepositAddressResolver maps the addresses of deposits to accounts with resolver instead of the deposit
+// address registry of the Walleter, see AssignDepositAddress.
+func WithDepositAddressResolver(resolver DepositAddressResolver) Option {
+	return func(s *Walleter) {
+		s.depositAddresses = resolver
+	}
+}
+
+// WithConfirmations keeps the deposits of the chains of policies pending until they are deep enough in their
+// chain, see ProcessConfirmations. Deposits of other chains are credited as soon as they are ingested.
+func WithConfirmations(policies map[CommandSourceType]ConfirmationPolicy) Option {
+	return func(s *Walleter) {
+		s.confirmations = policies
+	}
+}
+
+// WithoutAutoMigrate stops New from applying pending migrations, the schema is then managed
+// with Migrate or MigrateTo, e.g. from a deploy step. New still expects an up to date schema.
+func WithoutAutoMigrate() Option {
+	return func(s *Walleter) {
+		s.autoMigrate = false
+	}
+}
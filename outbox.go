@@ -0,0 +1,401 @@
+package walleter
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const WalletChangedEventType = "wallet_changed"
+
+// OutboxStatus delivery state of an outbox event.
+type OutboxStatus int
+
+const (
+	OutboxPending    OutboxStatus = 0
+	OutboxDelivered  OutboxStatus = 1
+	OutboxDeadLetter OutboxStatus = 2
+)
+
+func (s OutboxStatus) String() string {
+	switch s {
+	case OutboxPending:
+		return "pending"
+	case OutboxDelivered:
+		return "delivered"
+	case OutboxDeadLetter:
+		return "dead"
+	}
+	return "unknown"
+}
+
+// ERC20Change the change of one ERC20 token caused by a command, Delta includes fees.
+type ERC20Change struct {
+	Token   string  `json:"token"`
+	Delta   float64 `json:"delta"`
+	Balance float64 `json:"balance"`
+}
+
+// ERC1155Change the change of one ERC1155 id caused by a command.
+type ERC1155Change struct {
+	Id      uint64 `json:"id"`
+	Delta   int64  `json:"delta"`
+	Balance uint64 `json:"balance"`
+}
+
+// WalletChangedEvent is published for every command which settled successfully.
+type WalletChangedEvent struct {
+	EventId        uint            `json:"event_id"`
+	AccountId      uint64          `json:"account_id"`
+	AssetType      AssetType       `json:"asset_type"`
+	ActionType     string          `json:"action_type"`
+	BusinessModule string          `json:"business_module"`
+	Source         string          `json:"source"`
+	LogId          uint            `json:"log_id"`
+	ERC20Changes   []ERC20Change   `json:"erc20_changes"`
+	ERC1155Changes []ERC1155Change `json:"erc1155_changes"`
+	OccurredAt     time.Time       `json:"occurred_at"`
+}
+
+func (WalletChangedEvent) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (e WalletChangedEvent) Value() (driver.Value, error) {
+	b, err := json.Marshal(e)
+	return string(b), err
+}
+
+func (e *WalletChangedEvent) Scan(input interface{}) error {
+	return scanJSON(input, e)
+}
+
+// OutboxEvent an event waiting for, or done with, delivery to subscribers.
+// It is written with the same db handle as the wallet changes, so it commits or rolls back with them.
+type OutboxEvent struct {
+	gorm.Model    `swagger-ignore:"true"`
+	AccountId     uint64             `json:"account_id" gorm:"not null;index"`
+	EventType     string             `json:"event_type" gorm:"type:varchar(32);not null"`
+	Payload       WalletChangedEvent `json:"payload" gorm:"type:json;not null"`
+	Status        string             `json:"status" gorm:"type:varchar(10);not null;index:idx_outbox_event_due"`
+	Attempts      int                `json:"attempts"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" gorm:"index:idx_outbox_event_due"`
+	LastError     string             `json:"last_error" gorm:"type:varchar(255)"`
+}
+
+type outboxEventDAO struct{}
+
+var outboxDAO = &outboxEventDAO{}
+
+func (dao outboxEventDAO) insertEvent(db *gorm.DB, event OutboxEvent) (OutboxEvent, error) {
+	err := db.Create(&event).Error
+	return event, err
+}
+
+// getDueEvents returns due events heading their account, i.e. without an older pending event of the same account,
+// oldest first. The later events of an account blocked by a failing event are not read, so they never fill a batch
+// and hold back the events of other accounts.
+func (dao outboxEventDAO) getDueEvents(db *gorm.DB, now time.Time, limit int) (result []OutboxEvent, err error) {
+	err = db.Where("status = ? AND next_attempt_at <= ?", OutboxPending.String(), now).
+		Where("NOT EXISTS (SELECT 1 FROM outbox_events older WHERE older.account_id = outbox_events.account_id "+
+			"AND older.status = ? AND older.id < outbox_events.id AND older.deleted_at IS NULL)", OutboxPending.String()).
+		Order("id").Limit(limit).Find(&result).Error
+	return result, err
+}
+
+// getNextEvent returns the oldest pending event of an account after afterId, reports false if there is none.
+func (dao outboxEventDAO) getNextEvent(db *gorm.DB, accountId uint64, afterId uint) (OutboxEvent, bool, error) {
+	var events []OutboxEvent
+	err := db.Where("account_id = ? AND status = ? AND id > ?", accountId, OutboxPending.String(), afterId).
+		Order("id").Limit(1).Find(&events).Error
+	if err != nil || len(events) == 0 {
+		return OutboxEvent{}, false, err
+	}
+	return events[0], true, nil
+}
+
+// claimEvent leases a due event until leaseUntil, reports false when another dispatcher holds it.
+func (dao outboxEventDAO) claimEvent(db *gorm.DB, event OutboxEvent, now time.Time, leaseUntil time.Time) (bool, error) {
+	result := db.Model(&OutboxEvent{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", event.ID, OutboxPending.String(), now).
+		Update("next_attempt_at", leaseUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (dao outboxEventDAO) updateEvent(db *gorm.DB, id uint, values map[string]interface{}) error {
+	return db.Model(&OutboxEvent{}).Where("id = ?", id).Updates(values).Error
+}
+
+// /----------------------------
+// Wallet event service
+type walletEventService struct{}
+
+func newWalletEventService() *walletEventService {
+	return &walletEventService{}
+}
+
+// publishWalletChanged stores a WalletChanged event of a settled command in the outbox.
+func (receiver *walletEventService) publishWalletChanged(repo Repository, command WalletCommand, logId uint, settledWallet Wallet) error {
+	event := WalletChangedEvent{
+		AccountId:      command.AccountId,
+		AssetType:      command.AssetType,
+		ActionType:     command.ActionType.String(),
+		BusinessModule: command.BusinessModule,
+		Source:         command.CommandSource.String(),
+		LogId:          logId,
+		OccurredAt:     time.Now(),
+	}
+
+	sign := movementSign(command.ActionType.String())
+	erc20Deltas := map[string]float64{}
+	for _, token := range command.ERC20Commands {
+		erc20Deltas[token.Token.String()] += sign * token.Value
+	}
+	for _, fee := range command.FeeCommands {
+		if fee.Value > 0 {
+			erc20Deltas[fee.Token.String()] -= fee.Value
+		}
+	}
+	for _, token := range settledWallet.ERC20TokenData {
+		if delta, ok := erc20Deltas[token.Token]; ok {
+			event.ERC20Changes = append(event.ERC20Changes, ERC20Change{Token: token.Token, Delta: delta, Balance: token.Balance})
+		}
+	}
+	sort.Slice(event.ERC20Changes, func(i, j int) bool { return event.ERC20Changes[i].Token < event.ERC20Changes[j].Token })
+
+	ids := convertStringToUIntArray(settledWallet.ERC1155TokenData.Ids)
+	values := convertStringToUIntArray(settledWallet.ERC1155TokenData.Values)
+	for index, id := range command.ERC1155Command.Ids {
+		change := ERC1155Change{Id: id, Delta: int64(sign) * int64(command.ERC1155Command.Values[index])}
+		if i := indexOfArray(ids, id); i != -1 && i < len(values) {
+			change.Balance = values[i]
+		}
+		event.ERC1155Changes = append(event.ERC1155Changes, change)
+	}
+
+	_, err := repo.InsertOutboxEvent(OutboxEvent{
+		AccountId:     command.AccountId,
+		EventType:     WalletChangedEventType,
+		Payload:       event,
+		Status:        OutboxPending.String(),
+		NextAttemptAt: event.OccurredAt,
+	})
+	return err
+}
+
+// /----------------------------
+// Event dispatcher
+
+// WalletEventHandler receives wallet events. Delivery is at least once, handlers should be idempotent on EventId.
+type WalletEventHandler func(ctx context.Context, event WalletChangedEvent) error
+
+// EventDispatcherConfig tuning of an EventDispatcher, zero values fall back to defaults.
+type EventDispatcherConfig struct {
+	// MaxAttempts before an event is moved to the dead-letter state, defaults to 10.
+	MaxAttempts int
+	// RetryBackoff delay before the first retry, doubled for every further attempt, defaults to 1s.
+	RetryBackoff time.Duration
+	// MaxRetryBackoff upper bound of the retry delay, defaults to 10m.
+	MaxRetryBackoff time.Duration
+	// Lease how long a dispatcher owns an event while delivering it, defaults to 1m.
+	Lease time.Duration
+	// BatchSize events attempted per DispatchOnce, defaults to 100.
+	BatchSize int
+	// PollInterval of Run, defaults to 1s.
+	PollInterval time.Duration
+}
+
+type eventSubscriber struct {
+	name    string
+	handler WalletEventHandler
+}
+
+// EventDispatcher delivers outbox events to subscribers. Events of one account are delivered in order,
+// a failing event blocks the later events of its account until it is delivered or dead-lettered.
+// Several dispatchers may poll the same outbox, every event is leased before delivery.
+type EventDispatcher struct {
+	db          *gorm.DB
+	config      EventDispatcherConfig
+	logger      Logger
+	mu          sync.RWMutex
+	subscribers []eventSubscriber
+}
+
+// NewEventDispatcher creates a dispatcher reading the outbox of this Walleter. DispatchOnce fails with
+// ErrDatabaseRequired when the Walleter does not store into a gorm database.
+func (s *Walleter) NewEventDispatcher(config EventDispatcherConfig) *EventDispatcher {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 10
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = time.Second
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = 10 * time.Minute
+	}
+	if config.Lease <= 0 {
+		config.Lease = time.Minute
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	return &EventDispatcher{db: s.db, config: config, logger: s.logger}
+}
+
+// Subscribe registers a handler, name identifies it in error messages.
+func (d *EventDispatcher) Subscribe(name string, handler WalletEventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscribers = append(d.subscribers, eventSubscriber{name: name, handler: handler})
+}
+
+// Run dispatches due events every PollInterval until ctx is cancelled.
+func (d *EventDispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchOnce(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce delivers one batch of due events and returns how many were delivered. Every account whose oldest
+// pending event is due gets its events delivered in order, until one fails or is not due yet.
+func (d *EventDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	if d.db == nil {
+		return 0, ErrDatabaseRequired
+	}
+	events, err := outboxDAO.getDueEvents(d.db, time.Now(), d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	attempted := 0
+	for _, event := range events {
+		for attempted < d.config.BatchSize {
+			if ctx.Err() != nil {
+				return delivered, ctx.Err()
+			}
+			now := time.Now()
+			claimed, err := outboxDAO.claimEvent(d.db, event, now, now.Add(d.config.Lease))
+			if err != nil {
+				return delivered, err
+			}
+			if !claimed {
+				break
+			}
+			attempted++
+			if err = d.deliver(ctx, event); err != nil {
+				break
+			}
+			delivered++
+
+			next, found, err := outboxDAO.getNextEvent(d.db, event.AccountId, event.ID)
+			if err != nil {
+				return delivered, err
+			}
+			if !found {
+				break
+			}
+			event = next
+		}
+	}
+	return delivered, nil
+}
+
+// deliver hands an event to every subscriber and records the outcome.
+func (d *EventDispatcher) deliver(ctx context.Context, event OutboxEvent) error {
+	payload := event.Payload
+	payload.EventId = event.ID
+
+	d.mu.RLock()
+	subscribers := append([]eventSubscriber{}, d.subscribers...)
+	d.mu.RUnlock()
+
+	var deliveryErr error
+	for _, subscriber := range subscribers {
+		if err := subscriber.handler(ctx, payload); err != nil {
+			deliveryErr = fmt.Errorf("subscriber %s: %w", subscriber.name, err)
+			break
+		}
+	}
+
+	if deliveryErr == nil {
+		return outboxDAO.updateEvent(d.db, event.ID, map[string]interface{}{
+			"status":     OutboxDelivered.String(),
+			"attempts":   event.Attempts + 1,
+			"last_error": "",
+		})
+	}
+
+	attempts := event.Attempts + 1
+	values := map[string]interface{}{
+		"attempts":        attempts,
+		"last_error":      truncateErrorMessage(deliveryErr.Error()),
+		"next_attempt_at": time.Now().Add(d.retryDelay(attempts)),
+	}
+	keyvals := []interface{}{"event_id", event.ID, "account_id", event.AccountId, "attempts", attempts, "error", deliveryErr.Error()}
+	if attempts >= d.config.MaxAttempts {
+		values["status"] = OutboxDeadLetter.String()
+		d.logger.Error("outbox event moved to dead letter", keyvals...)
+	} else {
+		d.logger.Warn("outbox event delivery failed, will retry", keyvals...)
+	}
+	if err := outboxDAO.updateEvent(d.db, event.ID, values); err != nil {
+		return err
+	}
+	return deliveryErr
+}
+
+func (d *EventDispatcher) retryDelay(attempts int) time.Duration {
+	delay := d.config.RetryBackoff
+	for i := 1; i < attempts && delay < d.config.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.config.MaxRetryBackoff {
+		delay = d.config.MaxRetryBackoff
+	}
+	return delay
+}
+
+// ListDeadLetterEvents returns dead-lettered events, oldest first.
+func (s *Walleter) ListDeadLetterEvents(limit int) (result []OutboxEvent, err error) {
+	db, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+	err = db.Where("status = ?", OutboxDeadLetter.String()).Order("id").Limit(limit).Find(&result).Error
+	return result, err
+}
+
+// RequeueDeadLetterEvent moves a dead-lettered event back to pending with its attempts reset.
+func (s *Walleter) RequeueDeadLetterEvent(id uint) error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+	return db.Model(&OutboxEvent{}).
+		Where("id = ? AND status = ?", id, OutboxDeadLetter.String()).
+		Updates(map[string]interface{}{
+			"status":          OutboxPending.String(),
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		}).Error
+}
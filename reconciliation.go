@@ -0,0 +1,156 @@
+package walleter
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// reconciliationTolerance absorbs the rounding of float64 totals summed over many commands.
+const reconciliationTolerance = 1e-6
+
+// walletBatchSize how many wallets are loaded at once when every wallet is checked.
+const walletBatchSize = 200
+
+// ReconciliationIssueKind what a ReconciliationIssue found wrong.
+type ReconciliationIssueKind string
+
+const (
+	// IssueCheckSign the check sign does not match the wallet, its commands are rejected until it is repaired.
+	IssueCheckSign ReconciliationIssueKind = "check_sign"
+	// IssueTotals an ERC20 balance differs from income + deposit - spend - withdraw - fee - locked.
+	IssueTotals ReconciliationIssueKind = "totals"
+	// IssueBalanceHistory a balance differs from the last balance recorded in its history.
+	IssueBalanceHistory ReconciliationIssueKind = "balance_history"
+	// IssueERC1155Data the ids and amounts of ERC1155 holdings do not pair up.
+	IssueERC1155Data ReconciliationIssueKind = "erc1155_data"
+)
+
+// ReconciliationIssue one disagreement found in a wallet. Expected is what the totals or the history
+// say, Actual what the wallet holds.
+type ReconciliationIssue struct {
+	AccountId uint64                  `json:"account_id"`
+	Kind      ReconciliationIssueKind `json:"kind"`
+	Token     string                  `json:"token,omitempty"`
+	ItemId    uint64                  `json:"item_id,omitempty"`
+	Expected  float64                 `json:"expected"`
+	Actual    float64                 `json:"actual"`
+	Message   string                  `json:"message"`
+}
+
+// ReconciliationReport the result of Reconcile, a wallet without issues is consistent.
+type ReconciliationReport struct {
+	GeneratedAt time.Time             `json:"generated_at"`
+	Wallets     int                   `json:"wallets"`
+	Issues      []ReconciliationIssue `json:"issues"`
+}
+
+// Reconcile checks that the wallets of accountIds, every wallet when empty, agree with themselves and with
+// their balance history: check signs are valid, ERC20 balances match their totals and the last balance
+// recorded for every token and item. Tokens and items without history are not compared to it.
+func (s *Walleter) Reconcile(accountIds []uint64) (ReconciliationReport, error) {
+	histories, err := storeOf[BalanceHistoryStore](s.repo)
+	if err != nil {
+		return ReconciliationReport{}, err
+	}
+	report := ReconciliationReport{GeneratedAt: time.Now()}
+	if len(accountIds) > 0 {
+		for _, accountId := range accountIds {
+			wallet, err := s.repo.GetWallet(accountId)
+			if err != nil {
+				return ReconciliationReport{}, err
+			}
+			if err := reconcileWallet(histories, &report, wallet); err != nil {
+				return ReconciliationReport{}, err
+			}
+		}
+		return report, nil
+	}
+
+	err = forEachWallet(s.repo, func(wallet Wallet) error {
+		return reconcileWallet(histories, &report, wallet)
+	})
+	if err != nil {
+		return ReconciliationReport{}, err
+	}
+	return report, nil
+}
+
+func reconcileWallet(histories BalanceHistoryStore, report *ReconciliationReport, wallet Wallet) error {
+	report.Wallets++
+	addIssue := func(issue ReconciliationIssue) {
+		issue.AccountId = wallet.AccountId
+		report.Issues = append(report.Issues, issue)
+	}
+
+	// 1. The check sign
+	if err := VerifyWallet(wallet); err != nil {
+		addIssue(ReconciliationIssue{Kind: IssueCheckSign, Message: err.Error()})
+	}
+
+	// 2. ERC20 balances against their totals and their history
+	erc20Histories, err := histories.GetLatestERC20BalanceHistories(wallet.AccountId, time.Now())
+	if err != nil {
+		return err
+	}
+	lastERC20Balances := map[string]float64{}
+	for _, history := range erc20Histories {
+		lastERC20Balances[history.Token] = history.Balance
+	}
+	for _, token := range wallet.ERC20TokenData {
+		expected := token.TotalIncome + token.TotalDeposit - token.TotalSpend - token.TotalWithdraw - token.TotalFee - token.Locked
+		if math.Abs(expected-token.Balance) > reconciliationTolerance {
+			addIssue(ReconciliationIssue{Kind: IssueTotals, Token: token.Token, Expected: expected, Actual: token.Balance,
+				Message: fmt.Sprintf("%s balance does not match its totals", token.Token)})
+		}
+		if last, ok := lastERC20Balances[token.Token]; ok && math.Abs(last-token.Balance) > reconciliationTolerance {
+			addIssue(ReconciliationIssue{Kind: IssueBalanceHistory, Token: token.Token, Expected: last, Actual: token.Balance,
+				Message: fmt.Sprintf("%s balance does not match its history", token.Token)})
+		}
+	}
+
+	// 3. ERC1155 amounts against their history
+	ids := convertStringToUIntArray(wallet.ERC1155TokenData.Ids)
+	values := convertStringToUIntArray(wallet.ERC1155TokenData.Values)
+	if len(ids) != len(values) {
+		addIssue(ReconciliationIssue{Kind: IssueERC1155Data, Expected: float64(len(ids)), Actual: float64(len(values)),
+			Message: "erc1155 ids and amounts differ in number"})
+		return nil
+	}
+	amounts := map[uint64]uint64{}
+	for index, id := range ids {
+		amounts[id] += values[index]
+	}
+	erc1155Histories, err := histories.GetLatestERC1155BalanceHistories(wallet.AccountId, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, history := range erc1155Histories {
+		if amounts[history.TokenId] != history.Balance {
+			addIssue(ReconciliationIssue{Kind: IssueBalanceHistory, ItemId: history.TokenId,
+				Expected: float64(history.Balance), Actual: float64(amounts[history.TokenId]),
+				Message: fmt.Sprintf("item %d amount does not match its history", history.TokenId)})
+		}
+	}
+	return nil
+}
+
+// forEachWallet calls fn with every wallet of repo in account order, loading them in batches.
+func forEachWallet(repo Repository, fn func(wallet Wallet) error) error {
+	var afterAccountId uint64
+	for {
+		wallets, err := repo.ListWallets(afterAccountId, walletBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, wallet := range wallets {
+			if err := fn(wallet); err != nil {
+				return err
+			}
+			afterAccountId = wallet.AccountId
+		}
+		if len(wallets) < walletBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package walleter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const recoveryBatchSize = 100
+
+// RecoveredLog the decision taken for one stale Pending log.
+type RecoveredLog struct {
+	AssetType AssetType `json:"asset_type"`
+	LogId     uint      `json:"log_id"`
+	AccountId uint64    `json:"account_id"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason"`
+}
+
+// RecoveryReport summary of one recovery run. Skipped counts logs resolved by another instance meanwhile.
+type RecoveryReport struct {
+	Done    int            `json:"done"`
+	Failed  int            `json:"failed"`
+	Skipped int            `json:"skipped"`
+	Logs    []RecoveredLog `json:"logs"`
+}
+
+// RecoverStaleLogs resolves logs which are still Pending staleAfter after their creation, this happens
+// when a process dies between inserting a log and settling it. A log is marked Done when the wallet
+// carries its changes and Failed otherwise, with a failure detail of code CodeInterrupted giving the reason.
+// Every log is only resolved while it is still Pending, so several instances can run recovery at the same time.
+func (s *Walleter) RecoverStaleLogs(staleAfter time.Duration) (RecoveryReport, error) {
+	report := RecoveryReport{}
+	db, err := s.database()
+	if err != nil {
+		return report, err
+	}
+	cutoff := time.Now().Add(-staleAfter)
+
+	var erc20Logs []ERC20WalletLog
+	err = db.Where("status = ? AND created_at < ?", Pending.String(), cutoff).
+		Order("id").Limit(recoveryBatchSize).Find(&erc20Logs).Error
+	if err != nil {
+		return report, err
+	}
+	for _, item := range erc20Logs {
+		status, settledWallet, reason, err := decideStaleLog(db, item.toEntry(), item.OriginalWallet)
+		if err != nil {
+			return report, err
+		}
+		claimed, err := recoveryDAO.resolveERC20WalletLog(db, item.ID, status, settledWallet, recoveryFailureOf(status, item.AccountId, reason))
+		if err != nil {
+			return report, err
+		}
+		report.add(ERC20AssetType, item.AccountId, item.ID, claimed, status, reason)
+		s.logRecovery(ERC20AssetType, item.AccountId, item.ID, claimed, status, reason)
+	}
+
+	var erc1155Logs []ERC1155WalletLog
+	err = db.Where("status = ? AND created_at < ?", Pending.String(), cutoff).
+		Order("id").Limit(recoveryBatchSize).Find(&erc1155Logs).Error
+	if err != nil {
+		return report, err
+	}
+	for _, item := range erc1155Logs {
+		status, settledWallet, reason, err := decideStaleLog(db, item.toEntry(), item.OriginalWallet)
+		if err != nil {
+			return report, err
+		}
+		claimed, err := recoveryDAO.resolveERC1155WalletLog(db, item.ID, status, settledWallet, recoveryFailureOf(status, item.AccountId, reason))
+		if err != nil {
+			return report, err
+		}
+		report.add(ERC1155AssetType, item.AccountId, item.ID, claimed, status, reason)
+		s.logRecovery(ERC1155AssetType, item.AccountId, item.ID, claimed, status, reason)
+	}
+	return report, nil
+}
+
+// RunRecoveryWorker calls RecoverStaleLogs every interval until ctx is cancelled.
+func (s *Walleter) RunRecoveryWorker(ctx context.Context, interval time.Duration, staleAfter time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.RecoverStaleLogs(staleAfter); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// recoveryFailureOf the failure detail of a log recovery marks Failed, nil for a log marked Done. The reason of
+// Done logs is only reported and logged.
+func recoveryFailureOf(status WalletLogStatus, accountId uint64, reason string) *WalletError {
+	if status != Failed {
+		return nil
+	}
+	failure := newWalletError(ErrCommandInterrupted, accountId)
+	failure.Message = reason
+	return failure
+}
+
+func (report *RecoveryReport) add(assetType AssetType, accountId uint64, logId uint, claimed bool, status WalletLogStatus, reason string) {
+	if !claimed {
+		report.Skipped++
+		return
+	}
+	if status == Done {
+		report.Done++
+	} else {
+		report.Failed++
+	}
+	report.Logs = append(report.Logs, RecoveredLog{
+		AssetType: assetType,
+		LogId:     logId,
+		AccountId: accountId,
+		Status:    status.String(),
+		Reason:    reason,
+	})
+}
+
+func (s *Walleter) logRecovery(assetType AssetType, accountId uint64, logId uint, claimed bool, status WalletLogStatus, reason string) {
+	if !claimed {
+		s.logger.Debug("stale pending log already resolved by another instance",
+			"asset_type", assetType.String(), "log_id", logId, "account_id", accountId)
+		return
+	}
+	keyvals := []interface{}{
+		"asset_type", assetType.String(), "log_id", logId, "account_id", accountId,
+		"status", status.String(), "reason", reason,
+	}
+	if status == Done {
+		s.logger.Warn("stale pending log recovered as done", keyvals...)
+	} else {
+		s.logger.Warn("stale pending log recovered as failed", keyvals...)
+	}
+}
+
+// decideStaleLog works out whether the change of a stale log reached the wallet.
+// The wallet is compared with the wallet before the change and with the wallet expected after it,
+// when it has moved on since, the earliest later log tells which of both it started from.
+func decideStaleLog(db *gorm.DB, entry WalletLogEntry, originalWallet Wallet) (WalletLogStatus, Wallet, string, error) {
+	currentWallet, err := walletDAO.getWallet(db, entry.AccountId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Failed, Wallet{}, "recovery: wallet does not exist, change was not applied", nil
+	}
+	if err != nil {
+		return Failed, Wallet{}, "", err
+	}
+
+	if entry.ActionType == Initialize.String() {
+		return Done, currentWallet, "recovery: wallet exists, initialization was applied", nil
+	}
+
+	before := newBalanceState(originalWallet)
+	after := before.apply(entry)
+	current := newBalanceState(currentWallet)
+	if current.equal(after) && !after.equal(before) {
+		return Done, currentWallet, "recovery: wallet matches the expected result, change was applied", nil
+	}
+	if current.equal(before) {
+		return Failed, currentWallet, "recovery: wallet unchanged, change was not applied", nil
+	}
+
+	next, found, err := recoveryDAO.getNextOriginalWallet(db, entry)
+	if err != nil {
+		return Failed, Wallet{}, "", err
+	}
+	if found {
+		nextState := newBalanceState(next)
+		if nextState.equal(after) {
+			return Done, currentWallet, "recovery: the following command started from the expected result, change was applied", nil
+		}
+		if nextState.equal(before) {
+			return Failed, currentWallet, "recovery: the following command started from the original wallet, change was not applied", nil
+		}
+	}
+	return Failed, currentWallet, "recovery: undetermined, wallet diverged from the log, needs manual review", nil
+}
+
+// balanceState the comparable part of a wallet: balances of erc20 tokens and amounts of erc1155 ids.
+type balanceState struct {
+	erc20   map[string]float64
+	erc1155 map[uint64]uint64
+}
+
+func newBalanceState(w Wallet) balanceState {
+	state := balanceState{erc20: map[string]float64{}, erc1155: map[uint64]uint64{}}
+	for _, token := range w.ERC20TokenData {
+		if token.Balance != 0 {
+			state.erc20[token.Token] = token.Balance
+		}
+	}
+	ids := convertStringToUIntArray(w.ERC1155TokenData.Ids)
+	values := convertStringToUIntArray(w.ERC1155TokenData.Values)
+	for index, id := range ids {
+		if index < len(values) && values[index] != 0 {
+			state.erc1155[id] = values[index]
+		}
+	}
+	return state
+}
+
+// apply returns the state after the changes of a log entry, mirroring the command handlers.
+func (state balanceState) apply(entry WalletLogEntry) balanceState {
+	result := balanceState{erc20: map[string]float64{}, erc1155: map[uint64]uint64{}}
+	for key, value := range state.erc20 {
+		result.erc20[key] = value
+	}
+	for key, value := range state.erc1155 {
+		result.erc1155[key] = value
+	}
+
+	sign := movementSign(entry.ActionType)
+	for _, fee := range entry.Fees {
+		if fee.Amount > 0 {
+			result.erc20[fee.Token] -= fee.Amount
+		}
+	}
+	for _, token := range entry.Tokens {
+		result.erc20[token.Token] += sign * token.Amount
+	}
+	for _, item := range entry.Items {
+		if sign > 0 {
+			result.erc1155[item.Id] += item.Amount
+		} else if result.erc1155[item.Id] >= item.Amount {
+			result.erc1155[item.Id] -= item.Amount
+		}
+	}
+	for key, value := range result.erc20 {
+		if value == 0 {
+			delete(result.erc20, key)
+		}
+	}
+	for key, value := range result.erc1155 {
+		if value == 0 {
+			delete(result.erc1155, key)
+		}
+	}
+	return result
+}
+
+func (state balanceState) equal(other balanceState) bool {
+	if len(state.erc20) != len(other.erc20) || len(state.erc1155) != len(other.erc1155) {
+		return false
+	}
+	for key, value := range state.erc20 {
+		if other.erc20[key] != value {
+			return false
+		}
+	}
+	for key, value := range state.erc1155 {
+		if other.erc1155[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+type walletLogRecoveryDAO struct{}
+
+var recoveryDAO = &walletLogRecoveryDAO{}
+
+// resolveERC20WalletLog settles a log only if it is still Pending, reports whether this call did it.
+func (dao walletLogRecoveryDAO) resolveERC20WalletLog(db *gorm.DB, id uint, status WalletLogStatus, settledWallet Wallet, failure *WalletError) (bool, error) {
+	values := map[string]interface{}{
+		"status":         status.String(),
+		"settled_wallet": settledWallet,
+	}
+	if failure != nil {
+		values["failure_detail"] = failure
+	}
+	result := db.Model(&ERC20WalletLog{}).
+		Where("id = ? AND status = ?", id, Pending.String()).
+		Updates(values)
+	return result.RowsAffected == 1, result.Error
+}
+
+// resolveERC1155WalletLog settles a log only if it is still Pending, reports whether this call did it.
+func (dao walletLogRecoveryDAO) resolveERC1155WalletLog(db *gorm.DB, id uint, status WalletLogStatus, settledWallet Wallet, failure *WalletError) (bool, error) {
+	values := map[string]interface{}{
+		"status":         status.String(),
+		"settled_wallet": settledWallet,
+	}
+	if failure != nil {
+		values["failure_detail"] = failure
+	}
+	result := db.Model(&ERC1155WalletLog{}).
+		Where("id = ? AND status = ?", id, Pending.String()).
+		Updates(values)
+	return result.RowsAffected == 1, result.Error
+}
+
+// getNextOriginalWallet finds the original wallet of the earliest settled log of the same account after entry.
+func (dao walletLogRecoveryDAO) getNextOriginalWallet(db *gorm.DB, entry WalletLogEntry) (Wallet, bool, error) {
+	later := func(excludeId bool) *gorm.DB {
+		query := db.Select("id", "created_at", "original_wallet").
+			Where("account_id = ? AND created_at >= ? AND status = ?", entry.AccountId, entry.CreatedAt, Done.String())
+		if excludeId {
+			query = query.Where("id <> ?", entry.Id)
+		}
+		return query.Order("created_at, id").Limit(1)
+	}
+
+	var erc20Logs []ERC20WalletLog
+	if err := later(entry.AssetType == ERC20AssetType).Find(&erc20Logs).Error; err != nil {
+		return Wallet{}, false, err
+	}
+	var erc1155Logs []ERC1155WalletLog
+	if err := later(entry.AssetType == ERC1155AssetType).Find(&erc1155Logs).Error; err != nil {
+		return Wallet{}, false, err
+	}
+
+	switch {
+	case len(erc20Logs) == 1 && (len(erc1155Logs) == 0 || !erc1155Logs[0].CreatedAt.Before(erc20Logs[0].CreatedAt)):
+		return erc20Logs[0].OriginalWallet, true, nil
+	case len(erc1155Logs) == 1:
+		return erc1155Logs[0].OriginalWallet, true, nil
+	}
+	return Wallet{}, false, nil
+}
@@ -0,0 +1,409 @@
+package walleter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repository stores wallets, their token rows and the records written while handling a command:
+// wallet logs, balance history and outbox events. Commands only touch storage through it.
+// GetWallet returns gorm.ErrRecordNotFound for an unknown account, whatever the implementation.
+//
+// The features built around commands keep their records in the stores below, which a Repository
+// implements as well to support them: BalanceHistoryStore, ChainDepositStore, DepositAddressStore, AdminStore,
+// AdjustmentStore and WithdrawalHoldStore. A feature whose store is missing fails with ErrStoreNotSupported.
+// The repositories returned by NewGormRepository and NewMemoryRepository implement every store.
+type Repository interface {
+	// WithContext returns a Repository issuing its calls with ctx.
+	WithContext(ctx context.Context) Repository
+	// Context the context of the calls of this Repository.
+	Context() context.Context
+	// Transaction runs fn atomically, every change made through the Repository passed to fn
+	// is kept when fn returns nil and discarded otherwise.
+	Transaction(fn func(tx Repository) error) error
+
+	GetWallet(accountId uint64) (Wallet, error)
+	// ListWallets returns up to limit wallets of accounts above afterAccountId, in account order.
+	ListWallets(afterAccountId uint64, limit int) ([]Wallet, error)
+	// CreateWallet stores a new wallet with its token rows, a wallet already existing for the account is kept.
+	CreateWallet(wallet Wallet) error
+	UpdateERC20TokenWallet(tokenWallet ERC20TokenWallet) error
+	UpdateERC1155TokenWallet(tokenWallet ERC1155TokenWallet) error
+	UpdateWalletCheckSign(accountId uint64, checkSign string) error
+
+	InsertERC20WalletLog(log ERC20WalletLog) (ERC20WalletLog, error)
+	UpdateERC20WalletLog(log ERC20WalletLog) (ERC20WalletLog, error)
+	ListERC20WalletLogs(accountId uint64) ([]ERC20WalletLog, error)
+	GetERC20WalletLog(id uint) (ERC20WalletLog, error)
+	InsertERC1155WalletLog(log ERC1155WalletLog) (ERC1155WalletLog, error)
+	UpdateERC1155WalletLog(log ERC1155WalletLog) (ERC1155WalletLog, error)
+	ListERC1155WalletLogs(accountId uint64) ([]ERC1155WalletLog, error)
+	GetERC1155WalletLog(id uint) (ERC1155WalletLog, error)
+	// FindERC20WalletLogs returns up to limit logs matching the column filters of query, From and To included,
+	// which come after afterId in the order of query. Token filters are left to the caller.
+	FindERC20WalletLogs(query LogQuery, afterId uint, limit int) ([]ERC20WalletLog, error)
+	// FindERC1155WalletLogs is FindERC20WalletLogs on the ERC1155 logs.
+	FindERC1155WalletLogs(query LogQuery, afterId uint, limit int) ([]ERC1155WalletLog, error)
+
+	InsertERC20BalanceHistory(history ERC20BalanceHistory) error
+	InsertERC1155BalanceHistory(history ERC1155BalanceHistory) error
+	InsertOutboxEvent(event OutboxEvent) (OutboxEvent, error)
+}
+
+// BalanceHistoryStore reads the balance history written by commands, for point-in-time balances, statements
+// and reconciliation.
+type BalanceHistoryStore interface {
+	// GetLatestERC20BalanceHistories returns the last history row of each token of accountId recorded no later than at.
+	GetLatestERC20BalanceHistories(accountId uint64, at time.Time) ([]ERC20BalanceHistory, error)
+	// GetLatestERC1155BalanceHistories returns the last history row of each ERC1155 id of accountId recorded no later than at.
+	GetLatestERC1155BalanceHistories(accountId uint64, at time.Time) ([]ERC1155BalanceHistory, error)
+	// GetEarliestERC20BalanceHistories returns the first history row of each token of accountId recorded after at.
+	GetEarliestERC20BalanceHistories(accountId uint64, at time.Time) ([]ERC20BalanceHistory, error)
+	// GetEarliestERC1155BalanceHistories returns the first history row of each ERC1155 id of accountId recorded after at.
+	GetEarliestERC1155BalanceHistories(accountId uint64, at time.Time) ([]ERC1155BalanceHistory, error)
+	// ListERC20BalanceHistories returns the history rows of token of accountId recorded within [from, to], oldest first.
+	ListERC20BalanceHistories(accountId uint64, token string, from, to time.Time) ([]ERC20BalanceHistory, error)
+	// ListERC1155BalanceHistories returns the history rows of tokenId of accountId recorded within [from, to], oldest first.
+	ListERC1155BalanceHistories(accountId uint64, tokenId uint64, from, to time.Time) ([]ERC1155BalanceHistory, error)
+}
+
+// ChainDepositStore stores the ingested chain deposits and the flags raised on accounts by reverted ones.
+type ChainDepositStore interface {
+	// GetIngestedDeposit returns gorm.ErrRecordNotFound for a deposit which was not ingested.
+	GetIngestedDeposit(chain string, txHash string, logIndex uint) (IngestedDeposit, error)
+	// InsertIngestedDeposit fails with ErrDuplicateDeposit when a deposit with the same chain, tx hash and
+	// log index exists.
+	InsertIngestedDeposit(deposit IngestedDeposit) (IngestedDeposit, error)
+	// UpdateIngestedDeposit saves the status and block of deposit only if its status is still from, and reports
+	// whether it was.
+	UpdateIngestedDeposit(deposit IngestedDeposit, from DepositStatus) (bool, error)
+	// ListIngestedDeposits returns the deposits of accountId with one of statuses, oldest first. Every account
+	// is listed when accountId is 0, every status when statuses is empty.
+	ListIngestedDeposits(accountId uint64, statuses []DepositStatus) ([]IngestedDeposit, error)
+	InsertAccountFlag(flag AccountFlag) (AccountFlag, error)
+	// ListAccountFlags returns the flags of accountId, of every account when it is 0, oldest first.
+	ListAccountFlags(accountId uint64) ([]AccountFlag, error)
+}
+
+// DepositAddressStore stores the deposit addresses of accounts.
+type DepositAddressStore interface {
+	InsertDepositAddress(address DepositAddress) (DepositAddress, error)
+	// GetDepositAddress returns gorm.ErrRecordNotFound for an address which belongs to no account.
+	GetDepositAddress(chain string, address string) (DepositAddress, error)
+	// UpdateDepositAddress saves when address was assigned and retired.
+	UpdateDepositAddress(address DepositAddress) error
+	// ListDepositAddresses returns the addresses of accountId on chain in the order they were assigned.
+	ListDepositAddresses(accountId uint64, chain string) ([]DepositAddress, error)
+	// LockWallet locks the wallet of accountId until the transaction ends, so concurrent assignments of its
+	// addresses run one after the other. It returns gorm.ErrRecordNotFound for an unknown account.
+	LockWallet(accountId uint64) error
+}
+
+// AdminStore stores account freezes and the audit log of operator actions. Without it no account is frozen.
+type AdminStore interface {
+	// GetAccountFreeze returns gorm.ErrRecordNotFound for an account which is not frozen.
+	GetAccountFreeze(accountId uint64) (AccountFreeze, error)
+	CreateAccountFreeze(freeze AccountFreeze) (AccountFreeze, error)
+	DeleteAccountFreeze(accountId uint64) error
+	InsertAuditLog(log AuditLog) (AuditLog, error)
+	ListAuditLogs(accountId uint64) ([]AuditLog, error)
+}
+
+// AdjustmentStore stores the manual adjustments proposed by operators.
+type AdjustmentStore interface {
+	InsertAdjustmentRequest(request AdjustmentRequest) (AdjustmentRequest, error)
+	// GetAdjustmentRequest returns gorm.ErrRecordNotFound for an unknown request.
+	GetAdjustmentRequest(id uint) (AdjustmentRequest, error)
+	// ReviewAdjustmentRequest saves the review of request only if it is still pending, and reports whether it was.
+	ReviewAdjustmentRequest(request AdjustmentRequest) (bool, error)
+	// ListAdjustmentRequests returns the requests with status, every request when status is empty, oldest first.
+	ListAdjustmentRequests(status AdjustmentStatus) ([]AdjustmentRequest, error)
+}
+
+// WithdrawalHoldStore stores the withdrawals held for approval and their approvals.
+type WithdrawalHoldStore interface {
+	InsertWithdrawalHold(hold WithdrawalHold) (WithdrawalHold, error)
+	// GetWithdrawalHold returns gorm.ErrRecordNotFound for an unknown hold.
+	GetWithdrawalHold(id uint) (WithdrawalHold, error)
+	// AddWithdrawalApproval stores approval and counts it on its hold, returning the updated hold. It fails with
+	// ErrDuplicateApproval when the operator already approved the hold and ErrWithdrawalNotPending once it is resolved.
+	AddWithdrawalApproval(approval WithdrawalApproval) (WithdrawalHold, error)
+	// ResolveWithdrawalHold saves the resolution of hold only if it is still pending, and reports whether it was.
+	ResolveWithdrawalHold(hold WithdrawalHold) (bool, error)
+	// ListWithdrawalHolds returns the holds with status, every hold when status is empty, oldest first.
+	ListWithdrawalHolds(status WithdrawalHoldStatus) ([]WithdrawalHold, error)
+	// ListExpiredWithdrawalHolds returns the pending holds expiring at or before now.
+	ListExpiredWithdrawalHolds(now time.Time) ([]WithdrawalHold, error)
+	ListWithdrawalApprovals(holdId uint) ([]WithdrawalApproval, error)
+}
+
+// storeOf returns the store T of repo, failing with ErrStoreNotSupported when repo does not implement it.
+func storeOf[T any](repo Repository) (T, error) {
+	store, ok := repo.(T)
+	if !ok {
+		return store, fmt.Errorf("%w: %T is no %s", ErrStoreNotSupported, repo, reflect.TypeOf(&store).Elem().Name())
+	}
+	return store, nil
+}
+
+// the gorm and memory repositories implement every store.
+var (
+	_ interface {
+		Repository
+		BalanceHistoryStore
+		ChainDepositStore
+		DepositAddressStore
+		AdminStore
+		AdjustmentStore
+		WithdrawalHoldStore
+	} = (*gormRepository)(nil)
+	_ interface {
+		Repository
+		BalanceHistoryStore
+		ChainDepositStore
+		DepositAddressStore
+		AdminStore
+		AdjustmentStore
+		WithdrawalHoldStore
+	} = (*MemoryRepository)(nil)
+)
+
+// gormRepository the Repository on a gorm connection, used by New.
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewGormRepository returns the Repository storing into db. Calls join the transaction db belongs to, if any.
+func NewGormRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) WithContext(ctx context.Context) Repository {
+	return &gormRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *gormRepository) Context() context.Context {
+	return contextOf(r.db)
+}
+
+func (r *gormRepository) Transaction(fn func(tx Repository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormRepository{db: tx})
+	})
+}
+
+func (r *gormRepository) GetWallet(accountId uint64) (Wallet, error) {
+	return walletDAO.getWallet(r.db, accountId)
+}
+
+func (r *gormRepository) ListWallets(afterAccountId uint64, limit int) ([]Wallet, error) {
+	return walletDAO.listWallets(r.db, afterAccountId, limit)
+}
+
+func (r *gormRepository) CreateWallet(wallet Wallet) error {
+	return walletDAO.createWallet(r.db, wallet)
+}
+
+func (r *gormRepository) UpdateERC20TokenWallet(tokenWallet ERC20TokenWallet) error {
+	return walletDAO.updateERC20WalletData(r.db, tokenWallet)
+}
+
+func (r *gormRepository) UpdateERC1155TokenWallet(tokenWallet ERC1155TokenWallet) error {
+	return walletDAO.updateERC1155WalletData(r.db, tokenWallet)
+}
+
+func (r *gormRepository) UpdateWalletCheckSign(accountId uint64, checkSign string) error {
+	return walletDAO.updateWalletCheckSign(r.db, Wallet{AccountId: accountId, CheckSign: checkSign})
+}
+
+func (r *gormRepository) LockWallet(accountId uint64) error {
+	return walletDAO.lockWallet(r.db, accountId)
+}
+
+func (r *gormRepository) InsertERC20WalletLog(log ERC20WalletLog) (ERC20WalletLog, error) {
+	return erc20LogDAO.insertERC20WalletLog(r.db, log)
+}
+
+func (r *gormRepository) UpdateERC20WalletLog(log ERC20WalletLog) (ERC20WalletLog, error) {
+	return erc20LogDAO.updateERC20WalletLogStatus(r.db, log)
+}
+
+func (r *gormRepository) ListERC20WalletLogs(accountId uint64) (result []ERC20WalletLog, err error) {
+	err = r.db.Where("account_id = ?", accountId).Order("id").Find(&result).Error
+	return result, err
+}
+
+func (r *gormRepository) GetERC20WalletLog(id uint) (result ERC20WalletLog, err error) {
+	err = r.db.First(&result, id).Error
+	return result, err
+}
+
+func (r *gormRepository) InsertERC1155WalletLog(log ERC1155WalletLog) (ERC1155WalletLog, error) {
+	return erc1155LogDAO.insertERC1155WalletLog(r.db, log)
+}
+
+func (r *gormRepository) UpdateERC1155WalletLog(log ERC1155WalletLog) (ERC1155WalletLog, error) {
+	return erc1155LogDAO.updateERC1155WalletLogStatus(r.db, log)
+}
+
+func (r *gormRepository) ListERC1155WalletLogs(accountId uint64) (result []ERC1155WalletLog, err error) {
+	err = r.db.Where("account_id = ?", accountId).Order("id").Find(&result).Error
+	return result, err
+}
+
+func (r *gormRepository) GetERC1155WalletLog(id uint) (result ERC1155WalletLog, err error) {
+	err = r.db.First(&result, id).Error
+	return result, err
+}
+
+func (r *gormRepository) FindERC20WalletLogs(query LogQuery, afterId uint, limit int) ([]ERC20WalletLog, error) {
+	return logQueryDAO.findERC20Logs(r.db, query, afterId, limit)
+}
+
+func (r *gormRepository) FindERC1155WalletLogs(query LogQuery, afterId uint, limit int) ([]ERC1155WalletLog, error) {
+	return logQueryDAO.findERC1155Logs(r.db, query, afterId, limit)
+}
+
+func (r *gormRepository) InsertERC20BalanceHistory(history ERC20BalanceHistory) error {
+	return historyDAO.insertERC20History(r.db, history)
+}
+
+func (r *gormRepository) InsertERC1155BalanceHistory(history ERC1155BalanceHistory) error {
+	return historyDAO.insertERC1155History(r.db, history)
+}
+
+func (r *gormRepository) GetLatestERC20BalanceHistories(accountId uint64, at time.Time) ([]ERC20BalanceHistory, error) {
+	return historyDAO.getLatestERC20Histories(r.db, accountId, at)
+}
+
+func (r *gormRepository) GetLatestERC1155BalanceHistories(accountId uint64, at time.Time) ([]ERC1155BalanceHistory, error) {
+	return historyDAO.getLatestERC1155Histories(r.db, accountId, at)
+}
+
+func (r *gormRepository) GetEarliestERC20BalanceHistories(accountId uint64, at time.Time) ([]ERC20BalanceHistory, error) {
+	return historyDAO.getEarliestERC20Histories(r.db, accountId, at)
+}
+
+func (r *gormRepository) GetEarliestERC1155BalanceHistories(accountId uint64, at time.Time) ([]ERC1155BalanceHistory, error) {
+	return historyDAO.getEarliestERC1155Histories(r.db, accountId, at)
+}
+
+func (r *gormRepository) ListERC20BalanceHistories(accountId uint64, token string, from, to time.Time) ([]ERC20BalanceHistory, error) {
+	return historyDAO.getERC20Histories(r.db, accountId, token, from, to)
+}
+
+func (r *gormRepository) ListERC1155BalanceHistories(accountId uint64, tokenId uint64, from, to time.Time) ([]ERC1155BalanceHistory, error) {
+	return historyDAO.getERC1155Histories(r.db, accountId, tokenId, from, to)
+}
+
+func (r *gormRepository) InsertOutboxEvent(event OutboxEvent) (OutboxEvent, error) {
+	return outboxDAO.insertEvent(r.db, event)
+}
+
+func (r *gormRepository) GetIngestedDeposit(chain string, txHash string, logIndex uint) (IngestedDeposit, error) {
+	return depositDAO.getDeposit(r.db, chain, txHash, logIndex)
+}
+
+func (r *gormRepository) InsertIngestedDeposit(deposit IngestedDeposit) (IngestedDeposit, error) {
+	return depositDAO.insertDeposit(r.db, deposit)
+}
+
+func (r *gormRepository) UpdateIngestedDeposit(deposit IngestedDeposit, from DepositStatus) (bool, error) {
+	return depositDAO.updateDeposit(r.db, deposit, from)
+}
+
+func (r *gormRepository) ListIngestedDeposits(accountId uint64, statuses []DepositStatus) ([]IngestedDeposit, error) {
+	return depositDAO.listDeposits(r.db, accountId, statuses)
+}
+
+func (r *gormRepository) InsertDepositAddress(address DepositAddress) (DepositAddress, error) {
+	return addressDAO.insertAddress(r.db, address)
+}
+
+func (r *gormRepository) GetDepositAddress(chain string, address string) (DepositAddress, error) {
+	return addressDAO.getAddress(r.db, chain, address)
+}
+
+func (r *gormRepository) UpdateDepositAddress(address DepositAddress) error {
+	return addressDAO.updateAddress(r.db, address)
+}
+
+func (r *gormRepository) ListDepositAddresses(accountId uint64, chain string) ([]DepositAddress, error) {
+	return addressDAO.listAddresses(r.db, accountId, chain)
+}
+
+func (r *gormRepository) InsertAccountFlag(flag AccountFlag) (AccountFlag, error) {
+	return flagDAO.insertFlag(r.db, flag)
+}
+
+func (r *gormRepository) ListAccountFlags(accountId uint64) ([]AccountFlag, error) {
+	return flagDAO.listFlags(r.db, accountId)
+}
+
+func (r *gormRepository) GetAccountFreeze(accountId uint64) (AccountFreeze, error) {
+	return accountAdminDAO.getAccountFreeze(r.db, accountId)
+}
+
+func (r *gormRepository) CreateAccountFreeze(freeze AccountFreeze) (AccountFreeze, error) {
+	return accountAdminDAO.createAccountFreeze(r.db, freeze)
+}
+
+func (r *gormRepository) DeleteAccountFreeze(accountId uint64) error {
+	return accountAdminDAO.deleteAccountFreeze(r.db, accountId)
+}
+
+func (r *gormRepository) InsertAuditLog(log AuditLog) (AuditLog, error) {
+	return accountAdminDAO.insertAuditLog(r.db, log)
+}
+
+func (r *gormRepository) ListAuditLogs(accountId uint64) ([]AuditLog, error) {
+	return accountAdminDAO.listAuditLogs(r.db, accountId)
+}
+
+func (r *gormRepository) InsertAdjustmentRequest(request AdjustmentRequest) (AdjustmentRequest, error) {
+	return adjustmentDAO.insertRequest(r.db, request)
+}
+
+func (r *gormRepository) GetAdjustmentRequest(id uint) (AdjustmentRequest, error) {
+	return adjustmentDAO.getRequest(r.db, id)
+}
+
+func (r *gormRepository) ReviewAdjustmentRequest(request AdjustmentRequest) (bool, error) {
+	return adjustmentDAO.reviewRequest(r.db, request)
+}
+
+func (r *gormRepository) ListAdjustmentRequests(status AdjustmentStatus) ([]AdjustmentRequest, error) {
+	return adjustmentDAO.listRequests(r.db, status)
+}
+
+func (r *gormRepository) InsertWithdrawalHold(hold WithdrawalHold) (WithdrawalHold, error) {
+	return withdrawalDAO.insertHold(r.db, hold)
+}
+
+func (r *gormRepository) GetWithdrawalHold(id uint) (WithdrawalHold, error) {
+	return withdrawalDAO.getHold(r.db, id)
+}
+
+func (r *gormRepository) AddWithdrawalApproval(approval WithdrawalApproval) (WithdrawalHold, error) {
+	return withdrawalDAO.addApproval(r.db, approval)
+}
+
+func (r *gormRepository) ResolveWithdrawalHold(hold WithdrawalHold) (bool, error) {
+	return withdrawalDAO.resolveHold(r.db, hold)
+}
+
+func (r *gormRepository) ListWithdrawalHolds(status WithdrawalHoldStatus) ([]WithdrawalHold, error) {
+	return withdrawalDAO.listHolds(r.db, status)
+}
+
+func (r *gormRepository) ListExpiredWithdrawalHolds(now time.Time) ([]WithdrawalHold, error) {
+	return withdrawalDAO.listExpiredHolds(r.db, now)
+}
+
+func (r *gormRepository) ListWithdrawalApprovals(holdId uint) ([]WithdrawalApproval, error) {
+	return withdrawalDAO.listApprovals(r.db, holdId)
+}
@@ -0,0 +1,382 @@
+package walleter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StatementFormat output format of an account statement.
+type StatementFormat int
+
+const (
+	CSVStatement       StatementFormat = 0
+	JSONLinesStatement StatementFormat = 1
+)
+
+// StatementRecordKind the type of a row in an account statement.
+type StatementRecordKind string
+
+const (
+	OpeningBalanceRecord StatementRecordKind = "opening"
+	MovementRecord       StatementRecordKind = "movement"
+	ClosingBalanceRecord StatementRecordKind = "closing"
+)
+
+// StatementRecord one row of an account statement. A movement touching several tokens is split
+// into one record per token, Amount is signed and excludes the Fee charged in the same token.
+type StatementRecord struct {
+	Kind           StatementRecordKind `json:"kind"`
+	Time           time.Time           `json:"time"`
+	LogId          uint                `json:"log_id,omitempty"`
+	AssetType      string              `json:"asset_type"`
+	ActionType     string              `json:"action_type,omitempty"`
+	BusinessModule string              `json:"business_module,omitempty"`
+	Source         string              `json:"source,omitempty"`
+	Token          string              `json:"token"`
+	Amount         float64             `json:"amount"`
+	Fee            float64             `json:"fee"`
+	Balance        float64             `json:"balance"`
+}
+
+var statementCSVHeader = []string{
+	"kind", "time", "log_id", "asset_type", "action_type", "business_module", "source", "token", "amount", "fee", "balance",
+}
+
+// ExportStatement writes the statement of accountId for the period (from, to] into out: the balances at from,
+// the movements after from up to and including to, and the balances at to. Movements are read page by page,
+// so the whole history of large accounts is never held in memory. The fee charger account is rejected with
+// ErrStatementNotSupported: the fees credited to it have no logs of its own, so its movements would not add up
+// to its balances.
+func (s *Walleter) ExportStatement(out io.Writer, accountId uint64, from, to time.Time, format StatementFormat) error {
+	if accountId == feeChargerAccountId {
+		return fmt.Errorf("%w: account %d is the fee charger", ErrStatementNotSupported, accountId)
+	}
+	writer := newStatementWriter(out, format)
+
+	movements := &statementMovements{
+		walleter: s,
+		from:     from,
+		query: LogQuery{
+			AccountId: accountId,
+			Statuses:  []WalletLogStatus{Done},
+			From:      from,
+			To:        to,
+			Limit:     maxLogQueryLimit,
+			Ascending: true,
+		},
+	}
+	entries, err := movements.next()
+	if err != nil {
+		return err
+	}
+	opening, err := s.statementBalancesAt(accountId, from)
+	if err != nil {
+		return err
+	}
+	if err = writeBalanceRecords(writer, OpeningBalanceRecord, from, opening); err != nil {
+		return err
+	}
+
+	erc20Balances := opening.ERC20Balances
+	erc1155Balances := opening.ERC1155Balances
+	for len(entries) > 0 {
+		for _, entry := range entries {
+			for _, record := range movementRecords(entry, erc20Balances, erc1155Balances) {
+				if err = writer.write(record); err != nil {
+					return err
+				}
+			}
+		}
+		if entries, err = movements.next(); err != nil {
+			return err
+		}
+	}
+
+	closing, err := s.statementBalancesAt(accountId, to)
+	if err != nil {
+		return err
+	}
+	if err = writeBalanceRecords(writer, ClosingBalanceRecord, to, closing); err != nil {
+		return err
+	}
+	return writer.flush()
+}
+
+// statementBalancesAt the balances of accountId at the given moment as a statement reports them: those the first
+// movement after it started from. The balance history of a change is recorded after its log, so it cannot tell
+// the movements right at a period boundary apart, it is only used when no movement follows.
+func (s *Walleter) statementBalancesAt(accountId uint64, at time.Time) (WalletSnapshot, error) {
+	following := &statementMovements{
+		walleter: s,
+		from:     at,
+		query: LogQuery{
+			AccountId: accountId,
+			Statuses:  []WalletLogStatus{Done},
+			From:      at,
+			Limit:     defaultLogQueryLimit,
+			Ascending: true,
+		},
+	}
+	entries, err := following.next()
+	if err != nil {
+		return WalletSnapshot{}, err
+	}
+	if len(entries) > 0 {
+		var original Wallet
+		if entries[0].AssetType == ERC20AssetType {
+			log, err := s.repo.GetERC20WalletLog(entries[0].Id)
+			if err != nil {
+				return WalletSnapshot{}, err
+			}
+			original = log.OriginalWallet
+		} else {
+			log, err := s.repo.GetERC1155WalletLog(entries[0].Id)
+			if err != nil {
+				return WalletSnapshot{}, err
+			}
+			original = log.OriginalWallet
+		}
+		return walletSnapshot(original, at), nil
+	}
+
+	snapshot, err := s.GetWalletAt(accountId, at)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return walletSnapshot(Wallet{AccountId: accountId}, at), nil
+	}
+	return snapshot, err
+}
+
+// walletSnapshot the balances of w as a snapshot at the given moment, a zero wallet has none.
+func walletSnapshot(w Wallet, at time.Time) WalletSnapshot {
+	snapshot := WalletSnapshot{
+		AccountId:       w.AccountId,
+		At:              at,
+		ERC20Balances:   map[string]float64{},
+		ERC1155Balances: map[uint64]uint64{},
+	}
+	for _, token := range w.ERC20TokenData {
+		snapshot.ERC20Balances[token.Token] = token.Balance
+	}
+	for _, item := range w.ERC1155TokenData.Amounts() {
+		if item.Amount > 0 {
+			snapshot.ERC1155Balances[item.Id] = item.Amount
+		}
+	}
+	return snapshot
+}
+
+// statementMovements reads the Done logs of a statement period page by page.
+type statementMovements struct {
+	walleter *Walleter
+	from     time.Time
+	query    LogQuery
+	done     bool
+}
+
+// next returns the logs of the next page created after from, nil once every page was read.
+func (m *statementMovements) next() ([]WalletLogEntry, error) {
+	for !m.done {
+		page, err := m.walleter.ListWalletLogs(m.query)
+		if err != nil {
+			return nil, err
+		}
+		m.query.Cursor = page.NextCursor
+		m.done = page.NextCursor == ""
+
+		var result []WalletLogEntry
+		for _, entry := range page.Entries {
+			if entry.CreatedAt.After(m.from) {
+				result = append(result, entry)
+			}
+		}
+		if len(result) > 0 {
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// movementRecords converts a log entry into statement records and applies it to the running balances.
+func movementRecords(entry WalletLogEntry, erc20Balances map[string]float64, erc1155Balances map[uint64]uint64) []StatementRecord {
+	base := StatementRecord{
+		Kind:           MovementRecord,
+		Time:           entry.CreatedAt,
+		LogId:          entry.Id,
+		ActionType:     entry.ActionType,
+		BusinessModule: entry.BusinessModule,
+		Source:         entry.Source,
+	}
+	sign := movementSign(entry.ActionType)
+
+	var result []StatementRecord
+	feeCharged := map[string]bool{}
+	for _, token := range entry.Tokens {
+		record := base
+		record.AssetType = AssetType(ERC20AssetType).String()
+		record.Token = token.Token
+		record.Amount = sign * token.Amount
+		for _, fee := range entry.Fees {
+			if fee.Token == token.Token {
+				record.Fee += fee.Amount
+			}
+		}
+		feeCharged[token.Token] = true
+		erc20Balances[token.Token] += record.Amount - record.Fee
+		record.Balance = erc20Balances[token.Token]
+		result = append(result, record)
+	}
+	for _, fee := range entry.Fees {
+		if feeCharged[fee.Token] {
+			continue
+		}
+		record := base
+		record.AssetType = AssetType(ERC20AssetType).String()
+		record.Token = fee.Token
+		record.Fee = fee.Amount
+		erc20Balances[fee.Token] -= fee.Amount
+		record.Balance = erc20Balances[fee.Token]
+		result = append(result, record)
+	}
+	for _, item := range entry.Items {
+		record := base
+		record.AssetType = AssetType(ERC1155AssetType).String()
+		record.Token = strconv.FormatUint(item.Id, 10)
+		record.Amount = sign * float64(item.Amount)
+		if sign > 0 {
+			erc1155Balances[item.Id] += item.Amount
+		} else if erc1155Balances[item.Id] >= item.Amount {
+			erc1155Balances[item.Id] -= item.Amount
+		} else {
+			erc1155Balances[item.Id] = 0
+		}
+		record.Balance = float64(erc1155Balances[item.Id])
+		result = append(result, record)
+	}
+	return result
+}
+
+// movementSign whether an action adds to (1) or subtracts from (-1) the wallet.
+func movementSign(actionType string) float64 {
+	switch actionType {
+	case Withdraw.String(), Spend.String(), ChargeFee.String(), RevertDeposit.String(), Deduct.String():
+		return -1
+	case Initialize.String():
+		return 0
+	}
+	return 1
+}
+
+func writeBalanceRecords(writer statementWriter, kind StatementRecordKind, at time.Time, snapshot WalletSnapshot) error {
+	var tokens []string
+	for token := range snapshot.ERC20Balances {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	for _, token := range tokens {
+		err := writer.write(StatementRecord{
+			Kind:      kind,
+			Time:      at,
+			AssetType: AssetType(ERC20AssetType).String(),
+			Token:     token,
+			Balance:   snapshot.ERC20Balances[token],
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var ids []uint64
+	for id := range snapshot.ERC1155Balances {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		err := writer.write(StatementRecord{
+			Kind:      kind,
+			Time:      at,
+			AssetType: AssetType(ERC1155AssetType).String(),
+			Token:     strconv.FormatUint(id, 10),
+			Balance:   float64(snapshot.ERC1155Balances[id]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type statementWriter interface {
+	write(record StatementRecord) error
+	flush() error
+}
+
+func newStatementWriter(out io.Writer, format StatementFormat) statementWriter {
+	if format == JSONLinesStatement {
+		return &jsonLinesStatementWriter{encoder: json.NewEncoder(out)}
+	}
+	return &csvStatementWriter{writer: csv.NewWriter(out)}
+}
+
+type csvStatementWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (receiver *csvStatementWriter) writeHeader() error {
+	if receiver.headerWritten {
+		return nil
+	}
+	receiver.headerWritten = true
+	return receiver.writer.Write(statementCSVHeader)
+}
+
+func (receiver *csvStatementWriter) write(record StatementRecord) error {
+	if err := receiver.writeHeader(); err != nil {
+		return err
+	}
+	logId := ""
+	if record.LogId != 0 {
+		logId = strconv.FormatUint(uint64(record.LogId), 10)
+	}
+	return receiver.writer.Write([]string{
+		string(record.Kind),
+		record.Time.Format(time.RFC3339Nano),
+		logId,
+		record.AssetType,
+		record.ActionType,
+		record.BusinessModule,
+		record.Source,
+		record.Token,
+		strconv.FormatFloat(record.Amount, 'f', -1, 64),
+		strconv.FormatFloat(record.Fee, 'f', -1, 64),
+		strconv.FormatFloat(record.Balance, 'f', -1, 64),
+	})
+}
+
+func (receiver *csvStatementWriter) flush() error {
+	if err := receiver.writeHeader(); err != nil {
+		return err
+	}
+	receiver.writer.Flush()
+	return receiver.writer.Error()
+}
+
+type jsonLinesStatementWriter struct {
+	encoder *json.Encoder
+}
+
+func (receiver *jsonLinesStatementWriter) write(record StatementRecord) error {
+	return receiver.encoder.Encode(record)
+}
+
+func (receiver *jsonLinesStatementWriter) flush() error {
+	return nil
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+func TestFreezeAccount(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	income := walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil)
+
+	if _, err := w.FreezeAccount(ctx, accountId, "alice", "suspected fraud"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ExecuteCommand(ctx, income); !errors.Is(err, walleter.ErrAccountFrozen) {
+		t.Fatalf("command on a frozen account returned %v", err)
+	}
+	// freezing again keeps the first freeze
+	freeze, err := w.FreezeAccount(ctx, accountId, "bob", "again")
+	if err != nil || freeze.Operator != "alice" {
+		t.Fatalf("second freeze returned %+v, %v", freeze, err)
+	}
+
+	if err := w.UnfreezeAccount(ctx, accountId, "alice", "cleared"); err != nil {
+		t.Fatal(err)
+	}
+	handleCommand(t, db, w, income)
+	if err := w.UnfreezeAccount(ctx, accountId, "alice", "cleared"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("unfreezing an active account returned %v", err)
+	}
+
+	logs, err := w.ListAuditLogs(accountId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].Action != walleter.AuditFreeze || logs[1].Action != walleter.AuditUnfreeze {
+		t.Fatalf("audit logs %+v", logs)
+	}
+}
+
+func TestAdjustWallet(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
+	if _, err := w.FreezeAccount(ctx, accountId, "alice", "investigation"); err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := w.ProposeAdjustment(ctx, walleter.Adjustment{
+		AccountId: accountId,
+		Operator:  "alice",
+		Reason:    "refund of ticket 42",
+		Tokens:    map[walleter.ERC20TokenEnum]float64{walleter.BUSD: -4, walleter.FISHX: 25},
+		Items:     map[uint64]int64{10001: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Status != walleter.AdjustmentPending || erc20Balance(getWallet(t, w, accountId), walleter.FISHX) != 0 {
+		t.Fatalf("proposal applied before approval: %+v", request)
+	}
+	if _, err := w.ApproveAdjustment(ctx, request.ID, "Alice", ""); !errors.Is(err, walleter.ErrSelfApproval) {
+		t.Fatalf("self approval returned %v", err)
+	}
+
+	// approved adjustments apply to frozen accounts
+	wallet, err := w.ApproveAdjustment(ctx, request.ID, "bob", "checked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if erc20Balance(wallet, walleter.BUSD) != 6 || erc20Balance(wallet, walleter.FISHX) != 25 || wallet.ERC1155TokenData.Ids != "10001" {
+		t.Fatalf("adjusted wallet %+v", wallet)
+	}
+	if _, err := w.ApproveAdjustment(ctx, request.ID, "carol", ""); !errors.Is(err, walleter.ErrAdjustmentNotPending) {
+		t.Fatalf("second approval returned %v", err)
+	}
+	page, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, BusinessModule: walleter.AdjustmentBusinessModule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 3 {
+		t.Fatalf("adjustment logs %+v", page.Entries)
+	}
+	// debits are deducted as spent, they are no withdrawals
+	for _, token := range wallet.ERC20TokenData {
+		if token.Token == walleter.BUSD.String() && (token.TotalSpend != 4 || token.TotalWithdraw != 0 || token.TotalFee != 0) {
+			t.Fatalf("totals of the debited token %+v", token)
+		}
+	}
+	if deducted, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId,
+		ActionTypes: []walleter.WalletActionType{walleter.Deduct}}); err != nil || len(deducted.Entries) != 1 {
+		t.Fatalf("deduction logs %+v, %v", deducted, err)
+	}
+	if report, err := w.Reconcile([]uint64{accountId}); err != nil || len(report.Issues) != 0 {
+		t.Fatalf("reconciliation after the adjustment %+v, %v", report, err)
+	}
+	for _, entry := range page.Entries {
+		approval := entry.Approval
+		if approval == nil || approval.RequestId != request.ID || approval.ProposedBy != "alice" ||
+			approval.ApprovedBy != "bob" || approval.Reason != "refund of ticket 42" {
+			t.Fatalf("log approval %+v", approval)
+		}
+	}
+
+	// rejected adjustments never apply
+	rejected, err := w.ProposeAdjustment(ctx, walleter.Adjustment{AccountId: accountId, Operator: "alice", Reason: "typo",
+		Tokens: map[walleter.ERC20TokenEnum]float64{walleter.FISHX: 1000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.RejectAdjustment(ctx, rejected.ID, "bob", ""); !errors.Is(err, walleter.ErrInvalidOperatorAction) {
+		t.Fatalf("rejection without note returned %v", err)
+	}
+	if _, err := w.RejectAdjustment(ctx, rejected.ID, "bob", "wrong amount"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ApproveAdjustment(ctx, rejected.ID, "carol", ""); !errors.Is(err, walleter.ErrAdjustmentNotPending) {
+		t.Fatalf("approval of a rejected request returned %v", err)
+	}
+
+	// a debit beyond the balance fails the whole adjustment and leaves it pending
+	overdraft, err := w.ProposeAdjustment(ctx, walleter.Adjustment{AccountId: accountId, Operator: "alice", Reason: "correction",
+		Tokens: map[walleter.ERC20TokenEnum]float64{walleter.FISHX: 5, walleter.BUSD: -100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ApproveAdjustment(ctx, overdraft.ID, "bob", ""); !errors.Is(err, walleter.ErrNoEnoughERC20Balance) {
+		t.Fatalf("debit beyond the balance returned %v", err)
+	}
+	if erc20Balance(getWallet(t, w, accountId), walleter.FISHX) != 25 {
+		t.Fatal("failed adjustment was partially applied")
+	}
+	if pending, err := w.ListAdjustmentRequests(walleter.AdjustmentPending); err != nil || len(pending) != 1 || pending[0].ID != overdraft.ID {
+		t.Fatalf("pending requests %+v, %v", pending, err)
+	}
+
+	if _, err := w.ProposeAdjustment(ctx, walleter.Adjustment{AccountId: accountId, Operator: "alice",
+		Tokens: map[walleter.ERC20TokenEnum]float64{walleter.FISHX: 5}}); !errors.Is(err, walleter.ErrInvalidOperatorAction) {
+		t.Fatalf("adjustment without reason returned %v", err)
+	}
+	if err := w.UnfreezeAccount(ctx, accountId, "alice", "cleared"); err != nil {
+		t.Fatal(err)
+	}
+	direct := walleter.NewERC20WalletCommand(accountId, walleter.Income, walleter.AdjustmentBusinessModule, walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.FISHX: 5}, nil)
+	if _, err := w.ExecuteCommand(ctx, direct); !errors.Is(err, walleter.ErrApprovalRequired) {
+		t.Fatalf("unapproved adjustment command returned %v", err)
+	}
+	// neither a made up approval nor a deduction passes outside of ApproveAdjustment
+	forged := walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.FISHX: 5}, nil)
+	forged.Approval = &walleter.CommandApproval{RequestId: request.ID, ProposedBy: "alice", ApprovedBy: "bob"}
+	if _, err := w.ExecuteCommand(ctx, forged); !errors.Is(err, walleter.ErrApprovalRequired) {
+		t.Fatalf("command with a made up approval returned %v", err)
+	}
+	deduct := walleter.NewERC20WalletCommand(accountId, walleter.Deduct, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.FISHX: 5}, nil)
+	if _, err := w.ExecuteCommand(ctx, deduct); !errors.Is(err, walleter.ErrApprovalRequired) {
+		t.Fatalf("deduction returned %v", err)
+	}
+	if erc20Balance(getWallet(t, w, accountId), walleter.FISHX) != 25 {
+		t.Fatal("rejected commands changed the wallet")
+	}
+
+	logs, err := w.ListAuditLogs(accountId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []walleter.AuditAction
+	for _, log := range logs {
+		actions = append(actions, log.Action)
+	}
+	expected := []walleter.AuditAction{walleter.AuditFreeze, walleter.AuditProposeAdjustment, walleter.AuditApproveAdjustment,
+		walleter.AuditProposeAdjustment, walleter.AuditRejectAdjustment, walleter.AuditProposeAdjustment, walleter.AuditUnfreeze}
+	if fmt.Sprint(actions) != fmt.Sprint(expected) || logs[2].Operator != "bob" || logs[2].Detail.Tokens["BUSD"] != -4 || logs[2].Detail.Items[10001] != 3 {
+		t.Fatalf("audit logs %+v", logs)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		[]uint64{10001}, []uint64{2}, nil))
+
+	report, err := w.Reconcile([]uint64{accountId, testFeeChargerId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Wallets != 2 || len(report.Issues) != 0 {
+		t.Fatalf("consistent wallets reported %+v", report)
+	}
+
+	// a balance changed behind the back of walleter
+	err = db.Model(&walleter.ERC20TokenWallet{}).
+		Where("account_id = ? AND token = ?", accountId, walleter.BUSD.String()).
+		Update("balance", 1000).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = w.Reconcile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[walleter.ReconciliationIssueKind]bool{}
+	for _, issue := range report.Issues {
+		if issue.AccountId != accountId {
+			t.Fatalf("issue of an untouched wallet %+v", issue)
+		}
+		kinds[issue.Kind] = true
+	}
+	if !kinds[walleter.IssueCheckSign] || !kinds[walleter.IssueTotals] || !kinds[walleter.IssueBalanceHistory] {
+		t.Fatalf("tampered wallet reported %+v", report.Issues)
+	}
+}
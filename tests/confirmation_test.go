@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nami-land/walleter"
+)
+
+func TestDepositConfirmations(t *testing.T) {
+	db, _, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	w := walleter.New(db, testFeeChargerId, walleter.WithDepositAddressResolver(testDepositAddresses(accountId)),
+		walleter.WithConfirmations(map[walleter.CommandSourceType]walleter.ConfirmationPolicy{
+			walleter.BSC: {Confirmations: 3, Finality: 6},
+		}))
+	feed := walleter.NewMemoryBlockFeed()
+	feed.SetBlock(walleter.BSC, 100, testBlockHash("a1"))
+
+	deposit := walleter.ChainDeposit{Chain: walleter.BSC, TxHash: "0x" + strings.Repeat("ab", 32), BlockNumber: 100,
+		BlockHash: testBlockHash("a1"), Address: testDepositAddress, AssetType: walleter.ERC20AssetType, Token: walleter.BUSD, Amount: 25}
+	result, err := w.IngestDeposit(ctx, deposit)
+	if err != nil || result.Deposit.Status != walleter.DepositPending || erc20Balance(result.Wallet, walleter.BUSD) != 0 {
+		t.Fatalf("ingested %+v, %v", result, err)
+	}
+	unlocated := deposit
+	unlocated.LogIndex, unlocated.BlockHash = 1, ""
+	if _, err := w.IngestDeposit(ctx, unlocated); !errors.Is(err, walleter.ErrInvalidDeposit) {
+		t.Fatalf("deposit without its block returned %v", err)
+	}
+
+	report, err := w.ProcessConfirmations(ctx, feed)
+	if err != nil || report != (walleter.ConfirmationReport{}) {
+		t.Fatalf("first confirmation %+v, %v", report, err)
+	}
+	feed.SetHead(walleter.BSC, 102)
+	report, err = w.ProcessConfirmations(ctx, feed)
+	if err != nil || report != (walleter.ConfirmationReport{Credited: 1}) {
+		t.Fatalf("third confirmation %+v, %v", report, err)
+	}
+	if balance := erc20Balance(getWallet(t, w, accountId), walleter.BUSD); balance != 25 {
+		t.Fatalf("balance after crediting %v", balance)
+	}
+	feed.SetHead(walleter.BSC, 105)
+	report, err = w.ProcessConfirmations(ctx, feed)
+	if err != nil || report != (walleter.ConfirmationReport{Finalized: 1}) {
+		t.Fatalf("sixth confirmation %+v, %v", report, err)
+	}
+	deposits, err := w.ListIngestedDeposits(accountId, walleter.DepositFinal)
+	if err != nil || len(deposits) != 1 || deposits[0].BlockNumber != 100 {
+		t.Fatalf("final deposits %+v, %v", deposits, err)
+	}
+
+	// chains without a policy are credited right away
+	other := walleter.ChainDeposit{Chain: walleter.Ethereum, TxHash: "0x" + strings.Repeat("cd", 32), Address: testDepositAddress,
+		AssetType: walleter.ERC20AssetType, Token: walleter.USDT, Amount: 7}
+	result, err = w.IngestDeposit(ctx, other)
+	if err != nil || result.Deposit.Status != walleter.DepositFinal || erc20Balance(result.Wallet, walleter.USDT) != 7 {
+		t.Fatalf("ingested %+v, %v", result, err)
+	}
+}
+
+func TestDepositReorganization(t *testing.T) {
+	db, _, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	w := walleter.New(db, testFeeChargerId, walleter.WithDepositAddressResolver(testDepositAddresses(accountId)),
+		walleter.WithConfirmations(map[walleter.CommandSourceType]walleter.ConfirmationPolicy{
+			walleter.BSC: {Confirmations: 2, Finality: 10},
+		}))
+	feed := walleter.NewMemoryBlockFeed()
+	feed.SetBlock(walleter.BSC, 200, testBlockHash("a1"))
+	feed.SetBlock(walleter.BSC, 201, testBlockHash("a2"))
+
+	tokens := walleter.ChainDeposit{Chain: walleter.BSC, TxHash: "0x" + strings.Repeat("ef", 32), BlockNumber: 200,
+		BlockHash: testBlockHash("a1"), Address: testDepositAddress, AssetType: walleter.ERC20AssetType, Token: walleter.BUSD, Amount: 100}
+	items := tokens
+	items.LogIndex, items.AssetType, items.Ids, items.Values = 1, walleter.ERC1155AssetType, []uint64{10001}, []uint64{3}
+	late := tokens
+	late.TxHash, late.BlockNumber, late.BlockHash, late.Amount = "0x"+strings.Repeat("12", 32), 201, testBlockHash("a2"), 5
+	for _, deposit := range []walleter.ChainDeposit{tokens, items, late} {
+		if _, err := w.IngestDeposit(ctx, deposit); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if report, err := w.ProcessConfirmations(ctx, feed); err != nil || report.Credited != 2 {
+		t.Fatalf("confirmation %+v, %v", report, err)
+	}
+	// the deposits are spent before the reorganization
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Spend, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 60}, nil))
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(accountId, walleter.Spend, "Testing", walleter.InGame,
+		[]uint64{10001}, []uint64{1}, nil))
+
+	feed.SetHead(walleter.BSC, 199)
+	feed.SetBlock(walleter.BSC, 200, testBlockHash("b1"))
+	feed.SetBlock(walleter.BSC, 201, testBlockHash("b2"))
+	report, err := w.ProcessConfirmations(ctx, feed)
+	if err != nil || report != (walleter.ConfirmationReport{Dropped: 1, Reverted: 2}) {
+		t.Fatalf("reorganization %+v, %v", report, err)
+	}
+	wallet := getWallet(t, w, accountId)
+	if erc20Balance(wallet, walleter.BUSD) != -60 || wallet.ERC1155TokenData.Values != "0" {
+		t.Fatalf("reverted wallet %+v", wallet)
+	}
+	flags, err := w.ListAccountFlags(accountId)
+	if err != nil || len(flags) != 2 || flags[0].Shortfall.Tokens["BUSD"] != 60 || flags[1].Shortfall.Items[10001] != 1 {
+		t.Fatalf("account flags %+v, %v", flags, err)
+	}
+	page, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, ActionTypes: []walleter.WalletActionType{walleter.RevertDeposit}})
+	if err != nil || len(page.Entries) != 2 || page.Entries[0].ChainRef == nil {
+		t.Fatalf("revert logs %+v, %v", page.Entries, err)
+	}
+	reconciliation, err := w.Reconcile([]uint64{accountId})
+	if err != nil || len(reconciliation.Issues) != 0 {
+		t.Fatalf("reconciliation %+v, %v", reconciliation, err)
+	}
+
+	// the dropped transfer is mined again in another block
+	late.BlockHash = testBlockHash("b2")
+	result, err := w.IngestDeposit(ctx, late)
+	if err != nil || result.Duplicate || result.Deposit.Status != walleter.DepositPending {
+		t.Fatalf("reincluded %+v, %v", result, err)
+	}
+	feed.SetBlock(walleter.BSC, 202, testBlockHash("b3"))
+	if report, err := w.ProcessConfirmations(ctx, feed); err != nil || report.Credited != 1 {
+		t.Fatalf("confirmation of the reincluded deposit %+v, %v", report, err)
+	}
+
+	// reversals are only issued by the confirmation of deposits
+	_, err = w.ExecuteCommand(ctx, walleter.NewERC20WalletCommand(accountId, walleter.RevertDeposit, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1}, nil))
+	if !errors.Is(err, walleter.ErrActionTypeNotSupport) {
+		t.Fatalf("revert command returned %v", err)
+	}
+}
+
+// testBlockHash returns a block hash repeating the hex byte b.
+func testBlockHash(b string) string {
+	return "0x" + strings.Repeat(b, 32)
+}
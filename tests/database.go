@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os"
+
+	"gorm.io/gorm"
+	"tests/internal/testdb"
+)
+
+// openDatabase connects to the database named by WALLETER_TEST_DIALECT (sqlite, mysql or postgres)
+// and WALLETER_TEST_DSN. Without them every call opens a new in-memory SQLite database, so no server is needed.
+func openDatabase() (*gorm.DB, error) {
+	return testdb.Open(os.Getenv("WALLETER_TEST_DIALECT"), os.Getenv("WALLETER_TEST_DSN"))
+}
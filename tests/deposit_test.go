@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nami-land/walleter"
+)
+
+const testDepositAddress = "0x52908400098527886E0F7030069857D2E4169EE7"
+
+func TestIngestDeposit(t *testing.T) {
+	db, _, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	w := walleter.New(db, testFeeChargerId, walleter.WithDepositAddressResolver(testDepositAddresses(accountId)))
+
+	deposit := walleter.ChainDeposit{
+		Chain:     walleter.BSC,
+		TxHash:    "0x" + strings.Repeat("ab", 32),
+		LogIndex:  3,
+		Address:   testDepositAddress,
+		AssetType: walleter.ERC20AssetType,
+		Token:     walleter.BUSD,
+		Amount:    25,
+	}
+	result, err := w.IngestDeposit(ctx, deposit)
+	if err != nil || result.Duplicate || erc20Balance(result.Wallet, walleter.BUSD) != 25 {
+		t.Fatalf("ingested %+v, %v", result, err)
+	}
+
+	// replays of the indexer are credited once, whatever the case of the hash
+	replay := deposit
+	replay.TxHash = "0x" + strings.ToUpper(deposit.TxHash[2:])
+	result, err = w.IngestDeposit(ctx, replay)
+	if err != nil || !result.Duplicate || erc20Balance(result.Wallet, walleter.BUSD) != 25 {
+		t.Fatalf("replay %+v, %v", result, err)
+	}
+	conflict := deposit
+	conflict.Amount = 2500
+	if _, err := w.IngestDeposit(ctx, conflict); !errors.Is(err, walleter.ErrInvalidDeposit) {
+		t.Fatalf("conflicting replay returned %v", err)
+	}
+	// the same transaction may hold several transfers
+	other := deposit
+	other.LogIndex = 4
+	if _, err := w.IngestDeposit(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	items := walleter.ChainDeposit{Chain: walleter.Ethereum, TxHash: "0x" + strings.Repeat("cd", 32), Address: testDepositAddress,
+		AssetType: walleter.ERC1155AssetType, Ids: []uint64{10001}, Values: []uint64{2}}
+	if result, err = w.IngestDeposit(ctx, items); err != nil || result.Wallet.ERC1155TokenData.Values != "2" {
+		t.Fatalf("ingested items %+v, %v", result, err)
+	}
+
+	unknown := deposit
+	unknown.Address = "0x" + strings.Repeat("11", 20)
+	if _, err := w.IngestDeposit(ctx, unknown); !errors.Is(err, walleter.ErrUnknownDepositAddress) {
+		t.Fatalf("deposit to an unknown address returned %v", err)
+	}
+	malformed := deposit
+	malformed.TxHash = "0x1234"
+	if _, err := w.IngestDeposit(ctx, malformed); !errors.Is(err, walleter.ErrInvalidDeposit) {
+		t.Fatalf("malformed deposit returned %v", err)
+	}
+
+	page, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, BusinessModule: walleter.ChainDepositBusinessModule,
+		AssetTypes: []walleter.AssetType{walleter.ERC20AssetType}, Ascending: true})
+	if err != nil || len(page.Entries) != 2 {
+		t.Fatalf("deposit logs %+v, %v", page.Entries, err)
+	}
+	ref := page.Entries[0].ChainRef
+	if ref == nil || ref.Chain != "bsc" || ref.TxHash != strings.ToLower(deposit.TxHash) || ref.LogIndex != 3 ||
+		ref.Address != strings.ToLower(testDepositAddress) {
+		t.Fatalf("chain reference %+v", ref)
+	}
+}
+
+func TestRunDepositIngestion(t *testing.T) {
+	db, _, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	w := walleter.New(db, testFeeChargerId, walleter.WithDepositAddressResolver(testDepositAddresses(accountId)))
+	if _, err := w.FreezeAccount(ctx, accountId, "alice", "investigation"); err != nil {
+		t.Fatal(err)
+	}
+
+	deposit := walleter.ChainDeposit{Chain: walleter.Ethereum, TxHash: "0x" + strings.Repeat("ef", 32), Address: testDepositAddress,
+		AssetType: walleter.ERC20AssetType, Token: walleter.USDT, Amount: 40}
+	unknown := deposit
+	unknown.LogIndex = 1
+	unknown.Address = "0x" + strings.Repeat("22", 20)
+	source := walleter.NewMemoryDepositSource()
+	source.Publish(deposit, unknown)
+	// the indexer replays the block
+	source.Publish(deposit)
+	source.Close()
+
+	if err := w.RunDepositIngestion(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+	// deposits are credited to frozen accounts as well
+	if balance := erc20Balance(getWallet(t, w, accountId), walleter.USDT); balance != 40 {
+		t.Fatalf("balance after ingestion %v", balance)
+	}
+}
+
+// testDepositAddresses resolves testDepositAddress, in any case, to accountId.
+func testDepositAddresses(accountId uint64) walleter.DepositAddressResolver {
+	return walleter.DepositAddressResolverFunc(func(ctx context.Context, chain walleter.CommandSourceType, address string) (uint64, error) {
+		if strings.EqualFold(address, testDepositAddress) {
+			return accountId, nil
+		}
+		return 0, walleter.ErrUnknownDepositAddress
+	})
+}
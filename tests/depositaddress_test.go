@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+func TestDepositAddressRegistry(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	otherId := newTestAccountId()
+	handleCommand(t, db, w, walleter.NewInitWalletCommand(otherId))
+
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	address, err := w.AssignDepositAddress(ctx, accountId, walleter.BSC, checksummed)
+	if err != nil || address.Address != checksummed || address.RetiredAt != nil {
+		t.Fatalf("assigned %+v, %v", address, err)
+	}
+	if _, err := w.AssignDepositAddress(ctx, accountId, walleter.BSC, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"); !errors.Is(err, walleter.ErrInvalidDepositAddress) {
+		t.Fatalf("assigning a wrong checksum returned %v", err)
+	}
+	// addresses in a single case carry no checksum
+	address, err = w.AssignDepositAddress(ctx, otherId, walleter.BSC, "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359")
+	if err != nil || address.Address != "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359" {
+		t.Fatalf("assigned %+v, %v", address, err)
+	}
+	if _, err := w.AssignDepositAddress(ctx, otherId, walleter.BSC, strings.ToLower(checksummed)); !errors.Is(err, walleter.ErrDepositAddressTaken) {
+		t.Fatalf("assigning the address of another account returned %v", err)
+	}
+
+	// rotation keeps the retired address of the account
+	if _, err := w.AssignDepositAddress(ctx, accountId, walleter.BSC, testDepositAddress); err != nil {
+		t.Fatal(err)
+	}
+	history, err := w.ListDepositAddresses(accountId, walleter.BSC)
+	if err != nil || len(history) != 2 || history[0].RetiredAt == nil || history[1].Address != testDepositAddress {
+		t.Fatalf("address history %+v, %v", history, err)
+	}
+	if current, err := w.GetDepositAddress(accountId, walleter.BSC); err != nil || current.Address != testDepositAddress {
+		t.Fatalf("current address %+v, %v", current, err)
+	}
+	if owner, err := w.AccountOfDepositAddress(ctx, walleter.BSC, strings.ToLower(checksummed)); err != nil || owner != accountId {
+		t.Fatalf("owner of the retired address %d, %v", owner, err)
+	}
+	if _, err := w.AccountOfDepositAddress(ctx, walleter.Ethereum, checksummed); !errors.Is(err, walleter.ErrUnknownDepositAddress) {
+		t.Fatalf("address on another chain returned %v", err)
+	}
+
+	// the registry resolves chain deposits, retired addresses included
+	result, err := w.IngestDeposit(ctx, walleter.ChainDeposit{Chain: walleter.BSC, TxHash: "0x" + strings.Repeat("ab", 32),
+		Address: checksummed, AssetType: walleter.ERC20AssetType, Token: walleter.BUSD, Amount: 10})
+	if err != nil || result.Deposit.AccountId != accountId || erc20Balance(result.Wallet, walleter.BUSD) != 10 {
+		t.Fatalf("deposit to a retired address %+v, %v", result, err)
+	}
+
+	// deposit commands may reference the address instead of the account
+	command := walleter.NewERC20WalletCommand(0, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 5}, nil)
+	command.DepositAddress = strings.ToLower(testDepositAddress)
+	if wallet := handleCommand(t, db, w, command); wallet.AccountId != accountId || erc20Balance(wallet, walleter.BUSD) != 15 {
+		t.Fatalf("deposit by address %+v", wallet)
+	}
+	command.AccountId = otherId
+	if _, err := w.ExecuteCommand(ctx, command); !errors.Is(err, walleter.ErrDepositAddressTaken) {
+		t.Fatalf("deposit by the address of another account returned %v", err)
+	}
+	command.AccountId, command.DepositAddress = 0, "0x"+strings.Repeat("11", 20)
+	if _, err := w.ExecuteCommand(ctx, command); !errors.Is(err, walleter.ErrUnknownDepositAddress) {
+		t.Fatalf("deposit by an unknown address returned %v", err)
+	}
+	spend := walleter.NewERC20WalletCommand(0, walleter.Spend, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1}, nil)
+	spend.DepositAddress = testDepositAddress
+	if _, err := w.ExecuteCommand(ctx, spend); !errors.Is(err, walleter.ErrActionTypeNotSupport) {
+		t.Fatalf("spend by address returned %v", err)
+	}
+}
+
+func TestImportDepositAddresses(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	otherId := newTestAccountId()
+	handleCommand(t, db, w, walleter.NewInitWalletCommand(otherId))
+
+	assignedAt := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	retiredAt := assignedAt.Add(24 * time.Hour)
+	addresses := []walleter.ImportedDepositAddress{
+		{AccountId: accountId, Chain: walleter.Ethereum, Address: "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+			AssignedAt: assignedAt, RetiredAt: &retiredAt},
+		{AccountId: accountId, Chain: walleter.Ethereum, Address: "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb", AssignedAt: retiredAt},
+	}
+	if imported, err := w.ImportDepositAddresses(ctx, addresses); err != nil || imported != 2 {
+		t.Fatalf("imported %d, %v", imported, err)
+	}
+	// imports are idempotent
+	if imported, err := w.ImportDepositAddresses(ctx, addresses); err != nil || imported != 0 {
+		t.Fatalf("imported again %d, %v", imported, err)
+	}
+	history, err := w.ListDepositAddresses(accountId, walleter.Ethereum)
+	if err != nil || len(history) != 2 || history[0].RetiredAt == nil || !history[0].RetiredAt.Equal(retiredAt) || history[1].RetiredAt != nil {
+		t.Fatalf("imported history %+v, %v", history, err)
+	}
+
+	// a conflicting entry rolls back the whole import
+	conflicting := []walleter.ImportedDepositAddress{
+		{AccountId: otherId, Chain: walleter.Ethereum, Address: "0x8617E340B3D01FA5F11F306F4090FD50E238070D", AssignedAt: assignedAt},
+		{AccountId: otherId, Chain: walleter.Ethereum, Address: addresses[0].Address, AssignedAt: assignedAt},
+	}
+	if _, err := w.ImportDepositAddresses(ctx, conflicting); !errors.Is(err, walleter.ErrDepositAddressTaken) {
+		t.Fatalf("conflicting import returned %v", err)
+	}
+	if _, err := w.GetDepositAddress(otherId, walleter.Ethereum); err == nil {
+		t.Fatal("conflicting import stored an address")
+	}
+}
+
+func TestAssignDepositAddressConcurrently(t *testing.T) {
+	_, w, accountId := newTestWalleter(t)
+	ctx := context.Background()
+
+	const assignments = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, assignments)
+	for i := 0; i < assignments; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := w.AssignDepositAddress(ctx, accountId, walleter.BSC, fmt.Sprintf("0x%040x", i+1))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the assignments ran one after the other, each retiring the address before it
+	history, err := w.ListDepositAddresses(accountId, walleter.BSC)
+	if err != nil || len(history) != assignments {
+		t.Fatalf("address history %+v, %v", history, err)
+	}
+	current := 0
+	for _, address := range history {
+		if address.RetiredAt == nil {
+			current++
+		}
+	}
+	if current != 1 {
+		t.Fatalf("%d current addresses", current)
+	}
+
+	if _, err := w.AssignDepositAddress(ctx, newTestAccountId(), walleter.BSC, testDepositAddress); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("assigning an address to an account without wallet returned %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+// economyTotals refreshes the aggregates and returns the report of w grouped by action type for BUSD.
+func economyTotals(t *testing.T, w *walleter.Walleter) map[string]walleter.EconomyReportRow {
+	t.Helper()
+	if err := w.RefreshEconomyAggregates(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := w.QueryEconomyReport(walleter.EconomyReportQuery{
+		GroupBy: []walleter.ReportDimension{walleter.ActionTypeDimension, walleter.TokenDimension},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := map[string]walleter.EconomyReportRow{}
+	for _, row := range rows {
+		if row.Token == walleter.BUSD.String() {
+			result[row.ActionType] = row
+		}
+	}
+	return result
+}
+
+// depositBUSD deposits amount BUSD to accountId and returns the id of its log.
+func depositBUSD(t *testing.T, db *gorm.DB, w *walleter.Walleter, accountId uint64, amount float64) uint {
+	t.Helper()
+	_, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: amount}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log walleter.ERC20WalletLog
+	if err := db.Where("account_id = ? AND action_type = ?", accountId, walleter.Deposit.String()).Order("id DESC").First(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+	return log.ID
+}
+
+func setLogStatus(t *testing.T, db *gorm.DB, id uint, status walleter.WalletLogStatus) {
+	t.Helper()
+	if err := db.Model(&walleter.ERC20WalletLog{}).Where("id = ?", id).Update("status", status.String()).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefreshEconomyAggregates(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	depositBUSD(t, db, w, accountId, 10)
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 4}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	// a rejected command is not aggregated
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 40}, nil)); err == nil {
+		t.Fatal("withdrawing more than the balance succeeded")
+	}
+
+	for run := 0; run < 2; run++ {
+		totals := economyTotals(t, w)
+		deposit, withdraw := totals[walleter.Deposit.String()], totals[walleter.Withdraw.String()]
+		if deposit.Deposit != 10 || deposit.Count != 1 || withdraw.Withdraw != 4 || withdraw.Count != 1 {
+			t.Fatalf("refresh %d aggregated %+v", run, totals)
+		}
+	}
+}
+
+func TestRefreshEconomyAggregatesPastPendingLog(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	stalled := depositBUSD(t, db, w, accountId, 10)
+	setLogStatus(t, db, stalled, walleter.Pending)
+	depositBUSD(t, db, w, accountId, 5)
+
+	// the deposit after the stalled log is aggregated right away
+	if deposit := economyTotals(t, w)[walleter.Deposit.String()]; deposit.Deposit != 5 || deposit.Count != 1 {
+		t.Fatalf("aggregated %+v past a pending log", deposit)
+	}
+	depositBUSD(t, db, w, accountId, 1)
+	if deposit := economyTotals(t, w)[walleter.Deposit.String()]; deposit.Deposit != 6 || deposit.Count != 2 {
+		t.Fatalf("aggregated %+v while a log is pending", deposit)
+	}
+
+	// once settled the stalled log is aggregated exactly once
+	setLogStatus(t, db, stalled, walleter.Done)
+	for run := 0; run < 2; run++ {
+		if deposit := economyTotals(t, w)[walleter.Deposit.String()]; deposit.Deposit != 16 || deposit.Count != 3 {
+			t.Fatalf("refresh %d aggregated %+v after the pending log settled", run, deposit)
+		}
+	}
+
+	// a pending log which fails is dropped
+	failed := depositBUSD(t, db, w, accountId, 7)
+	setLogStatus(t, db, failed, walleter.Pending)
+	economyTotals(t, w)
+	setLogStatus(t, db, failed, walleter.Failed)
+	if deposit := economyTotals(t, w)[walleter.Deposit.String()]; deposit.Deposit != 16 || deposit.Count != 3 {
+		t.Fatalf("aggregated %+v after the pending log failed", deposit)
+	}
+	var unsettled int64
+	if err := db.Model(&walleter.EconomyAggregateUnsettled{}).Count(&unsettled).Error; err != nil || unsettled != 0 {
+		t.Fatalf("unsettled logs left %d, %v", unsettled, err)
+	}
+}
+
+func TestConcurrentRefreshesShareAggregateRows(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	depositBUSD(t, db, w, accountId, 100)
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		[]uint64{7}, []uint64{10}, nil))
+	// the fees of both log tables are aggregated into the same BUSD spend row
+	const spends = 5
+	for i := 0; i < spends; i++ {
+		handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Spend, "Shop", walleter.InGame,
+			map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1}, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1}))
+		handleCommand(t, db, w, walleter.NewERC1155WalletCommand(accountId, walleter.Spend, "Shop", walleter.InGame,
+			[]uint64{7}, []uint64{1}, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1}))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- w.RefreshEconomyAggregates()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if spend := economyTotals(t, w)[walleter.Spend.String()]; spend.Spend != spends || spend.Fee != 2*spends || spend.Count != spends {
+		t.Fatalf("aggregated %+v", spend)
+	}
+}
@@ -1,297 +1,172 @@
 package main
 
 import (
-	"fmt"
-	"github.com/neco-fun/walleter"
-	"github.com/sirupsen/logrus"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
 	"testing"
+
+	"github.com/nami-land/walleter"
 )
 
 func TestERC1155Income(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
-	}
-
-	// init a walleter instance
-	w := walleter.New(db, 1)
+	db, w, accountId := newTestWalleter(t)
 
 	// Testing income operation
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC1155WalletCommand(
-				testUserId,
-				walleter.Income,
-				"Testing",
-				walleter.InGame,
-				[]uint64{10001, 10002, 10003},
-				[]uint64{1, 2, 3},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
-	}
-
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
-	}
-
-	if userWallet.ERC1155TokenData.Values != "1,2,3" {
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId,
+		walleter.Income,
+		"Testing",
+		walleter.InGame,
+		[]uint64{10001, 10002, 10003},
+		[]uint64{1, 2, 3},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	if getWallet(t, w, accountId).ERC1155TokenData.Values != "1,2,3" {
 		t.Fatalf("%s testing failed", "TestERC1155Income")
 	}
 }
 
 func TestERC1155Spend(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
-	}
-
-	// init a walleter instance
-	w := walleter.New(db, 1)
-
-	// Testing income operation
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC1155WalletCommand(
-				testUserId,
-				walleter.Spend,
-				"Testing",
-				walleter.InGame,
-				[]uint64{10001, 10002, 10003},
-				[]uint64{1, 2, 3},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
-	}
-
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
-	}
-
-	if userWallet.ERC1155TokenData.Values != "0,0,0" {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId, walleter.Income, "Testing", walleter.InGame,
+		[]uint64{10001, 10002, 10003}, []uint64{1, 2, 3}, map[walleter.ERC20TokenEnum]float64{}))
+
+	// Testing spend operation
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId,
+		walleter.Spend,
+		"Testing",
+		walleter.InGame,
+		[]uint64{10001, 10002, 10003},
+		[]uint64{1, 2, 3},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	if getWallet(t, w, accountId).ERC1155TokenData.Values != "0,0,0" {
 		t.Fatalf("%s testing failed", "TestERC1155Spend")
 	}
 }
 
 func TestERC1155Deposit(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
-	}
-
-	// init a walleter instance
-	w := walleter.New(db, 1)
-
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC1155WalletCommand(
-				testUserId,
-				walleter.Deposit,
-				"Testing",
-				walleter.InGame,
-				[]uint64{10001, 10002, 10003},
-				[]uint64{1, 2, 3},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
-	}
-
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
-	}
-
-	if userWallet.ERC1155TokenData.Values != "1,2,3" {
-		t.Fatalf("%s testing failed", "TestERC1155Income")
+	db, w, accountId := newTestWalleter(t)
+
+	// Testing deposit operation
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId,
+		walleter.Deposit,
+		"Testing",
+		walleter.BSC,
+		[]uint64{10001, 10002, 10003},
+		[]uint64{1, 2, 3},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	if getWallet(t, w, accountId).ERC1155TokenData.Values != "1,2,3" {
+		t.Fatalf("%s testing failed", "TestERC1155Deposit")
 	}
 }
 
 func TestERC1155Withdraw(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
-	}
-
-	// init a walleter instance
-	w := walleter.New(db, 1)
-
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC1155WalletCommand(
-				testUserId,
-				walleter.Withdraw,
-				"Testing",
-				walleter.InGame,
-				[]uint64{10001, 10002, 10003},
-				[]uint64{1, 2, 3},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId, walleter.Deposit, "Testing", walleter.BSC,
+		[]uint64{10001, 10002, 10003}, []uint64{1, 2, 3}, map[walleter.ERC20TokenEnum]float64{}))
+
+	// Testing withdraw operation
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId,
+		walleter.Withdraw,
+		"Testing",
+		walleter.BSC,
+		[]uint64{10001, 10002, 10003},
+		[]uint64{1, 2, 3},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	if getWallet(t, w, accountId).ERC1155TokenData.Values != "0,0,0" {
+		t.Fatalf("%s testing failed", "TestERC1155Withdraw")
 	}
+}
 
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
+func TestERC1155FeeCharge(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId,
+		walleter.Deposit,
+		"Testing",
+		walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{
+			walleter.FISHX: 10.0,
+			walleter.BUSD:  10.0,
+		},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId,
+		walleter.Deposit,
+		"Testing",
+		walleter.InGame,
+		[]uint64{10001, 10002, 10003},
+		[]uint64{1, 2, 3},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	// Testing withdraw operation with fees
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId,
+		walleter.Withdraw,
+		"Testing",
+		walleter.InGame,
+		[]uint64{10001, 10002, 10003},
+		[]uint64{1, 2, 3},
+		map[walleter.ERC20TokenEnum]float64{
+			walleter.FISHX: 10.0,
+			walleter.BUSD:  10.0,
+		},
+	))
+
+	userWallet := getWallet(t, w, accountId)
+	for _, erc20 := range userWallet.ERC20TokenData {
+		if erc20.Balance != 0.0 {
+			t.Fatalf("%s failed", "TestERC1155FeeCharge")
+		}
 	}
-
 	if userWallet.ERC1155TokenData.Values != "0,0,0" {
-		t.Fatalf("%s testing failed", "TestERC1155Spend")
+		t.Fatalf("%s testing failed", "TestERC1155FeeCharge")
 	}
 }
 
-func TestERC1155FeeCharge(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
-	}
-
-	// init a walleter instance
-	w := walleter.New(db, 1)
+func TestERC1155LogKeepsOriginalWallet(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId, walleter.Deposit, "Testing", walleter.InGame, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(
+		accountId, walleter.Deposit, "Testing", walleter.InGame, []uint64{10001}, []uint64{1},
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 4}))
 
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC20WalletCommand(
-				testUserId,
-				walleter.Deposit,
-				"Testing",
-				walleter.InGame,
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 10.0,
-					walleter.BUSD:  10.0,
-				},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
+	// the fee taken from the erc20 tokens only shows in the settled wallet
+	var log walleter.ERC1155WalletLog
+	if err := db.Where("account_id = ? AND action_type = ?", accountId, walleter.Deposit.String()).First(&log).Error; err != nil {
+		t.Fatal(err)
 	}
-
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC1155WalletCommand(
-				testUserId,
-				walleter.Deposit,
-				"Testing",
-				walleter.InGame,
-				[]uint64{10001, 10002, 10003},
-				[]uint64{1, 2, 3},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
-	}
-
-	// Testing income operation
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC1155WalletCommand(
-				testUserId,
-				walleter.Withdraw,
-				"Testing",
-				walleter.InGame,
-				[]uint64{10001, 10002, 10003},
-				[]uint64{1, 2, 3},
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 10.0,
-					walleter.BUSD:  10.0,
-				},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
+	if erc20Balance(log.OriginalWallet, walleter.BUSD) != 10 || erc20Balance(log.SettledWallet, walleter.BUSD) != 6 {
+		t.Fatalf("log moved from %v to %v", erc20Balance(log.OriginalWallet, walleter.BUSD), erc20Balance(log.SettledWallet, walleter.BUSD))
 	}
+}
 
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
-	}
+func TestERC1155RejectedCommandKeepsWallet(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId, walleter.Deposit, "Testing", walleter.InGame, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
 
-	for _, erc20 := range userWallet.ERC20TokenData {
-		if erc20.Balance != 0.0 {
-			t.Fatalf("%s failed", "TestERC1155FeeCharge")
-		}
+	// the fee is charged before the withdrawal fails, outside a transaction of the caller
+	_, err := w.HandleWalletCommand(db, walleter.NewERC1155WalletCommand(
+		accountId, walleter.Withdraw, "Testing", walleter.InGame, []uint64{10001}, []uint64{1},
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 2}))
+	if err == nil {
+		t.Fatal("withdrawing an item not held succeeded")
 	}
-
-	if userWallet.ERC1155TokenData.Values != "0,0,0" {
-		t.Fatalf("%s testing failed", "TestERC1155Spend")
+	if balance := erc20Balance(getWallet(t, w, accountId), walleter.BUSD); balance != 10 {
+		t.Fatalf("balance after the rejected command %v", balance)
 	}
 }
@@ -1,308 +1,288 @@
 package main
 
 import (
-	"fmt"
-	"github.com/neco-fun/walleter"
-	"github.com/sirupsen/logrus"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
+	"context"
+	"strings"
+	"sync"
 	"testing"
-)
+	"time"
 
-var testUserId uint64 = 10
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
 
 func TestERC20Income(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
-	}
-
-	// init a walleter instance
-	w := walleter.New(db, 1)
+	db, w, accountId := newTestWalleter(t)
 
 	// Testing income operation
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC20WalletCommand(
-				testUserId,
-				walleter.Income,
-				"Testing",
-				walleter.InGame,
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 110.0,
-					walleter.BUSD:  10,
-				},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId,
+		walleter.Income,
+		"Testing",
+		walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{
+			walleter.FISHX: 110.0,
+			walleter.BUSD:  10,
+		},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	userWallet := getWallet(t, w, accountId)
+	if erc20Balance(userWallet, walleter.FISHX) != 110.0 || erc20Balance(userWallet, walleter.BUSD) != 10.0 {
+		t.Fatalf("%s failed", "TestERC20Income")
 	}
+}
 
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
+func TestERC20Spend(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	tokens := map[walleter.ERC20TokenEnum]float64{
+		walleter.FISHX: 110.0,
+		walleter.BUSD:  10,
 	}
-
-	for _, erc20 := range userWallet.ERC20TokenData {
-		if erc20.Token == walleter.NFISH.String() {
-			if erc20.Balance != 110.0 {
-				t.Fatalf("%s failed", "TestERC20Income")
-			}
-		} else if erc20.Token == walleter.BUSD.String() {
-			if erc20.Balance != 10.0 {
-				t.Fatalf("%s failed", "TestERC20Income")
-			}
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId, walleter.Income, "Testing", walleter.InGame, tokens, map[walleter.ERC20TokenEnum]float64{}))
+
+	// Testing spend operation
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId,
+		walleter.Spend,
+		"Testing",
+		walleter.InGame,
+		tokens,
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	for _, erc20 := range getWallet(t, w, accountId).ERC20TokenData {
+		if erc20.Balance != 0.0 {
+			t.Fatalf("%s failed", "TestERC20Spend")
 		}
 	}
 }
 
-func TestERC20Spend(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
-	}
-
-	// init a walleter instance
-	w := walleter.New(db, 1)
-
-	// Testing income operation
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC20WalletCommand(
-				testUserId,
-				walleter.Spend,
-				"Testing",
-				walleter.InGame,
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 110.0,
-					walleter.BUSD:  10,
-				},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
+func TestERC20Deposit(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+
+	// Testing deposit operation
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId,
+		walleter.Deposit,
+		"Testing",
+		walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{
+			walleter.FISHX: 110.0,
+			walleter.BUSD:  10,
+		},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	userWallet := getWallet(t, w, accountId)
+	if erc20Balance(userWallet, walleter.FISHX) != 110.0 || erc20Balance(userWallet, walleter.BUSD) != 10.0 {
+		t.Fatalf("%s failed", "TestERC20Deposit")
 	}
+}
 
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
+func TestERC20Withdraw(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	tokens := map[walleter.ERC20TokenEnum]float64{
+		walleter.FISHX: 110.0,
+		walleter.BUSD:  10,
 	}
-
-	for _, erc20 := range userWallet.ERC20TokenData {
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId, walleter.Deposit, "Testing", walleter.BSC, tokens, map[walleter.ERC20TokenEnum]float64{}))
+
+	// Testing withdraw operation
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId,
+		walleter.Withdraw,
+		"Testing",
+		walleter.BSC,
+		tokens,
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	for _, erc20 := range getWallet(t, w, accountId).ERC20TokenData {
 		if erc20.Balance != 0.0 {
-			t.Fatalf("%s failed", "TestERC20Spend")
+			t.Fatalf("%s failed", "TestERC20Withdraw")
 		}
 	}
 }
 
-func TestERC20Deposit(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
+func TestERC20FeeCharge(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId,
+		walleter.Deposit,
+		"Testing",
+		walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{
+			walleter.FISHX: 110.0,
+			walleter.BUSD:  10,
+		},
+		map[walleter.ERC20TokenEnum]float64{},
+	))
+
+	// Testing charge fee operation
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId,
+		walleter.ChargeFee,
+		"Testing",
+		walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{
+			walleter.FISHX: 100.0,
+			walleter.BUSD:  9,
+		},
+		map[walleter.ERC20TokenEnum]float64{
+			walleter.FISHX: 10.0,
+			walleter.BUSD:  1,
+		},
+	))
+
+	for _, erc20 := range getWallet(t, w, accountId).ERC20TokenData {
+		if erc20.Balance != 0.0 {
+			t.Fatalf("%s failed", "TestERC20FeeCharge")
+		}
 	}
+}
 
-	// init a walleter instance
-	w := walleter.New(db, 1)
+func TestERC20LogKeepsOriginalWallet(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId, walleter.Income, "Testing", walleter.InGame, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
 
-	// Testing income operation
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC20WalletCommand(
-				testUserId,
-				walleter.Deposit,
-				"Testing",
-				walleter.InGame,
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 110.0,
-					walleter.BUSD:  10,
-				},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
+	var log walleter.ERC20WalletLog
+	if err := db.Where("account_id = ? AND action_type = ?", accountId, walleter.Income.String()).First(&log).Error; err != nil {
+		t.Fatal(err)
 	}
-
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
+	if erc20Balance(log.OriginalWallet, walleter.BUSD) != 0 || erc20Balance(log.SettledWallet, walleter.BUSD) != 10 {
+		t.Fatalf("log moved from %v to %v", erc20Balance(log.OriginalWallet, walleter.BUSD), erc20Balance(log.SettledWallet, walleter.BUSD))
 	}
+}
 
-	for _, erc20 := range userWallet.ERC20TokenData {
-		if erc20.Token == walleter.NFISH.String() {
-			if erc20.Balance != 110.0 {
-				t.Fatalf("%s failed", "TestERC20Deposit")
-			}
-		} else if erc20.Token == walleter.BUSD.String() {
-			if erc20.Balance != 10.0 {
-				t.Fatalf("%s failed", "TestERC20Deposit")
-			}
-		}
+func TestERC20RejectedCommandKeepsWallet(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId, walleter.Deposit, "Testing", walleter.InGame, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
+	feeCharger := getWallet(t, w, testFeeChargerId)
+
+	// the fee is charged before the withdrawal fails, outside a transaction of the caller
+	_, err := w.HandleWalletCommand(db, walleter.NewERC20WalletCommand(
+		accountId, walleter.Withdraw, "Testing", walleter.InGame, map[walleter.ERC20TokenEnum]float64{walleter.FISHX: 5},
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 2}))
+	if err == nil {
+		t.Fatal("withdrawing more than the balance succeeded")
+	}
+	if balance := erc20Balance(getWallet(t, w, accountId), walleter.BUSD); balance != 10 {
+		t.Fatalf("balance after the rejected command %v", balance)
+	}
+	if balance := erc20Balance(getWallet(t, w, testFeeChargerId), walleter.BUSD); balance != erc20Balance(feeCharger, walleter.BUSD) {
+		t.Fatalf("fee charger balance %v after the rejected command", balance)
+	}
+	var log walleter.ERC20WalletLog
+	if err := db.Where("account_id = ? AND action_type = ?", accountId, walleter.Withdraw.String()).First(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+	if log.Status != walleter.Failed.String() || erc20Balance(log.SettledWallet, walleter.BUSD) != 10 {
+		t.Fatalf("log %s settled with %v", log.Status, erc20Balance(log.SettledWallet, walleter.BUSD))
 	}
 }
 
-func TestERC20Withdraw(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
+func TestFeeChargerCheckSign(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId, walleter.Deposit, "Testing", walleter.InGame, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		accountId, walleter.Spend, "Testing", walleter.InGame, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 4}, nil))
+
+	if err := walleter.VerifyWallet(getWallet(t, w, testFeeChargerId)); err != nil {
+		t.Fatal(err)
 	}
+	// the fee charger account keeps working after collecting
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(
+		testFeeChargerId, walleter.Withdraw, "Testing", walleter.InGame, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 4}, nil))
+}
 
-	// init a walleter instance
-	w := walleter.New(db, 1)
+func TestExecuteCommandRollsBack(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
 
-	// Testing income operation
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC20WalletCommand(
-				testUserId,
-				walleter.Withdraw,
-				"Testing",
-				walleter.InGame,
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 110.0,
-					walleter.BUSD:  10,
-				},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
-	})
-	if err != nil {
-		logrus.Fatalln(err)
+	// publishing the event fails after the wallet and its log were changed
+	if err := db.Migrator().DropTable("outbox_events"); err != nil {
+		t.Fatal(err)
 	}
-
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
+	_, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(
+		accountId, walleter.Deposit, "Testing", walleter.BSC, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
+	if err == nil {
+		t.Fatal("command without outbox succeeded")
 	}
-
-	for _, erc20 := range userWallet.ERC20TokenData {
-		if erc20.Balance != 0.0 {
-			t.Fatalf("%s failed", "TestERC20Withdraw")
-		}
+	if balance := erc20Balance(getWallet(t, w, accountId), walleter.BUSD); balance != 0 {
+		t.Fatalf("balance after the rolled back command %v", balance)
+	}
+	var logs int64
+	if err := db.Model(&walleter.ERC20WalletLog{}).Where("account_id = ? AND action_type = ?", accountId, walleter.Deposit.String()).Count(&logs).Error; err != nil || logs != 0 {
+		t.Fatalf("%d logs of the rolled back command, %v", logs, err)
 	}
 }
 
-func TestERC20FeeCharge(t *testing.T) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		panic("connect database failed")
-	}
+// statementRecorder a gorm logger keeping every statement it traces.
+type statementRecorder struct {
+	mutex      sync.Mutex
+	statements []string
+}
 
-	// init a walleter instance
-	w := walleter.New(db, 1)
-
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC20WalletCommand(
-				testUserId,
-				walleter.Deposit,
-				"Testing",
-				walleter.InGame,
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 110.0,
-					walleter.BUSD:  10,
-				},
-				map[walleter.ERC20TokenEnum]float64{},
-			),
-		)
-		return err
+func (r *statementRecorder) LogMode(logger.LogLevel) logger.Interface { return r }
+
+func (r *statementRecorder) Info(context.Context, string, ...interface{}) {}
+
+func (r *statementRecorder) Warn(context.Context, string, ...interface{}) {}
+
+func (r *statementRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *statementRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	statement, _ := fc()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.statements = append(r.statements, statement)
+}
+
+func TestInitializeRacingWalletInTransaction(t *testing.T) {
+	db, w, _ := newTestWalleter(t)
+	accountId := newTestAccountId()
+	// another instance creates the wallet between the lookup and the insert of the initialization,
+	// which first inserts its logs
+	raced := false
+	err := db.Callback().Create().Before("gorm:create").Register("test:racing_wallet", func(tx *gorm.DB) {
+		if tx.Statement.Table != "erc20_wallet_logs" || raced {
+			return
+		}
+		raced = true
+		now := time.Now()
+		tx.Session(&gorm.Session{NewDB: true}).Exec("INSERT INTO wallets (created_at, updated_at, account_id, check_sign) VALUES (?, ?, ?, ?)",
+			now, now, accountId, "")
 	})
 	if err != nil {
-		logrus.Fatalln(err)
+		t.Fatal(err)
 	}
 
-	// Testing income operation
-	err = db.Transaction(func(tx *gorm.DB) error {
-		_, err := w.HandleWalletCommand(
-			db,
-			walleter.NewERC20WalletCommand(
-				testUserId,
-				walleter.ChargeFee,
-				"Testing",
-				walleter.InGame,
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 100.0,
-					walleter.BUSD:  9,
-				},
-				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 10.0,
-					walleter.BUSD:  1,
-				},
-			),
-		)
+	recorder := &statementRecorder{}
+	err = db.Session(&gorm.Session{Logger: recorder}).Transaction(func(tx *gorm.DB) error {
+		_, err := w.HandleWalletCommand(tx, walleter.NewInitWalletCommand(accountId))
 		return err
 	})
-	if err != nil {
-		logrus.Fatalln(err)
-	}
-
-	userWallet, err := w.GetWalletByAccountId(testUserId)
-	if err != nil {
-		logrus.Fatalln(err)
+	if err != nil || !raced {
+		t.Fatalf("initialization racing another one returned %v", err)
 	}
-
-	for _, erc20 := range userWallet.ERC20TokenData {
-		if erc20.Balance != 0.0 {
-			t.Fatalf("%s failed", "TestERC20FeeCharge")
+	// the rejected insert is rolled back on its own, PostgreSQL aborts the transaction of the caller otherwise
+	rolledBack := false
+	for _, statement := range recorder.statements {
+		if strings.HasPrefix(statement, "ROLLBACK TO SAVEPOINT") {
+			rolledBack = true
 		}
 	}
+	if !rolledBack {
+		t.Fatal("duplicate wallet not rolled back to a savepoint")
+	}
+	var count int64
+	if err := db.Model(&walleter.Wallet{}).Where("account_id = ?", accountId).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("%d wallets of the account, %v", count, err)
+	}
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nami-land/walleter"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	cases := []struct {
+		err  error
+		code walleter.ErrorCode
+	}{
+		{walleter.ErrNoEnoughERC20Balance, walleter.CodeInsufficientBalance},
+		{fmt.Errorf("withdraw: %w", walleter.ErrAccountFrozen), walleter.CodeAccountFrozen},
+		{&walleter.WalletError{Code: walleter.CodeInsufficientNFT}, walleter.CodeInsufficientNFT},
+		{fmt.Errorf("handle: %w", &walleter.WalletError{Code: walleter.CodeApprovalRequired}), walleter.CodeApprovalRequired},
+		{fmt.Errorf("%w: not a cursor", walleter.ErrInvalidCursor), walleter.CodeInvalidCursor},
+		{fmt.Errorf("%w: 0x52 on bsc", walleter.ErrUnknownDepositAddress), walleter.CodeUnknownDepositAddress},
+		{walleter.ErrDepositAddressTaken, walleter.CodeDepositAddressTaken},
+		{walleter.ErrDuplicateDeposit, walleter.CodeDuplicateDeposit},
+		{walleter.ErrWithdrawalNotPending, walleter.CodeWithdrawalNotPending},
+		{walleter.ErrSelfApproval, walleter.CodeSelfApproval},
+		{errors.New("connection reset"), walleter.CodeInternal},
+	}
+	for _, item := range cases {
+		if code := walleter.ErrorCodeOf(item.err); code != item.code {
+			t.Errorf("code of %v is %s, expected %s", item.err, code, item.code)
+		}
+	}
+}
+
+func TestWalletErrorOfRejectedCommand(t *testing.T) {
+	_, w, accountId := newTestWalleter(t)
+	_, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 5}, nil))
+	if !errors.Is(err, walleter.ErrNoEnoughERC20Balance) {
+		t.Fatalf("spending more than the balance returned %v", err)
+	}
+	var walletErr *walleter.WalletError
+	if !errors.As(err, &walletErr) || walletErr.Code != walleter.CodeInsufficientBalance || walletErr.AccountId != accountId ||
+		walletErr.Token != walleter.BUSD.String() || walletErr.Requested != 5 || walletErr.Available != 0 {
+		t.Fatalf("wallet error %+v", walletErr)
+	}
+	if walletErr.Unwrap() != walleter.ErrNoEnoughERC20Balance {
+		t.Fatalf("wallet error unwraps to %v", walletErr.Unwrap())
+	}
+
+	// the failure detail of the log reads back with its sentinel
+	page, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, Statuses: []walleter.WalletLogStatus{walleter.Failed}})
+	if err != nil || len(page.Entries) != 1 {
+		t.Fatalf("failed logs %+v, %v", page, err)
+	}
+	detail := page.Entries[0].FailureDetail
+	if detail == nil || detail.Code != walleter.CodeInsufficientBalance || detail.Requested != 5 || !errors.Is(detail, walleter.ErrNoEnoughERC20Balance) {
+		t.Fatalf("failure detail %+v", detail)
+	}
+}
+
+func TestWalletErrorValueScan(t *testing.T) {
+	original := walleter.WalletError{Code: walleter.CodeInsufficientNFT, AccountId: 7, ItemId: 3, Requested: 2, Available: 1, Message: "insufficient nft balance"}
+	value, err := original.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []interface{}{value, []byte(value.(string))} {
+		var scanned walleter.WalletError
+		if err := scanned.Scan(input); err != nil {
+			t.Fatal(err)
+		}
+		if scanned.Error() != original.Error() || scanned.Code != original.Code || scanned.ItemId != original.ItemId {
+			t.Fatalf("scanned %+v from %+v", scanned, original)
+		}
+		if !errors.Is(&scanned, walleter.ErrNoEnoughNFT) {
+			t.Fatalf("scanned %+v does not unwrap to its sentinel", scanned)
+		}
+	}
+	var unknown walleter.WalletError
+	if err := unknown.Scan(`{"code":"internal","message":"connection reset"}`); err != nil || unknown.Unwrap() != nil {
+		t.Fatalf("scanned %+v, %v", unknown, err)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/nami-land/walleter"
+	"github.com/nami-land/walleter/grpcserver"
+	"github.com/nami-land/walleter/walleterpb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+// newTestGRPCClient serves a new test walleter over an in-memory listener and connects to it.
+func newTestGRPCClient(t *testing.T) (*gorm.DB, *grpc.ClientConn, uint64) {
+	t.Helper()
+	db, w, accountId := newTestWalleter(t)
+	return db, serveGRPC(t, w), accountId
+}
+
+// serveGRPC serves w over an in-memory listener and connects to it.
+func serveGRPC(t *testing.T, w *walleter.Walleter) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpcserver.Register(server, w)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestGRPCHandleWalletCommand(t *testing.T) {
+	_, conn, accountId := newTestGRPCClient(t)
+	client := walleterpb.NewWalletServiceClient(conn)
+	ctx := context.Background()
+
+	response, err := client.HandleWalletCommand(ctx, &walleterpb.HandleWalletCommandRequest{Command: &walleterpb.WalletCommand{
+		AccountId:     accountId,
+		AssetType:     walleterpb.AssetType_ASSET_TYPE_ERC20,
+		ActionType:    walleterpb.WalletActionType_WALLET_ACTION_TYPE_DEPOSIT,
+		Erc20Commands: []*walleterpb.ERC20Command{{Token: walleterpb.ERC20Token_ERC20_TOKEN_BUSD, Value: 10}},
+		CommandSource: walleterpb.CommandSource_COMMAND_SOURCE_BSC,
+	}})
+	if err != nil || response.GetStatus() != walleterpb.WalletLogStatus_WALLET_LOG_STATUS_DONE {
+		t.Fatalf("deposit %+v, %v", response, err)
+	}
+	for _, token := range response.GetWallet().GetErc20TokenData() {
+		if token.GetToken() == walleter.BUSD.String() && token.GetBalance() != 10 {
+			t.Fatalf("balance after the deposit %v", token.GetBalance())
+		}
+	}
+
+	// a rejected command maps to its gRPC code and carries the details of the wallet error
+	_, err = client.HandleWalletCommand(ctx, &walleterpb.HandleWalletCommandRequest{Command: &walleterpb.WalletCommand{
+		AccountId:     accountId,
+		AssetType:     walleterpb.AssetType_ASSET_TYPE_ERC20,
+		ActionType:    walleterpb.WalletActionType_WALLET_ACTION_TYPE_WITHDRAW,
+		Erc20Commands: []*walleterpb.ERC20Command{{Token: walleterpb.ERC20Token_ERC20_TOKEN_BUSD, Value: 25}},
+		CommandSource: walleterpb.CommandSource_COMMAND_SOURCE_BSC,
+	}})
+	if status.Code(err) != codes.FailedPrecondition || grpcserver.ErrorCodeOf(err) != walleter.CodeInsufficientBalance {
+		t.Fatalf("withdrawing more than the balance returned %v", err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, detail := range status.Convert(err).Details() {
+		if item, ok := detail.(*errdetails.ErrorInfo); ok {
+			info = item
+		}
+	}
+	if info == nil || info.GetDomain() != "walleter" || info.GetMetadata()["token"] != walleter.BUSD.String() ||
+		info.GetMetadata()["requested"] != "25" || info.GetMetadata()["available"] != "10" {
+		t.Fatalf("error info %+v", info)
+	}
+
+	_, err = client.HandleWalletCommand(ctx, &walleterpb.HandleWalletCommandRequest{})
+	if status.Code(err) != codes.InvalidArgument || grpcserver.ErrorCodeOf(err) != walleter.CodeInternal {
+		t.Fatalf("a request without command returned %v", err)
+	}
+	_, err = client.GetWallet(ctx, &walleterpb.GetWalletRequest{AccountId: newTestAccountId()})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("getting an unknown wallet returned %v", err)
+	}
+	_, err = client.GetWalletAt(ctx, &walleterpb.GetWalletAtRequest{AccountId: accountId})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("a snapshot without time returned %v", err)
+	}
+}
+
+func TestGRPCGetWalletLocked(t *testing.T) {
+	db, _, accountId := newTestWalleter(t)
+	w := walleter.New(db, testFeeChargerId, walleter.WithWithdrawalPolicy(walleter.WithdrawalPolicy{
+		Thresholds: map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 100},
+		Approvals:  1,
+	}))
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1000}, nil))
+	client := walleterpb.NewWalletServiceClient(serveGRPC(t, w))
+	// a held withdrawal succeeds with its hold
+	held, err := client.HandleWalletCommand(context.Background(), &walleterpb.HandleWalletCommandRequest{Command: &walleterpb.WalletCommand{
+		AccountId:     accountId,
+		AssetType:     walleterpb.AssetType_ASSET_TYPE_ERC20,
+		ActionType:    walleterpb.WalletActionType_WALLET_ACTION_TYPE_WITHDRAW,
+		Erc20Commands: []*walleterpb.ERC20Command{{Token: walleterpb.ERC20Token_ERC20_TOKEN_BUSD, Value: 300}},
+		CommandSource: walleterpb.CommandSource_COMMAND_SOURCE_IN_GAME,
+	}})
+	if err != nil || held.GetStatus() != walleterpb.WalletLogStatus_WALLET_LOG_STATUS_HELD || held.GetWithdrawalHoldId() == 0 {
+		t.Fatalf("held withdrawal %+v, %v", held, err)
+	}
+	holds, err := w.ListWithdrawalHolds(walleter.WithdrawalPending)
+	if err != nil || len(holds) != 1 || uint64(holds[0].ID) != held.GetWithdrawalHoldId() {
+		t.Fatalf("pending holds %+v, %v", holds, err)
+	}
+
+	response, err := client.GetWallet(context.Background(), &walleterpb.GetWalletRequest{AccountId: accountId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var busd *walleterpb.ERC20TokenWallet
+	for _, token := range response.GetWallet().GetErc20TokenData() {
+		if token.GetToken() == walleter.BUSD.String() {
+			busd = token
+		}
+	}
+	// the held withdrawal is locked, not gone from the wallet
+	if busd.GetBalance() != 700 || busd.GetLocked() != 300 {
+		t.Fatalf("BUSD wallet %+v", busd)
+	}
+}
+
+func TestGRPCListWalletLogs(t *testing.T) {
+	_, conn, accountId := newTestGRPCClient(t)
+	client := walleterpb.NewWalletServiceClient(conn)
+	ctx := context.Background()
+	erc20Command := func(actionType walleterpb.WalletActionType, value float64) *walleterpb.HandleWalletCommandRequest {
+		return &walleterpb.HandleWalletCommandRequest{Command: &walleterpb.WalletCommand{
+			AccountId:     accountId,
+			AssetType:     walleterpb.AssetType_ASSET_TYPE_ERC20,
+			ActionType:    actionType,
+			Erc20Commands: []*walleterpb.ERC20Command{{Token: walleterpb.ERC20Token_ERC20_TOKEN_BUSD, Value: value}},
+			CommandSource: walleterpb.CommandSource_COMMAND_SOURCE_BSC,
+		}}
+	}
+	for _, value := range []float64{10, 20, 30} {
+		if _, err := client.HandleWalletCommand(ctx, erc20Command(walleterpb.WalletActionType_WALLET_ACTION_TYPE_DEPOSIT, value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.HandleWalletCommand(ctx, erc20Command(walleterpb.WalletActionType_WALLET_ACTION_TYPE_WITHDRAW, 100)); err == nil {
+		t.Fatal("withdrawing more than the balance succeeded")
+	}
+
+	// the deposits, newest first, in pages of two
+	request := &walleterpb.ListWalletLogsRequest{
+		AccountId:   accountId,
+		ActionTypes: []walleterpb.WalletActionType{walleterpb.WalletActionType_WALLET_ACTION_TYPE_DEPOSIT},
+		Tokens:      []walleterpb.ERC20Token{walleterpb.ERC20Token_ERC20_TOKEN_BUSD},
+		Limit:       2,
+	}
+	first, err := client.ListWalletLogs(ctx, request)
+	if err != nil || len(first.GetLogs()) != 2 || first.GetNextCursor() == "" {
+		t.Fatalf("first page %+v, %v", first, err)
+	}
+	if log := first.GetLogs()[0]; log.GetAccountId() != accountId || log.GetActionType() != walleter.Deposit.String() ||
+		log.GetStatus() != walleter.Done.String() || len(log.GetTokens()) != 1 || log.GetTokens()[0].GetAmount() != 30 {
+		t.Fatalf("newest deposit %+v", log)
+	}
+	request.Cursor = first.GetNextCursor()
+	second, err := client.ListWalletLogs(ctx, request)
+	if err != nil || len(second.GetLogs()) != 1 || second.GetNextCursor() != "" || second.GetLogs()[0].GetTokens()[0].GetAmount() != 10 {
+		t.Fatalf("second page %+v, %v", second, err)
+	}
+
+	failed, err := client.ListWalletLogs(ctx, &walleterpb.ListWalletLogsRequest{
+		AccountId: accountId,
+		Statuses:  []walleterpb.WalletLogStatus{walleterpb.WalletLogStatus_WALLET_LOG_STATUS_FAILED},
+	})
+	if err != nil || len(failed.GetLogs()) != 1 || failed.GetLogs()[0].GetFailureCode() != string(walleter.CodeInsufficientBalance) {
+		t.Fatalf("failed logs %+v, %v", failed, err)
+	}
+
+	_, err = client.ListWalletLogs(ctx, &walleterpb.ListWalletLogsRequest{AccountId: accountId, Cursor: "not a cursor"})
+	if status.Code(err) != codes.InvalidArgument || grpcserver.ErrorCodeOf(err) != walleter.CodeInvalidCursor {
+		t.Fatalf("an invalid cursor returned %v", err)
+	}
+}
+
+func TestGRPCHealth(t *testing.T) {
+	db, conn, _ := newTestGRPCClient(t)
+	client := healthpb.NewHealthClient(conn)
+	ctx := context.Background()
+
+	for _, service := range []string{"", walleterpb.WalletService_ServiceDesc.ServiceName} {
+		response, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil || response.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			t.Fatalf("health of %q %v, %v", service, response.GetStatus(), err)
+		}
+	}
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown.v1.Service"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("health of an unknown service returned %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sqlDB.Close()
+	response, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil || response.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("health without database %v, %v", response.GetStatus(), err)
+	}
+}
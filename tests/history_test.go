@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+func TestGetWalletAt(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	beforeDeposit := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	depositBUSD(t, db, w, accountId, 10)
+	afterDeposit := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 4}, nil))
+
+	for _, item := range []struct {
+		at      time.Time
+		balance float64
+	}{{beforeDeposit, 0}, {afterDeposit, 10}, {time.Now(), 6}} {
+		snapshot, err := w.GetWalletAt(accountId, item.at)
+		if err != nil || snapshot.ERC20Balances[walleter.BUSD.String()] != item.balance {
+			t.Fatalf("snapshot at %v %+v, %v", item.at, snapshot, err)
+		}
+	}
+	if _, err := w.GetWalletAt(accountId, beforeDeposit.Add(-time.Hour)); err == nil {
+		t.Fatal("snapshot before the wallet was created")
+	}
+}
+
+func TestGetWalletAtBeforeBalanceHistory(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	depositBUSD(t, db, w, accountId, 10)
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		[]uint64{7, 8}, []uint64{3, 1}, nil))
+	// the changes so far predate the balance history tables
+	for _, model := range []interface{}{&walleter.ERC20BalanceHistory{}, &walleter.ERC1155BalanceHistory{}} {
+		if err := db.Unscoped().Where("account_id = ?", accountId).Delete(model).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	beforeChanges := time.Now()
+	snapshot, err := w.GetWalletAt(accountId, beforeChanges)
+	if err != nil || snapshot.ERC20Balances[walleter.BUSD.String()] != 10 || snapshot.ERC1155Balances[7] != 3 || snapshot.ERC1155Balances[8] != 1 {
+		t.Fatalf("snapshot without history %+v, %v", snapshot, err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 4}, nil))
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(accountId, walleter.Spend, "Testing", walleter.InGame,
+		[]uint64{7}, []uint64{2}, nil))
+
+	// before the first recorded change a token held what that change started from
+	snapshot, err = w.GetWalletAt(accountId, beforeChanges)
+	if err != nil || snapshot.ERC20Balances[walleter.BUSD.String()] != 10 || snapshot.ERC1155Balances[7] != 3 || snapshot.ERC1155Balances[8] != 1 {
+		t.Fatalf("snapshot before the first recorded change %+v, %v", snapshot, err)
+	}
+	snapshot, err = w.GetWalletAt(accountId, time.Now())
+	if err != nil || snapshot.ERC20Balances[walleter.BUSD.String()] != 6 || snapshot.ERC1155Balances[7] != 1 || snapshot.ERC1155Balances[8] != 1 {
+		t.Fatalf("current snapshot %+v, %v", snapshot, err)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nami-land/walleter"
+	"github.com/nami-land/walleter/httpserver"
+)
+
+// newTestHTTPServer serves a new walleter over HTTP, returning it along with an initialized account.
+func newTestHTTPServer(t *testing.T) (*httptest.Server, uint64) {
+	t.Helper()
+	_, w, accountId := newTestWalleter(t)
+	server := httptest.NewServer(httpserver.NewServer(w))
+	t.Cleanup(server.Close)
+	return server, accountId
+}
+
+// doJSON sends body as JSON, checks the response status and decodes the response into result.
+func doJSON(t *testing.T, method, url string, body interface{}, wantStatus int, result interface{}) {
+	t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	request, err := http.NewRequest(method, url, &reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != wantStatus {
+		var failure httpserver.ErrorResponse
+		_ = json.NewDecoder(response.Body).Decode(&failure)
+		t.Fatalf("%s %s: status %d, want %d: %+v", method, url, response.StatusCode, wantStatus, failure)
+	}
+	if result != nil {
+		if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestHTTPCreateAndGetWallet(t *testing.T) {
+	server, _ := newTestHTTPServer(t)
+	accountId := newTestAccountId()
+
+	var created, existing, read walleter.Wallet
+	doJSON(t, http.MethodPost, server.URL+"/v1/wallets", httpserver.CreateWalletRequest{AccountId: accountId}, http.StatusCreated, &created)
+	doJSON(t, http.MethodPost, server.URL+"/v1/wallets", httpserver.CreateWalletRequest{AccountId: accountId}, http.StatusOK, &existing)
+	doJSON(t, http.MethodGet, fmt.Sprintf("%s/v1/wallets/%d", server.URL, accountId), nil, http.StatusOK, &read)
+	if created.AccountId != accountId || existing.CheckSign != created.CheckSign || read.CheckSign != created.CheckSign {
+		t.Fatalf("created %+v, existing %+v, read %+v", created, existing, read)
+	}
+
+	var failure httpserver.ErrorResponse
+	doJSON(t, http.MethodGet, fmt.Sprintf("%s/v1/wallets/%d", server.URL, newTestAccountId()), nil, http.StatusNotFound, &failure)
+	if failure.Code != "not_found" {
+		t.Fatalf("missing wallet answered %+v", failure)
+	}
+}
+
+func TestHTTPExecuteCommand(t *testing.T) {
+	server, accountId := newTestHTTPServer(t)
+	commands := fmt.Sprintf("%s/v1/wallets/%d/commands", server.URL, accountId)
+
+	var wallet walleter.Wallet
+	doJSON(t, http.MethodPost, commands, httpserver.CommandRequest{
+		AssetType:      "erc20",
+		ActionType:     "income",
+		BusinessModule: "Testing",
+		Tokens:         map[string]float64{"FISHX": 100},
+	}, http.StatusOK, &wallet)
+	doJSON(t, http.MethodPost, commands, httpserver.CommandRequest{
+		AssetType:      "erc1155",
+		ActionType:     "income",
+		BusinessModule: "Testing",
+		Items:          []httpserver.ItemRequest{{Id: 10001, Amount: 2}},
+		Fees:           map[string]float64{"FISHX": 10},
+	}, http.StatusOK, &wallet)
+	if erc20Balance(wallet, walleter.FISHX) != 90 || wallet.ERC1155TokenData.Ids != "10001" {
+		t.Fatalf("wallet after commands %+v", wallet)
+	}
+
+	// rejected commands describe why, and leave the wallet as it was
+	var failure httpserver.ErrorResponse
+	doJSON(t, http.MethodPost, commands, httpserver.CommandRequest{
+		AssetType:      "erc20",
+		ActionType:     "withdraw",
+		BusinessModule: "Testing",
+		Tokens:         map[string]float64{"FISHX": 1000},
+	}, http.StatusUnprocessableEntity, &failure)
+	if failure.Code != string(walleter.CodeInsufficientBalance) || failure.Detail == nil || failure.Detail.Available != 90 {
+		t.Fatalf("withdraw beyond the balance answered %+v", failure)
+	}
+}
+
+func TestHTTPHeldWithdrawal(t *testing.T) {
+	db, _, accountId := newTestWalleter(t)
+	w := walleter.New(db, testFeeChargerId, walleter.WithWithdrawalPolicy(walleter.WithdrawalPolicy{
+		Thresholds: map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 100},
+	}))
+	server := httptest.NewServer(httpserver.NewServer(w))
+	t.Cleanup(server.Close)
+	commands := fmt.Sprintf("%s/v1/wallets/%d/commands", server.URL, accountId)
+
+	doJSON(t, http.MethodPost, commands, httpserver.CommandRequest{
+		AssetType:      "erc20",
+		ActionType:     "income",
+		BusinessModule: "Testing",
+		Tokens:         map[string]float64{"BUSD": 1000},
+	}, http.StatusOK, nil)
+	var held httpserver.HeldWithdrawalResponse
+	doJSON(t, http.MethodPost, commands, httpserver.CommandRequest{
+		AssetType:      "erc20",
+		ActionType:     "withdraw",
+		BusinessModule: "Testing",
+		Tokens:         map[string]float64{"BUSD": 300},
+	}, http.StatusAccepted, &held)
+	hold, err := w.GetWithdrawalHold(held.HoldId)
+	if err != nil || hold.AccountId != accountId || hold.Status != walleter.WithdrawalPending {
+		t.Fatalf("hold %d of the response %+v, %v", held.HoldId, hold, err)
+	}
+	if erc20Balance(held.Wallet, walleter.BUSD) != 700 || erc20Locked(held.Wallet, walleter.BUSD) != 300 {
+		t.Fatalf("wallet of the held withdrawal %+v", held.Wallet)
+	}
+}
+
+func TestHTTPRequestValidation(t *testing.T) {
+	server, accountId := newTestHTTPServer(t)
+	commands := fmt.Sprintf("%s/v1/wallets/%d/commands", server.URL, accountId)
+
+	cases := []struct {
+		name    string
+		request interface{}
+		field   string
+	}{
+		{"unknown asset type", httpserver.CommandRequest{AssetType: "erc721", ActionType: "income", BusinessModule: "Testing"}, "asset_type"},
+		{"missing business module", httpserver.CommandRequest{AssetType: "erc20", ActionType: "income", Tokens: map[string]float64{"BUSD": 1}}, "business_module"},
+		{"unknown token", httpserver.CommandRequest{AssetType: "erc20", ActionType: "income", BusinessModule: "Testing", Tokens: map[string]float64{"DOGE": 1}}, "tokens.DOGE"},
+		{"negative amount", httpserver.CommandRequest{AssetType: "erc20", ActionType: "income", BusinessModule: "Testing", Tokens: map[string]float64{"BUSD": -1}}, "tokens.BUSD"},
+		{"repeated item", httpserver.CommandRequest{AssetType: "erc1155", ActionType: "income", BusinessModule: "Testing",
+			Items: []httpserver.ItemRequest{{Id: 1, Amount: 1}, {Id: 1, Amount: 1}}}, "items[1]"},
+		{"erc1155 fee", httpserver.CommandRequest{AssetType: "erc1155", ActionType: "fee", BusinessModule: "Testing",
+			Items: []httpserver.ItemRequest{{Id: 1, Amount: 1}}}, "action_type"},
+		{"unknown field", map[string]interface{}{"asset_type": "erc20", "amount": 1}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var failure httpserver.ErrorResponse
+			doJSON(t, http.MethodPost, commands, c.request, http.StatusBadRequest, &failure)
+			if failure.Code != "invalid_request" {
+				t.Fatalf("answered %+v", failure)
+			}
+			if _, ok := failure.Fields[c.field]; c.field != "" && !ok {
+				t.Fatalf("answered %+v, want a reason for %s", failure, c.field)
+			}
+		})
+	}
+}
+
+func TestHTTPListLogs(t *testing.T) {
+	server, accountId := newTestHTTPServer(t)
+	commands := fmt.Sprintf("%s/v1/wallets/%d/commands", server.URL, accountId)
+	for i := 0; i < 3; i++ {
+		doJSON(t, http.MethodPost, commands, httpserver.CommandRequest{
+			AssetType:      "erc20",
+			ActionType:     "deposit",
+			BusinessModule: "Testing",
+			Source:         "bsc",
+			Tokens:         map[string]float64{"BUSD": 1},
+		}, http.StatusOK, nil)
+	}
+
+	logs := fmt.Sprintf("%s/v1/wallets/%d/logs?action_type=deposit&source=bsc&limit=2", server.URL, accountId)
+	var first, second walleter.WalletLogPage
+	doJSON(t, http.MethodGet, logs, nil, http.StatusOK, &first)
+	if len(first.Entries) != 2 || first.NextCursor == "" {
+		t.Fatalf("first page %+v", first)
+	}
+	doJSON(t, http.MethodGet, logs+"&cursor="+first.NextCursor, nil, http.StatusOK, &second)
+	if len(second.Entries) != 1 || second.Entries[0].Id >= first.Entries[1].Id {
+		t.Fatalf("second page %+v after %+v", second, first)
+	}
+
+	var failure httpserver.ErrorResponse
+	doJSON(t, http.MethodGet, logs+"&cursor=garbage", nil, http.StatusBadRequest, &failure)
+	doJSON(t, http.MethodGet, logs+"&order=sideways", nil, http.StatusBadRequest, &failure)
+	if _, ok := failure.Fields["order"]; !ok {
+		t.Fatalf("invalid order answered %+v", failure)
+	}
+}
+
+func TestHTTPOpenAPI(t *testing.T) {
+	server, _ := newTestHTTPServer(t)
+
+	var document struct {
+		OpenAPI    string                            `json:"openapi"`
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]interface{} `json:"properties"`
+				Required   []string               `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	doJSON(t, http.MethodGet, server.URL+"/openapi.json", nil, http.StatusOK, &document)
+	if document.OpenAPI == "" || document.Paths["/v1/wallets/{account_id}/commands"]["post"] == nil {
+		t.Fatalf("document %+v", document)
+	}
+	command := document.Components.Schemas["CommandRequest"]
+	if command.Properties["tokens"] == nil || len(command.Required) != 3 {
+		t.Fatalf("CommandRequest schema %+v", command)
+	}
+	if _, ok := document.Components.Schemas["Wallet"].Properties["ID"]; ok {
+		t.Fatal("swagger-ignore fields are documented")
+	}
+}
@@ -0,0 +1,46 @@
+// Package testdb opens the databases the tests and the stress harness run against.
+package testdb
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var lastDatabaseId int64
+
+// Open connects to dsn with the driver of dialect: sqlite, mysql or postgres. An empty dialect means sqlite,
+// where an empty dsn opens a new in-memory database on every call, so no server is needed.
+func Open(dialect, dsn string) (*gorm.DB, error) {
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	switch dialect {
+	case "mysql":
+		return gorm.Open(mysql.Open(dsn), config)
+	case "postgres":
+		return gorm.Open(postgres.Open(dsn), config)
+	case "", "sqlite":
+	default:
+		return nil, fmt.Errorf("unsupported dialect %q", dialect)
+	}
+
+	if dsn == "" {
+		dsn = fmt.Sprintf("file:walleter_test_db_%d?mode=memory&cache=shared", atomic.AddInt64(&lastDatabaseId, 1))
+	}
+	db, err := gorm.Open(sqlite.Open(dsn), config)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, a single connection keeps transactions from locking each other out.
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
+}
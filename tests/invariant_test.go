@@ -0,0 +1,414 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+var (
+	invariantSeed  = flag.Int64("invariant.seed", 0, "seed of the random command sequences, 0 takes one from the clock")
+	invariantSteps = flag.Int("invariant.steps", 300, "random commands issued by each invariant test")
+)
+
+const invariantUsers = 3
+
+// invariantItemIds the ERC1155 ids random commands move.
+var invariantItemIds = []uint64{10001, 10002, 10003, 10004, 10005}
+
+func TestInvariantsOnMemoryRepository(t *testing.T) {
+	repo := walleter.NewMemoryRepository()
+	runInvariants(t, walleter.NewWithRepository(repo, testFeeChargerId), repo)
+}
+
+func TestInvariantsOnDatabase(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runInvariants(t, walleter.New(db, testFeeChargerId), walleter.NewGormRepository(db))
+}
+
+// walletModel what the wallets are expected to hold, computed apart from walleter.
+type walletModel struct {
+	balances map[uint64]map[string]float64
+	items    map[uint64]map[uint64]uint64
+	// supply of every token: what came in through Income and Deposit minus what left through Withdraw.
+	// Fees and spends only move tokens between accounts, so the balances of all accounts always add up to it.
+	supply map[string]float64
+}
+
+// apply performs command on a copy of the model, returning the copy, or false when walleter must reject command.
+// Commands are all or nothing: a rejected command leaves every wallet as it was.
+func (m walletModel) apply(command walleter.WalletCommand) (walletModel, bool) {
+	next := walletModel{
+		balances: map[uint64]map[string]float64{},
+		items:    map[uint64]map[uint64]uint64{},
+		supply:   map[string]float64{},
+	}
+	for accountId, balances := range m.balances {
+		next.balances[accountId] = map[string]float64{}
+		for token, balance := range balances {
+			next.balances[accountId][token] = balance
+		}
+	}
+	for accountId, items := range m.items {
+		next.items[accountId] = map[uint64]uint64{}
+		for id, amount := range items {
+			next.items[accountId][id] = amount
+		}
+	}
+	for token, supply := range m.supply {
+		next.supply[token] = supply
+	}
+
+	balances := next.balances[command.AccountId]
+	charge := func(token walleter.ERC20Command) bool {
+		if balances[token.Token.String()] < token.Value {
+			return false
+		}
+		balances[token.Token.String()] -= token.Value
+		next.balances[testFeeChargerId][token.Token.String()] += token.Value
+		return true
+	}
+
+	for _, fee := range command.FeeCommands {
+		if fee.Value > 0 && !charge(fee) {
+			return m, false
+		}
+	}
+
+	if command.AssetType == walleter.ERC1155AssetType {
+		items := next.items[command.AccountId]
+		for index, id := range command.ERC1155Command.Ids {
+			value := command.ERC1155Command.Values[index]
+			switch command.ActionType {
+			case walleter.Income, walleter.Deposit:
+				items[id] += value
+			default:
+				if items[id] < value {
+					return m, false
+				}
+				items[id] -= value
+			}
+		}
+		return next, true
+	}
+
+	for _, token := range command.ERC20Commands {
+		symbol := token.Token.String()
+		switch command.ActionType {
+		case walleter.Income, walleter.Deposit:
+			balances[symbol] += token.Value
+			next.supply[symbol] += token.Value
+		case walleter.Withdraw:
+			if balances[symbol] < token.Value {
+				return m, false
+			}
+			balances[symbol] -= token.Value
+			next.supply[symbol] -= token.Value
+		default:
+			if !charge(token) {
+				return m, false
+			}
+		}
+	}
+	return next, true
+}
+
+// invariantRun issues random commands through w and checks the wallets and logs in repo after each of them.
+type invariantRun struct {
+	t        *testing.T
+	w        *walleter.Walleter
+	repo     walleter.Repository
+	random   *rand.Rand
+	seed     int64
+	tokens   []walleter.ERC20TokenEnum
+	accounts []uint64
+	model    walletModel
+}
+
+func runInvariants(t *testing.T, w *walleter.Walleter, repo walleter.Repository) {
+	seed := *invariantSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.Logf("seed %d, rerun with -invariant.seed=%d", seed, seed)
+
+	run := &invariantRun{
+		t:      t,
+		w:      w,
+		repo:   repo,
+		random: rand.New(rand.NewSource(seed)),
+		seed:   seed,
+		model: walletModel{
+			balances: map[uint64]map[string]float64{},
+			items:    map[uint64]map[uint64]uint64{},
+			supply:   map[string]float64{},
+		},
+	}
+	for i := 0; i < invariantUsers; i++ {
+		accountId := newTestAccountId()
+		if _, err := w.ExecuteCommand(context.Background(), walleter.NewInitWalletCommand(accountId)); err != nil {
+			t.Fatal(err)
+		}
+		run.accounts = append(run.accounts, accountId)
+	}
+
+	// the model starts from the wallets as they are, the fee charger may hold tokens of earlier runs
+	for _, accountId := range append([]uint64{testFeeChargerId}, run.accounts...) {
+		wallet := run.wallet(accountId)
+		run.model.balances[accountId] = map[string]float64{}
+		for _, token := range wallet.ERC20TokenData {
+			run.model.balances[accountId][token.Token] = token.Balance
+			run.model.supply[token.Token] += token.Balance
+		}
+		run.model.items[accountId] = parseItems(wallet.ERC1155TokenData)
+	}
+	run.tokens = walletTokens(run.wallet(run.accounts[0]))
+	if len(run.tokens) == 0 {
+		t.Fatal("initialized wallets hold no known token")
+	}
+
+	for step := 0; step < *invariantSteps; step++ {
+		run.step(step)
+	}
+}
+
+func (run *invariantRun) step(step int) {
+	command := run.randomCommand()
+	before := run.wallet(command.AccountId)
+	logCount := run.logCount(command)
+
+	expected, ok := run.model.apply(command)
+	_, err := run.w.ExecuteCommand(context.Background(), command)
+	if ok && err != nil {
+		run.fatalf(step, command, "rejected: %v", err)
+	}
+	if !ok && err == nil {
+		run.fatalf(step, command, "accepted although the wallet cannot cover it")
+	}
+	run.model = expected
+
+	after := run.wallet(command.AccountId)
+	run.checkWallets(step, command)
+	run.checkLog(step, command, logCount, before, after, err)
+}
+
+// checkWallets asserts that no balance is negative, every token row adds up, check signs are valid,
+// the wallets hold what the model expects and no token was created or lost.
+func (run *invariantRun) checkWallets(step int, command walleter.WalletCommand) {
+	total := map[string]float64{}
+	for _, accountId := range append([]uint64{testFeeChargerId}, run.accounts...) {
+		wallet := run.wallet(accountId)
+		if err := walleter.VerifyWallet(wallet); err != nil {
+			run.fatalf(step, command, "account %d: %v", accountId, err)
+		}
+		for _, token := range wallet.ERC20TokenData {
+			if token.Balance < 0 {
+				run.fatalf(step, command, "account %d: negative %s balance %v", accountId, token.Token, token.Balance)
+			}
+			totals := token.TotalIncome + token.TotalDeposit - token.TotalSpend - token.TotalWithdraw - token.TotalFee
+			if token.Balance != totals {
+				run.fatalf(step, command, "account %d: %s balance %v but totals add up to %v", accountId, token.Token, token.Balance, totals)
+			}
+			if expected := run.model.balances[accountId][token.Token]; token.Balance != expected {
+				run.fatalf(step, command, "account %d: %s balance %v, expected %v", accountId, token.Token, token.Balance, expected)
+			}
+			total[token.Token] += token.Balance
+		}
+		if items := parseItems(wallet.ERC1155TokenData); !sameItems(items, run.model.items[accountId]) {
+			run.fatalf(step, command, "account %d: items %v, expected %v", accountId, items, run.model.items[accountId])
+		}
+	}
+	for token, supply := range run.model.supply {
+		if total[token] != supply {
+			run.fatalf(step, command, "%s balances add up to %v, expected a supply of %v", token, total[token], supply)
+		}
+	}
+}
+
+// checkLog asserts that command left exactly one log, telling its outcome and the wallet before and after it.
+func (run *invariantRun) checkLog(step int, command walleter.WalletCommand, logCount int, before, after walleter.Wallet, err error) {
+	var status, actionType string
+	var failure *walleter.WalletError
+	var original, settled walleter.Wallet
+	if command.AssetType == walleter.ERC1155AssetType {
+		logs, listErr := run.repo.ListERC1155WalletLogs(command.AccountId)
+		if listErr != nil {
+			run.t.Fatal(listErr)
+		}
+		if len(logs) != logCount+1 {
+			run.fatalf(step, command, "%d new logs", len(logs)-logCount)
+		}
+		log := logs[len(logs)-1]
+		status, actionType, failure, original, settled = log.Status, log.ActionType, log.FailureDetail, log.OriginalWallet, log.SettledWallet
+	} else {
+		logs, listErr := run.repo.ListERC20WalletLogs(command.AccountId)
+		if listErr != nil {
+			run.t.Fatal(listErr)
+		}
+		if len(logs) != logCount+1 {
+			run.fatalf(step, command, "%d new logs", len(logs)-logCount)
+		}
+		log := logs[len(logs)-1]
+		status, actionType, failure, original, settled = log.Status, log.ActionType, log.FailureDetail, log.OriginalWallet, log.SettledWallet
+	}
+
+	if actionType != command.ActionType.String() {
+		run.fatalf(step, command, "log action %s", actionType)
+	}
+	if !sameHoldings(original, before) {
+		run.fatalf(step, command, "log original wallet differs from the wallet before the command")
+	}
+	if err != nil {
+		if status != walleter.Failed.String() || failure == nil || failure.Code != walleter.ErrorCodeOf(err) {
+			run.fatalf(step, command, "rejected command logged as %s with failure %+v", status, failure)
+		}
+		return
+	}
+	if status != walleter.Done.String() {
+		run.fatalf(step, command, "accepted command logged as %s", status)
+	}
+	if !sameHoldings(settled, after) || settled.CheckSign != after.CheckSign {
+		run.fatalf(step, command, "log settled wallet differs from the wallet after the command")
+	}
+}
+
+func (run *invariantRun) logCount(command walleter.WalletCommand) int {
+	if command.AssetType == walleter.ERC1155AssetType {
+		logs, err := run.repo.ListERC1155WalletLogs(command.AccountId)
+		if err != nil {
+			run.t.Fatal(err)
+		}
+		return len(logs)
+	}
+	logs, err := run.repo.ListERC20WalletLogs(command.AccountId)
+	if err != nil {
+		run.t.Fatal(err)
+	}
+	return len(logs)
+}
+
+func (run *invariantRun) randomCommand() walleter.WalletCommand {
+	accountId := run.accounts[run.random.Intn(len(run.accounts))]
+
+	// amounts are multiples of 0.25, which float64 adds up exactly
+	fees := map[walleter.ERC20TokenEnum]float64{}
+	if run.random.Intn(3) == 0 {
+		for _, token := range run.randomTokens(2) {
+			fees[token] = float64(run.random.Intn(20)+1) / 4
+		}
+	}
+
+	if run.random.Intn(3) == 0 {
+		actions := []walleter.WalletActionType{walleter.Income, walleter.Spend, walleter.Deposit, walleter.Withdraw}
+		var ids, values []uint64
+		for _, index := range run.random.Perm(len(invariantItemIds))[:run.random.Intn(3)+1] {
+			ids = append(ids, invariantItemIds[index])
+			values = append(values, uint64(run.random.Intn(5)+1))
+		}
+		return walleter.NewERC1155WalletCommand(accountId, actions[run.random.Intn(len(actions))],
+			"Invariants", walleter.InGame, ids, values, fees)
+	}
+
+	actions := []walleter.WalletActionType{walleter.Income, walleter.Spend, walleter.Deposit, walleter.Withdraw, walleter.ChargeFee}
+	tokens := map[walleter.ERC20TokenEnum]float64{}
+	for _, token := range run.randomTokens(3) {
+		tokens[token] = float64(run.random.Intn(200)+1) / 4
+	}
+	return walleter.NewERC20WalletCommand(accountId, actions[run.random.Intn(len(actions))],
+		"Invariants", walleter.InGame, tokens, fees)
+}
+
+// randomTokens returns between one and max distinct tokens.
+func (run *invariantRun) randomTokens(max int) []walleter.ERC20TokenEnum {
+	var result []walleter.ERC20TokenEnum
+	for _, index := range run.random.Perm(len(run.tokens))[:run.random.Intn(max)+1] {
+		result = append(result, run.tokens[index])
+	}
+	return result
+}
+
+func (run *invariantRun) wallet(accountId uint64) walleter.Wallet {
+	wallet, err := run.w.GetWalletByAccountId(accountId)
+	if err != nil {
+		run.t.Fatal(err)
+	}
+	return wallet
+}
+
+func (run *invariantRun) fatalf(step int, command walleter.WalletCommand, format string, args ...interface{}) {
+	run.t.Helper()
+	run.t.Fatalf("seed %d, step %d, %s %s of account %d (%+v, fees %+v, items %+v): %s",
+		run.seed, step, command.ActionType, command.AssetType, command.AccountId,
+		command.ERC20Commands, command.FeeCommands, command.ERC1155Command, fmt.Sprintf(format, args...))
+}
+
+// walletTokens the tokens of wallet which commands can name.
+func walletTokens(wallet walleter.Wallet) []walleter.ERC20TokenEnum {
+	var result []walleter.ERC20TokenEnum
+	for token := walleter.ERC20TokenEnum(0); token < 32; token++ {
+		for _, item := range wallet.ERC20TokenData {
+			if item.Token == token.String() {
+				result = append(result, token)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// parseItems the non zero amounts of the ERC1155 wallet by id.
+func parseItems(wallet walleter.ERC1155TokenWallet) map[uint64]uint64 {
+	result := map[uint64]uint64{}
+	if wallet.Ids == "" {
+		return result
+	}
+	ids := strings.Split(wallet.Ids, ",")
+	values := strings.Split(wallet.Values, ",")
+	for index := range ids {
+		id, _ := strconv.ParseUint(ids[index], 10, 64)
+		value, _ := strconv.ParseUint(values[index], 10, 64)
+		if value > 0 {
+			result[id] += value
+		}
+	}
+	return result
+}
+
+func sameItems(a, b map[uint64]uint64) bool {
+	for id, amount := range a {
+		if b[id] != amount {
+			return false
+		}
+	}
+	for id, amount := range b {
+		if amount > 0 && a[id] != amount {
+			return false
+		}
+	}
+	return true
+}
+
+// sameHoldings reports whether both wallets hold the same tokens and items.
+func sameHoldings(a, b walleter.Wallet) bool {
+	balances := func(wallet walleter.Wallet) []string {
+		var result []string
+		for _, token := range wallet.ERC20TokenData {
+			result = append(result, fmt.Sprintf("%s=%v", token.Token, token.Balance))
+		}
+		sort.Strings(result)
+		return result
+	}
+	return strings.Join(balances(a), ",") == strings.Join(balances(b), ",") &&
+		sameItems(parseItems(a.ERC1155TokenData), parseItems(b.ERC1155TokenData))
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nami-land/walleter"
+)
+
+func TestLiabilityReportSolvency(t *testing.T) {
+	db, w, userId := newTestWalleter(t)
+	otherUserId := newTestAccountId()
+	systemId := newTestAccountId()
+	for _, accountId := range []uint64{otherUserId, systemId} {
+		handleCommand(t, db, w, walleter.NewInitWalletCommand(accountId))
+	}
+
+	depositBUSD(t, db, w, userId, 100)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(userId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1}))
+	depositBUSD(t, db, w, otherUserId, 50)
+	depositBUSD(t, db, w, systemId, 1000)
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(userId, walleter.Income, "Testing", walleter.InGame,
+		[]uint64{7}, []uint64{2}, nil))
+	handleCommand(t, db, w, walleter.NewERC1155WalletCommand(systemId, walleter.Income, "Testing", walleter.InGame,
+		[]uint64{7}, []uint64{5}, nil))
+
+	report, err := w.GetLiabilityReport([]uint64{systemId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var busd walleter.TokenLiability
+	for _, item := range report.ERC20 {
+		if item.Token == walleter.BUSD.String() {
+			busd = item
+		}
+	}
+	if busd.Users != 139 || busd.FeeCharger != 1 || busd.System != 1000 || busd.Total != 1140 {
+		t.Fatalf("BUSD liability %+v", busd)
+	}
+	if len(report.ERC1155) != 1 || report.ERC1155[0].Users != 2 || report.ERC1155[0].System != 5 || report.ERC1155[0].Total != 7 {
+		t.Fatalf("item liabilities %+v", report.ERC1155)
+	}
+
+	// custody only has to cover what users own, not the balances of the game's own accounts
+	check := report.CheckSolvency(walleter.CustodyBalances{
+		ERC20:   map[string]float64{walleter.BUSD.String(): 139, "ETH": 3},
+		ERC1155: map[uint64]uint64{7: 2},
+	})
+	if !check.Solvent {
+		t.Fatalf("check %+v", check)
+	}
+	for _, item := range check.ERC20 {
+		switch item.Token {
+		case walleter.BUSD.String():
+			if item.Owed != 139 || item.Ledger != 1140 || item.Shortfall != 0 || !item.Solvent {
+				t.Fatalf("BUSD solvency %+v", item)
+			}
+		case "ETH":
+			if item.Owed != 0 || item.Custody != 3 || !item.Solvent {
+				t.Fatalf("solvency of a token only in custody %+v", item)
+			}
+		}
+	}
+
+	check = report.CheckSolvency(walleter.CustodyBalances{
+		ERC20:   map[string]float64{walleter.BUSD.String(): 138.5},
+		ERC1155: map[uint64]uint64{7: 1},
+	})
+	if check.Solvent {
+		t.Fatalf("custody below the user balances is solvent %+v", check)
+	}
+	for _, item := range check.ERC20 {
+		if item.Token == walleter.BUSD.String() && (item.Shortfall != 0.5 || item.Solvent) {
+			t.Fatalf("BUSD solvency %+v", item)
+		}
+	}
+	if item := check.ERC1155[0]; item.Shortfall != 1 || item.Solvent {
+		t.Fatalf("item solvency %+v", item)
+	}
+
+	// without system accounts their balances are owed like any other
+	report, err = w.GetLiabilityReport(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check := report.CheckSolvency(walleter.CustodyBalances{ERC20: map[string]float64{walleter.BUSD.String(): 139}}); check.Solvent {
+		t.Fatalf("system balances left out without system accounts %+v", check)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build go1.21
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/nami-land/walleter"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	accountId := runLoggedCommands(t, walleter.NewSlogLogger(logger))
+
+	var entries []loggedEntry
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		fields := map[string]interface{}{}
+		decoder := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&fields); err != nil {
+			t.Fatal(err)
+		}
+		level := strings.ToLower(fields["level"].(string))
+		if level == "warn" {
+			level = "warning"
+		}
+		entries = append(entries, loggedEntry{level: level, message: fields["msg"].(string), fields: fields})
+	}
+	checkCommandEntries(t, accountId, entries)
+}
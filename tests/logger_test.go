@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nami-land/walleter"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// loggedEntry one log event as seen by the logging library behind a walleter.Logger adapter.
+type loggedEntry struct {
+	level   string
+	message string
+	fields  map[string]interface{}
+}
+
+// runLoggedCommands runs a deposit and a rejected withdrawal through a walleter logging to logger.
+func runLoggedCommands(t *testing.T, logger walleter.Logger) uint64 {
+	t.Helper()
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := walleter.New(db, testFeeChargerId, walleter.WithLogger(logger))
+	accountId := newTestAccountId()
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewInitWalletCommand(accountId)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 25}, nil)); err == nil {
+		t.Fatal("withdrawing more than the balance succeeded")
+	}
+	return accountId
+}
+
+// checkCommandEntries checks the events logged for the commands of runLoggedCommands.
+func checkCommandEntries(t *testing.T, accountId uint64, entries []loggedEntry) {
+	t.Helper()
+	var finished, rejected *loggedEntry
+	for index, entry := range entries {
+		if fmt.Sprint(entry.fields["account_id"]) != fmt.Sprint(accountId) {
+			continue
+		}
+		switch {
+		case entry.message == "wallet command finished" && entry.fields["action_type"] == walleter.Deposit.String():
+			finished = &entries[index]
+		case entry.message == "wallet command rejected":
+			rejected = &entries[index]
+		}
+	}
+	if finished == nil || finished.level != "info" || finished.fields["asset_type"] != walleter.AssetType(walleter.ERC20AssetType).String() ||
+		finished.fields["business_module"] != "Testing" || finished.fields["source"] != walleter.BSC.String() || finished.fields["elapsed"] == nil {
+		t.Fatalf("finished command logged as %+v", finished)
+	}
+	if rejected == nil || rejected.level != "warning" || rejected.fields["action_type"] != walleter.Withdraw.String() ||
+		rejected.fields["code"] != string(walleter.CodeInsufficientBalance) || rejected.fields["error"] == nil {
+		t.Fatalf("rejected command logged as %+v", rejected)
+	}
+}
+
+func TestLogrusLogger(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	accountId := runLoggedCommands(t, walleter.NewLogrusLogger(logger))
+
+	var entries []loggedEntry
+	for _, entry := range hook.AllEntries() {
+		entries = append(entries, loggedEntry{level: entry.Level.String(), message: entry.Message, fields: entry.Data})
+	}
+	checkCommandEntries(t, accountId, entries)
+
+	// keys without value or of another type than string are kept
+	hook.Reset()
+	walleter.NewLogrusLogger(logger).Error("odd keyvals", "account_id", 7, 8, "first", "last")
+	data := hook.LastEntry().Data
+	if hook.LastEntry().Level != logrus.ErrorLevel || data["account_id"] != 7 || data["!badkey"] != "first" || data["last"] != "!missing" {
+		t.Fatalf("odd keyvals logged as %+v", data)
+	}
+}
+
+func TestZapLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	accountId := runLoggedCommands(t, walleter.NewZapLogger(zap.New(core)))
+
+	var entries []loggedEntry
+	for _, entry := range logs.All() {
+		level := entry.Level.String()
+		if entry.Level == zapcore.WarnLevel {
+			level = "warning"
+		}
+		entries = append(entries, loggedEntry{level: level, message: entry.Message, fields: entry.ContextMap()})
+	}
+	checkCommandEntries(t, accountId, entries)
+}
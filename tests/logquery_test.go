@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+// listAllLogs follows the cursors of query page by page and returns every entry.
+func listAllLogs(t *testing.T, w *walleter.Walleter, query walleter.LogQuery) []walleter.WalletLogEntry {
+	t.Helper()
+	var result []walleter.WalletLogEntry
+	for pages := 0; ; pages++ {
+		if pages > 100 {
+			t.Fatal("cursor does not advance")
+		}
+		page, err := w.ListWalletLogs(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Entries) > query.Limit {
+			t.Fatalf("page of %d entries with limit %d", len(page.Entries), query.Limit)
+		}
+		result = append(result, page.Entries...)
+		if page.NextCursor == "" {
+			return result
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+func TestListWalletLogsPagination(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	// erc20 and erc1155 logs interleaved in time
+	for round := 0; round < 3; round++ {
+		depositBUSD(t, db, w, accountId, 10)
+		time.Sleep(2 * time.Millisecond)
+		handleCommand(t, db, w, walleter.NewERC1155WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+			[]uint64{7}, []uint64{1}, nil))
+		time.Sleep(2 * time.Millisecond)
+		handleCommand(t, db, w, walleter.NewERC1155WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+			[]uint64{8}, []uint64{1}, nil))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	for _, ascending := range []bool{true, false} {
+		query := walleter.LogQuery{AccountId: accountId, Statuses: []walleter.WalletLogStatus{walleter.Done}, Ascending: ascending}
+		query.Limit = 100
+		all := listAllLogs(t, w, query)
+		// both initialization logs and nine commands
+		if len(all) != 11 {
+			t.Fatalf("ascending %v: %d logs", ascending, len(all))
+		}
+		for index := 1; index < len(all); index++ {
+			previous, current := all[index-1].CreatedAt, all[index].CreatedAt
+			if (ascending && current.Before(previous)) || (!ascending && current.After(previous)) {
+				t.Fatalf("ascending %v: log %d out of order", ascending, index)
+			}
+		}
+
+		for _, limit := range []int{1, 2, 4} {
+			query.Limit = limit
+			paged := listAllLogs(t, w, query)
+			if len(paged) != len(all) {
+				t.Fatalf("ascending %v, limit %d: %d logs instead of %d", ascending, limit, len(paged), len(all))
+			}
+			for index := range paged {
+				if paged[index].AssetType != all[index].AssetType || paged[index].Id != all[index].Id {
+					t.Fatalf("ascending %v, limit %d: log %d is %v %d instead of %v %d", ascending, limit, index,
+						paged[index].AssetType, paged[index].Id, all[index].AssetType, all[index].Id)
+				}
+			}
+		}
+	}
+
+	// filters keep applying across pages
+	items := listAllLogs(t, w, walleter.LogQuery{AccountId: accountId, TokenIds: []uint64{8}, Limit: 2, Ascending: true})
+	if len(items) != 3 {
+		t.Fatalf("%d logs of item 8", len(items))
+	}
+	for _, entry := range items {
+		if entry.AssetType != walleter.ERC1155AssetType || len(entry.Items) != 1 || entry.Items[0].Id != 8 {
+			t.Fatalf("entry %+v", entry)
+		}
+	}
+	tokens := listAllLogs(t, w, walleter.LogQuery{AccountId: accountId, Tokens: []walleter.ERC20TokenEnum{walleter.BUSD}, Limit: 2})
+	// the initialization and the three deposits
+	if len(tokens) != 4 {
+		t.Fatalf("%d logs of BUSD", len(tokens))
+	}
+
+	if _, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, Cursor: "not a cursor"}); !errors.Is(err, walleter.ErrInvalidCursor) {
+		t.Fatalf("invalid cursor returned %v", err)
+	}
+}
@@ -3,23 +3,13 @@ package main
 import (
 	"fmt"
 
-	"github.com/neco-fun/walleter"
+	"github.com/nami-land/walleter"
 	"github.com/sirupsen/logrus"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
 
 func main() {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		"root",
-		"root123..",
-		"localhost",
-		"3306",
-		"walleter_test_db",
-	)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := openDatabase()
 	if err != nil {
 		panic("connect database failed")
 	}
@@ -45,14 +35,14 @@ func main() {
 	// Testing income operation
 	//err = db.Transaction(func(tx *gorm.DB) error {
 	//	_, err := w.HandleWalletCommand(
-	//		db,
+	//		tx,
 	//		walleter.NewERC20WalletCommand(
 	//			userId,
 	//			walleter.Income,
 	//			"Testing",
 	//			walleter.InGame,
 	//			map[walleter.ERC20TokenEnum]float64{
-	//				walleter.NFISH: 100.0,
+	//				walleter.FISHX: 100.0,
 	//				walleter.BUSD:  5,
 	//			},
 	//			map[walleter.ERC20TokenEnum]float64{},
@@ -73,14 +63,14 @@ func main() {
 	// Testing spend operation
 	//err = db.Transaction(func(tx *gorm.DB) error {
 	//	_, err := w.HandleWalletCommand(
-	//		db,
+	//		tx,
 	//		walleter.NewERC20WalletCommand(
 	//			userId,
 	//			walleter.Spend,
 	//			"Testing",
 	//			walleter.InGame,
 	//			map[walleter.ERC20TokenEnum]float64{
-	//				walleter.NFISH: 100.0,
+	//				walleter.FISHX: 100.0,
 	//				walleter.BUSD:  5,
 	//			},
 	//			map[walleter.ERC20TokenEnum]float64{},
@@ -101,14 +91,14 @@ func main() {
 	// Testing deposit operation
 	//err = db.Transaction(func(tx *gorm.DB) error {
 	//	_, err := w.HandleWalletCommand(
-	//		db,
+	//		tx,
 	//		walleter.NewERC20WalletCommand(
 	//			userId,
 	//			walleter.Deposit,
 	//			"Testing",
 	//			walleter.BSC,
 	//			map[walleter.ERC20TokenEnum]float64{
-	//				walleter.NFISH: 100.0,
+	//				walleter.FISHX: 100.0,
 	//				walleter.BUSD:  5,
 	//			},
 	//			map[walleter.ERC20TokenEnum]float64{},
@@ -129,18 +119,18 @@ func main() {
 	// Testing withdraw operation
 	//err = db.Transaction(func(tx *gorm.DB) error {
 	//	_, err := w.HandleWalletCommand(
-	//		db,
+	//		tx,
 	//		walleter.NewERC20WalletCommand(
 	//			userId,
 	//			walleter.Withdraw,
 	//			"Testing",
 	//			walleter.BSC,
 	//			map[walleter.ERC20TokenEnum]float64{
-	//				walleter.NFISH: 90.0,
+	//				walleter.FISHX: 90.0,
 	//				walleter.BUSD:  4,
 	//			},
 	//			map[walleter.ERC20TokenEnum]float64{
-	//				walleter.NFISH: 10.0,
+	//				walleter.FISHX: 10.0,
 	//				walleter.BUSD:  1,
 	//			},
 	//		),
@@ -160,14 +150,14 @@ func main() {
 	// Testing income operation
 	err = db.Transaction(func(tx *gorm.DB) error {
 		_, err := w.HandleWalletCommand(
-			db,
+			tx,
 			walleter.NewERC20WalletCommand(
 				userId,
 				walleter.Income,
 				"Testing",
 				walleter.InGame,
 				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 10.0,
+					walleter.FISHX: 10.0,
 					walleter.BUSD:  5.0,
 				},
 				map[walleter.ERC20TokenEnum]float64{},
@@ -182,14 +172,14 @@ func main() {
 	//// Testing charge fee operation
 	//err = db.Transaction(func(tx *gorm.DB) error {
 	//	_, err := w.HandleWalletCommand(
-	//		db,
+	//		tx,
 	//		walleter.NewERC20WalletCommand(
 	//			userId,
 	//			walleter.ChargeFee,
 	//			"Testing",
 	//			walleter.InGame,
 	//			map[walleter.ERC20TokenEnum]float64{
-	//				walleter.NFISH: 10.0,
+	//				walleter.FISHX: 10.0,
 	//				walleter.BUSD:  5.0,
 	//			},
 	//			map[walleter.ERC20TokenEnum]float64{},
@@ -204,7 +194,7 @@ func main() {
 	// Testing erc1155 income operation
 	err = db.Transaction(func(tx *gorm.DB) error {
 		_, err := w.HandleWalletCommand(
-			db,
+			tx,
 			walleter.NewERC1155WalletCommand(
 				userId,
 				walleter.Income,
@@ -224,7 +214,7 @@ func main() {
 	// Testing erc1155 spend operation
 	err = db.Transaction(func(tx *gorm.DB) error {
 		_, err := w.HandleWalletCommand(
-			db,
+			tx,
 			walleter.NewERC1155WalletCommand(
 				userId,
 				walleter.Spend,
@@ -233,7 +223,7 @@ func main() {
 				[]uint64{10001, 10002, 10003},
 				[]uint64{1, 2, 3},
 				map[walleter.ERC20TokenEnum]float64{
-					walleter.NFISH: 10.0,
+					walleter.FISHX: 10.0,
 					walleter.BUSD:  5.0,
 				},
 			),
@@ -247,7 +237,7 @@ func main() {
 	// Testing erc1155 income operation
 	err = db.Transaction(func(tx *gorm.DB) error {
 		_, err := w.HandleWalletCommand(
-			db,
+			tx,
 			walleter.NewERC1155WalletCommand(
 				userId,
 				walleter.Deposit,
@@ -267,7 +257,7 @@ func main() {
 	// Testing erc1155 income operation
 	err = db.Transaction(func(tx *gorm.DB) error {
 		_, err := w.HandleWalletCommand(
-			db,
+			tx,
 			walleter.NewERC1155WalletCommand(
 				userId,
 				walleter.Withdraw,
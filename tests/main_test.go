@@ -0,0 +1,74 @@
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+const testFeeChargerId uint64 = 1
+
+// lastAccountId starts from the clock, so runs against a persistent database never meet wallets of earlier runs.
+var lastAccountId = uint64(time.Now().UnixNano() / int64(time.Millisecond) * 1000)
+
+// newTestAccountId returns an account id no other test uses.
+func newTestAccountId() uint64 {
+	return atomic.AddUint64(&lastAccountId, 1)
+}
+
+// newTestWalleter opens a database of its own for t, creates a walleter on it and initializes
+// the wallet of a new account, which is returned with them.
+func newTestWalleter(t *testing.T) (*gorm.DB, *walleter.Walleter, uint64) {
+	t.Helper()
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	w := walleter.New(db, testFeeChargerId)
+	accountId := newTestAccountId()
+	handleCommand(t, db, w, walleter.NewInitWalletCommand(accountId))
+	return db, w, accountId
+}
+
+// handleCommand handles command in a transaction, failing t when it is rejected.
+func handleCommand(t *testing.T, db *gorm.DB, w *walleter.Walleter, command walleter.WalletCommand) walleter.Wallet {
+	t.Helper()
+	var wallet walleter.Wallet
+	err := db.Transaction(func(tx *gorm.DB) (err error) {
+		wallet, err = w.HandleWalletCommand(tx, command)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wallet
+}
+
+// getWallet loads the wallet of accountId, failing t when it cannot.
+func getWallet(t *testing.T, w *walleter.Walleter, accountId uint64) walleter.Wallet {
+	t.Helper()
+	wallet, err := w.GetWalletByAccountId(accountId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wallet
+}
+
+// erc20Balance returns the balance of token in wallet.
+func erc20Balance(wallet walleter.Wallet, token walleter.ERC20TokenEnum) float64 {
+	for _, item := range wallet.ERC20TokenData {
+		if item.Token == token.String() {
+			return item.Balance
+		}
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+func TestReadsOnMemoryRepository(t *testing.T) {
+	w := walleter.NewWithRepository(walleter.NewMemoryRepository(), testFeeChargerId)
+	ctx := context.Background()
+	accountId := newTestAccountId()
+	execute := func(command walleter.WalletCommand) {
+		t.Helper()
+		if _, err := w.ExecuteCommand(ctx, command); err != nil {
+			t.Fatal(err)
+		}
+	}
+	execute(walleter.NewInitWalletCommand(accountId))
+	beforeDeposit := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	execute(walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 100}, nil))
+	execute(walleter.NewERC20WalletCommand(accountId, walleter.Spend, "Shop", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 40}, nil))
+	execute(walleter.NewERC1155WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+		[]uint64{7}, []uint64{3}, nil))
+
+	snapshot, err := w.GetWalletAt(accountId, time.Now())
+	if err != nil || snapshot.ERC20Balances[walleter.BUSD.String()] != 60 || snapshot.ERC1155Balances[7] != 3 {
+		t.Fatalf("current snapshot %+v, %v", snapshot, err)
+	}
+	if snapshot, err = w.GetWalletAt(accountId, beforeDeposit); err != nil || snapshot.ERC20Balances[walleter.BUSD.String()] != 0 {
+		t.Fatalf("snapshot before the deposit %+v, %v", snapshot, err)
+	}
+	history, err := w.GetERC20BalanceHistory(accountId, walleter.BUSD, beforeDeposit, time.Now())
+	if err != nil || len(history) != 2 || history[1].Balance != 60 {
+		t.Fatalf("balance history %+v, %v", history, err)
+	}
+
+	// five logs: the initialization of both tables, three commands
+	page, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, Statuses: []walleter.WalletLogStatus{walleter.Done}, Limit: 3})
+	if err != nil || len(page.Entries) != 3 || page.NextCursor == "" || page.Entries[0].AssetType != walleter.ERC1155AssetType {
+		t.Fatalf("first page %+v, %v", page, err)
+	}
+	page, err = w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, Statuses: []walleter.WalletLogStatus{walleter.Done},
+		Limit: 3, Cursor: page.NextCursor})
+	if err != nil || len(page.Entries) != 2 || page.NextCursor != "" || page.Entries[1].ActionType != walleter.Initialize.String() {
+		t.Fatalf("second page %+v, %v", page, err)
+	}
+	page, err = w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, Tokens: []walleter.ERC20TokenEnum{walleter.BUSD},
+		ActionTypes: []walleter.WalletActionType{walleter.Deposit, walleter.Spend}})
+	if err != nil || len(page.Entries) != 2 || page.Entries[0].ActionType != walleter.Spend.String() {
+		t.Fatalf("logs of BUSD %+v, %v", page, err)
+	}
+
+	var out bytes.Buffer
+	if err := w.ExportStatement(&out, accountId, beforeDeposit, time.Now(), walleter.CSVStatement); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines < 4 {
+		t.Fatalf("statement has %d lines:\n%s", lines, out.String())
+	}
+
+	report, err := w.Reconcile(nil)
+	if err != nil || report.Wallets != 2 || len(report.Issues) != 0 {
+		t.Fatalf("reconciliation %+v, %v", report, err)
+	}
+	liabilities, err := w.GetLiabilityReport(nil)
+	if err != nil || len(liabilities.ERC20) == 0 || len(liabilities.ERC1155) != 1 || liabilities.ERC1155[0].Users != 3 {
+		t.Fatalf("liabilities %+v, %v", liabilities, err)
+	}
+
+	// workers reading the database directly refuse to run
+	if _, err := w.RecoverStaleLogs(time.Minute); !errors.Is(err, walleter.ErrDatabaseRequired) {
+		t.Fatalf("recovery returned %v", err)
+	}
+	if err := w.RefreshEconomyAggregates(); !errors.Is(err, walleter.ErrDatabaseRequired) {
+		t.Fatalf("economy refresh returned %v", err)
+	}
+	if _, err := w.NewEventDispatcher(walleter.EventDispatcherConfig{}).DispatchOnce(ctx); !errors.Is(err, walleter.ErrDatabaseRequired) {
+		t.Fatalf("dispatch returned %v", err)
+	}
+	if _, err := w.NewWebhookService(walleter.WebhookConfig{}).ListEndpoints(); !errors.Is(err, walleter.ErrDatabaseRequired) {
+		t.Fatalf("listing webhook endpoints returned %v", err)
+	}
+}
+
+func TestMemoryRepositoryLogsAreCopies(t *testing.T) {
+	repo := walleter.NewMemoryRepository()
+	w := walleter.NewWithRepository(repo, testFeeChargerId)
+	accountId := newTestAccountId()
+	for _, command := range []walleter.WalletCommand{
+		walleter.NewInitWalletCommand(accountId),
+		walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+			map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 100}, nil),
+		walleter.NewERC1155WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC, []uint64{7}, []uint64{3}, nil),
+	} {
+		if _, err := w.ExecuteCommand(context.Background(), command); err != nil {
+			t.Fatal(err)
+		}
+	}
+	erc20Logs, err := repo.ListERC20WalletLogs(accountId)
+	if err != nil || len(erc20Logs) == 0 {
+		t.Fatalf("erc20 logs %+v, %v", erc20Logs, err)
+	}
+	erc1155Logs, err := repo.ListERC1155WalletLogs(accountId)
+	if err != nil || len(erc1155Logs) == 0 {
+		t.Fatalf("erc1155 logs %+v, %v", erc1155Logs, err)
+	}
+	erc20Id, erc1155Id := erc20Logs[len(erc20Logs)-1].ID, erc1155Logs[len(erc1155Logs)-1].ID
+
+	// changing a log read from the repository leaves the stored one alone
+	erc20Log, err := repo.GetERC20WalletLog(erc20Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance := erc20Log.SettledWallet.ERC20TokenData[0].Balance
+	erc20Log.SettledWallet.ERC20TokenData[0].Balance += 1000
+	if erc20Log, err = repo.GetERC20WalletLog(erc20Id); err != nil || erc20Log.SettledWallet.ERC20TokenData[0].Balance != balance {
+		t.Fatalf("stored erc20 log changed %+v, %v", erc20Log, err)
+	}
+	erc1155Log, err := repo.GetERC1155WalletLog(erc1155Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance = erc1155Log.SettledWallet.ERC20TokenData[0].Balance
+	erc1155Log.SettledWallet.ERC20TokenData[0].Balance += 1000
+	if erc1155Log, err = repo.GetERC1155WalletLog(erc1155Id); err != nil || erc1155Log.SettledWallet.ERC20TokenData[0].Balance != balance {
+		t.Fatalf("stored erc1155 log changed %+v, %v", erc1155Log, err)
+	}
+}
+
+// coreRepository exposes only the Repository interface of the memory repository, none of its feature stores.
+type coreRepository struct {
+	walleter.Repository
+}
+
+func (r coreRepository) WithContext(ctx context.Context) walleter.Repository {
+	return coreRepository{r.Repository.WithContext(ctx)}
+}
+
+func (r coreRepository) Transaction(fn func(tx walleter.Repository) error) error {
+	return r.Repository.Transaction(func(tx walleter.Repository) error {
+		return fn(coreRepository{tx})
+	})
+}
+
+func TestRepositoryWithoutStores(t *testing.T) {
+	w := walleter.NewWithRepository(coreRepository{walleter.NewMemoryRepository()}, testFeeChargerId)
+	ctx := context.Background()
+	accountId := newTestAccountId()
+	if _, err := w.ExecuteCommand(ctx, walleter.NewInitWalletCommand(accountId)); err != nil {
+		t.Fatal(err)
+	}
+	wallet, err := w.ExecuteCommand(ctx, walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 100}, nil))
+	if err != nil || erc20Balance(wallet, walleter.BUSD) != 100 {
+		t.Fatalf("deposit %+v, %v", wallet, err)
+	}
+
+	if _, err := w.GetWalletAt(accountId, time.Now()); !errors.Is(err, walleter.ErrStoreNotSupported) {
+		t.Fatalf("wallet snapshot without balance history store: %v", err)
+	}
+	if _, err := w.ListAuditLogs(accountId); !errors.Is(err, walleter.ErrStoreNotSupported) {
+		t.Fatalf("audit logs without admin store: %v", err)
+	}
+	if _, err := w.FreezeAccount(ctx, accountId, "ops", "testing"); !errors.Is(err, walleter.ErrStoreNotSupported) {
+		t.Fatalf("freeze without admin store: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nami-land/walleter"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// gathered sums the counts of the samples of the metric family name in registry whose labels include labels,
+// histograms count their observations.
+func gathered(t *testing.T, registry *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0.0
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	samples:
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if value, ok := labels[pair.GetName()]; ok && value != pair.GetValue() {
+					continue samples
+				}
+			}
+			switch {
+			case metric.GetCounter() != nil:
+				total += metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				total += metric.GetGauge().GetValue()
+			case metric.GetHistogram() != nil:
+				total += float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return total
+}
+
+func newMeteredWalleter(t *testing.T) (*gorm.DB, *walleter.Walleter, *prometheus.Registry) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := walleter.NewMetrics("")
+	registry := prometheus.NewRegistry()
+	if err := metrics.Register(registry); err != nil {
+		t.Fatal(err)
+	}
+	return db, walleter.New(db, testFeeChargerId, walleter.WithMetrics(metrics)), registry
+}
+
+func TestMetrics(t *testing.T) {
+	db, w, registry := newMeteredWalleter(t)
+	accountId := newTestAccountId()
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewInitWalletCommand(accountId)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 25}, nil)); err == nil {
+		t.Fatal("withdrawing more than the balance succeeded")
+	}
+
+	deposit := map[string]string{"action_type": walleter.Deposit.String(), "status": walleter.Done.String()}
+	if count := gathered(t, registry, "walleter_commands_total", deposit); count != 1 {
+		t.Fatalf("deposits counted %v", count)
+	}
+	if volume := gathered(t, registry, "walleter_token_volume_total", map[string]string{"token": walleter.BUSD.String(), "action_type": walleter.Deposit.String()}); volume != 10 {
+		t.Fatalf("token volume %v", volume)
+	}
+	// spends, fee charges and the fees of commands are all credited to the fee charger
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.Spend, "Shop", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 3}, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ExecuteCommand(context.Background(), walleter.NewERC20WalletCommand(accountId, walleter.ChargeFee, "Shop", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 2}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if fees := gathered(t, registry, "walleter_fees_collected_total", map[string]string{"token": walleter.BUSD.String()}); fees != 6 {
+		t.Fatalf("fees collected %v", fees)
+	}
+	rejected := map[string]string{"action_type": walleter.Withdraw.String(), "code": string(walleter.CodeInsufficientBalance)}
+	if count := gathered(t, registry, "walleter_command_errors_total", rejected); count != 1 {
+		t.Fatalf("rejected withdrawals counted %v", count)
+	}
+	// commands failing to resolve their deposit address are counted as well
+	byAddress := walleter.NewERC20WalletCommand(0, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 5}, nil)
+	byAddress.DepositAddress = testDepositAddress
+	if _, err := w.ExecuteCommand(context.Background(), byAddress); !errors.Is(err, walleter.ErrUnknownDepositAddress) {
+		t.Fatalf("deposit to an unknown address returned %v", err)
+	}
+	unresolved := map[string]string{"action_type": walleter.Deposit.String(), "code": string(walleter.CodeUnknownDepositAddress)}
+	if count := gathered(t, registry, "walleter_command_errors_total", unresolved); count != 1 {
+		t.Fatalf("deposits to unknown addresses counted %v", count)
+	}
+	if pending := gathered(t, registry, "walleter_pending_logs", nil); pending != 0 {
+		t.Fatalf("pending logs %v", pending)
+	}
+	if events := gathered(t, registry, "walleter_outbox_events", map[string]string{"status": walleter.OutboxPending.String()}); events == 0 {
+		t.Fatal("undelivered outbox events not reported")
+	}
+
+	// queries of the host on the same db are not measured
+	queries := gathered(t, registry, "walleter_db_duration_seconds", nil)
+	if queries == 0 {
+		t.Fatal("database calls of walleter not measured")
+	}
+	var count int64
+	if err := db.Table("wallets").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("SELECT 1").Error; err != nil {
+		t.Fatal(err)
+	}
+	if after := gathered(t, registry, "walleter_db_duration_seconds", nil); after != queries {
+		t.Fatalf("host queries measured, %v database calls instead of %v", after, queries)
+	}
+}
+
+func TestMetricsNotConfigured(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walleter.New(db, testFeeChargerId)
+	if _, ok := db.Config.Plugins["walleter:metrics"]; ok {
+		t.Fatal("metrics plugin registered without metrics")
+	}
+}
+
+// namedPlugin a plugin of the host taking the name of a plugin of walleter.
+type namedPlugin struct {
+	name string
+}
+
+func (p namedPlugin) Name() string {
+	return p.name
+}
+
+func (p namedPlugin) Initialize(*gorm.DB) error {
+	return nil
+}
+
+func TestMetricsPluginNameTaken(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(namedPlugin{name: "walleter:metrics"}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		recovered := recover()
+		message, _ := recovered.(string)
+		if !strings.Contains(message, gorm.ErrRegistered.Error()) {
+			t.Fatalf("New recovered %v", recovered)
+		}
+	}()
+	walleter.New(db, testFeeChargerId, walleter.WithMetrics(walleter.NewMetrics("")))
+}
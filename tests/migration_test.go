@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+func TestMigrate(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := walleter.Migrate(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+	applied, err := walleter.AppliedMigrations(db)
+	if err != nil || len(applied) != len(walleter.Migrations()) {
+		t.Fatalf("applied %+v, %v", applied, err)
+	}
+	for index, migration := range walleter.Migrations() {
+		if applied[index].Version != migration.Version || applied[index].Name != migration.Name {
+			t.Fatalf("applied %+v instead of migration %d %s", applied[index], migration.Version, migration.Name)
+		}
+	}
+	if pending, err := walleter.PendingMigrations(db); err != nil || len(pending) != 0 {
+		t.Fatalf("pending %+v, %v", pending, err)
+	}
+	for _, table := range []string{"erc20_wallet_logs", "erc1155_wallet_logs"} {
+		if !db.Migrator().HasIndex(table, "idx_"+table+"_status") {
+			t.Fatalf("%s has no status index", table)
+		}
+	}
+	if err := walleter.MigrateTo(db, walleter.LatestSchemaVersion()+1); err == nil {
+		t.Fatal("migrating to an unknown version succeeded")
+	}
+}
+
+func TestMigrateRoundTrip(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 25}, nil))
+
+	// every version only has the columns it was released with
+	if err := walleter.MigrateTo(db, 2); err != nil {
+		t.Fatal(err)
+	}
+	if db.Migrator().HasColumn("erc20_token_wallets", "locked") {
+		t.Fatal("version 2 has the locked column of version 9")
+	}
+	for _, column := range []string{"approval", "chain_ref"} {
+		if db.Migrator().HasColumn("erc20_wallet_logs", column) || db.Migrator().HasColumn("erc1155_wallet_logs", column) {
+			t.Fatalf("version 2 has the %s column of a later version", column)
+		}
+	}
+	if db.Migrator().HasTable("outbox_events") {
+		t.Fatal("version 2 has the outbox of version 5")
+	}
+	if pending, err := walleter.PendingMigrations(db); err != nil || len(pending) != len(walleter.Migrations())-2 {
+		t.Fatalf("pending %d, %v", len(pending), err)
+	}
+
+	// wallets survive reverting and reapplying the later migrations
+	if err := walleter.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if balance := erc20Balance(getWallet(t, w, accountId), walleter.BUSD); balance != 25 {
+		t.Fatalf("balance after the round trip %v", balance)
+	}
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Spend, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 5}, nil))
+
+	// every step down and back up
+	for version := walleter.LatestSchemaVersion(); version > 0; version-- {
+		if err := walleter.MigrateTo(db, version-1); err != nil {
+			t.Fatal(err)
+		}
+		if err := walleter.MigrateTo(db, version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := walleter.MigrateTo(db, 0); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		if table != "schema_version" && table != "sqlite_sequence" {
+			t.Fatalf("table %s left after reverting every migration", table)
+		}
+	}
+	if err := walleter.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentMigrate(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// instances booting at the same time all succeed, each migration is applied once
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for index := range errs {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			errs[index] = walleter.Migrate(db.Session(&gorm.Session{}))
+		}(index)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if applied, err := walleter.AppliedMigrations(db); err != nil || len(applied) != len(walleter.Migrations()) {
+		t.Fatalf("applied %+v, %v", applied, err)
+	}
+}
+
+func TestMigrationInspectionIsReadOnly(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := walleter.AppliedMigrations(db); err != nil || len(applied) != 0 {
+		t.Fatalf("applied %+v, %v on an empty database", applied, err)
+	}
+	if pending, err := walleter.PendingMigrations(db); err != nil || len(pending) != len(walleter.Migrations()) {
+		t.Fatalf("pending %d, %v on an empty database", len(pending), err)
+	}
+	if db.Migrator().HasTable("schema_version") {
+		t.Fatal("inspecting the migrations created schema_version")
+	}
+}
+
+func TestMigrationFilesOfEveryDialect(t *testing.T) {
+	for _, dialect := range []string{"mysql", "postgres", "sqlite"} {
+		for _, migration := range walleter.Migrations() {
+			for _, direction := range []string{"up", "down"} {
+				name := fmt.Sprintf("%04d_%s.%s.sql", migration.Version, migration.Name, direction)
+				if _, err := os.Stat(filepath.Join("..", "migrations", dialect, name)); err != nil {
+					t.Errorf("%s migration %d: %v", dialect, migration.Version, err)
+				}
+			}
+		}
+	}
+}
+
+// baselineERC20WalletLog the wallet log of the releases which created their tables with AutoMigrate.
+type baselineERC20WalletLog struct {
+	gorm.Model
+	AccountId      uint64
+	BusinessModule string `gorm:"type:varchar(64);not null;"`
+	ActionType     string `gorm:"type:varchar(64);not null;"`
+	Source         string `gorm:"type:varchar(20)"`
+	Tokens         string `gorm:"type:text;not null"`
+	Fees           string `gorm:"type:text;"`
+	Status         string `gorm:"type:varchar(64);not null;"`
+	OriginalWallet string `gorm:"type:text;not null;"`
+	SettledWallet  string `gorm:"type:text;not null;"`
+}
+
+func (baselineERC20WalletLog) TableName() string { return "erc20_wallet_logs" }
+
+func TestMigrateAdoptsAutoMigratedTables(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&baselineERC20WalletLog{}); err != nil {
+		t.Fatal(err)
+	}
+	log := baselineERC20WalletLog{AccountId: 7, BusinessModule: "Testing", ActionType: walleter.Spend.String(),
+		Tokens: `{"items":[]}`, Fees: `{"items":[]}`, Status: walleter.Done.String(), OriginalWallet: "{}", SettledWallet: "{}"}
+	if err := db.Create(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := walleter.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	var adopted walleter.ERC20WalletLog
+	if err := db.First(&adopted, log.ID).Error; err != nil || adopted.AccountId != 7 || adopted.FailureDetail != nil {
+		t.Fatalf("adopted log %+v, %v", adopted, err)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+// income issues n income commands for accountId, each publishing one event.
+func income(t *testing.T, db *gorm.DB, w *walleter.Walleter, accountId uint64, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+			map[walleter.ERC20TokenEnum]float64{walleter.BUSD: float64(i + 1)}, nil))
+	}
+}
+
+func TestDispatchInOrder(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	otherId := newTestAccountId()
+	handleCommand(t, db, w, walleter.NewInitWalletCommand(otherId))
+	income(t, db, w, accountId, 3)
+	income(t, db, w, otherId, 2)
+
+	received := map[uint64][]float64{}
+	dispatcher := w.NewEventDispatcher(walleter.EventDispatcherConfig{})
+	dispatcher.Subscribe("recorder", func(ctx context.Context, event walleter.WalletChangedEvent) error {
+		if event.ActionType == walleter.Income.String() {
+			received[event.AccountId] = append(received[event.AccountId], event.ERC20Changes[0].Delta)
+		}
+		return nil
+	})
+	if _, err := dispatcher.DispatchOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for id, expected := range map[uint64][]float64{accountId: {1, 2, 3}, otherId: {1, 2}} {
+		if len(received[id]) != len(expected) {
+			t.Fatalf("account %d received %v", id, received[id])
+		}
+		for index := range expected {
+			if received[id][index] != expected[index] {
+				t.Fatalf("account %d received %v", id, received[id])
+			}
+		}
+	}
+	if delivered, err := dispatcher.DispatchOnce(context.Background()); err != nil || delivered != 0 {
+		t.Fatalf("second dispatch delivered %d, %v", delivered, err)
+	}
+}
+
+func TestDispatchBlockedAccount(t *testing.T) {
+	db, w, blockedId := newTestWalleter(t)
+	otherId := newTestAccountId()
+	handleCommand(t, db, w, walleter.NewInitWalletCommand(otherId))
+	income(t, db, w, blockedId, 5)
+	income(t, db, w, otherId, 1)
+
+	// the failing account has more pending events than a batch holds
+	var received []uint64
+	dispatcher := w.NewEventDispatcher(walleter.EventDispatcherConfig{BatchSize: 4, RetryBackoff: time.Hour})
+	dispatcher.Subscribe("recorder", func(ctx context.Context, event walleter.WalletChangedEvent) error {
+		if event.AccountId == blockedId {
+			return errors.New("subscriber down")
+		}
+		if event.ActionType == walleter.Income.String() {
+			received = append(received, event.AccountId)
+		}
+		return nil
+	})
+	if _, err := dispatcher.DispatchOnce(context.Background()); err != nil || len(received) != 1 || received[0] != otherId {
+		t.Fatalf("delivered to %v, %v", received, err)
+	}
+
+	// the later events of the blocked account wait for the failed one, the event of its initialization
+	var events []walleter.OutboxEvent
+	if err := db.Where("account_id = ?", blockedId).Order("id").Find(&events).Error; err != nil || len(events) != 6 {
+		t.Fatalf("events %d, %v", len(events), err)
+	}
+	if events[0].Attempts != 1 || events[0].LastError != "subscriber recorder: subscriber down" {
+		t.Fatalf("failed event %+v", events[0])
+	}
+	for _, event := range events[1:] {
+		if event.Attempts != 0 || event.Status != walleter.OutboxPending.String() {
+			t.Fatalf("later event %+v", event)
+		}
+	}
+}
+
+func TestDispatchLongErrorTruncated(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	// the 255th byte of the error falls inside a two byte rune
+	message := "x" + strings.Repeat("é", 200)
+	dispatcher := w.NewEventDispatcher(walleter.EventDispatcherConfig{RetryBackoff: time.Hour})
+	dispatcher.Subscribe("recorder", func(ctx context.Context, event walleter.WalletChangedEvent) error {
+		return errors.New(message)
+	})
+	if _, err := dispatcher.DispatchOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var event walleter.OutboxEvent
+	if err := db.Where("account_id = ?", accountId).Order("id").First(&event).Error; err != nil {
+		t.Fatal(err)
+	}
+	full := "subscriber recorder: " + message
+	if len(event.LastError) > 255 || len(event.LastError) < 254 || !utf8.ValidString(event.LastError) ||
+		!strings.HasPrefix(full, event.LastError) {
+		t.Fatalf("last error of %d bytes %q", len(event.LastError), event.LastError)
+	}
+}
+
+func TestDispatchBackoffAndDeadLetter(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	income(t, db, w, accountId, 2)
+
+	// the event of the initialization fails until recovered
+	recovered := false
+	var received []string
+	dispatcher := w.NewEventDispatcher(walleter.EventDispatcherConfig{MaxAttempts: 3, RetryBackoff: time.Minute, MaxRetryBackoff: 3 * time.Minute})
+	dispatcher.Subscribe("recorder", func(ctx context.Context, event walleter.WalletChangedEvent) error {
+		if event.AccountId != accountId {
+			return nil
+		}
+		if event.ActionType == walleter.Initialize.String() && !recovered {
+			return errors.New("subscriber down")
+		}
+		received = append(received, event.ActionType)
+		return nil
+	})
+	head := func() walleter.OutboxEvent {
+		t.Helper()
+		var event walleter.OutboxEvent
+		if err := db.Where("account_id = ?", accountId).Order("id").First(&event).Error; err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+	// makeDue moves the retry of the head event to now
+	makeDue := func() {
+		t.Helper()
+		if err := db.Model(&walleter.OutboxEvent{}).Where("id = ?", head().ID).Update("next_attempt_at", time.Now()).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the delay doubles with every attempt up to MaxRetryBackoff
+	for attempt, delay := range []time.Duration{time.Minute, 2 * time.Minute} {
+		started := time.Now()
+		if _, err := dispatcher.DispatchOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		event := head()
+		if event.Attempts != attempt+1 || event.Status != walleter.OutboxPending.String() {
+			t.Fatalf("event after attempt %d %+v", attempt+1, event)
+		}
+		if retry := event.NextAttemptAt.Sub(started); retry < delay || retry > delay+time.Second {
+			t.Fatalf("retry after attempt %d in %v, expected %v", attempt+1, retry, delay)
+		}
+		if delivered, err := dispatcher.DispatchOnce(context.Background()); err != nil || delivered != 0 {
+			t.Fatalf("dispatch before the retry is due delivered %d, %v", delivered, err)
+		}
+		makeDue()
+	}
+
+	// the last attempt dead-letters the event, the next event of the account follows
+	if _, err := dispatcher.DispatchOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	dead, err := w.ListDeadLetterEvents(10)
+	if err != nil || len(dead) != 1 || dead[0].AccountId != accountId || dead[0].Attempts != 3 {
+		t.Fatalf("dead letters %+v, %v", dead, err)
+	}
+	if _, err := dispatcher.DispatchOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 || received[0] != walleter.Income.String() || received[1] != walleter.Income.String() {
+		t.Fatalf("received %v", received)
+	}
+
+	// a requeued event is delivered again
+	recovered = true
+	if err := w.RequeueDeadLetterEvent(dead[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if delivered, err := dispatcher.DispatchOnce(context.Background()); err != nil || delivered != 1 || len(received) != 3 || received[2] != walleter.Initialize.String() {
+		t.Fatalf("dispatch of the requeued event delivered %d, received %v, %v", delivered, received, err)
+	}
+}
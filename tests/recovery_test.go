@@ -0,0 +1,127 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+)
+
+// staleDeposit deposits 10 BUSD to accountId and turns its log back into a Pending log created an hour ago,
+// as left by a process dying before settling it. original replaces the original wallet of the log when set.
+func staleDeposit(t *testing.T, db *gorm.DB, w *walleter.Walleter, accountId uint64, original *walleter.Wallet) uint {
+	t.Helper()
+	id := depositBUSD(t, db, w, accountId, 10)
+	values := map[string]interface{}{
+		"status":     walleter.Pending.String(),
+		"created_at": time.Now().Add(-time.Hour),
+	}
+	if original != nil {
+		values["original_wallet"] = *original
+	}
+	if err := db.Model(&walleter.ERC20WalletLog{}).Where("id = ?", id).Updates(values).Error; err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func getERC20Log(t *testing.T, db *gorm.DB, id uint) walleter.ERC20WalletLog {
+	t.Helper()
+	var log walleter.ERC20WalletLog
+	if err := db.First(&log, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func TestRecoverAppliedLog(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	id := staleDeposit(t, db, w, accountId, nil)
+
+	report, err := w.RecoverStaleLogs(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Done != 1 || report.Failed != 0 || report.Skipped != 0 || len(report.Logs) != 1 || report.Logs[0].LogId != id {
+		t.Fatalf("report %+v", report)
+	}
+	log := getERC20Log(t, db, id)
+	if log.Status != walleter.Done.String() || log.FailureDetail != nil || erc20Balance(log.SettledWallet, walleter.BUSD) != 10 {
+		t.Fatalf("recovered log %+v", log)
+	}
+	if balance := erc20Balance(getWallet(t, w, accountId), walleter.BUSD); balance != 10 {
+		t.Fatalf("balance after recovery %v", balance)
+	}
+}
+
+func TestRecoverNotAppliedLog(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	// the log claims to start from the current wallet, so its change never reached it
+	current := getWallet(t, w, accountId)
+	for index := range current.ERC20TokenData {
+		if current.ERC20TokenData[index].Token == walleter.BUSD.String() {
+			current.ERC20TokenData[index].Balance = 10
+		}
+	}
+	id := staleDeposit(t, db, w, accountId, &current)
+
+	report, err := w.RecoverStaleLogs(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Done != 0 || report.Failed != 1 || len(report.Logs) != 1 || report.Logs[0].Status != walleter.Failed.String() {
+		t.Fatalf("report %+v", report)
+	}
+	log := getERC20Log(t, db, id)
+	if log.Status != walleter.Failed.String() || log.FailureDetail == nil || log.FailureDetail.Code != walleter.CodeInterrupted ||
+		log.FailureDetail.Message != "recovery: wallet unchanged, change was not applied" {
+		t.Fatalf("recovered log %+v, failure %+v", log, log.FailureDetail)
+	}
+
+	// a log which is not stale yet is left alone
+	fresh := depositBUSD(t, db, w, accountId, 1)
+	if err := db.Model(&walleter.ERC20WalletLog{}).Where("id = ?", fresh).Update("status", walleter.Pending.String()).Error; err != nil {
+		t.Fatal(err)
+	}
+	if report, err := w.RecoverStaleLogs(time.Minute); err != nil || len(report.Logs) != 0 || report.Skipped != 0 {
+		t.Fatalf("report %+v, %v", report, err)
+	}
+	if log := getERC20Log(t, db, fresh); log.Status != walleter.Pending.String() {
+		t.Fatalf("fresh log recovered as %s", log.Status)
+	}
+}
+
+func TestRecoverConcurrentClaim(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	id := staleDeposit(t, db, w, accountId, nil)
+
+	// a second worker recovers the log right after the first one found it
+	var second walleter.RecoveryReport
+	var secondErr error
+	armed := true
+	err := db.Callback().Query().After("gorm:query").Register("test:second_worker", func(tx *gorm.DB) {
+		if !armed || tx.Statement.Table != "erc20_wallet_logs" {
+			return
+		}
+		armed = false
+		second, secondErr = w.RecoverStaleLogs(time.Minute)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := w.RecoverStaleLogs(time.Minute)
+	if err != nil || secondErr != nil {
+		t.Fatal(err, secondErr)
+	}
+	if second.Done != 1 || second.Skipped != 0 {
+		t.Fatalf("report of the second worker %+v", second)
+	}
+	if first.Done != 0 || first.Failed != 0 || first.Skipped != 1 || len(first.Logs) != 0 {
+		t.Fatalf("report of the first worker %+v", first)
+	}
+	if log := getERC20Log(t, db, id); log.Status != walleter.Done.String() {
+		t.Fatalf("recovered log %s", log.Status)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+// statementBUSD exports the statement of accountId for (from, to] in format and returns its BUSD records.
+func statementBUSD(t *testing.T, w *walleter.Walleter, accountId uint64, from, to time.Time, format walleter.StatementFormat) []walleter.StatementRecord {
+	t.Helper()
+	var out bytes.Buffer
+	if err := w.ExportStatement(&out, accountId, from, to, format); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []walleter.StatementRecord
+	if format == walleter.JSONLinesStatement {
+		scanner := bufio.NewScanner(&out)
+		for scanner.Scan() {
+			var record walleter.StatementRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, record)
+		}
+	} else {
+		rows, err := csv.NewReader(&out).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) == 0 || rows[0][0] != "kind" || rows[0][10] != "balance" {
+			t.Fatalf("header %v", rows)
+		}
+		for _, row := range rows[1:] {
+			logId, _ := strconv.ParseUint(row[2], 10, 64)
+			amount, _ := strconv.ParseFloat(row[8], 64)
+			balance, _ := strconv.ParseFloat(row[10], 64)
+			records = append(records, walleter.StatementRecord{
+				Kind: walleter.StatementRecordKind(row[0]), LogId: uint(logId), Token: row[7], Amount: amount, Balance: balance,
+			})
+		}
+	}
+
+	var result []walleter.StatementRecord
+	for _, record := range records {
+		if record.Token == walleter.BUSD.String() {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+func TestExportStatementPeriod(t *testing.T) {
+	db, w, accountId := newTestWalleter(t)
+	first := depositBUSD(t, db, w, accountId, 10)
+	time.Sleep(5 * time.Millisecond)
+	second := depositBUSD(t, db, w, accountId, 5)
+	time.Sleep(5 * time.Millisecond)
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 3}, nil))
+	time.Sleep(5 * time.Millisecond)
+	depositBUSD(t, db, w, accountId, 100)
+
+	var withdrawal walleter.ERC20WalletLog
+	if err := db.Where("account_id = ? AND action_type = ?", accountId, walleter.Withdraw.String()).First(&withdrawal).Error; err != nil {
+		t.Fatal(err)
+	}
+	// the period starts exactly at the first deposit and ends exactly at the withdrawal
+	from := getERC20Log(t, db, first).CreatedAt
+	to := withdrawal.CreatedAt
+
+	expected := []walleter.StatementRecord{
+		{Kind: walleter.OpeningBalanceRecord, Balance: 10},
+		{Kind: walleter.MovementRecord, LogId: second, Amount: 5, Balance: 15},
+		{Kind: walleter.MovementRecord, LogId: withdrawal.ID, Amount: -3, Balance: 12},
+		{Kind: walleter.ClosingBalanceRecord, Balance: 12},
+	}
+	for _, format := range []walleter.StatementFormat{walleter.CSVStatement, walleter.JSONLinesStatement} {
+		records := statementBUSD(t, w, accountId, from, to, format)
+		if len(records) != len(expected) {
+			t.Fatalf("format %d records %+v", format, records)
+		}
+		for index, record := range records {
+			want := expected[index]
+			if record.Kind != want.Kind || record.LogId != want.LogId || record.Amount != want.Amount || record.Balance != want.Balance {
+				t.Fatalf("format %d record %d %+v, expected %+v", format, index, record, want)
+			}
+		}
+	}
+
+	// the fees credited to the fee charger have no logs of its own to list
+	if err := w.ExportStatement(&bytes.Buffer{}, testFeeChargerId, from, to, walleter.CSVStatement); !errors.Is(err, walleter.ErrStatementNotSupported) {
+		t.Fatalf("statement of the fee charger returned %v", err)
+	}
+
+	// a period without movements reports the same balance twice
+	records := statementBUSD(t, w, accountId, to, to.Add(time.Millisecond), walleter.JSONLinesStatement)
+	if len(records) != 2 || records[0].Balance != 12 || records[1].Balance != 12 {
+		t.Fatalf("records of an empty period %+v", records)
+	}
+}
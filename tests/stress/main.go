@@ -0,0 +1,337 @@
+// Command stress drives concurrent fee bearing commands against a database and checks that no fee
+// credited to the fee charger account is lost, reporting throughput, conflicts and latencies.
+//
+//	go run ./stress -dialect mysql -dsn 'root:secret@tcp(localhost:3306)/walleter_stress?parseTime=True' \
+//		-accounts 100 -workers 64 -commands 20000
+//
+// -dialect and -dsn are required: the run is only meaningful against a MySQL or PostgreSQL server, where the
+// workers really contend for the row of the fee charger account. SQLite allows a single writer, so its
+// workers run one after the other; -allow-sqlite runs the harness on it anyway, as a smoke test only.
+//
+// Use an empty database: the fee charger account collects fees across runs and is measured from its
+// balance at start. The exit status is 1 when conservation does not hold.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/nami-land/walleter"
+	"gorm.io/gorm"
+	"tests/internal/testdb"
+)
+
+const (
+	feeToken     = walleter.FISHX
+	fundingValue = 1000000.0
+	// conservationTolerance the rounding error allowed when comparing sums of fees, which are not exact
+	// in float64 for every -fee
+	conservationTolerance = 1e-6
+)
+
+type config struct {
+	dialect   string
+	dsn       string
+	chargerId uint64
+	accounts  int
+	workers   int
+	commands  int
+	retries   int
+	fee       float64
+	maxOpen   int
+	seed      int64
+	// allowSQLite accepts the sqlite dialect, whose single writer serializes the workers.
+	allowSQLite bool
+}
+
+// result the outcome of one command, after its retries.
+type result struct {
+	credit    float64
+	attempts  int
+	conflicts int
+	code      walleter.ErrorCode
+	err       error
+	latency   time.Duration
+}
+
+func main() {
+	var c config
+	flag.StringVar(&c.dialect, "dialect", "", "database driver: mysql or postgres, sqlite needs -allow-sqlite")
+	flag.StringVar(&c.dsn, "dsn", "", "data source name, an in-memory SQLite database when empty with -allow-sqlite")
+	flag.BoolVar(&c.allowSQLite, "allow-sqlite", false, "accept sqlite, whose single writer serializes the workers: a smoke test only")
+	flag.Uint64Var(&c.chargerId, "charger", 1, "fee charger account id")
+	flag.IntVar(&c.accounts, "accounts", 50, "user accounts commands are spread over")
+	flag.IntVar(&c.workers, "workers", 32, "goroutines issuing commands")
+	flag.IntVar(&c.commands, "commands", 5000, "commands issued in total")
+	flag.IntVar(&c.retries, "retries", 3, "retries of a command failing on a deadlock or serialization conflict")
+	flag.Float64Var(&c.fee, "fee", 0.25, "fee of every command, in "+feeToken.String())
+	flag.IntVar(&c.maxOpen, "max-open", 0, "maximum open connections, 0 keeps the driver default")
+	flag.Int64Var(&c.seed, "seed", 0, "seed of the command mix, 0 takes one from the clock")
+	flag.Parse()
+
+	if err := run(c); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(c config) error {
+	switch {
+	case c.dialect == "sqlite" && !c.allowSQLite:
+		return errors.New("sqlite serializes the workers and proves nothing about contention, pass -allow-sqlite to run it anyway")
+	case c.dialect == "":
+		return errors.New("-dialect is required: mysql or postgres")
+	case c.dialect != "sqlite" && c.dsn == "":
+		return errors.New("-dsn is required")
+	}
+	db, err := testdb.Open(c.dialect, c.dsn)
+	if err != nil {
+		return err
+	}
+	if c.maxOpen > 0 && c.dialect != "sqlite" {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		sqlDB.SetMaxOpenConns(c.maxOpen)
+	}
+	if c.seed == 0 {
+		c.seed = time.Now().UnixNano()
+	}
+	w := walleter.New(db, c.chargerId)
+
+	// 1. Create and fund the accounts, one at a time
+	base := uint64(time.Now().UnixNano() / int64(time.Millisecond) * 1000)
+	var accounts []uint64
+	for i := 0; i < c.accounts; i++ {
+		accountId := base + uint64(i)
+		if _, err := w.HandleWalletCommand(db, walleter.NewInitWalletCommand(accountId)); err != nil {
+			return fmt.Errorf("initialize account %d: %w", accountId, err)
+		}
+		deposit := walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Stress", walleter.BSC,
+			map[walleter.ERC20TokenEnum]float64{feeToken: fundingValue}, nil)
+		if _, err := w.HandleWalletCommand(db, deposit); err != nil {
+			return fmt.Errorf("fund account %d: %w", accountId, err)
+		}
+		accounts = append(accounts, accountId)
+	}
+	chargerBefore, err := balanceOf(w, c.chargerId)
+	if err != nil {
+		return err
+	}
+
+	// 2. Issue the commands from every worker
+	startedAt := time.Now()
+	jobs := make(chan walleter.WalletCommand)
+	results := make(chan result, c.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for command := range jobs {
+				results <- execute(db, w, command, c.retries)
+			}
+		}()
+	}
+	go func() {
+		random := rand.New(rand.NewSource(c.seed))
+		for i := 0; i < c.commands; i++ {
+			jobs <- randomCommand(random, accounts, c.fee)
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	var (
+		expectedCredit float64
+		committed      int
+		attempts       int
+		conflicts      int
+		failures       = map[walleter.ErrorCode]int{}
+		failureSample  = map[walleter.ErrorCode]error{}
+		latencies      []time.Duration
+	)
+	for r := range results {
+		attempts += r.attempts
+		conflicts += r.conflicts
+		latencies = append(latencies, r.latency)
+		if r.err != nil {
+			failures[r.code]++
+			failureSample[r.code] = r.err
+			continue
+		}
+		committed++
+		expectedCredit += r.credit
+	}
+	elapsed := time.Since(startedAt)
+
+	// 3. Check conservation: what the users were debited is what the fee charger was credited
+	chargerAfter, err := balanceOf(w, c.chargerId)
+	if err != nil {
+		return err
+	}
+	var userDebit float64
+	for _, accountId := range accounts {
+		wallet, err := w.GetWalletByAccountId(accountId)
+		if err != nil {
+			return err
+		}
+		for _, token := range wallet.ERC20TokenData {
+			if token.Token == feeToken.String() {
+				userDebit += token.TotalFee
+			}
+		}
+	}
+	chargerCredit := chargerAfter - chargerBefore
+
+	fmt.Printf("dialect %s, %d accounts, %d workers, %d commands, seed %d\n", c.dialect, c.accounts, c.workers, c.commands, c.seed)
+	fmt.Printf("elapsed %s, %.1f commands/s, %d committed\n", elapsed.Round(time.Millisecond),
+		float64(c.commands)/elapsed.Seconds(), committed)
+	fmt.Printf("attempts %d, conflicts %d (%.2f%% of attempts)\n", attempts, conflicts, percent(conflicts, attempts))
+	fmt.Printf("latency p50 %s, p95 %s, p99 %s, max %s\n",
+		quantile(latencies, .5), quantile(latencies, .95), quantile(latencies, .99), quantile(latencies, 1))
+	var codes []string
+	for code := range failures {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Printf("failed %s: %d (%.2f%%), e.g. %v\n", code, failures[walleter.ErrorCode(code)],
+			percent(failures[walleter.ErrorCode(code)], c.commands), failureSample[walleter.ErrorCode(code)])
+	}
+	fmt.Printf("expected credit %v, fee charger credited %v, users debited %v\n", expectedCredit, chargerCredit, userDebit)
+
+	var violations []string
+	if math.Abs(chargerCredit-expectedCredit) > conservationTolerance {
+		violations = append(violations, fmt.Sprintf("fee charger lost %v %s", expectedCredit-chargerCredit, feeToken))
+	}
+	if math.Abs(userDebit-expectedCredit) > conservationTolerance {
+		violations = append(violations, fmt.Sprintf("users were debited %v %s instead of %v", userDebit, feeToken, expectedCredit))
+	}
+	if len(violations) > 0 {
+		return errors.New("conservation violated: " + strings.Join(violations, ", "))
+	}
+	fmt.Println("conservation holds")
+	return nil
+}
+
+// execute handles command in a transaction, retrying it when the database aborts it on a conflict.
+func execute(db *gorm.DB, w *walleter.Walleter, command walleter.WalletCommand, retries int) result {
+	startedAt := time.Now()
+	var r result
+	for {
+		r.attempts++
+		err := db.Transaction(func(tx *gorm.DB) error {
+			_, err := w.HandleWalletCommand(tx, command)
+			return err
+		})
+		if err == nil {
+			r.credit = creditOf(command)
+			break
+		}
+		if isConflict(err) {
+			r.conflicts++
+			if r.attempts <= retries {
+				continue
+			}
+			r.code = "CONFLICT"
+		} else {
+			r.code = walleter.ErrorCodeOf(err)
+		}
+		r.err = err
+		break
+	}
+	r.latency = time.Since(startedAt)
+	return r
+}
+
+// randomCommand a command of a random account paying fee, spends and fee charges are credited
+// to the fee charger as well.
+func randomCommand(random *rand.Rand, accounts []uint64, fee float64) walleter.WalletCommand {
+	accountId := accounts[random.Intn(len(accounts))]
+	fees := map[walleter.ERC20TokenEnum]float64{feeToken: fee}
+	value := float64(random.Intn(8)+1) / 4
+
+	switch random.Intn(4) {
+	case 0:
+		return walleter.NewERC20WalletCommand(accountId, walleter.Income, "Stress", walleter.InGame,
+			map[walleter.ERC20TokenEnum]float64{walleter.BUSD: value}, fees)
+	case 1:
+		return walleter.NewERC20WalletCommand(accountId, walleter.Spend, "Stress", walleter.InGame,
+			map[walleter.ERC20TokenEnum]float64{feeToken: value}, fees)
+	case 2:
+		return walleter.NewERC20WalletCommand(accountId, walleter.ChargeFee, "Stress", walleter.InGame,
+			map[walleter.ERC20TokenEnum]float64{feeToken: value}, nil)
+	default:
+		return walleter.NewERC1155WalletCommand(accountId, walleter.Income, "Stress", walleter.InGame,
+			[]uint64{10001}, []uint64{1}, fees)
+	}
+}
+
+// creditOf what command credits to the fee charger account.
+func creditOf(command walleter.WalletCommand) float64 {
+	var credit float64
+	for _, fee := range command.FeeCommands {
+		credit += fee.Value
+	}
+	if command.ActionType == walleter.Spend || command.ActionType == walleter.ChargeFee {
+		for _, token := range command.ERC20Commands {
+			credit += token.Value
+		}
+	}
+	return credit
+}
+
+// isConflict reports whether err is a deadlock, lock timeout or serialization failure, after which
+// the transaction can be retried.
+func isConflict(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+	var sqlStateErr interface{ SQLState() string }
+	if errors.As(err, &sqlStateErr) {
+		return sqlStateErr.SQLState() == "40001" || sqlStateErr.SQLState() == "40P01"
+	}
+	return strings.Contains(err.Error(), "database is locked")
+}
+
+func balanceOf(w *walleter.Walleter, accountId uint64) (float64, error) {
+	wallet, err := w.GetWalletByAccountId(accountId)
+	if err != nil {
+		return 0, err
+	}
+	for _, token := range wallet.ERC20TokenData {
+		if token.Token == feeToken.String() {
+			return token.Balance, nil
+		}
+	}
+	return 0, nil
+}
+
+func quantile(latencies []time.Duration, q float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(q*float64(len(sorted)-1))].Round(time.Microsecond)
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nami-land/walleter"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spansNamed returns the spans of recorder called name which ended after the first skip ones.
+func spansNamed(recorder *tracetest.SpanRecorder, skip int, name string) []sdktrace.ReadOnlySpan {
+	var result []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended()[skip:] {
+		if span.Name() == name {
+			result = append(result, span)
+		}
+	}
+	return result
+}
+
+func TestTracing(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	w := walleter.New(db, testFeeChargerId, walleter.WithTracerProvider(provider))
+	accountId := newTestAccountId()
+	ctx, parent := provider.Tracer("host").Start(context.Background(), "host request")
+	if _, err := w.ExecuteCommand(ctx, walleter.NewInitWalletCommand(accountId)); err != nil {
+		t.Fatal(err)
+	}
+	skip := len(recorder.Ended())
+
+	if _, err := w.ExecuteCommand(ctx, walleter.NewERC20WalletCommand(accountId, walleter.Deposit, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil)); err != nil {
+		t.Fatal(err)
+	}
+	commands := spansNamed(recorder, skip, "walleter.HandleWalletCommand")
+	if len(commands) != 1 || commands[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("command spans %v", commands)
+	}
+	command := commands[0]
+	steps := spansNamed(recorder, skip, "walleter.insert_log")
+	if len(steps) != 1 || steps[0].Parent().SpanID() != command.SpanContext().SpanID() {
+		t.Fatalf("insert log spans %v", steps)
+	}
+	creates := spansNamed(recorder, skip, "walleter.db.create")
+	found := false
+	for _, span := range creates {
+		if span.Parent().SpanID() == steps[0].SpanContext().SpanID() {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("no database span below the insert log step among %d creates", len(creates))
+	}
+
+	// a rejected command records its error code
+	skip = len(recorder.Ended())
+	if _, err := w.ExecuteCommand(ctx, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 25}, nil)); err == nil {
+		t.Fatal("withdrawing more than the balance succeeded")
+	}
+	commands = spansNamed(recorder, skip, "walleter.HandleWalletCommand")
+	if len(commands) != 1 || commands[0].Status().Code != codes.Error {
+		t.Fatalf("rejected command spans %v", commands)
+	}
+	code := ""
+	for _, attribute := range commands[0].Attributes() {
+		if attribute.Key == "walleter.error_code" {
+			code = attribute.Value.AsString()
+		}
+	}
+	if code != string(walleter.CodeInsufficientBalance) {
+		t.Fatalf("error code of the span %q", code)
+	}
+
+	// queries of the host on the same db are not traced by walleter
+	skip = len(recorder.Ended())
+	var count int64
+	if err := db.WithContext(ctx).Table("wallets").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+	if spans := recorder.Ended()[skip:]; len(spans) != 1 || spans[0].Name() != "host request" {
+		t.Fatalf("spans of a host query %v", spans)
+	}
+}
+
+func TestTracingNotConfigured(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walleter.New(db, testFeeChargerId)
+	if _, ok := db.Config.Plugins["walleter:tracing"]; ok {
+		t.Fatal("tracing plugin registered without tracer provider")
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+)
+
+// webhookReceiver an endpoint recording the requests it accepts, answering statuses in turn once they run out 200.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	writer.WriteHeader(status)
+}
+
+// newTestWebhooks registers receiver for income and withdraw events of a new test walleter and
+// issues an income and a withdrawal, their events are handed to the webhook service.
+func newTestWebhooks(t *testing.T, receiver http.Handler, config walleter.WebhookConfig, filter walleter.WebhookFilter) (*walleter.WebhookService, walleter.WebhookEndpoint, uint64) {
+	t.Helper()
+	db, w, accountId := newTestWalleter(t)
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	webhooks := w.NewWebhookService(config)
+	filter.ActionTypes = []string{walleter.Income.String(), walleter.Withdraw.String()}
+	endpoint, err := webhooks.RegisterEndpoint("partner", server.URL, "secret", filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 10}, nil))
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.BSC,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 4}, nil))
+
+	dispatcher := w.NewEventDispatcher(walleter.EventDispatcherConfig{})
+	dispatcher.Subscribe("webhooks", webhooks.HandleWalletEvent)
+	if _, err := dispatcher.DispatchOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return webhooks, endpoint, accountId
+}
+
+func TestWebhookSignature(t *testing.T) {
+	receiver := &webhookReceiver{}
+	webhooks, endpoint, accountId := newTestWebhooks(t, receiver, walleter.WebhookConfig{}, walleter.WebhookFilter{})
+	if sent, err := webhooks.DeliverOnce(context.Background()); err != nil || sent != 3 {
+		t.Fatalf("sent %d, %v", sent, err)
+	}
+
+	// the income, the withdrawal and its completion
+	types := map[string]int{}
+	for index, request := range receiver.requests {
+		body := receiver.bodies[index]
+		if err := walleter.VerifyWebhookSignature("secret", request.Header.Get(walleter.WebhookSignatureHeader), body, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		var payload walleter.WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if strconv.FormatUint(uint64(payload.DeliveryId), 10) != request.Header.Get(walleter.WebhookDeliveryHeader) ||
+			payload.Type != request.Header.Get(walleter.WebhookEventHeader) || payload.Data.AccountId != accountId {
+			t.Fatalf("payload %+v with headers %v", payload, request.Header)
+		}
+		types[payload.Type]++
+	}
+	if types[walleter.WebhookWalletChanged] != 2 || types[walleter.WebhookWithdrawalCompleted] != 1 {
+		t.Fatalf("event types %v", types)
+	}
+	deliveries, err := webhooks.ListDeliveries(endpoint.ID, walleter.OutboxDelivered.String(), 10)
+	if err != nil || len(deliveries) != 3 || deliveries[0].LastStatusCode != http.StatusOK || deliveries[0].DeliveredAt == nil {
+		t.Fatalf("deliveries %+v, %v", deliveries, err)
+	}
+
+	// other secrets, changed bodies and old signatures are rejected
+	body := receiver.bodies[0]
+	header := receiver.requests[0].Header.Get(walleter.WebhookSignatureHeader)
+	if err := walleter.VerifyWebhookSignature("other", header, body, time.Minute); !errors.Is(err, walleter.ErrInvalidWebhookSignature) {
+		t.Fatalf("verifying with another secret returned %v", err)
+	}
+	if err := walleter.VerifyWebhookSignature("secret", header, append([]byte{' '}, body...), time.Minute); !errors.Is(err, walleter.ErrInvalidWebhookSignature) {
+		t.Fatalf("verifying a changed body returned %v", err)
+	}
+	old := walleter.SignWebhookPayload("secret", time.Now().Add(-time.Hour), body)
+	if err := walleter.VerifyWebhookSignature("secret", old, body, time.Minute); !errors.Is(err, walleter.ErrInvalidWebhookSignature) {
+		t.Fatalf("verifying an old signature returned %v", err)
+	}
+	if err := walleter.VerifyWebhookSignature("secret", "t=1", body, 0); !errors.Is(err, walleter.ErrInvalidWebhookSignature) {
+		t.Fatalf("verifying a header without signature returned %v", err)
+	}
+}
+
+func TestWebhookRetry(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+	config := walleter.WebhookConfig{MaxAttempts: 2, RetryBackoff: time.Minute}
+	webhooks, endpoint, _ := newTestWebhooks(t, receiver, config,
+		walleter.WebhookFilter{EventTypes: []string{walleter.WebhookWithdrawalCompleted}})
+
+	started := time.Now()
+	if sent, err := webhooks.DeliverOnce(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("sent %d, %v", sent, err)
+	}
+	deliveries, err := webhooks.ListDeliveries(endpoint.ID, walleter.OutboxPending.String(), 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("deliveries %+v, %v", deliveries, err)
+	}
+	delivery := deliveries[0]
+	if delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusInternalServerError || delivery.LastError != "endpoint responded 500" {
+		t.Fatalf("delivery after a failure %+v", delivery)
+	}
+	if retry := delivery.NextAttemptAt.Sub(started); retry < time.Minute || retry > time.Minute+time.Second {
+		t.Fatalf("retry in %v", retry)
+	}
+	if sent, err := webhooks.DeliverOnce(context.Background()); err != nil || sent != 0 || len(receiver.requests) != 1 {
+		t.Fatalf("delivery before the retry is due sent %d, %d requests, %v", sent, len(receiver.requests), err)
+	}
+
+	// a replayed delivery is due at once with its attempts reset, until the endpoint accepts it
+	if err := webhooks.ReplayDelivery(delivery.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := webhooks.DeliverOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := webhooks.ReplayDelivery(delivery.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := webhooks.DeliverOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if deliveries, err = webhooks.ListDeliveries(endpoint.ID, walleter.OutboxDelivered.String(), 10); err != nil || len(deliveries) != 1 {
+		t.Fatalf("delivered %+v, %v", deliveries, err)
+	}
+}
+
+func TestWebhookGiveUpAndReplay(t *testing.T) {
+	receiver := &webhookReceiver{statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError}}
+	config := walleter.WebhookConfig{MaxAttempts: 2, RetryBackoff: time.Nanosecond}
+	webhooks, endpoint, _ := newTestWebhooks(t, receiver, config,
+		walleter.WebhookFilter{EventTypes: []string{walleter.WebhookWithdrawalCompleted}})
+
+	for attempt := 0; attempt < 2; attempt++ {
+		time.Sleep(time.Millisecond)
+		if _, err := webhooks.DeliverOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dead, err := webhooks.ListDeliveries(endpoint.ID, walleter.OutboxDeadLetter.String(), 10)
+	if err != nil || len(dead) != 1 || dead[0].Attempts != 2 {
+		t.Fatalf("given up deliveries %+v, %v", dead, err)
+	}
+
+	// replaying the events queues the given up delivery again, with the same payload
+	replayed, err := webhooks.ReplayEvents(endpoint.ID, time.Now().Add(-time.Hour))
+	if err != nil || replayed != 1 {
+		t.Fatalf("replayed %d, %v", replayed, err)
+	}
+	if sent, err := webhooks.DeliverOnce(context.Background()); err != nil || sent != 1 {
+		t.Fatalf("sent %d, %v", sent, err)
+	}
+	if len(receiver.bodies) != 3 || string(receiver.bodies[2]) != string(receiver.bodies[0]) || string(receiver.bodies[2]) != dead[0].Payload {
+		t.Fatalf("replayed body %s", receiver.bodies[len(receiver.bodies)-1])
+	}
+}
+
+// blockingReceiver holds the first request it receives until release is closed.
+type blockingReceiver struct {
+	webhookReceiver
+	received chan struct{}
+	release  chan struct{}
+	once     sync.Once
+}
+
+func (r *blockingReceiver) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	first := false
+	r.once.Do(func() { first = true })
+	if first {
+		close(r.received)
+		<-r.release
+	}
+	r.webhookReceiver.ServeHTTP(writer, request)
+}
+
+func TestWebhookDeliveryLease(t *testing.T) {
+	receiver := &blockingReceiver{received: make(chan struct{}), release: make(chan struct{})}
+	webhooks, endpoint, _ := newTestWebhooks(t, receiver, walleter.WebhookConfig{},
+		walleter.WebhookFilter{EventTypes: []string{walleter.WebhookWithdrawalCompleted}})
+	var releaseOnce sync.Once
+	release := func() { releaseOnce.Do(func() { close(receiver.release) }) }
+	// runs before the server closes, which waits for the held request
+	t.Cleanup(release)
+
+	sent := make(chan int, 1)
+	go func() {
+		count, err := webhooks.DeliverOnce(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		sent <- count
+	}()
+
+	// a second poll while the delivery is being sent leaves it to the first one
+	<-receiver.received
+	if count, err := webhooks.DeliverOnce(context.Background()); err != nil || count != 0 {
+		t.Fatalf("concurrent poll sent %d, %v", count, err)
+	}
+	release()
+	if count := <-sent; count != 1 {
+		t.Fatalf("first poll sent %d", count)
+	}
+	if len(receiver.requests) != 1 {
+		t.Fatalf("endpoint received %d requests", len(receiver.requests))
+	}
+	deliveries, err := webhooks.ListDeliveries(endpoint.ID, walleter.OutboxDelivered.String(), 10)
+	if err != nil || len(deliveries) != 1 || deliveries[0].Attempts != 1 {
+		t.Fatalf("delivered %+v, %v", deliveries, err)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nami-land/walleter"
+	"github.com/prometheus/client_golang/prometheus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+func TestWithdrawalApprovals(t *testing.T) {
+	db, _, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	w := walleter.New(db, testFeeChargerId, walleter.WithWithdrawalPolicy(walleter.WithdrawalPolicy{
+		Thresholds: map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 100},
+		Approvals:  2,
+	}))
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1000}, nil))
+
+	// withdrawals up to the threshold go straight through
+	wallet := handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 100}, nil))
+	if erc20Balance(wallet, walleter.BUSD) != 900 {
+		t.Fatalf("small withdrawal %+v", wallet)
+	}
+
+	wallet, held := holdWithdrawal(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 500}, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1}))
+	if erc20Balance(wallet, walleter.BUSD) != 400 || erc20Locked(wallet, walleter.BUSD) != 500 {
+		t.Fatalf("held withdrawal %+v", wallet)
+	}
+	holds, err := w.ListWithdrawalHolds(walleter.WithdrawalPending)
+	if err != nil || len(holds) != 1 || holds[0].Amounts["BUSD"] != 500 || holds[0].RequiredApprovals != 2 {
+		t.Fatalf("pending holds %+v, %v", holds, err)
+	}
+	if held.HoldId != holds[0].ID || held.AccountId != accountId {
+		t.Fatalf("held withdrawal reported %+v, hold %d", held, holds[0].ID)
+	}
+	hold := holds[0]
+	if status := withdrawalLogStatus(t, w, accountId, hold.LogId); status != walleter.Held.String() {
+		t.Fatalf("held log status %s", status)
+	}
+
+	// locked funds cannot be spent
+	_, err = w.ExecuteCommand(ctx, walleter.NewERC20WalletCommand(accountId, walleter.Spend, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 450}, nil))
+	if !errors.Is(err, walleter.ErrNoEnoughBalanceForFee) {
+		t.Fatalf("spending locked funds returned %v", err)
+	}
+
+	if hold, _, err = w.ApproveWithdrawal(ctx, hold.ID, "alice"); err != nil || hold.Status != walleter.WithdrawalPending {
+		t.Fatalf("first approval %+v, %v", hold, err)
+	}
+	if _, _, err := w.ApproveWithdrawal(ctx, hold.ID, "Alice"); !errors.Is(err, walleter.ErrDuplicateApproval) {
+		t.Fatalf("second approval of the same operator returned %v", err)
+	}
+	hold, wallet, err = w.ApproveWithdrawal(ctx, hold.ID, "bob")
+	if err != nil || hold.Status != walleter.WithdrawalApproved {
+		t.Fatalf("final approval %+v, %v", hold, err)
+	}
+	if erc20Balance(wallet, walleter.BUSD) != 399 || erc20Locked(wallet, walleter.BUSD) != 0 {
+		t.Fatalf("approved withdrawal %+v", wallet)
+	}
+	if status := withdrawalLogStatus(t, w, accountId, hold.LogId); status != walleter.Done.String() {
+		t.Fatalf("approved log status %s", status)
+	}
+	if _, _, err := w.ApproveWithdrawal(ctx, hold.ID, "carol"); !errors.Is(err, walleter.ErrWithdrawalNotPending) {
+		t.Fatalf("approval of an approved withdrawal returned %v", err)
+	}
+
+	// rejection releases the funds
+	holdWithdrawal(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 200}, nil))
+	holds, err = w.ListWithdrawalHolds(walleter.WithdrawalPending)
+	if err != nil || len(holds) != 1 {
+		t.Fatalf("pending holds %+v, %v", holds, err)
+	}
+	if _, _, err := w.ApproveWithdrawal(ctx, holds[0].ID, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.RejectWithdrawal(ctx, holds[0].ID, "bob", "destination is blacklisted"); err != nil {
+		t.Fatal(err)
+	}
+	wallet = getWallet(t, w, accountId)
+	if erc20Balance(wallet, walleter.BUSD) != 399 || erc20Locked(wallet, walleter.BUSD) != 0 {
+		t.Fatalf("rejected withdrawal %+v", wallet)
+	}
+	if status := withdrawalLogStatus(t, w, accountId, holds[0].LogId); status != walleter.Failed.String() {
+		t.Fatalf("rejected log status %s", status)
+	}
+
+	report, err := w.Reconcile([]uint64{accountId})
+	if err != nil || len(report.Issues) != 0 {
+		t.Fatalf("reconciliation %+v, %v", report, err)
+	}
+	logs, err := w.ListAuditLogs(accountId)
+	if err != nil || len(logs) != 4 || logs[3].Action != walleter.AuditRejectWithdrawal {
+		t.Fatalf("audit logs %+v, %v", logs, err)
+	}
+}
+
+func TestWithdrawalTimeout(t *testing.T) {
+	db, _, accountId := newTestWalleter(t)
+	ctx := context.Background()
+	w := walleter.New(db, testFeeChargerId, walleter.WithWithdrawalPolicy(walleter.WithdrawalPolicy{
+		Thresholds: map[walleter.ERC20TokenEnum]float64{walleter.USDT: 10},
+		Timeout:    time.Millisecond,
+	}))
+	handleCommand(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.USDT: 50}, nil))
+	holdWithdrawal(t, db, w, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.InGame,
+		map[walleter.ERC20TokenEnum]float64{walleter.USDT: 20}, nil))
+	holds, err := w.ListWithdrawalHolds(walleter.WithdrawalPending)
+	if err != nil || len(holds) != 1 || holds[0].ExpiresAt == nil {
+		t.Fatalf("pending holds %+v, %v", holds, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if _, _, err := w.ApproveWithdrawal(ctx, holds[0].ID, "alice"); !errors.Is(err, walleter.ErrWithdrawalExpired) {
+		t.Fatalf("approval of an expired withdrawal returned %v", err)
+	}
+	expired, err := w.ExpireWithdrawals(ctx)
+	if err != nil || expired != 1 {
+		t.Fatalf("expired %d withdrawals, %v", expired, err)
+	}
+	wallet := getWallet(t, w, accountId)
+	if erc20Balance(wallet, walleter.USDT) != 50 || erc20Locked(wallet, walleter.USDT) != 0 {
+		t.Fatalf("expired withdrawal %+v", wallet)
+	}
+	hold, err := w.GetWithdrawalHold(holds[0].ID)
+	if err != nil || hold.Status != walleter.WithdrawalExpired {
+		t.Fatalf("expired hold %+v, %v", hold, err)
+	}
+	page, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, Statuses: []walleter.WalletLogStatus{walleter.Failed}})
+	if err != nil || len(page.Entries) != 1 || page.Entries[0].FailureDetail.Code != walleter.CodeWithdrawalExpired {
+		t.Fatalf("failed logs %+v, %v", page.Entries, err)
+	}
+}
+
+// holdWithdrawal handles a withdrawal the WithdrawalPolicy of w holds and commits it.
+func holdWithdrawal(t *testing.T, db *gorm.DB, w *walleter.Walleter, command walleter.WalletCommand) (walleter.Wallet, *walleter.WithdrawalHeldError) {
+	t.Helper()
+	var wallet walleter.Wallet
+	var held *walleter.WithdrawalHeldError
+	err := db.Transaction(func(tx *gorm.DB) (err error) {
+		wallet, err = w.HandleWalletCommand(tx, command)
+		if errors.As(err, &held) {
+			return nil
+		}
+		return err
+	})
+	if err != nil || held == nil || !errors.Is(held, walleter.ErrWithdrawalHeld) {
+		t.Fatalf("withdrawal was not held: %+v, %v", wallet, err)
+	}
+	return wallet, held
+}
+
+// erc20Locked returns the amount of token locked in wallet.
+func erc20Locked(wallet walleter.Wallet, token walleter.ERC20TokenEnum) float64 {
+	for _, item := range wallet.ERC20TokenData {
+		if item.Token == token.String() {
+			return item.Locked
+		}
+	}
+	return 0
+}
+
+// withdrawalLogStatus returns the status of the withdrawal log logId of accountId.
+func withdrawalLogStatus(t *testing.T, w *walleter.Walleter, accountId uint64, logId uint) string {
+	t.Helper()
+	page, err := w.ListWalletLogs(walleter.LogQuery{AccountId: accountId, ActionTypes: []walleter.WalletActionType{walleter.Withdraw}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range page.Entries {
+		if entry.Id == logId {
+			return entry.Status
+		}
+	}
+	t.Fatalf("no withdrawal log %d", logId)
+	return ""
+}
+
+func TestHeldWithdrawalObservability(t *testing.T) {
+	db, err := openDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := walleter.NewMetrics("")
+	registry := prometheus.NewRegistry()
+	if err := metrics.Register(registry); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	logger, hook := logrustest.NewNullLogger()
+	w := walleter.New(db, testFeeChargerId, walleter.WithMetrics(metrics),
+		walleter.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		walleter.WithLogger(walleter.NewLogrusLogger(logger)),
+		walleter.WithWithdrawalPolicy(walleter.WithdrawalPolicy{Thresholds: map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 100}}))
+	ctx := context.Background()
+	accountId := newTestAccountId()
+	for _, command := range []walleter.WalletCommand{
+		walleter.NewInitWalletCommand(accountId),
+		walleter.NewERC20WalletCommand(accountId, walleter.Income, "Testing", walleter.InGame,
+			map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 1000}, nil),
+	} {
+		if _, err := w.ExecuteCommand(ctx, command); err != nil {
+			t.Fatal(err)
+		}
+	}
+	withdraw := func(value float64) *walleter.WithdrawalHeldError {
+		t.Helper()
+		_, err := w.ExecuteCommand(ctx, walleter.NewERC20WalletCommand(accountId, walleter.Withdraw, "Testing", walleter.InGame,
+			map[walleter.ERC20TokenEnum]float64{walleter.BUSD: value}, map[walleter.ERC20TokenEnum]float64{walleter.BUSD: 2}))
+		var held *walleter.WithdrawalHeldError
+		if !errors.As(err, &held) {
+			t.Fatalf("withdrawal of %v returned %v", value, err)
+		}
+		return held
+	}
+	withdrawals := func(status string) float64 {
+		return gathered(t, registry, "walleter_commands_total", map[string]string{"action_type": walleter.Withdraw.String(), "status": status})
+	}
+	busdFees := map[string]string{"token": walleter.BUSD.String()}
+
+	// a held withdrawal is neither done nor failed, it moves nothing and charges no fee yet
+	approved := withdraw(300)
+	if withdrawals(walleter.Held.String()) != 1 || withdrawals(walleter.Done.String()) != 0 || withdrawals(walleter.Failed.String()) != 0 {
+		t.Fatalf("held withdrawal counted as %v held, %v done, %v failed", withdrawals(walleter.Held.String()),
+			withdrawals(walleter.Done.String()), withdrawals(walleter.Failed.String()))
+	}
+	if fees := gathered(t, registry, "walleter_fees_collected_total", busdFees); fees != 0 {
+		t.Fatalf("fees of a held withdrawal %v", fees)
+	}
+	if entry := hook.LastEntry(); entry.Message != "withdrawal held for approval" || entry.Data["hold_id"] != approved.HoldId {
+		t.Fatalf("held withdrawal logged as %+v", entry)
+	}
+
+	// its approval completes the command
+	skip := len(recorder.Ended())
+	if _, _, err := w.ApproveWithdrawal(ctx, approved.HoldId, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if withdrawals(walleter.Done.String()) != 1 || gathered(t, registry, "walleter_fees_collected_total", busdFees) != 2 ||
+		gathered(t, registry, "walleter_token_volume_total", map[string]string{"action_type": walleter.Withdraw.String()}) != 300 {
+		t.Fatal("approved withdrawal not counted as done with its volume and fees")
+	}
+	spans := spansNamed(recorder, skip, "walleter.ResolveWithdrawalHold")
+	if len(spans) != 1 || spans[0].Status().Code == codes.Error {
+		t.Fatalf("approval spans %v", spans)
+	}
+	if entry := hook.LastEntry(); entry.Message != "wallet command finished" || entry.Data["action_type"] != walleter.Withdraw.String() {
+		t.Fatalf("approved withdrawal logged as %+v", entry)
+	}
+
+	// its rejection fails it
+	rejected := withdraw(200)
+	skip = len(recorder.Ended())
+	if _, err := w.RejectWithdrawal(ctx, rejected.HoldId, "bob", "destination is blacklisted"); err != nil {
+		t.Fatal(err)
+	}
+	if withdrawals(walleter.Failed.String()) != 1 || gathered(t, registry, "walleter_command_errors_total",
+		map[string]string{"code": string(walleter.CodeWithdrawalRejected)}) != 1 {
+		t.Fatal("rejected withdrawal not counted as failed")
+	}
+	if spans := spansNamed(recorder, skip, "walleter.ResolveWithdrawalHold"); len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Fatalf("rejection spans %v", spans)
+	}
+	rejectedLogged := false
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "wallet command rejected" && entry.Data["code"] == string(walleter.CodeWithdrawalRejected) {
+			rejectedLogged = true
+		}
+	}
+	if !rejectedLogged {
+		t.Fatal("rejected withdrawal not logged")
+	}
+}
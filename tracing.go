@@ -0,0 +1,194 @@
+package walleter
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName     = "github.com/nami-land/walleter"
+	tracingSpanKey = "walleter:tracing_span"
+
+	// instrumentationKey the setting marking the statements of a Walleter created by New. The plugins of walleter
+	// are registered on the db of the caller, they leave the statements the caller issues on it alone.
+	instrumentationKey = "walleter:instrumentation"
+)
+
+// instrumentation what the plugins record for the statements of one Walleter.
+type instrumentation struct {
+	tracing bool
+	metrics *Metrics
+}
+
+// instrument registers the plugins the options of this Walleter ask for on db and returns db marking
+// the statements issued through it for them.
+func (s *Walleter) instrument(db *gorm.DB) (*gorm.DB, error) {
+	s.instrumentation = &instrumentation{tracing: s.traced, metrics: s.metrics}
+	if s.traced {
+		if err := usePlugin(db, &tracingPlugin{}); err != nil {
+			return nil, err
+		}
+	}
+	if s.metrics != nil {
+		if err := usePlugin(db, &metricsPlugin{}); err != nil {
+			return nil, err
+		}
+		s.metrics.db = db
+	}
+	return s.instrumented(db), nil
+}
+
+// instrumented returns db marking its statements for the plugins of this Walleter.
+func (s *Walleter) instrumented(db *gorm.DB) *gorm.DB {
+	if s.instrumentation == nil {
+		return db
+	}
+	return db.Session(&gorm.Session{}).Set(instrumentationKey, s.instrumentation).Session(&gorm.Session{})
+}
+
+// instrumentationOf the instrumentation of the Walleter issuing the statement of tx, nil for statements of others.
+func instrumentationOf(tx *gorm.DB) *instrumentation {
+	value, ok := tx.Get(instrumentationKey)
+	if !ok {
+		return nil
+	}
+	result, _ := value.(*instrumentation)
+	return result
+}
+
+// usePlugin registers plugin on db unless a Walleter sharing db registered it already, the plugins of walleter
+// keep no state of their own. Other plugins of the same name fail with gorm.ErrRegistered.
+func usePlugin(db *gorm.DB, plugin gorm.Plugin) error {
+	if registered, ok := db.Config.Plugins[plugin.Name()]; ok && reflect.TypeOf(registered) == reflect.TypeOf(plugin) {
+		return nil
+	}
+	return db.Use(plugin)
+}
+
+// commandAttributes the span attributes describing command.
+func commandAttributes(command WalletCommand) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("walleter.account_id", int64(command.AccountId)),
+		attribute.String("walleter.action_type", command.ActionType.String()),
+		attribute.String("walleter.asset_type", command.AssetType.String()),
+		attribute.String("walleter.business_module", command.BusinessModule),
+	}
+}
+
+// startCommandSpan starts the root span name of a command as child of the span in the context of repo,
+// the returned repo carries the new span so every step and database call is traced below it.
+func (s *Walleter) startCommandSpan(repo Repository, name string, command WalletCommand) (Repository, trace.Span) {
+	ctx, span := s.tracer.Start(repo.Context(), name,
+		trace.WithAttributes(commandAttributes(command)...))
+	if !span.IsRecording() {
+		return repo, span
+	}
+	return repo.WithContext(ctx), span
+}
+
+// startSpan starts the span of one step of command. Nothing is traced unless the context of repo
+// carries a recording span, which is the case below a span started by startCommandSpan.
+func startSpan(repo Repository, name string, command WalletCommand) (Repository, trace.Span) {
+	ctx := repo.Context()
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return repo, trace.SpanFromContext(context.Background())
+	}
+	ctx, span := parent.TracerProvider().Tracer(tracerName).Start(ctx, name,
+		trace.WithAttributes(commandAttributes(command)...))
+	return repo.WithContext(ctx), span
+}
+
+// endSpan ends span, recording err and its error code when the step failed. A held withdrawal did not fail,
+// its hold id is recorded instead.
+func endSpan(span trace.Span, err error) {
+	if held := heldWithdrawalOf(err); held != nil {
+		span.SetAttributes(attribute.Int64("walleter.withdrawal_hold_id", int64(held.HoldId)))
+	} else if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.String("walleter.error_code", string(ErrorCodeOf(err))))
+	}
+	span.End()
+}
+
+func contextOf(db *gorm.DB) context.Context {
+	if db.Statement != nil && db.Statement.Context != nil {
+		return db.Statement.Context
+	}
+	return context.Background()
+}
+
+// tracingPlugin starts a span for every database call of a traced Walleter whose context carries a recording span,
+// so each DAO call shows up below the step issuing it.
+type tracingPlugin struct{}
+
+func (p *tracingPlugin) Name() string {
+	return "walleter:tracing"
+}
+
+func (p *tracingPlugin) Initialize(db *gorm.DB) error {
+	before := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if scope := instrumentationOf(tx); scope == nil || !scope.tracing {
+				return
+			}
+			parent := trace.SpanFromContext(contextOf(tx))
+			if !parent.IsRecording() {
+				return
+			}
+			ctx, span := parent.TracerProvider().Tracer(tracerName).Start(contextOf(tx), "walleter.db."+operation,
+				trace.WithSpanKind(trace.SpanKindClient))
+			tx.Statement.Context = ctx
+			tx.InstanceSet(tracingSpanKey, span)
+		}
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			value, ok := tx.InstanceGet(tracingSpanKey)
+			if !ok {
+				return
+			}
+			span := value.(trace.Span)
+			span.SetAttributes(
+				attribute.String("db.operation", operation),
+				attribute.String("db.sql.table", tx.Statement.Table),
+				attribute.String("db.statement", tx.Statement.SQL.String()),
+				attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+			)
+			err := tx.Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = nil
+			}
+			endSpan(span, err)
+		}
+	}
+
+	callback := db.Callback()
+	registrations := []error{
+		callback.Create().Before("gorm:create").Register("walleter:tracing_before_create", before("create")),
+		callback.Create().After("gorm:create").Register("walleter:tracing_after_create", after("create")),
+		callback.Query().Before("gorm:query").Register("walleter:tracing_before_query", before("query")),
+		callback.Query().After("gorm:query").Register("walleter:tracing_after_query", after("query")),
+		callback.Update().Before("gorm:update").Register("walleter:tracing_before_update", before("update")),
+		callback.Update().After("gorm:update").Register("walleter:tracing_after_update", after("update")),
+		callback.Delete().Before("gorm:delete").Register("walleter:tracing_before_delete", before("delete")),
+		callback.Delete().After("gorm:delete").Register("walleter:tracing_after_delete", after("delete")),
+		callback.Row().Before("gorm:row").Register("walleter:tracing_before_row", before("row")),
+		callback.Row().After("gorm:row").Register("walleter:tracing_after_row", after("row")),
+		callback.Raw().Before("gorm:raw").Register("walleter:tracing_before_raw", before("raw")),
+		callback.Raw().After("gorm:raw").Register("walleter:tracing_after_raw", after("raw")),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
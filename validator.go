@@ -3,6 +3,7 @@ package walleter
 import (
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"gorm.io/gorm"
@@ -42,6 +43,7 @@ func (receiver walletValidator) generateNewSignHash(w Wallet) (string, error) {
 			TotalDeposit:  token.TotalDeposit,
 			TotalWithdraw: token.TotalWithdraw,
 			TotalFee:      token.TotalFee,
+			Locked:        token.Locked,
 		}
 		newERC20TokenData = append(newERC20TokenData, erc20Data)
 	}
@@ -70,9 +72,33 @@ func (receiver walletValidator) generateNewSignHash(w Wallet) (string, error) {
 	return md5Value, nil
 }
 
+// VerifyWallet checks the check sign of wallet, an ErrIncorrectCheckSign WalletError means its
+// token rows were changed outside walleter.
+func VerifyWallet(wallet Wallet) error {
+	_, err := newWalletValidator().validateWallet(wallet)
+	if errors.Is(err, ErrIncorrectCheckSign) {
+		return newWalletError(err, wallet.AccountId)
+	}
+	return err
+}
+
 func md5Value(str string) string {
 	data := []byte(str)
 	has := md5.Sum(data)
 	md5str := fmt.Sprintf("%x", has)
 	return md5str
 }
+
+// updateCheckSign generates the check sign of userWallet and stores it.
+func updateCheckSign(repo Repository, userWallet Wallet) (Wallet, error) {
+	newCheckSign, err := newWalletValidator().generateNewSignHash(userWallet)
+	if err != nil {
+		return Wallet{}, err
+	}
+	userWallet.CheckSign = newCheckSign
+	err = repo.UpdateWalletCheckSign(userWallet.AccountId, userWallet.CheckSign)
+	if err != nil {
+		return Wallet{}, err
+	}
+	return userWallet, nil
+}
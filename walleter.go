@@ -1,10 +1,11 @@
 package walleter
 
 import (
+	"context"
 	"errors"
-	"os"
+	"time"
 
-	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
@@ -35,6 +36,15 @@ type WalletCommand struct {
 
 	// Command happened source.
 	CommandSource CommandSourceType
+
+	// Approval of the adjustment request this command applies, only set by ApproveAdjustment.
+	Approval *CommandApproval
+
+	// ChainRef the on-chain transfer a deposit credits or reverts, only set for chain deposits.
+	ChainRef *ChainReference
+
+	// DepositAddress a deposit address of the account on CommandSource, a Deposit command may set it instead of AccountId.
+	DepositAddress string
 }
 
 type ERC20Command struct {
@@ -50,40 +60,170 @@ type ERC1155Command struct {
 
 // Walleter the library entry object.
 type Walleter struct {
-	db *gorm.DB
+	db      *gorm.DB
+	repo    Repository
+	metrics *Metrics
+	tracer  trace.Tracer
+	traced  bool
+	logger  Logger
+
+	instrumentation *instrumentation
+
+	autoMigrate      bool
+	withdrawalPolicy WithdrawalPolicy
+	depositAddresses DepositAddressResolver
+	confirmations    map[CommandSourceType]ConfirmationPolicy
 }
 
 var feeChargerAccountId uint64
 
-func New(db *gorm.DB, chargerAccountId uint64) *Walleter {
-	migration(db)
+func New(db *gorm.DB, chargerAccountId uint64, opts ...Option) *Walleter {
+	walleter := newWalleter(nil, opts)
+	db, err := walleter.instrument(db)
+	if err != nil {
+		panic("initialize walleter instrumentation failed: " + err.Error())
+	}
+	walleter.db = db
+	walleter.repo = NewGormRepository(db)
+
+	if walleter.autoMigrate {
+		if err := Migrate(db); err != nil {
+			panic("migrate walleter schema failed: " + err.Error())
+		}
+	}
+	feeChargerAccountId = chargerAccountId
+	_, err = walleter.setFeeChargerAccount()
+	if err != nil {
+		panic("initialize fee charger account failed: " + err.Error())
+	}
+	return walleter
+}
+
+// NewWithRepository creates a Walleter storing into repo, e.g. a MemoryRepository in unit tests.
+// Commands, wallet reads, log queries, statements and the liability report go through repo. Balance
+// history, deposits, deposit addresses, freezes, adjustments and withdrawal holds go through the stores
+// repo implements, they fail with ErrStoreNotSupported when it does not. Recovery, economy aggregates,
+// the outbox dispatcher and webhooks work on the database directly, they fail with ErrDatabaseRequired
+// unless repo was created by NewGormRepository.
+func NewWithRepository(repo Repository, chargerAccountId uint64, opts ...Option) *Walleter {
+	walleter := newWalleter(repo, opts)
+	if gormRepo, ok := repo.(*gormRepository); ok {
+		walleter.db = gormRepo.db
+	}
 	feeChargerAccountId = chargerAccountId
-	walleter := Walleter{db: db}
 	_, err := walleter.setFeeChargerAccount()
 	if err != nil {
-		panic("initialize fee charger account failed")
+		panic("initialize fee charger account failed: " + err.Error())
+	}
+	return walleter
+}
+
+func newWalleter(repo Repository, opts []Option) *Walleter {
+	walleter := &Walleter{
+		repo:        repo,
+		tracer:      trace.NewNoopTracerProvider().Tracer(tracerName),
+		logger:      nopLogger{},
+		autoMigrate: true,
+	}
+	for _, opt := range opts {
+		opt(walleter)
 	}
-	return &walleter
+	return walleter
 }
 
+// HandleWalletCommand handles command on db, which may be a transaction of the caller. A withdrawal held by the
+// WithdrawalPolicy returns the wallet with a WithdrawalHeldError, callers commit its changes as for a success.
 func (s *Walleter) HandleWalletCommand(db *gorm.DB, command WalletCommand) (Wallet, error) {
+	return s.handleCommand(NewGormRepository(s.instrumented(db)), command, false)
+}
+
+// ExecuteCommand handles command on the Repository of this Walleter in one transaction, so the changes, their log
+// and the outbox event commit together. A command rejected with a WalletError still commits its Failed log,
+// a withdrawal held by the WithdrawalPolicy commits its hold and returns the wallet with a WithdrawalHeldError,
+// any other error rolls everything back.
+func (s *Walleter) ExecuteCommand(ctx context.Context, command WalletCommand) (wallet Wallet, err error) {
+	txErr := s.repo.WithContext(ctx).Transaction(func(tx Repository) error {
+		wallet, err = s.handleCommand(tx, command, false)
+		var walletErr *WalletError
+		if errors.As(err, &walletErr) || heldWithdrawalOf(err) != nil {
+			return nil
+		}
+		return err
+	})
+	if err == nil && txErr != nil {
+		return Wallet{}, txErr
+	}
+	return wallet, err
+}
+
+// handleCommand handles command on repo. byOperator commands, issued by operators or crediting and reverting chain
+// deposits, apply to frozen accounts as well and are never held by the WithdrawalPolicy. RevertDeposit is only
+// accepted from them.
+func (s *Walleter) handleCommand(repo Repository, command WalletCommand, byOperator bool) (wallet Wallet, err error) {
+	startedAt := time.Now()
+	repo, span := s.startCommandSpan(repo, "walleter.HandleWalletCommand", command)
+	s.logger.Debug("wallet command started", commandKeyvals(command)...)
+	defer func() {
+		endSpan(span, err)
+		s.metrics.observeCommand(command, err, time.Since(startedAt))
+		s.logCommand(command, err, time.Since(startedAt))
+	}()
+	// after the defer, so commands referencing an unknown deposit address are traced, counted and logged too
+	if command, err = s.resolveCommandAccount(repo, command); err != nil {
+		return Wallet{}, err
+	}
+
 	switch command.ActionType {
 	case Initialize:
-		wallet, err := s.GetWalletByAccountId(command.AccountId)
+		wallet, err := repo.GetWallet(command.AccountId)
 		// if user's wallet doesn't exist, create a new one.
 		if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
-			command := NewInitWalletCommand(feeChargerAccountId)
-			return initWallet(db, command)
+			command := NewInitWalletCommand(command.AccountId)
+			return initWallet(repo, command)
 		}
 		// otherwise return the old one.
 		return wallet, nil
 	default:
-		return updateWallet(db, command)
+		if command.ActionType == RevertDeposit && !byOperator {
+			return Wallet{}, newWalletError(ErrActionTypeNotSupport, command.AccountId)
+		}
+		if err := checkApproval(command, byOperator); err != nil {
+			return Wallet{}, err
+		}
+		if !byOperator {
+			if err := checkNotFrozen(repo, command.AccountId); err != nil {
+				return Wallet{}, err
+			}
+			if s.withdrawalPolicy.holds(command) {
+				return s.holdWithdrawal(repo, command)
+			}
+		}
+		return updateWallet(repo, command)
 	}
 }
 
 func (s *Walleter) GetWalletByAccountId(accountId uint64) (Wallet, error) {
-	return walletDAO.getWallet(s.db, accountId)
+	return s.repo.GetWallet(accountId)
+}
+
+// Ping checks that the database of a Walleter created by New is reachable, for health checks.
+func (s *Walleter) Ping(ctx context.Context) error {
+	if s.db == nil {
+		return nil
+	}
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// database returns the gorm database of this Walleter, ErrDatabaseRequired when it stores into another Repository.
+func (s *Walleter) database() (*gorm.DB, error) {
+	if s.db == nil {
+		return nil, ErrDatabaseRequired
+	}
+	return s.db, nil
 }
 
 // initialize fee charger account in database.
@@ -95,27 +235,13 @@ func (s *Walleter) setFeeChargerAccount() (Wallet, error) {
 	wallet, err := s.GetWalletByAccountId(feeChargerAccountId)
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		command := NewInitWalletCommand(feeChargerAccountId)
-		return s.HandleWalletCommand(s.db, command)
+		return s.handleCommand(s.repo, command, false)
 	}
 	return wallet, nil
 }
 
-func migration(db *gorm.DB) {
-	_ = db.AutoMigrate(ERC20TokenWallet{})
-	_ = db.AutoMigrate(ERC1155TokenWallet{})
-	_ = db.AutoMigrate(Wallet{})
-	_ = db.AutoMigrate(ERC20WalletLog{})
-	_ = db.AutoMigrate(ERC1155WalletLog{})
-}
-
-func init() {
-	log.SetLevel(log.DebugLevel)
-	log.SetFormatter(&log.JSONFormatter{})
-	log.SetOutput(os.Stdout)
-}
-
-func initWallet(db *gorm.DB, command WalletCommand) (Wallet, error) {
-	err := db.Transaction(func(tx1 *gorm.DB) error {
+func initWallet(repo Repository, command WalletCommand) (Wallet, error) {
+	err := repo.Transaction(func(tx1 Repository) error {
 		// 1. Insert change logs, including ERC20 logs and ERC1155 Log.
 		walletLogService := newWalletLogService()
 		erc20WalletLog, err := walletLogService.insertNewERC20WalletLog(tx1, command, Wallet{})
@@ -145,7 +271,7 @@ func initWallet(db *gorm.DB, command WalletCommand) (Wallet, error) {
 		}
 		wallet.CheckSign = newCheckSign
 
-		err = walletDAO.createWallet(tx1, wallet)
+		err = tx1.CreateWallet(wallet)
 		if err != nil {
 			return err
 		}
@@ -161,25 +287,26 @@ func initWallet(db *gorm.DB, command WalletCommand) (Wallet, error) {
 			return err
 		}
 
-		return nil
+		// 5. publish the new wallet through the outbox
+		return newWalletEventService().publishWalletChanged(tx1, command, erc20WalletLog.ID, wallet)
 	})
 	if err != nil {
 		return Wallet{}, err
 	}
-	return walletDAO.getWallet(db, command.AccountId)
+	return repo.GetWallet(command.AccountId)
 }
 
-func updateWallet(db *gorm.DB, command WalletCommand) (Wallet, error) {
+func updateWallet(repo Repository, command WalletCommand) (Wallet, error) {
 	if command.AssetType == Other {
-		return Wallet{}, ErrIncorrectAssetType
+		return Wallet{}, newWalletError(ErrIncorrectAssetType, command.AccountId)
 	}
 	switch command.AssetType {
 	case ERC20AssetType:
-		return handleERC20Command(db, command)
+		return handleERC20Command(repo, command)
 	case ERC1155AssetType:
-		return handleERC1155Command(db, command)
+		return handleERC1155Command(repo, command)
 	}
-	return Wallet{}, ErrAssetTypeNotSupport
+	return Wallet{}, newWalletError(ErrAssetTypeNotSupport, command.AccountId)
 }
 
 func NewInitWalletCommand(accountId uint64) WalletCommand {
@@ -0,0 +1,5 @@
+// Package walleterpb holds the protobuf messages of the walleter gRPC API and the generated client,
+// see proto/walleter/v1/walleter.proto and the grpcserver package.
+package walleterpb
+
+//go:generate sh -c "cd .. && buf generate proto"
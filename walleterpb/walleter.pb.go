@@ -0,0 +1,2777 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        (unknown)
+// source: walleter/v1/walleter.proto
+
+package walleterpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type AssetType int32
+
+const (
+	AssetType_ASSET_TYPE_ERC20   AssetType = 0
+	AssetType_ASSET_TYPE_ERC1155 AssetType = 1
+	AssetType_ASSET_TYPE_OTHER   AssetType = 2
+)
+
+// Enum value maps for AssetType.
+var (
+	AssetType_name = map[int32]string{
+		0: "ASSET_TYPE_ERC20",
+		1: "ASSET_TYPE_ERC1155",
+		2: "ASSET_TYPE_OTHER",
+	}
+	AssetType_value = map[string]int32{
+		"ASSET_TYPE_ERC20":   0,
+		"ASSET_TYPE_ERC1155": 1,
+		"ASSET_TYPE_OTHER":   2,
+	}
+)
+
+func (x AssetType) Enum() *AssetType {
+	p := new(AssetType)
+	*p = x
+	return p
+}
+
+func (x AssetType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AssetType) Descriptor() protoreflect.EnumDescriptor {
+	return file_walleter_v1_walleter_proto_enumTypes[0].Descriptor()
+}
+
+func (AssetType) Type() protoreflect.EnumType {
+	return &file_walleter_v1_walleter_proto_enumTypes[0]
+}
+
+func (x AssetType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AssetType.Descriptor instead.
+func (AssetType) EnumDescriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{0}
+}
+
+type ERC20Token int32
+
+const (
+	ERC20Token_ERC20_TOKEN_ETH   ERC20Token = 0
+	ERC20Token_ERC20_TOKEN_BNB   ERC20Token = 1
+	ERC20Token_ERC20_TOKEN_USDT  ERC20Token = 2
+	ERC20Token_ERC20_TOKEN_USDC  ERC20Token = 3
+	ERC20Token_ERC20_TOKEN_BUSD  ERC20Token = 4
+	ERC20Token_ERC20_TOKEN_NAMIX ERC20Token = 5
+	ERC20Token_ERC20_TOKEN_FISHX ERC20Token = 6
+)
+
+// Enum value maps for ERC20Token.
+var (
+	ERC20Token_name = map[int32]string{
+		0: "ERC20_TOKEN_ETH",
+		1: "ERC20_TOKEN_BNB",
+		2: "ERC20_TOKEN_USDT",
+		3: "ERC20_TOKEN_USDC",
+		4: "ERC20_TOKEN_BUSD",
+		5: "ERC20_TOKEN_NAMIX",
+		6: "ERC20_TOKEN_FISHX",
+	}
+	ERC20Token_value = map[string]int32{
+		"ERC20_TOKEN_ETH":   0,
+		"ERC20_TOKEN_BNB":   1,
+		"ERC20_TOKEN_USDT":  2,
+		"ERC20_TOKEN_USDC":  3,
+		"ERC20_TOKEN_BUSD":  4,
+		"ERC20_TOKEN_NAMIX": 5,
+		"ERC20_TOKEN_FISHX": 6,
+	}
+)
+
+func (x ERC20Token) Enum() *ERC20Token {
+	p := new(ERC20Token)
+	*p = x
+	return p
+}
+
+func (x ERC20Token) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ERC20Token) Descriptor() protoreflect.EnumDescriptor {
+	return file_walleter_v1_walleter_proto_enumTypes[1].Descriptor()
+}
+
+func (ERC20Token) Type() protoreflect.EnumType {
+	return &file_walleter_v1_walleter_proto_enumTypes[1]
+}
+
+func (x ERC20Token) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ERC20Token.Descriptor instead.
+func (ERC20Token) EnumDescriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{1}
+}
+
+type WalletActionType int32
+
+const (
+	WalletActionType_WALLET_ACTION_TYPE_INITIALIZE WalletActionType = 0
+	WalletActionType_WALLET_ACTION_TYPE_INCOME     WalletActionType = 1
+	WalletActionType_WALLET_ACTION_TYPE_SPEND      WalletActionType = 2
+	WalletActionType_WALLET_ACTION_TYPE_DEPOSIT    WalletActionType = 3
+	WalletActionType_WALLET_ACTION_TYPE_WITHDRAW   WalletActionType = 4
+	WalletActionType_WALLET_ACTION_TYPE_CHARGE_FEE WalletActionType = 5
+)
+
+// Enum value maps for WalletActionType.
+var (
+	WalletActionType_name = map[int32]string{
+		0: "WALLET_ACTION_TYPE_INITIALIZE",
+		1: "WALLET_ACTION_TYPE_INCOME",
+		2: "WALLET_ACTION_TYPE_SPEND",
+		3: "WALLET_ACTION_TYPE_DEPOSIT",
+		4: "WALLET_ACTION_TYPE_WITHDRAW",
+		5: "WALLET_ACTION_TYPE_CHARGE_FEE",
+	}
+	WalletActionType_value = map[string]int32{
+		"WALLET_ACTION_TYPE_INITIALIZE": 0,
+		"WALLET_ACTION_TYPE_INCOME":     1,
+		"WALLET_ACTION_TYPE_SPEND":      2,
+		"WALLET_ACTION_TYPE_DEPOSIT":    3,
+		"WALLET_ACTION_TYPE_WITHDRAW":   4,
+		"WALLET_ACTION_TYPE_CHARGE_FEE": 5,
+	}
+)
+
+func (x WalletActionType) Enum() *WalletActionType {
+	p := new(WalletActionType)
+	*p = x
+	return p
+}
+
+func (x WalletActionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WalletActionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_walleter_v1_walleter_proto_enumTypes[2].Descriptor()
+}
+
+func (WalletActionType) Type() protoreflect.EnumType {
+	return &file_walleter_v1_walleter_proto_enumTypes[2]
+}
+
+func (x WalletActionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WalletActionType.Descriptor instead.
+func (WalletActionType) EnumDescriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{2}
+}
+
+type CommandSource int32
+
+const (
+	CommandSource_COMMAND_SOURCE_IN_GAME        CommandSource = 0
+	CommandSource_COMMAND_SOURCE_ETHEREUM       CommandSource = 1
+	CommandSource_COMMAND_SOURCE_GOERLI_TESTNET CommandSource = 2
+	CommandSource_COMMAND_SOURCE_BSC            CommandSource = 3
+	CommandSource_COMMAND_SOURCE_BSC_TESTNET    CommandSource = 4
+)
+
+// Enum value maps for CommandSource.
+var (
+	CommandSource_name = map[int32]string{
+		0: "COMMAND_SOURCE_IN_GAME",
+		1: "COMMAND_SOURCE_ETHEREUM",
+		2: "COMMAND_SOURCE_GOERLI_TESTNET",
+		3: "COMMAND_SOURCE_BSC",
+		4: "COMMAND_SOURCE_BSC_TESTNET",
+	}
+	CommandSource_value = map[string]int32{
+		"COMMAND_SOURCE_IN_GAME":        0,
+		"COMMAND_SOURCE_ETHEREUM":       1,
+		"COMMAND_SOURCE_GOERLI_TESTNET": 2,
+		"COMMAND_SOURCE_BSC":            3,
+		"COMMAND_SOURCE_BSC_TESTNET":    4,
+	}
+)
+
+func (x CommandSource) Enum() *CommandSource {
+	p := new(CommandSource)
+	*p = x
+	return p
+}
+
+func (x CommandSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CommandSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_walleter_v1_walleter_proto_enumTypes[3].Descriptor()
+}
+
+func (CommandSource) Type() protoreflect.EnumType {
+	return &file_walleter_v1_walleter_proto_enumTypes[3]
+}
+
+func (x CommandSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CommandSource.Descriptor instead.
+func (CommandSource) EnumDescriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{3}
+}
+
+type WalletLogStatus int32
+
+const (
+	WalletLogStatus_WALLET_LOG_STATUS_PENDING WalletLogStatus = 0
+	WalletLogStatus_WALLET_LOG_STATUS_DONE    WalletLogStatus = 1
+	WalletLogStatus_WALLET_LOG_STATUS_FAILED  WalletLogStatus = 2
+	WalletLogStatus_WALLET_LOG_STATUS_HELD    WalletLogStatus = 3
+)
+
+// Enum value maps for WalletLogStatus.
+var (
+	WalletLogStatus_name = map[int32]string{
+		0: "WALLET_LOG_STATUS_PENDING",
+		1: "WALLET_LOG_STATUS_DONE",
+		2: "WALLET_LOG_STATUS_FAILED",
+		3: "WALLET_LOG_STATUS_HELD",
+	}
+	WalletLogStatus_value = map[string]int32{
+		"WALLET_LOG_STATUS_PENDING": 0,
+		"WALLET_LOG_STATUS_DONE":    1,
+		"WALLET_LOG_STATUS_FAILED":  2,
+		"WALLET_LOG_STATUS_HELD":    3,
+	}
+)
+
+func (x WalletLogStatus) Enum() *WalletLogStatus {
+	p := new(WalletLogStatus)
+	*p = x
+	return p
+}
+
+func (x WalletLogStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WalletLogStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_walleter_v1_walleter_proto_enumTypes[4].Descriptor()
+}
+
+func (WalletLogStatus) Type() protoreflect.EnumType {
+	return &file_walleter_v1_walleter_proto_enumTypes[4]
+}
+
+func (x WalletLogStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WalletLogStatus.Descriptor instead.
+func (WalletLogStatus) EnumDescriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{4}
+}
+
+type ERC20Command struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token   ERC20Token `protobuf:"varint,1,opt,name=token,proto3,enum=walleter.v1.ERC20Token" json:"token,omitempty"`
+	Value   float64    `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	Decimal uint64     `protobuf:"varint,3,opt,name=decimal,proto3" json:"decimal,omitempty"`
+}
+
+func (x *ERC20Command) Reset() {
+	*x = ERC20Command{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ERC20Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ERC20Command) ProtoMessage() {}
+
+func (x *ERC20Command) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ERC20Command.ProtoReflect.Descriptor instead.
+func (*ERC20Command) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ERC20Command) GetToken() ERC20Token {
+	if x != nil {
+		return x.Token
+	}
+	return ERC20Token_ERC20_TOKEN_ETH
+}
+
+func (x *ERC20Command) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *ERC20Command) GetDecimal() uint64 {
+	if x != nil {
+		return x.Decimal
+	}
+	return 0
+}
+
+type ERC1155Command struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ids []uint64 `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	// values[i] is the amount of ids[i].
+	Values []uint64 `protobuf:"varint,2,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *ERC1155Command) Reset() {
+	*x = ERC1155Command{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ERC1155Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ERC1155Command) ProtoMessage() {}
+
+func (x *ERC1155Command) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ERC1155Command.ProtoReflect.Descriptor instead.
+func (*ERC1155Command) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ERC1155Command) GetIds() []uint64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *ERC1155Command) GetValues() []uint64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type WalletCommand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId      uint64           `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	AssetType      AssetType        `protobuf:"varint,2,opt,name=asset_type,json=assetType,proto3,enum=walleter.v1.AssetType" json:"asset_type,omitempty"`
+	ActionType     WalletActionType `protobuf:"varint,3,opt,name=action_type,json=actionType,proto3,enum=walleter.v1.WalletActionType" json:"action_type,omitempty"`
+	Erc20Commands  []*ERC20Command  `protobuf:"bytes,4,rep,name=erc20_commands,json=erc20Commands,proto3" json:"erc20_commands,omitempty"`
+	Erc1155Command *ERC1155Command  `protobuf:"bytes,5,opt,name=erc1155_command,json=erc1155Command,proto3" json:"erc1155_command,omitempty"`
+	// Fees charged from the account and credited to the fee charger account.
+	FeeCommands []*ERC20Command `protobuf:"bytes,6,rep,name=fee_commands,json=feeCommands,proto3" json:"fee_commands,omitempty"`
+	// Which part of the platform sent the command.
+	BusinessModule string        `protobuf:"bytes,7,opt,name=business_module,json=businessModule,proto3" json:"business_module,omitempty"`
+	CommandSource  CommandSource `protobuf:"varint,8,opt,name=command_source,json=commandSource,proto3,enum=walleter.v1.CommandSource" json:"command_source,omitempty"`
+}
+
+func (x *WalletCommand) Reset() {
+	*x = WalletCommand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WalletCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletCommand) ProtoMessage() {}
+
+func (x *WalletCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletCommand.ProtoReflect.Descriptor instead.
+func (*WalletCommand) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WalletCommand) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *WalletCommand) GetAssetType() AssetType {
+	if x != nil {
+		return x.AssetType
+	}
+	return AssetType_ASSET_TYPE_ERC20
+}
+
+func (x *WalletCommand) GetActionType() WalletActionType {
+	if x != nil {
+		return x.ActionType
+	}
+	return WalletActionType_WALLET_ACTION_TYPE_INITIALIZE
+}
+
+func (x *WalletCommand) GetErc20Commands() []*ERC20Command {
+	if x != nil {
+		return x.Erc20Commands
+	}
+	return nil
+}
+
+func (x *WalletCommand) GetErc1155Command() *ERC1155Command {
+	if x != nil {
+		return x.Erc1155Command
+	}
+	return nil
+}
+
+func (x *WalletCommand) GetFeeCommands() []*ERC20Command {
+	if x != nil {
+		return x.FeeCommands
+	}
+	return nil
+}
+
+func (x *WalletCommand) GetBusinessModule() string {
+	if x != nil {
+		return x.BusinessModule
+	}
+	return ""
+}
+
+func (x *WalletCommand) GetCommandSource() CommandSource {
+	if x != nil {
+		return x.CommandSource
+	}
+	return CommandSource_COMMAND_SOURCE_IN_GAME
+}
+
+type ERC20TokenWallet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token         string  `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Balance       float64 `protobuf:"fixed64,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Decimal       uint64  `protobuf:"varint,3,opt,name=decimal,proto3" json:"decimal,omitempty"`
+	TotalIncome   float64 `protobuf:"fixed64,4,opt,name=total_income,json=totalIncome,proto3" json:"total_income,omitempty"`
+	TotalSpend    float64 `protobuf:"fixed64,5,opt,name=total_spend,json=totalSpend,proto3" json:"total_spend,omitempty"`
+	TotalDeposit  float64 `protobuf:"fixed64,6,opt,name=total_deposit,json=totalDeposit,proto3" json:"total_deposit,omitempty"`
+	TotalWithdraw float64 `protobuf:"fixed64,7,opt,name=total_withdraw,json=totalWithdraw,proto3" json:"total_withdraw,omitempty"`
+	TotalFee      float64 `protobuf:"fixed64,8,opt,name=total_fee,json=totalFee,proto3" json:"total_fee,omitempty"`
+	Locked        float64 `protobuf:"fixed64,9,opt,name=locked,proto3" json:"locked,omitempty"`
+}
+
+func (x *ERC20TokenWallet) Reset() {
+	*x = ERC20TokenWallet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ERC20TokenWallet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ERC20TokenWallet) ProtoMessage() {}
+
+func (x *ERC20TokenWallet) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ERC20TokenWallet.ProtoReflect.Descriptor instead.
+func (*ERC20TokenWallet) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ERC20TokenWallet) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ERC20TokenWallet) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *ERC20TokenWallet) GetDecimal() uint64 {
+	if x != nil {
+		return x.Decimal
+	}
+	return 0
+}
+
+func (x *ERC20TokenWallet) GetTotalIncome() float64 {
+	if x != nil {
+		return x.TotalIncome
+	}
+	return 0
+}
+
+func (x *ERC20TokenWallet) GetTotalSpend() float64 {
+	if x != nil {
+		return x.TotalSpend
+	}
+	return 0
+}
+
+func (x *ERC20TokenWallet) GetTotalDeposit() float64 {
+	if x != nil {
+		return x.TotalDeposit
+	}
+	return 0
+}
+
+func (x *ERC20TokenWallet) GetTotalWithdraw() float64 {
+	if x != nil {
+		return x.TotalWithdraw
+	}
+	return 0
+}
+
+func (x *ERC20TokenWallet) GetTotalFee() float64 {
+	if x != nil {
+		return x.TotalFee
+	}
+	return 0
+}
+
+func (x *ERC20TokenWallet) GetLocked() float64 {
+	if x != nil {
+		return x.Locked
+	}
+	return 0
+}
+
+type ERC1155TokenWallet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ids []uint64 `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	// values[i] is the amount of ids[i].
+	Values []uint64 `protobuf:"varint,2,rep,packed,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *ERC1155TokenWallet) Reset() {
+	*x = ERC1155TokenWallet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ERC1155TokenWallet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ERC1155TokenWallet) ProtoMessage() {}
+
+func (x *ERC1155TokenWallet) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ERC1155TokenWallet.ProtoReflect.Descriptor instead.
+func (*ERC1155TokenWallet) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ERC1155TokenWallet) GetIds() []uint64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *ERC1155TokenWallet) GetValues() []uint64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type Wallet struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId        uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Erc20TokenData   []*ERC20TokenWallet    `protobuf:"bytes,2,rep,name=erc20_token_data,json=erc20TokenData,proto3" json:"erc20_token_data,omitempty"`
+	Erc1155TokenData *ERC1155TokenWallet    `protobuf:"bytes,3,opt,name=erc1155_token_data,json=erc1155TokenData,proto3" json:"erc1155_token_data,omitempty"`
+	CheckSign        string                 `protobuf:"bytes,4,opt,name=check_sign,json=checkSign,proto3" json:"check_sign,omitempty"`
+	CreatedAt        *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *Wallet) Reset() {
+	*x = Wallet{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Wallet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wallet) ProtoMessage() {}
+
+func (x *Wallet) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wallet.ProtoReflect.Descriptor instead.
+func (*Wallet) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Wallet) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *Wallet) GetErc20TokenData() []*ERC20TokenWallet {
+	if x != nil {
+		return x.Erc20TokenData
+	}
+	return nil
+}
+
+func (x *Wallet) GetErc1155TokenData() *ERC1155TokenWallet {
+	if x != nil {
+		return x.Erc1155TokenData
+	}
+	return nil
+}
+
+func (x *Wallet) GetCheckSign() string {
+	if x != nil {
+		return x.CheckSign
+	}
+	return ""
+}
+
+func (x *Wallet) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Wallet) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ERC20BalanceHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Token     string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	Balance   float64                `protobuf:"fixed64,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	Change    float64                `protobuf:"fixed64,4,opt,name=change,proto3" json:"change,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *ERC20BalanceHistory) Reset() {
+	*x = ERC20BalanceHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ERC20BalanceHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ERC20BalanceHistory) ProtoMessage() {}
+
+func (x *ERC20BalanceHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ERC20BalanceHistory.ProtoReflect.Descriptor instead.
+func (*ERC20BalanceHistory) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ERC20BalanceHistory) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *ERC20BalanceHistory) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ERC20BalanceHistory) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *ERC20BalanceHistory) GetChange() float64 {
+	if x != nil {
+		return x.Change
+	}
+	return 0
+}
+
+func (x *ERC20BalanceHistory) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ERC1155BalanceHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	TokenId   uint64                 `protobuf:"varint,2,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	Balance   uint64                 `protobuf:"varint,3,opt,name=balance,proto3" json:"balance,omitempty"`
+	Change    int64                  `protobuf:"varint,4,opt,name=change,proto3" json:"change,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *ERC1155BalanceHistory) Reset() {
+	*x = ERC1155BalanceHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ERC1155BalanceHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ERC1155BalanceHistory) ProtoMessage() {}
+
+func (x *ERC1155BalanceHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ERC1155BalanceHistory.ProtoReflect.Descriptor instead.
+func (*ERC1155BalanceHistory) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ERC1155BalanceHistory) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *ERC1155BalanceHistory) GetTokenId() uint64 {
+	if x != nil {
+		return x.TokenId
+	}
+	return 0
+}
+
+func (x *ERC1155BalanceHistory) GetBalance() uint64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *ERC1155BalanceHistory) GetChange() int64 {
+	if x != nil {
+		return x.Change
+	}
+	return 0
+}
+
+func (x *ERC1155BalanceHistory) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type TokenAmount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token   string  `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Amount  float64 `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Decimal uint64  `protobuf:"varint,3,opt,name=decimal,proto3" json:"decimal,omitempty"`
+}
+
+func (x *TokenAmount) Reset() {
+	*x = TokenAmount{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TokenAmount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenAmount) ProtoMessage() {}
+
+func (x *TokenAmount) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenAmount.ProtoReflect.Descriptor instead.
+func (*TokenAmount) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TokenAmount) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *TokenAmount) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *TokenAmount) GetDecimal() uint64 {
+	if x != nil {
+		return x.Decimal
+	}
+	return 0
+}
+
+type ItemAmount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Amount uint64 `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+}
+
+func (x *ItemAmount) Reset() {
+	*x = ItemAmount{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ItemAmount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemAmount) ProtoMessage() {}
+
+func (x *ItemAmount) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemAmount.ProtoReflect.Descriptor instead.
+func (*ItemAmount) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ItemAmount) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ItemAmount) GetAmount() uint64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+// WalletLog one command handled for an account, with its decoded amounts.
+type WalletLog struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id             uint64         `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AssetType      AssetType      `protobuf:"varint,2,opt,name=asset_type,json=assetType,proto3,enum=walleter.v1.AssetType" json:"asset_type,omitempty"`
+	AccountId      uint64         `protobuf:"varint,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	BusinessModule string         `protobuf:"bytes,4,opt,name=business_module,json=businessModule,proto3" json:"business_module,omitempty"`
+	ActionType     string         `protobuf:"bytes,5,opt,name=action_type,json=actionType,proto3" json:"action_type,omitempty"`
+	Source         string         `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Status         string         `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	Tokens         []*TokenAmount `protobuf:"bytes,8,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	Items          []*ItemAmount  `protobuf:"bytes,9,rep,name=items,proto3" json:"items,omitempty"`
+	Fees           []*TokenAmount `protobuf:"bytes,10,rep,name=fees,proto3" json:"fees,omitempty"`
+	// The walleter error code and message of a failed log, empty otherwise.
+	FailureCode    string                 `protobuf:"bytes,11,opt,name=failure_code,json=failureCode,proto3" json:"failure_code,omitempty"`
+	FailureMessage string                 `protobuf:"bytes,12,opt,name=failure_message,json=failureMessage,proto3" json:"failure_message,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *WalletLog) Reset() {
+	*x = WalletLog{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WalletLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletLog) ProtoMessage() {}
+
+func (x *WalletLog) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletLog.ProtoReflect.Descriptor instead.
+func (*WalletLog) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WalletLog) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *WalletLog) GetAssetType() AssetType {
+	if x != nil {
+		return x.AssetType
+	}
+	return AssetType_ASSET_TYPE_ERC20
+}
+
+func (x *WalletLog) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *WalletLog) GetBusinessModule() string {
+	if x != nil {
+		return x.BusinessModule
+	}
+	return ""
+}
+
+func (x *WalletLog) GetActionType() string {
+	if x != nil {
+		return x.ActionType
+	}
+	return ""
+}
+
+func (x *WalletLog) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *WalletLog) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WalletLog) GetTokens() []*TokenAmount {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *WalletLog) GetItems() []*ItemAmount {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *WalletLog) GetFees() []*TokenAmount {
+	if x != nil {
+		return x.Fees
+	}
+	return nil
+}
+
+func (x *WalletLog) GetFailureCode() string {
+	if x != nil {
+		return x.FailureCode
+	}
+	return ""
+}
+
+func (x *WalletLog) GetFailureMessage() string {
+	if x != nil {
+		return x.FailureMessage
+	}
+	return ""
+}
+
+func (x *WalletLog) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *WalletLog) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type HandleWalletCommandRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Command *WalletCommand `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+}
+
+func (x *HandleWalletCommandRequest) Reset() {
+	*x = HandleWalletCommandRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HandleWalletCommandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandleWalletCommandRequest) ProtoMessage() {}
+
+func (x *HandleWalletCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandleWalletCommandRequest.ProtoReflect.Descriptor instead.
+func (*HandleWalletCommandRequest) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *HandleWalletCommandRequest) GetCommand() *WalletCommand {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+type HandleWalletCommandResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Wallet *Wallet `protobuf:"bytes,1,opt,name=wallet,proto3" json:"wallet,omitempty"`
+	// WALLET_LOG_STATUS_DONE, or WALLET_LOG_STATUS_HELD for a withdrawal held for approval.
+	Status WalletLogStatus `protobuf:"varint,2,opt,name=status,proto3,enum=walleter.v1.WalletLogStatus" json:"status,omitempty"`
+	// The hold of a held withdrawal, 0 otherwise.
+	WithdrawalHoldId uint64 `protobuf:"varint,3,opt,name=withdrawal_hold_id,json=withdrawalHoldId,proto3" json:"withdrawal_hold_id,omitempty"`
+}
+
+func (x *HandleWalletCommandResponse) Reset() {
+	*x = HandleWalletCommandResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HandleWalletCommandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandleWalletCommandResponse) ProtoMessage() {}
+
+func (x *HandleWalletCommandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandleWalletCommandResponse.ProtoReflect.Descriptor instead.
+func (*HandleWalletCommandResponse) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *HandleWalletCommandResponse) GetWallet() *Wallet {
+	if x != nil {
+		return x.Wallet
+	}
+	return nil
+}
+
+func (x *HandleWalletCommandResponse) GetStatus() WalletLogStatus {
+	if x != nil {
+		return x.Status
+	}
+	return WalletLogStatus_WALLET_LOG_STATUS_PENDING
+}
+
+func (x *HandleWalletCommandResponse) GetWithdrawalHoldId() uint64 {
+	if x != nil {
+		return x.WithdrawalHoldId
+	}
+	return 0
+}
+
+type GetWalletRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId uint64 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+}
+
+func (x *GetWalletRequest) Reset() {
+	*x = GetWalletRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetWalletRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWalletRequest) ProtoMessage() {}
+
+func (x *GetWalletRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWalletRequest.ProtoReflect.Descriptor instead.
+func (*GetWalletRequest) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetWalletRequest) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+type GetWalletResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Wallet *Wallet `protobuf:"bytes,1,opt,name=wallet,proto3" json:"wallet,omitempty"`
+}
+
+func (x *GetWalletResponse) Reset() {
+	*x = GetWalletResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetWalletResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWalletResponse) ProtoMessage() {}
+
+func (x *GetWalletResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWalletResponse.ProtoReflect.Descriptor instead.
+func (*GetWalletResponse) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetWalletResponse) GetWallet() *Wallet {
+	if x != nil {
+		return x.Wallet
+	}
+	return nil
+}
+
+type GetWalletAtRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	At        *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+}
+
+func (x *GetWalletAtRequest) Reset() {
+	*x = GetWalletAtRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetWalletAtRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWalletAtRequest) ProtoMessage() {}
+
+func (x *GetWalletAtRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWalletAtRequest.ProtoReflect.Descriptor instead.
+func (*GetWalletAtRequest) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetWalletAtRequest) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *GetWalletAtRequest) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type GetWalletAtResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId       uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	At              *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	Erc20Balances   map[string]float64     `protobuf:"bytes,3,rep,name=erc20_balances,json=erc20Balances,proto3" json:"erc20_balances,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	Erc1155Balances map[uint64]uint64      `protobuf:"bytes,4,rep,name=erc1155_balances,json=erc1155Balances,proto3" json:"erc1155_balances,omitempty" protobuf_key:"varint,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *GetWalletAtResponse) Reset() {
+	*x = GetWalletAtResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetWalletAtResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWalletAtResponse) ProtoMessage() {}
+
+func (x *GetWalletAtResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWalletAtResponse.ProtoReflect.Descriptor instead.
+func (*GetWalletAtResponse) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetWalletAtResponse) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *GetWalletAtResponse) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *GetWalletAtResponse) GetErc20Balances() map[string]float64 {
+	if x != nil {
+		return x.Erc20Balances
+	}
+	return nil
+}
+
+func (x *GetWalletAtResponse) GetErc1155Balances() map[uint64]uint64 {
+	if x != nil {
+		return x.Erc1155Balances
+	}
+	return nil
+}
+
+type GetERC20BalanceHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Token     ERC20Token             `protobuf:"varint,2,opt,name=token,proto3,enum=walleter.v1.ERC20Token" json:"token,omitempty"`
+	From      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *GetERC20BalanceHistoryRequest) Reset() {
+	*x = GetERC20BalanceHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetERC20BalanceHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetERC20BalanceHistoryRequest) ProtoMessage() {}
+
+func (x *GetERC20BalanceHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetERC20BalanceHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetERC20BalanceHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetERC20BalanceHistoryRequest) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *GetERC20BalanceHistoryRequest) GetToken() ERC20Token {
+	if x != nil {
+		return x.Token
+	}
+	return ERC20Token_ERC20_TOKEN_ETH
+}
+
+func (x *GetERC20BalanceHistoryRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetERC20BalanceHistoryRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type GetERC20BalanceHistoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Histories []*ERC20BalanceHistory `protobuf:"bytes,1,rep,name=histories,proto3" json:"histories,omitempty"`
+}
+
+func (x *GetERC20BalanceHistoryResponse) Reset() {
+	*x = GetERC20BalanceHistoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetERC20BalanceHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetERC20BalanceHistoryResponse) ProtoMessage() {}
+
+func (x *GetERC20BalanceHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetERC20BalanceHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetERC20BalanceHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetERC20BalanceHistoryResponse) GetHistories() []*ERC20BalanceHistory {
+	if x != nil {
+		return x.Histories
+	}
+	return nil
+}
+
+type GetERC1155BalanceHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId uint64                 `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	TokenId   uint64                 `protobuf:"varint,2,opt,name=token_id,json=tokenId,proto3" json:"token_id,omitempty"`
+	From      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (x *GetERC1155BalanceHistoryRequest) Reset() {
+	*x = GetERC1155BalanceHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetERC1155BalanceHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetERC1155BalanceHistoryRequest) ProtoMessage() {}
+
+func (x *GetERC1155BalanceHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetERC1155BalanceHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetERC1155BalanceHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetERC1155BalanceHistoryRequest) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *GetERC1155BalanceHistoryRequest) GetTokenId() uint64 {
+	if x != nil {
+		return x.TokenId
+	}
+	return 0
+}
+
+func (x *GetERC1155BalanceHistoryRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetERC1155BalanceHistoryRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type GetERC1155BalanceHistoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Histories []*ERC1155BalanceHistory `protobuf:"bytes,1,rep,name=histories,proto3" json:"histories,omitempty"`
+}
+
+func (x *GetERC1155BalanceHistoryResponse) Reset() {
+	*x = GetERC1155BalanceHistoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetERC1155BalanceHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetERC1155BalanceHistoryResponse) ProtoMessage() {}
+
+func (x *GetERC1155BalanceHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetERC1155BalanceHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetERC1155BalanceHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetERC1155BalanceHistoryResponse) GetHistories() []*ERC1155BalanceHistory {
+	if x != nil {
+		return x.Histories
+	}
+	return nil
+}
+
+// Empty filters match every log, repeated filters match any of their values.
+type ListWalletLogsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccountId      uint64             `protobuf:"varint,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	AssetTypes     []AssetType        `protobuf:"varint,2,rep,packed,name=asset_types,json=assetTypes,proto3,enum=walleter.v1.AssetType" json:"asset_types,omitempty"`
+	ActionTypes    []WalletActionType `protobuf:"varint,3,rep,packed,name=action_types,json=actionTypes,proto3,enum=walleter.v1.WalletActionType" json:"action_types,omitempty"`
+	BusinessModule string             `protobuf:"bytes,4,opt,name=business_module,json=businessModule,proto3" json:"business_module,omitempty"`
+	Sources        []CommandSource    `protobuf:"varint,5,rep,packed,name=sources,proto3,enum=walleter.v1.CommandSource" json:"sources,omitempty"`
+	Statuses       []WalletLogStatus  `protobuf:"varint,6,rep,packed,name=statuses,proto3,enum=walleter.v1.WalletLogStatus" json:"statuses,omitempty"`
+	// Matches logs which change or charge fees in any of these tokens.
+	Tokens []ERC20Token `protobuf:"varint,7,rep,packed,name=tokens,proto3,enum=walleter.v1.ERC20Token" json:"tokens,omitempty"`
+	// Matches ERC1155 logs which change any of these ids.
+	TokenIds []uint64               `protobuf:"varint,8,rep,packed,name=token_ids,json=tokenIds,proto3" json:"token_ids,omitempty"`
+	From     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=from,proto3" json:"from,omitempty"`
+	To       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=to,proto3" json:"to,omitempty"`
+	// The next_cursor of the previous page, empty for the first page.
+	Cursor string `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Page size, defaults to 20 and is capped at 500.
+	Limit     int32 `protobuf:"varint,12,opt,name=limit,proto3" json:"limit,omitempty"`
+	Ascending bool  `protobuf:"varint,13,opt,name=ascending,proto3" json:"ascending,omitempty"`
+}
+
+func (x *ListWalletLogsRequest) Reset() {
+	*x = ListWalletLogsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListWalletLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWalletLogsRequest) ProtoMessage() {}
+
+func (x *ListWalletLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWalletLogsRequest.ProtoReflect.Descriptor instead.
+func (*ListWalletLogsRequest) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListWalletLogsRequest) GetAccountId() uint64 {
+	if x != nil {
+		return x.AccountId
+	}
+	return 0
+}
+
+func (x *ListWalletLogsRequest) GetAssetTypes() []AssetType {
+	if x != nil {
+		return x.AssetTypes
+	}
+	return nil
+}
+
+func (x *ListWalletLogsRequest) GetActionTypes() []WalletActionType {
+	if x != nil {
+		return x.ActionTypes
+	}
+	return nil
+}
+
+func (x *ListWalletLogsRequest) GetBusinessModule() string {
+	if x != nil {
+		return x.BusinessModule
+	}
+	return ""
+}
+
+func (x *ListWalletLogsRequest) GetSources() []CommandSource {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *ListWalletLogsRequest) GetStatuses() []WalletLogStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListWalletLogsRequest) GetTokens() []ERC20Token {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *ListWalletLogsRequest) GetTokenIds() []uint64 {
+	if x != nil {
+		return x.TokenIds
+	}
+	return nil
+}
+
+func (x *ListWalletLogsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListWalletLogsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ListWalletLogsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListWalletLogsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListWalletLogsRequest) GetAscending() bool {
+	if x != nil {
+		return x.Ascending
+	}
+	return false
+}
+
+type ListWalletLogsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Logs []*WalletLog `protobuf:"bytes,1,rep,name=logs,proto3" json:"logs,omitempty"`
+	// Empty when there are no more logs.
+	NextCursor string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+}
+
+func (x *ListWalletLogsResponse) Reset() {
+	*x = ListWalletLogsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_walleter_v1_walleter_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListWalletLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWalletLogsResponse) ProtoMessage() {}
+
+func (x *ListWalletLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_walleter_v1_walleter_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWalletLogsResponse.ProtoReflect.Descriptor instead.
+func (*ListWalletLogsResponse) Descriptor() ([]byte, []int) {
+	return file_walleter_v1_walleter_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListWalletLogsResponse) GetLogs() []*WalletLog {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+func (x *ListWalletLogsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+var File_walleter_v1_walleter_proto protoreflect.FileDescriptor
+
+var file_walleter_v1_walleter_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x2f, 0x77, 0x61,
+	0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x77, 0x61,
+	0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x6d, 0x0a, 0x0c, 0x45, 0x52,
+	0x43, 0x32, 0x30, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x2d, 0x0a, 0x05, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x77, 0x61, 0x6c, 0x6c,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x52, 0x43, 0x32, 0x30, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x22, 0x3a, 0x0a, 0x0e, 0x45, 0x52, 0x43,
+	0x31, 0x31, 0x35, 0x35, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x69,
+	0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x04, 0x52, 0x03, 0x69, 0x64, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52, 0x06, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0xd7, 0x03, 0x0a, 0x0d, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x35, 0x0a, 0x0a, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x77, 0x61, 0x6c,
+	0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x52, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x3e, 0x0a,
+	0x0b, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70,
+	0x65, 0x52, 0x0a, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x40, 0x0a,
+	0x0e, 0x65, 0x72, 0x63, 0x32, 0x30, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x52, 0x43, 0x32, 0x30, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x52, 0x0d, 0x65, 0x72, 0x63, 0x32, 0x30, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x73, 0x12,
+	0x44, 0x0a, 0x0f, 0x65, 0x72, 0x63, 0x31, 0x31, 0x35, 0x35, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65,
+	0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x0e, 0x65, 0x72, 0x63, 0x31, 0x31, 0x35, 0x35, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x3c, 0x0a, 0x0c, 0x66, 0x65, 0x65, 0x5f, 0x63, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x77, 0x61,
+	0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x52, 0x43, 0x32, 0x30, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x0b, 0x66, 0x65, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61,
+	0x6e, 0x64, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x62, 0x75, 0x73, 0x69, 0x6e, 0x65, 0x73, 0x73, 0x5f,
+	0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x62, 0x75,
+	0x73, 0x69, 0x6e, 0x65, 0x73, 0x73, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x41, 0x0a, 0x0e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22,
+	0xa1, 0x02, 0x0a, 0x10, 0x45, 0x52, 0x43, 0x32, 0x30, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x57, 0x61,
+	0x6c, 0x6c, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61,
+	0x6c, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x62, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x12, 0x21,
+	0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x49, 0x6e, 0x63, 0x6f, 0x6d,
+	0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x73, 0x70, 0x65, 0x6e, 0x64,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53, 0x70, 0x65,
+	0x6e, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x64, 0x65, 0x70, 0x6f,
+	0x73, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x44, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x5f, 0x77, 0x69, 0x74, 0x68, 0x64, 0x72, 0x61, 0x77, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x57, 0x69, 0x74, 0x68, 0x64, 0x72, 0x61, 0x77, 0x12, 0x1b,
+	0x0a, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x66, 0x65, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x46, 0x65, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6c,
+	0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x6c, 0x6f, 0x63,
+	0x6b, 0x65, 0x64, 0x22, 0x3e, 0x0a, 0x12, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x04, 0x52, 0x03, 0x69, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52, 0x06, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x22, 0xd4, 0x02, 0x0a, 0x06, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x47, 0x0a,
+	0x10, 0x65, 0x72, 0x63, 0x32, 0x30, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x52, 0x43, 0x32, 0x30, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x52, 0x0e, 0x65, 0x72, 0x63, 0x32, 0x30, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x4d, 0x0a, 0x12, 0x65, 0x72, 0x63, 0x31, 0x31, 0x35,
+	0x35, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x57, 0x61, 0x6c,
+	0x6c, 0x65, 0x74, 0x52, 0x10, 0x65, 0x72, 0x63, 0x31, 0x31, 0x35, 0x35, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x5f, 0x73,
+	0x69, 0x67, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x53, 0x69, 0x67, 0x6e, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f,
+	0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12,
+	0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xb7, 0x01, 0x0a, 0x13, 0x45,
+	0x52, 0x43, 0x32, 0x30, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x22, 0xbe, 0x01, 0x0a, 0x15, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35,
+	0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x1d,
+	0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x19, 0x0a,
+	0x08, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x07, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x6c, 0x61,
+	0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x62, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x55, 0x0a, 0x0b, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x41, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x07, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x22, 0x34, 0x0a, 0x0a,
+	0x49, 0x74, 0x65, 0x6d, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x22, 0xbc, 0x04, 0x0a, 0x09, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x4c, 0x6f, 0x67,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x35, 0x0a, 0x0a, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x09, 0x61, 0x73,
+	0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x62, 0x75, 0x73, 0x69, 0x6e, 0x65,
+	0x73, 0x73, 0x5f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0e, 0x62, 0x75, 0x73, 0x69, 0x6e, 0x65, 0x73, 0x73, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12,
+	0x1f, 0x0a, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x30, 0x0a, 0x06, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x06, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x73, 0x12, 0x2d, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x49, 0x74, 0x65, 0x6d, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d,
+	0x73, 0x12, 0x2c, 0x0a, 0x04, 0x66, 0x65, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x04, 0x66, 0x65, 0x65, 0x73, 0x12,
+	0x21, 0x0a, 0x0c, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x43, 0x6f,
+	0x64, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x5f, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x66, 0x61, 0x69,
+	0x6c, 0x75, 0x72, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x5f, 0x61, 0x74, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x22, 0x52, 0x0a, 0x1a, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x57, 0x61, 0x6c, 0x6c, 0x65,
+	0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x34, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57,
+	0x61, 0x6c, 0x6c, 0x65, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0xae, 0x01, 0x0a, 0x1b, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65,
+	0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x52, 0x06, 0x77, 0x61, 0x6c, 0x6c,
+	0x65, 0x74, 0x12, 0x34, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x77, 0x69, 0x74, 0x68,
+	0x64, 0x72, 0x61, 0x77, 0x61, 0x6c, 0x5f, 0x68, 0x6f, 0x6c, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x77, 0x69, 0x74, 0x68, 0x64, 0x72, 0x61, 0x77, 0x61, 0x6c,
+	0x48, 0x6f, 0x6c, 0x64, 0x49, 0x64, 0x22, 0x31, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c,
+	0x6c, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09,
+	0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x22, 0x40, 0x0a, 0x11, 0x47, 0x65, 0x74,
+	0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b,
+	0x0a, 0x06, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x6c,
+	0x6c, 0x65, 0x74, 0x52, 0x06, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x22, 0x5f, 0x0a, 0x12, 0x47,
+	0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64,
+	0x12, 0x2a, 0x0a, 0x02, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x61, 0x74, 0x22, 0xa4, 0x03, 0x0a,
+	0x13, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x49, 0x64, 0x12, 0x2a, 0x0a, 0x02, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x61, 0x74, 0x12,
+	0x5a, 0x0a, 0x0e, 0x65, 0x72, 0x63, 0x32, 0x30, 0x5f, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74,
+	0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x45, 0x72, 0x63, 0x32, 0x30, 0x42,
+	0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x65, 0x72,
+	0x63, 0x32, 0x30, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x60, 0x0a, 0x10, 0x65,
+	0x72, 0x63, 0x31, 0x31, 0x35, 0x35, 0x5f, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x35, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x45, 0x72, 0x63, 0x31, 0x31, 0x35, 0x35, 0x42,
+	0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0f, 0x65, 0x72,
+	0x63, 0x31, 0x31, 0x35, 0x35, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x1a, 0x40, 0x0a,
+	0x12, 0x45, 0x72, 0x63, 0x32, 0x30, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a,
+	0x42, 0x0a, 0x14, 0x45, 0x72, 0x63, 0x31, 0x31, 0x35, 0x35, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0xc9, 0x01, 0x0a, 0x1d, 0x47, 0x65, 0x74, 0x45, 0x52, 0x43, 0x32, 0x30,
+	0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x49, 0x64, 0x12, 0x2d, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x52, 0x43, 0x32, 0x30, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x05, 0x74, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x2e, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66,
+	0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22,
+	0x60, 0x0a, 0x1e, 0x47, 0x65, 0x74, 0x45, 0x52, 0x43, 0x32, 0x30, 0x42, 0x61, 0x6c, 0x61, 0x6e,
+	0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x3e, 0x0a, 0x09, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x45, 0x52, 0x43, 0x32, 0x30, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x09, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x65,
+	0x73, 0x22, 0xb7, 0x01, 0x0a, 0x1f, 0x47, 0x65, 0x74, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35,
+	0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x49, 0x64, 0x12,
+	0x2e, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12,
+	0x2a, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x64, 0x0a, 0x20, 0x47,
+	0x65, 0x74, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65,
+	0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x40, 0x0a, 0x09, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x22, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x09, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x69, 0x65,
+	0x73, 0x22, 0xc0, 0x04, 0x0a, 0x15, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74,
+	0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61,
+	0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x37, 0x0a, 0x0b, 0x61, 0x73,
+	0x73, 0x65, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0e, 0x32,
+	0x16, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x73,
+	0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x61, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x73, 0x12, 0x40, 0x0a, 0x0c, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x77, 0x61, 0x6c, 0x6c,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x62, 0x75, 0x73, 0x69, 0x6e, 0x65, 0x73,
+	0x73, 0x5f, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x62, 0x75, 0x73, 0x69, 0x6e, 0x65, 0x73, 0x73, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x34,
+	0x0a, 0x07, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0e, 0x32,
+	0x1a, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x07, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x12, 0x2f,
+	0x0a, 0x06, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x17,
+	0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x52, 0x43,
+	0x32, 0x30, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x06, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12,
+	0x1b, 0x0a, 0x09, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x08, 0x20, 0x03,
+	0x28, 0x04, 0x52, 0x08, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x49, 0x64, 0x73, 0x12, 0x2e, 0x0a, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02,
+	0x74, 0x6f, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73,
+	0x6f, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x73, 0x63, 0x65, 0x6e, 0x64,
+	0x69, 0x6e, 0x67, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x73, 0x63, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x22, 0x65, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x61, 0x6c, 0x6c,
+	0x65, 0x74, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a,
+	0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x77,
+	0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x6c, 0x6c, 0x65,
+	0x74, 0x4c, 0x6f, 0x67, 0x52, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x65,
+	0x78, 0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x2a, 0x4f, 0x0a, 0x09, 0x41,
+	0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x53, 0x53, 0x45,
+	0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45, 0x52, 0x43, 0x32, 0x30, 0x10, 0x00, 0x12, 0x16,
+	0x0a, 0x12, 0x41, 0x53, 0x53, 0x45, 0x54, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45, 0x52, 0x43,
+	0x31, 0x31, 0x35, 0x35, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x53, 0x53, 0x45, 0x54, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x4f, 0x54, 0x48, 0x45, 0x52, 0x10, 0x02, 0x2a, 0xa6, 0x01, 0x0a,
+	0x0a, 0x45, 0x52, 0x43, 0x32, 0x30, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x13, 0x0a, 0x0f, 0x45,
+	0x52, 0x43, 0x32, 0x30, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x45, 0x54, 0x48, 0x10, 0x00,
+	0x12, 0x13, 0x0a, 0x0f, 0x45, 0x52, 0x43, 0x32, 0x30, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f,
+	0x42, 0x4e, 0x42, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x52, 0x43, 0x32, 0x30, 0x5f, 0x54,
+	0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x55, 0x53, 0x44, 0x54, 0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x45,
+	0x52, 0x43, 0x32, 0x30, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x55, 0x53, 0x44, 0x43, 0x10,
+	0x03, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x52, 0x43, 0x32, 0x30, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e,
+	0x5f, 0x42, 0x55, 0x53, 0x44, 0x10, 0x04, 0x12, 0x15, 0x0a, 0x11, 0x45, 0x52, 0x43, 0x32, 0x30,
+	0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x4e, 0x41, 0x4d, 0x49, 0x58, 0x10, 0x05, 0x12, 0x15,
+	0x0a, 0x11, 0x45, 0x52, 0x43, 0x32, 0x30, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x46, 0x49,
+	0x53, 0x48, 0x58, 0x10, 0x06, 0x2a, 0xd6, 0x01, 0x0a, 0x10, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x21, 0x0a, 0x1d, 0x57, 0x41,
+	0x4c, 0x4c, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x49, 0x4e, 0x49, 0x54, 0x49, 0x41, 0x4c, 0x49, 0x5a, 0x45, 0x10, 0x00, 0x12, 0x1d, 0x0a,
+	0x19, 0x57, 0x41, 0x4c, 0x4c, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x43, 0x4f, 0x4d, 0x45, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18,
+	0x57, 0x41, 0x4c, 0x4c, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x53, 0x50, 0x45, 0x4e, 0x44, 0x10, 0x02, 0x12, 0x1e, 0x0a, 0x1a, 0x57, 0x41,
+	0x4c, 0x4c, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x44, 0x45, 0x50, 0x4f, 0x53, 0x49, 0x54, 0x10, 0x03, 0x12, 0x1f, 0x0a, 0x1b, 0x57, 0x41,
+	0x4c, 0x4c, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x57, 0x49, 0x54, 0x48, 0x44, 0x52, 0x41, 0x57, 0x10, 0x04, 0x12, 0x21, 0x0a, 0x1d, 0x57,
+	0x41, 0x4c, 0x4c, 0x45, 0x54, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x43, 0x48, 0x41, 0x52, 0x47, 0x45, 0x5f, 0x46, 0x45, 0x45, 0x10, 0x05, 0x2a, 0xa3,
+	0x01, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x12, 0x1a, 0x0a, 0x16, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x4f, 0x55, 0x52,
+	0x43, 0x45, 0x5f, 0x49, 0x4e, 0x5f, 0x47, 0x41, 0x4d, 0x45, 0x10, 0x00, 0x12, 0x1b, 0x0a, 0x17,
+	0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x45,
+	0x54, 0x48, 0x45, 0x52, 0x45, 0x55, 0x4d, 0x10, 0x01, 0x12, 0x21, 0x0a, 0x1d, 0x43, 0x4f, 0x4d,
+	0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x47, 0x4f, 0x45, 0x52,
+	0x4c, 0x49, 0x5f, 0x54, 0x45, 0x53, 0x54, 0x4e, 0x45, 0x54, 0x10, 0x02, 0x12, 0x16, 0x0a, 0x12,
+	0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f, 0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x42,
+	0x53, 0x43, 0x10, 0x03, 0x12, 0x1e, 0x0a, 0x1a, 0x43, 0x4f, 0x4d, 0x4d, 0x41, 0x4e, 0x44, 0x5f,
+	0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x42, 0x53, 0x43, 0x5f, 0x54, 0x45, 0x53, 0x54, 0x4e,
+	0x45, 0x54, 0x10, 0x04, 0x2a, 0x86, 0x01, 0x0a, 0x0f, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x4c,
+	0x6f, 0x67, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1d, 0x0a, 0x19, 0x57, 0x41, 0x4c, 0x4c,
+	0x45, 0x54, 0x5f, 0x4c, 0x4f, 0x47, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x50, 0x45,
+	0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x57, 0x41, 0x4c, 0x4c, 0x45,
+	0x54, 0x5f, 0x4c, 0x4f, 0x47, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x44, 0x4f, 0x4e,
+	0x45, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18, 0x57, 0x41, 0x4c, 0x4c, 0x45, 0x54, 0x5f, 0x4c, 0x4f,
+	0x47, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10,
+	0x02, 0x12, 0x1a, 0x0a, 0x16, 0x57, 0x41, 0x4c, 0x4c, 0x45, 0x54, 0x5f, 0x4c, 0x4f, 0x47, 0x5f,
+	0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x48, 0x45, 0x4c, 0x44, 0x10, 0x03, 0x32, 0xde, 0x04,
+	0x0a, 0x0d, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x68, 0x0a, 0x13, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x27, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x57, 0x61, 0x6c, 0x6c, 0x65,
+	0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x28, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61,
+	0x6e, 0x64, 0x6c, 0x65, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4a, 0x0a, 0x09, 0x47, 0x65, 0x74,
+	0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x12, 0x1d, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c,
+	0x65, 0x74, 0x41, 0x74, 0x12, 0x1f, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x41, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x71, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x45, 0x52,
+	0x43, 0x32, 0x30, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x12, 0x2a, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x45, 0x52, 0x43, 0x32, 0x30, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e,
+	0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x45,
+	0x52, 0x43, 0x32, 0x30, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x77, 0x0a, 0x18, 0x47, 0x65,
+	0x74, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x2c, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35, 0x42,
+	0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x45, 0x52, 0x43, 0x31, 0x31, 0x35, 0x35, 0x42, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65,
+	0x74, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x22, 0x2e, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x4c, 0x6f,
+	0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x77, 0x61, 0x6c, 0x6c,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x61, 0x6c, 0x6c,
+	0x65, 0x74, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2a,
+	0x5a, 0x28, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6e, 0x61, 0x6d,
+	0x69, 0x2d, 0x6c, 0x61, 0x6e, 0x64, 0x2f, 0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x2f,
+	0x77, 0x61, 0x6c, 0x6c, 0x65, 0x74, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_walleter_v1_walleter_proto_rawDescOnce sync.Once
+	file_walleter_v1_walleter_proto_rawDescData = file_walleter_v1_walleter_proto_rawDesc
+)
+
+func file_walleter_v1_walleter_proto_rawDescGZIP() []byte {
+	file_walleter_v1_walleter_proto_rawDescOnce.Do(func() {
+		file_walleter_v1_walleter_proto_rawDescData = protoimpl.X.CompressGZIP(file_walleter_v1_walleter_proto_rawDescData)
+	})
+	return file_walleter_v1_walleter_proto_rawDescData
+}
+
+var file_walleter_v1_walleter_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_walleter_v1_walleter_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_walleter_v1_walleter_proto_goTypes = []interface{}{
+	(AssetType)(0),                           // 0: walleter.v1.AssetType
+	(ERC20Token)(0),                          // 1: walleter.v1.ERC20Token
+	(WalletActionType)(0),                    // 2: walleter.v1.WalletActionType
+	(CommandSource)(0),                       // 3: walleter.v1.CommandSource
+	(WalletLogStatus)(0),                     // 4: walleter.v1.WalletLogStatus
+	(*ERC20Command)(nil),                     // 5: walleter.v1.ERC20Command
+	(*ERC1155Command)(nil),                   // 6: walleter.v1.ERC1155Command
+	(*WalletCommand)(nil),                    // 7: walleter.v1.WalletCommand
+	(*ERC20TokenWallet)(nil),                 // 8: walleter.v1.ERC20TokenWallet
+	(*ERC1155TokenWallet)(nil),               // 9: walleter.v1.ERC1155TokenWallet
+	(*Wallet)(nil),                           // 10: walleter.v1.Wallet
+	(*ERC20BalanceHistory)(nil),              // 11: walleter.v1.ERC20BalanceHistory
+	(*ERC1155BalanceHistory)(nil),            // 12: walleter.v1.ERC1155BalanceHistory
+	(*TokenAmount)(nil),                      // 13: walleter.v1.TokenAmount
+	(*ItemAmount)(nil),                       // 14: walleter.v1.ItemAmount
+	(*WalletLog)(nil),                        // 15: walleter.v1.WalletLog
+	(*HandleWalletCommandRequest)(nil),       // 16: walleter.v1.HandleWalletCommandRequest
+	(*HandleWalletCommandResponse)(nil),      // 17: walleter.v1.HandleWalletCommandResponse
+	(*GetWalletRequest)(nil),                 // 18: walleter.v1.GetWalletRequest
+	(*GetWalletResponse)(nil),                // 19: walleter.v1.GetWalletResponse
+	(*GetWalletAtRequest)(nil),               // 20: walleter.v1.GetWalletAtRequest
+	(*GetWalletAtResponse)(nil),              // 21: walleter.v1.GetWalletAtResponse
+	(*GetERC20BalanceHistoryRequest)(nil),    // 22: walleter.v1.GetERC20BalanceHistoryRequest
+	(*GetERC20BalanceHistoryResponse)(nil),   // 23: walleter.v1.GetERC20BalanceHistoryResponse
+	(*GetERC1155BalanceHistoryRequest)(nil),  // 24: walleter.v1.GetERC1155BalanceHistoryRequest
+	(*GetERC1155BalanceHistoryResponse)(nil), // 25: walleter.v1.GetERC1155BalanceHistoryResponse
+	(*ListWalletLogsRequest)(nil),            // 26: walleter.v1.ListWalletLogsRequest
+	(*ListWalletLogsResponse)(nil),           // 27: walleter.v1.ListWalletLogsResponse
+	nil,                                      // 28: walleter.v1.GetWalletAtResponse.Erc20BalancesEntry
+	nil,                                      // 29: walleter.v1.GetWalletAtResponse.Erc1155BalancesEntry
+	(*timestamppb.Timestamp)(nil),            // 30: google.protobuf.Timestamp
+}
+var file_walleter_v1_walleter_proto_depIdxs = []int32{
+	1,  // 0: walleter.v1.ERC20Command.token:type_name -> walleter.v1.ERC20Token
+	0,  // 1: walleter.v1.WalletCommand.asset_type:type_name -> walleter.v1.AssetType
+	2,  // 2: walleter.v1.WalletCommand.action_type:type_name -> walleter.v1.WalletActionType
+	5,  // 3: walleter.v1.WalletCommand.erc20_commands:type_name -> walleter.v1.ERC20Command
+	6,  // 4: walleter.v1.WalletCommand.erc1155_command:type_name -> walleter.v1.ERC1155Command
+	5,  // 5: walleter.v1.WalletCommand.fee_commands:type_name -> walleter.v1.ERC20Command
+	3,  // 6: walleter.v1.WalletCommand.command_source:type_name -> walleter.v1.CommandSource
+	8,  // 7: walleter.v1.Wallet.erc20_token_data:type_name -> walleter.v1.ERC20TokenWallet
+	9,  // 8: walleter.v1.Wallet.erc1155_token_data:type_name -> walleter.v1.ERC1155TokenWallet
+	30, // 9: walleter.v1.Wallet.created_at:type_name -> google.protobuf.Timestamp
+	30, // 10: walleter.v1.Wallet.updated_at:type_name -> google.protobuf.Timestamp
+	30, // 11: walleter.v1.ERC20BalanceHistory.created_at:type_name -> google.protobuf.Timestamp
+	30, // 12: walleter.v1.ERC1155BalanceHistory.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 13: walleter.v1.WalletLog.asset_type:type_name -> walleter.v1.AssetType
+	13, // 14: walleter.v1.WalletLog.tokens:type_name -> walleter.v1.TokenAmount
+	14, // 15: walleter.v1.WalletLog.items:type_name -> walleter.v1.ItemAmount
+	13, // 16: walleter.v1.WalletLog.fees:type_name -> walleter.v1.TokenAmount
+	30, // 17: walleter.v1.WalletLog.created_at:type_name -> google.protobuf.Timestamp
+	30, // 18: walleter.v1.WalletLog.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 19: walleter.v1.HandleWalletCommandRequest.command:type_name -> walleter.v1.WalletCommand
+	10, // 20: walleter.v1.HandleWalletCommandResponse.wallet:type_name -> walleter.v1.Wallet
+	4,  // 21: walleter.v1.HandleWalletCommandResponse.status:type_name -> walleter.v1.WalletLogStatus
+	10, // 22: walleter.v1.GetWalletResponse.wallet:type_name -> walleter.v1.Wallet
+	30, // 23: walleter.v1.GetWalletAtRequest.at:type_name -> google.protobuf.Timestamp
+	30, // 24: walleter.v1.GetWalletAtResponse.at:type_name -> google.protobuf.Timestamp
+	28, // 25: walleter.v1.GetWalletAtResponse.erc20_balances:type_name -> walleter.v1.GetWalletAtResponse.Erc20BalancesEntry
+	29, // 26: walleter.v1.GetWalletAtResponse.erc1155_balances:type_name -> walleter.v1.GetWalletAtResponse.Erc1155BalancesEntry
+	1,  // 27: walleter.v1.GetERC20BalanceHistoryRequest.token:type_name -> walleter.v1.ERC20Token
+	30, // 28: walleter.v1.GetERC20BalanceHistoryRequest.from:type_name -> google.protobuf.Timestamp
+	30, // 29: walleter.v1.GetERC20BalanceHistoryRequest.to:type_name -> google.protobuf.Timestamp
+	11, // 30: walleter.v1.GetERC20BalanceHistoryResponse.histories:type_name -> walleter.v1.ERC20BalanceHistory
+	30, // 31: walleter.v1.GetERC1155BalanceHistoryRequest.from:type_name -> google.protobuf.Timestamp
+	30, // 32: walleter.v1.GetERC1155BalanceHistoryRequest.to:type_name -> google.protobuf.Timestamp
+	12, // 33: walleter.v1.GetERC1155BalanceHistoryResponse.histories:type_name -> walleter.v1.ERC1155BalanceHistory
+	0,  // 34: walleter.v1.ListWalletLogsRequest.asset_types:type_name -> walleter.v1.AssetType
+	2,  // 35: walleter.v1.ListWalletLogsRequest.action_types:type_name -> walleter.v1.WalletActionType
+	3,  // 36: walleter.v1.ListWalletLogsRequest.sources:type_name -> walleter.v1.CommandSource
+	4,  // 37: walleter.v1.ListWalletLogsRequest.statuses:type_name -> walleter.v1.WalletLogStatus
+	1,  // 38: walleter.v1.ListWalletLogsRequest.tokens:type_name -> walleter.v1.ERC20Token
+	30, // 39: walleter.v1.ListWalletLogsRequest.from:type_name -> google.protobuf.Timestamp
+	30, // 40: walleter.v1.ListWalletLogsRequest.to:type_name -> google.protobuf.Timestamp
+	15, // 41: walleter.v1.ListWalletLogsResponse.logs:type_name -> walleter.v1.WalletLog
+	16, // 42: walleter.v1.WalletService.HandleWalletCommand:input_type -> walleter.v1.HandleWalletCommandRequest
+	18, // 43: walleter.v1.WalletService.GetWallet:input_type -> walleter.v1.GetWalletRequest
+	20, // 44: walleter.v1.WalletService.GetWalletAt:input_type -> walleter.v1.GetWalletAtRequest
+	22, // 45: walleter.v1.WalletService.GetERC20BalanceHistory:input_type -> walleter.v1.GetERC20BalanceHistoryRequest
+	24, // 46: walleter.v1.WalletService.GetERC1155BalanceHistory:input_type -> walleter.v1.GetERC1155BalanceHistoryRequest
+	26, // 47: walleter.v1.WalletService.ListWalletLogs:input_type -> walleter.v1.ListWalletLogsRequest
+	17, // 48: walleter.v1.WalletService.HandleWalletCommand:output_type -> walleter.v1.HandleWalletCommandResponse
+	19, // 49: walleter.v1.WalletService.GetWallet:output_type -> walleter.v1.GetWalletResponse
+	21, // 50: walleter.v1.WalletService.GetWalletAt:output_type -> walleter.v1.GetWalletAtResponse
+	23, // 51: walleter.v1.WalletService.GetERC20BalanceHistory:output_type -> walleter.v1.GetERC20BalanceHistoryResponse
+	25, // 52: walleter.v1.WalletService.GetERC1155BalanceHistory:output_type -> walleter.v1.GetERC1155BalanceHistoryResponse
+	27, // 53: walleter.v1.WalletService.ListWalletLogs:output_type -> walleter.v1.ListWalletLogsResponse
+	48, // [48:54] is the sub-list for method output_type
+	42, // [42:48] is the sub-list for method input_type
+	42, // [42:42] is the sub-list for extension type_name
+	42, // [42:42] is the sub-list for extension extendee
+	0,  // [0:42] is the sub-list for field type_name
+}
+
+func init() { file_walleter_v1_walleter_proto_init() }
+func file_walleter_v1_walleter_proto_init() {
+	if File_walleter_v1_walleter_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_walleter_v1_walleter_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ERC20Command); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ERC1155Command); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WalletCommand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ERC20TokenWallet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ERC1155TokenWallet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Wallet); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ERC20BalanceHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ERC1155BalanceHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TokenAmount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ItemAmount); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WalletLog); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HandleWalletCommandRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HandleWalletCommandResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetWalletRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetWalletResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetWalletAtRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetWalletAtResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetERC20BalanceHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetERC20BalanceHistoryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetERC1155BalanceHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetERC1155BalanceHistoryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListWalletLogsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_walleter_v1_walleter_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListWalletLogsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_walleter_v1_walleter_proto_rawDesc,
+			NumEnums:      5,
+			NumMessages:   25,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_walleter_v1_walleter_proto_goTypes,
+		DependencyIndexes: file_walleter_v1_walleter_proto_depIdxs,
+		EnumInfos:         file_walleter_v1_walleter_proto_enumTypes,
+		MessageInfos:      file_walleter_v1_walleter_proto_msgTypes,
+	}.Build()
+	File_walleter_v1_walleter_proto = out.File
+	file_walleter_v1_walleter_proto_rawDesc = nil
+	file_walleter_v1_walleter_proto_goTypes = nil
+	file_walleter_v1_walleter_proto_depIdxs = nil
+}
@@ -0,0 +1,301 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: walleter/v1/walleter.proto
+
+package walleterpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// WalletServiceClient is the client API for WalletService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WalletServiceClient interface {
+	// HandleWalletCommand handles a command and returns the wallet after it. A withdrawal held for approval
+	// succeeds with status WALLET_LOG_STATUS_HELD and the id of its hold, its amounts stay locked in the wallet.
+	HandleWalletCommand(ctx context.Context, in *HandleWalletCommandRequest, opts ...grpc.CallOption) (*HandleWalletCommandResponse, error)
+	// GetWallet returns the current wallet of an account, NOT_FOUND when it has none.
+	GetWallet(ctx context.Context, in *GetWalletRequest, opts ...grpc.CallOption) (*GetWalletResponse, error)
+	// GetWalletAt returns what the wallet of an account held at a given moment.
+	GetWalletAt(ctx context.Context, in *GetWalletAtRequest, opts ...grpc.CallOption) (*GetWalletAtResponse, error)
+	// GetERC20BalanceHistory returns the balance changes of one token within a period, oldest first.
+	GetERC20BalanceHistory(ctx context.Context, in *GetERC20BalanceHistoryRequest, opts ...grpc.CallOption) (*GetERC20BalanceHistoryResponse, error)
+	// GetERC1155BalanceHistory returns the amount changes of one ERC1155 id within a period, oldest first.
+	GetERC1155BalanceHistory(ctx context.Context, in *GetERC1155BalanceHistoryRequest, opts ...grpc.CallOption) (*GetERC1155BalanceHistoryResponse, error)
+	// ListWalletLogs returns one page of the logs of an account matching the filters, newest first unless ascending.
+	// An invalid cursor fails with INVALID_ARGUMENT.
+	ListWalletLogs(ctx context.Context, in *ListWalletLogsRequest, opts ...grpc.CallOption) (*ListWalletLogsResponse, error)
+}
+
+type walletServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWalletServiceClient(cc grpc.ClientConnInterface) WalletServiceClient {
+	return &walletServiceClient{cc}
+}
+
+func (c *walletServiceClient) HandleWalletCommand(ctx context.Context, in *HandleWalletCommandRequest, opts ...grpc.CallOption) (*HandleWalletCommandResponse, error) {
+	out := new(HandleWalletCommandResponse)
+	err := c.cc.Invoke(ctx, "/walleter.v1.WalletService/HandleWalletCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) GetWallet(ctx context.Context, in *GetWalletRequest, opts ...grpc.CallOption) (*GetWalletResponse, error) {
+	out := new(GetWalletResponse)
+	err := c.cc.Invoke(ctx, "/walleter.v1.WalletService/GetWallet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) GetWalletAt(ctx context.Context, in *GetWalletAtRequest, opts ...grpc.CallOption) (*GetWalletAtResponse, error) {
+	out := new(GetWalletAtResponse)
+	err := c.cc.Invoke(ctx, "/walleter.v1.WalletService/GetWalletAt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) GetERC20BalanceHistory(ctx context.Context, in *GetERC20BalanceHistoryRequest, opts ...grpc.CallOption) (*GetERC20BalanceHistoryResponse, error) {
+	out := new(GetERC20BalanceHistoryResponse)
+	err := c.cc.Invoke(ctx, "/walleter.v1.WalletService/GetERC20BalanceHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) GetERC1155BalanceHistory(ctx context.Context, in *GetERC1155BalanceHistoryRequest, opts ...grpc.CallOption) (*GetERC1155BalanceHistoryResponse, error) {
+	out := new(GetERC1155BalanceHistoryResponse)
+	err := c.cc.Invoke(ctx, "/walleter.v1.WalletService/GetERC1155BalanceHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletServiceClient) ListWalletLogs(ctx context.Context, in *ListWalletLogsRequest, opts ...grpc.CallOption) (*ListWalletLogsResponse, error) {
+	out := new(ListWalletLogsResponse)
+	err := c.cc.Invoke(ctx, "/walleter.v1.WalletService/ListWalletLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WalletServiceServer is the server API for WalletService service.
+// All implementations must embed UnimplementedWalletServiceServer
+// for forward compatibility
+type WalletServiceServer interface {
+	// HandleWalletCommand handles a command and returns the wallet after it. A withdrawal held for approval
+	// succeeds with status WALLET_LOG_STATUS_HELD and the id of its hold, its amounts stay locked in the wallet.
+	HandleWalletCommand(context.Context, *HandleWalletCommandRequest) (*HandleWalletCommandResponse, error)
+	// GetWallet returns the current wallet of an account, NOT_FOUND when it has none.
+	GetWallet(context.Context, *GetWalletRequest) (*GetWalletResponse, error)
+	// GetWalletAt returns what the wallet of an account held at a given moment.
+	GetWalletAt(context.Context, *GetWalletAtRequest) (*GetWalletAtResponse, error)
+	// GetERC20BalanceHistory returns the balance changes of one token within a period, oldest first.
+	GetERC20BalanceHistory(context.Context, *GetERC20BalanceHistoryRequest) (*GetERC20BalanceHistoryResponse, error)
+	// GetERC1155BalanceHistory returns the amount changes of one ERC1155 id within a period, oldest first.
+	GetERC1155BalanceHistory(context.Context, *GetERC1155BalanceHistoryRequest) (*GetERC1155BalanceHistoryResponse, error)
+	// ListWalletLogs returns one page of the logs of an account matching the filters, newest first unless ascending.
+	// An invalid cursor fails with INVALID_ARGUMENT.
+	ListWalletLogs(context.Context, *ListWalletLogsRequest) (*ListWalletLogsResponse, error)
+	mustEmbedUnimplementedWalletServiceServer()
+}
+
+// UnimplementedWalletServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedWalletServiceServer struct {
+}
+
+func (UnimplementedWalletServiceServer) HandleWalletCommand(context.Context, *HandleWalletCommandRequest) (*HandleWalletCommandResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandleWalletCommand not implemented")
+}
+func (UnimplementedWalletServiceServer) GetWallet(context.Context, *GetWalletRequest) (*GetWalletResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWallet not implemented")
+}
+func (UnimplementedWalletServiceServer) GetWalletAt(context.Context, *GetWalletAtRequest) (*GetWalletAtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWalletAt not implemented")
+}
+func (UnimplementedWalletServiceServer) GetERC20BalanceHistory(context.Context, *GetERC20BalanceHistoryRequest) (*GetERC20BalanceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetERC20BalanceHistory not implemented")
+}
+func (UnimplementedWalletServiceServer) GetERC1155BalanceHistory(context.Context, *GetERC1155BalanceHistoryRequest) (*GetERC1155BalanceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetERC1155BalanceHistory not implemented")
+}
+func (UnimplementedWalletServiceServer) ListWalletLogs(context.Context, *ListWalletLogsRequest) (*ListWalletLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWalletLogs not implemented")
+}
+func (UnimplementedWalletServiceServer) mustEmbedUnimplementedWalletServiceServer() {}
+
+// UnsafeWalletServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WalletServiceServer will
+// result in compilation errors.
+type UnsafeWalletServiceServer interface {
+	mustEmbedUnimplementedWalletServiceServer()
+}
+
+func RegisterWalletServiceServer(s grpc.ServiceRegistrar, srv WalletServiceServer) {
+	s.RegisterService(&WalletService_ServiceDesc, srv)
+}
+
+func _WalletService_HandleWalletCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandleWalletCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).HandleWalletCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walleter.v1.WalletService/HandleWalletCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).HandleWalletCommand(ctx, req.(*HandleWalletCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_GetWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).GetWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walleter.v1.WalletService/GetWallet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).GetWallet(ctx, req.(*GetWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_GetWalletAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).GetWalletAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walleter.v1.WalletService/GetWalletAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).GetWalletAt(ctx, req.(*GetWalletAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_GetERC20BalanceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetERC20BalanceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).GetERC20BalanceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walleter.v1.WalletService/GetERC20BalanceHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).GetERC20BalanceHistory(ctx, req.(*GetERC20BalanceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_GetERC1155BalanceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetERC1155BalanceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).GetERC1155BalanceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walleter.v1.WalletService/GetERC1155BalanceHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).GetERC1155BalanceHistory(ctx, req.(*GetERC1155BalanceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletService_ListWalletLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWalletLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletServiceServer).ListWalletLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walleter.v1.WalletService/ListWalletLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletServiceServer).ListWalletLogs(ctx, req.(*ListWalletLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WalletService_ServiceDesc is the grpc.ServiceDesc for WalletService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WalletService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "walleter.v1.WalletService",
+	HandlerType: (*WalletServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "HandleWalletCommand",
+			Handler:    _WalletService_HandleWalletCommand_Handler,
+		},
+		{
+			MethodName: "GetWallet",
+			Handler:    _WalletService_GetWallet_Handler,
+		},
+		{
+			MethodName: "GetWalletAt",
+			Handler:    _WalletService_GetWalletAt_Handler,
+		},
+		{
+			MethodName: "GetERC20BalanceHistory",
+			Handler:    _WalletService_GetERC20BalanceHistory_Handler,
+		},
+		{
+			MethodName: "GetERC1155BalanceHistory",
+			Handler:    _WalletService_GetERC1155BalanceHistory_Handler,
+		},
+		{
+			MethodName: "ListWalletLogs",
+			Handler:    _WalletService_ListWalletLogs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "walleter/v1/walleter.proto",
+}
@@ -3,8 +3,11 @@ package walleter
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // ERC20WalletLog Wallet flow log
@@ -16,7 +19,10 @@ type ERC20WalletLog struct {
 	Source         string               `json:"source" gorm:"type:varchar(20)"`
 	Tokens         erc20TokenCollection `json:"tokens" gorm:"type:json;not null"`
 	Fees           erc20TokenCollection `json:"fees" gorm:"type:json;"`
-	Status         string               `json:"status" gorm:"type:varchar(64);not null;"`
+	Status         string               `json:"status" gorm:"type:varchar(64);not null;index"`
+	FailureDetail  *WalletError         `json:"failure_detail" gorm:"type:json"`
+	Approval       *CommandApproval     `json:"approval,omitempty"`
+	ChainRef       *ChainReference      `json:"chain_ref,omitempty"`
 	OriginalWallet Wallet               `json:"original_wallet" gorm:"type:json;not null;"`
 	SettledWallet  Wallet               `json:"settled_wallet" gorm:"type:json;not null;"`
 }
@@ -31,7 +37,10 @@ type ERC1155WalletLog struct {
 	Ids            string               `json:"ids"`
 	Values         string               `json:"values"`
 	Fees           erc20TokenCollection `json:"fees" gorm:"type:json;"`
-	Status         string               `json:"status" gorm:"type:varchar(10);not null;"`
+	Status         string               `json:"status" gorm:"type:varchar(10);not null;index"`
+	FailureDetail  *WalletError         `json:"failure_detail" gorm:"type:json"`
+	Approval       *CommandApproval     `json:"approval,omitempty"`
+	ChainRef       *ChainReference      `json:"chain_ref,omitempty"`
 	OriginalWallet Wallet               `json:"original_wallet" gorm:"type:json;not null;"`
 	SettledWallet  Wallet               `json:"settled_wallet" gorm:"type:json;"`
 }
@@ -86,7 +95,11 @@ func (item erc20TokenCollection) Value() (driver.Value, error) {
 }
 
 func (item *erc20TokenCollection) Scan(input interface{}) error {
-	return json.Unmarshal(input.([]byte), item)
+	return scanJSON(input, item)
+}
+
+func (erc20TokenCollection) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
 }
 
 type erc20TokenData struct {
@@ -101,7 +114,33 @@ func (item erc20TokenData) Value() (driver.Value, error) {
 }
 
 func (item *erc20TokenData) Scan(input interface{}) error {
-	return json.Unmarshal(input.([]byte), item)
+	return scanJSON(input, item)
+}
+
+// jsonDataType the column type of json values on the dialect of db.
+func jsonDataType(db *gorm.DB) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "JSONB"
+	case "sqlite":
+		return "TEXT"
+	case "sqlserver":
+		return "NVARCHAR(MAX)"
+	}
+	return "JSON"
+}
+
+// scanJSON decodes a json column, drivers return it either as []byte or string.
+func scanJSON(input interface{}, v interface{}) error {
+	switch data := input.(type) {
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	case nil:
+		return nil
+	}
+	return fmt.Errorf("unsupported json column type %T", input)
 }
 
 // /----------------------------
@@ -113,27 +152,52 @@ func newWalletLogService() *walletLogService {
 }
 
 // insertNewERC20WalletLog Insert new log of ERC20 changes
-func (receiver *walletLogService) insertNewERC20WalletLog(db *gorm.DB, command WalletCommand, currentWallet Wallet) (ERC20WalletLog, error) {
+func (receiver *walletLogService) insertNewERC20WalletLog(repo Repository, command WalletCommand, currentWallet Wallet) (ERC20WalletLog, error) {
 	erc20WalletLog := parseCommandToERC20WalletLog(command, currentWallet)
-	return erc20LogDAO.insertERC20WalletLog(db, erc20WalletLog)
+	return repo.InsertERC20WalletLog(erc20WalletLog)
 }
 
 // updateERC20WalletLog Change the status of ERC20Log in batches
-func (receiver *walletLogService) updateERC20WalletLog(db *gorm.DB, log ERC20WalletLog, status WalletLogStatus, newWallet Wallet) (ERC20WalletLog, error) {
+func (receiver *walletLogService) updateERC20WalletLog(repo Repository, log ERC20WalletLog, status WalletLogStatus, newWallet Wallet) (ERC20WalletLog, error) {
 	log.Status = status.String()
 	log.SettledWallet = newWallet
-	return erc20LogDAO.updateERC20WalletLogStatus(db, log)
+	return repo.UpdateERC20WalletLog(log)
+}
+
+// failedERC20WalletLog Mark an ERC20 log as failed and keep the reason
+func (receiver *walletLogService) failedERC20WalletLog(repo Repository, log ERC20WalletLog, newWallet Wallet, reason error) (ERC20WalletLog, error) {
+	log.FailureDetail = failureDetailOf(reason, log.AccountId)
+	return receiver.updateERC20WalletLog(repo, log, Failed, newWallet)
 }
 
 // insertNewERC1155WalletLog Insert an ERC1155 asset change log
-func (receiver *walletLogService) insertNewERC1155WalletLog(db *gorm.DB, command WalletCommand, currentWallet Wallet) (ERC1155WalletLog, error) {
+func (receiver *walletLogService) insertNewERC1155WalletLog(repo Repository, command WalletCommand, currentWallet Wallet) (ERC1155WalletLog, error) {
 	erc1155WalletData := parseCommandToERC1155WalletLog(command, currentWallet)
-	return erc1155LogDAO.insertERC1155WalletLog(db, erc1155WalletData)
+	return repo.InsertERC1155WalletLog(erc1155WalletData)
 }
 
 // updateERC1155WalletLog Change the state of the ERC1155 log
-func (receiver *walletLogService) updateERC1155WalletLog(db *gorm.DB, log ERC1155WalletLog, status WalletLogStatus, newWallet Wallet) (ERC1155WalletLog, error) {
+func (receiver *walletLogService) updateERC1155WalletLog(repo Repository, log ERC1155WalletLog, status WalletLogStatus, newWallet Wallet) (ERC1155WalletLog, error) {
 	log.Status = status.String()
 	log.SettledWallet = newWallet
-	return erc1155LogDAO.updateERC1155WalletLogStatus(db, log)
+	return repo.UpdateERC1155WalletLog(log)
+}
+
+// failedERC1155WalletLog Mark an ERC1155 log as failed and keep the reason
+func (receiver *walletLogService) failedERC1155WalletLog(repo Repository, log ERC1155WalletLog, newWallet Wallet, reason error) (ERC1155WalletLog, error) {
+	log.FailureDetail = failureDetailOf(reason, log.AccountId)
+	return receiver.updateERC1155WalletLog(repo, log, Failed, newWallet)
+}
+
+// truncateErrorMessage keeps message within the size of the varchar(255) error columns. It cuts at a rune
+// boundary, PostgreSQL rejects strings which are not valid UTF-8.
+func truncateErrorMessage(message string) string {
+	if len(message) <= 255 {
+		return message
+	}
+	end := 255
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+	return message[:end]
 }
@@ -0,0 +1,509 @@
+package walleter
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	WebhookWalletChanged       = "wallet.changed"
+	WebhookWithdrawalCompleted = "withdrawal.completed"
+
+	WebhookSignatureHeader = "X-Walleter-Signature"
+	WebhookEventHeader     = "X-Walleter-Event"
+	WebhookDeliveryHeader  = "X-Walleter-Delivery"
+)
+
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// WebhookFilter selects the events an endpoint receives, empty fields match everything.
+type WebhookFilter struct {
+	EventTypes      []string `json:"event_types"`
+	ActionTypes     []string `json:"action_types"`
+	BusinessModules []string `json:"business_modules"`
+	Tokens          []string `json:"tokens"`
+}
+
+func (WebhookFilter) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (f WebhookFilter) Value() (driver.Value, error) {
+	b, err := json.Marshal(f)
+	return string(b), err
+}
+
+func (f *WebhookFilter) Scan(input interface{}) error {
+	return scanJSON(input, f)
+}
+
+// WebhookEndpoint a partner URL receiving signed wallet events.
+type WebhookEndpoint struct {
+	gorm.Model `swagger-ignore:"true"`
+	Name       string        `json:"name" gorm:"type:varchar(64);not null"`
+	URL        string        `json:"url" gorm:"type:varchar(512);not null"`
+	Secret     string        `json:"-" gorm:"type:varchar(128);not null"`
+	Filter     WebhookFilter `json:"filter" gorm:"type:json"`
+	Active     bool          `json:"active" gorm:"not null"`
+}
+
+// WebhookDelivery one event to be sent, or sent, to one endpoint, with its attempts.
+type WebhookDelivery struct {
+	gorm.Model     `swagger-ignore:"true"`
+	EndpointId     uint       `json:"endpoint_id" gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	EventId        uint       `json:"event_id" gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	EventType      string     `json:"event_type" gorm:"type:varchar(32);not null;uniqueIndex:idx_webhook_delivery_event"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(10);not null;index:idx_webhook_delivery_due"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_delivery_due"`
+	LastStatusCode int        `json:"last_status_code"`
+	LastError      string     `json:"last_error" gorm:"type:varchar(255)"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+}
+
+// WebhookPayload the JSON body posted to endpoints.
+type WebhookPayload struct {
+	DeliveryId uint               `json:"delivery_id"`
+	Type       string             `json:"type"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Data       WalletChangedEvent `json:"data"`
+}
+
+// WebhookConfig tuning of a WebhookService, zero values fall back to defaults.
+type WebhookConfig struct {
+	// HTTPClient used to post payloads, defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+	// MaxAttempts before a delivery is given up, defaults to 8.
+	MaxAttempts int
+	// RetryBackoff delay before the first retry, doubled for every further attempt, defaults to 5s.
+	RetryBackoff time.Duration
+	// MaxRetryBackoff upper bound of the retry delay, defaults to 1h.
+	MaxRetryBackoff time.Duration
+	// Lease how long a service owns a delivery while sending it, defaults to 1m.
+	Lease time.Duration
+	// BatchSize deliveries sent per DeliverOnce, defaults to 50.
+	BatchSize int
+	// PollInterval of Run, defaults to 1s.
+	PollInterval time.Duration
+}
+
+// WebhookService fans wallet events out to registered endpoints and delivers them.
+// Register HandleWalletEvent on an EventDispatcher and run Run (or DeliverOnce) to send deliveries.
+// Several services may deliver from the same database, every delivery is leased before it is sent.
+// Its methods fail with ErrDatabaseRequired when the Walleter does not store into a gorm database.
+type WebhookService struct {
+	db     *gorm.DB
+	config WebhookConfig
+	logger Logger
+}
+
+// NewWebhookService creates the webhook subsystem of this Walleter.
+func (s *Walleter) NewWebhookService(config WebhookConfig) *WebhookService {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 5 * time.Second
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = time.Hour
+	}
+	if config.Lease <= 0 {
+		config.Lease = time.Minute
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	return &WebhookService{db: s.db, config: config, logger: s.logger}
+}
+
+// RegisterEndpoint stores a new active endpoint.
+func (w *WebhookService) RegisterEndpoint(name string, url string, secret string, filter WebhookFilter) (WebhookEndpoint, error) {
+	if w.db == nil {
+		return WebhookEndpoint{}, ErrDatabaseRequired
+	}
+	endpoint := WebhookEndpoint{Name: name, URL: url, Secret: secret, Filter: filter, Active: true}
+	err := w.db.Create(&endpoint).Error
+	return endpoint, err
+}
+
+// UpdateEndpoint changes url, filter or activity of an endpoint, an empty secret keeps the current one.
+func (w *WebhookService) UpdateEndpoint(endpoint WebhookEndpoint) error {
+	if w.db == nil {
+		return ErrDatabaseRequired
+	}
+	values := map[string]interface{}{
+		"name":   endpoint.Name,
+		"url":    endpoint.URL,
+		"filter": endpoint.Filter,
+		"active": endpoint.Active,
+	}
+	if endpoint.Secret != "" {
+		values["secret"] = endpoint.Secret
+	}
+	return w.db.Model(&WebhookEndpoint{}).Where("id = ?", endpoint.ID).Updates(values).Error
+}
+
+// ListEndpoints returns every registered endpoint.
+func (w *WebhookService) ListEndpoints() (result []WebhookEndpoint, err error) {
+	if w.db == nil {
+		return nil, ErrDatabaseRequired
+	}
+	err = w.db.Order("id").Find(&result).Error
+	return result, err
+}
+
+// ListDeliveries returns the latest deliveries of an endpoint, optionally only those in status.
+func (w *WebhookService) ListDeliveries(endpointId uint, status string, limit int) (result []WebhookDelivery, err error) {
+	if w.db == nil {
+		return nil, ErrDatabaseRequired
+	}
+	db := w.db.Where("endpoint_id = ?", endpointId)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	err = db.Order("id DESC").Limit(limit).Find(&result).Error
+	return result, err
+}
+
+// ReplayDelivery sends an existing delivery again, whatever its current status.
+// It fails with gorm.ErrRecordNotFound when there is no delivery id.
+func (w *WebhookService) ReplayDelivery(id uint) error {
+	if w.db == nil {
+		return ErrDatabaseRequired
+	}
+	result := w.db.Model(&WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          OutboxPending.String(),
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// ReplayEvents queues every outbox event created since `since` for one endpoint again,
+// events already delivered to it are sent once more.
+func (w *WebhookService) ReplayEvents(endpointId uint, since time.Time) (int, error) {
+	if w.db == nil {
+		return 0, ErrDatabaseRequired
+	}
+	var endpoint WebhookEndpoint
+	if err := w.db.First(&endpoint, endpointId).Error; err != nil {
+		return 0, err
+	}
+	replayed := 0
+	var events []OutboxEvent
+	err := w.db.Where("created_at >= ?", since).
+		FindInBatches(&events, 200, func(tx *gorm.DB, batch int) error {
+			for _, event := range events {
+				payload := event.Payload
+				payload.EventId = event.ID
+				count, err := w.enqueue(endpoint, payload, true)
+				if err != nil {
+					return err
+				}
+				replayed += count
+			}
+			return nil
+		}).Error
+	return replayed, err
+}
+
+// HandleWalletEvent is a WalletEventHandler creating deliveries of event for every matching endpoint.
+func (w *WebhookService) HandleWalletEvent(ctx context.Context, event WalletChangedEvent) error {
+	if w.db == nil {
+		return ErrDatabaseRequired
+	}
+	var endpoints []WebhookEndpoint
+	if err := w.db.WithContext(ctx).Where("active = ?", true).Find(&endpoints).Error; err != nil {
+		return err
+	}
+	for _, endpoint := range endpoints {
+		if _, err := w.enqueue(endpoint, event, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueue creates the deliveries of event matching endpoint. Deliveries already created for the same
+// endpoint and event are left alone, unless replay asks to send them again.
+func (w *WebhookService) enqueue(endpoint WebhookEndpoint, event WalletChangedEvent, replay bool) (int, error) {
+	count := 0
+	for _, eventType := range webhookEventTypes(event) {
+		if !endpoint.Filter.matches(eventType, event) {
+			continue
+		}
+		delivery := WebhookDelivery{
+			EndpointId:    endpoint.ID,
+			EventId:       event.EventId,
+			EventType:     eventType,
+			Status:        OutboxPending.String(),
+			NextAttemptAt: time.Now(),
+		}
+		var existing WebhookDelivery
+		err := w.db.Where("endpoint_id = ? AND event_id = ? AND event_type = ?", endpoint.ID, event.EventId, eventType).
+			Limit(1).Find(&existing).Error
+		if err != nil {
+			return count, err
+		}
+		if existing.ID != 0 {
+			if replay {
+				if err = w.ReplayDelivery(existing.ID); err != nil {
+					return count, err
+				}
+				count++
+			}
+			continue
+		}
+		// the payload carries the id of the delivery, both are written in one transaction so the
+		// delivery is never seen, and sent, without its payload
+		err = w.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&delivery).Error; err != nil {
+				return err
+			}
+			body, err := json.Marshal(WebhookPayload{
+				DeliveryId: delivery.ID,
+				Type:       eventType,
+				CreatedAt:  event.OccurredAt,
+				Data:       event,
+			})
+			if err != nil {
+				return err
+			}
+			return tx.Model(&delivery).Update("payload", string(body)).Error
+		})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Run delivers due webhooks every PollInterval until ctx is cancelled.
+func (w *WebhookService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.DeliverOnce(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverOnce posts one batch of due deliveries and returns how many succeeded.
+func (w *WebhookService) DeliverOnce(ctx context.Context) (int, error) {
+	if w.db == nil {
+		return 0, ErrDatabaseRequired
+	}
+	var deliveries []WebhookDelivery
+	err := w.db.Where("status = ? AND next_attempt_at <= ?", OutboxPending.String(), time.Now()).
+		Order("id").Limit(w.config.BatchSize).Find(&deliveries).Error
+	if err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return succeeded, ctx.Err()
+		}
+		now := time.Now()
+		claimed, err := w.claimDelivery(delivery, now, now.Add(w.config.Lease))
+		if err != nil {
+			return succeeded, err
+		}
+		if !claimed {
+			continue
+		}
+		var endpoint WebhookEndpoint
+		if err = w.db.First(&endpoint, delivery.EndpointId).Error; err != nil {
+			return succeeded, err
+		}
+		statusCode, sendErr := w.send(ctx, endpoint, delivery)
+
+		attempts := delivery.Attempts + 1
+		values := map[string]interface{}{"attempts": attempts, "last_status_code": statusCode}
+		if sendErr == nil {
+			now := time.Now()
+			values["status"] = OutboxDelivered.String()
+			values["delivered_at"] = &now
+			values["last_error"] = ""
+			succeeded++
+		} else {
+			values["last_error"] = truncateErrorMessage(sendErr.Error())
+			values["next_attempt_at"] = time.Now().Add(w.retryDelay(attempts))
+			keyvals := []interface{}{
+				"delivery_id", delivery.ID, "endpoint_id", endpoint.ID, "event_id", delivery.EventId,
+				"attempts", attempts, "status_code", statusCode, "error", sendErr.Error(),
+			}
+			if attempts >= w.config.MaxAttempts {
+				values["status"] = OutboxDeadLetter.String()
+				w.logger.Error("webhook delivery given up", keyvals...)
+			} else {
+				w.logger.Warn("webhook delivery failed, will retry", keyvals...)
+			}
+		}
+		if err = w.db.Model(&WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(values).Error; err != nil {
+			return succeeded, err
+		}
+	}
+	return succeeded, nil
+}
+
+// claimDelivery leases a due delivery until leaseUntil, reports false when another service holds it.
+func (w *WebhookService) claimDelivery(delivery WebhookDelivery, now time.Time, leaseUntil time.Time) (bool, error) {
+	result := w.db.Model(&WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", delivery.ID, OutboxPending.String(), now).
+		Update("next_attempt_at", leaseUntil)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (w *WebhookService) send(ctx context.Context, endpoint WebhookEndpoint, delivery WebhookDelivery) (int, error) {
+	if !endpoint.Active {
+		return 0, errors.New("endpoint is disabled")
+	}
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookEventHeader, delivery.EventType)
+	request.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, time.Now(), body))
+
+	response, err := w.config.HTTPClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("endpoint responded %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+func (w *WebhookService) retryDelay(attempts int) time.Duration {
+	delay := w.config.RetryBackoff
+	for i := 1; i < attempts && delay < w.config.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > w.config.MaxRetryBackoff {
+		delay = w.config.MaxRetryBackoff
+	}
+	return delay
+}
+
+// SignWebhookPayload builds the signature header value "t=<unix>,v1=<hex hmac-sha256 of "<unix>.<body>">".
+func SignWebhookPayload(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// VerifyWebhookSignature checks a signature header against body, rejecting signatures older than tolerance.
+func VerifyWebhookSignature(secret string, header string, body []byte, tolerance time.Duration) error {
+	var timestamp, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || signature == "" {
+		return ErrInvalidWebhookSignature
+	}
+	signedAt := time.Unix(unix, 0)
+	if tolerance > 0 && time.Since(signedAt) > tolerance {
+		return ErrInvalidWebhookSignature
+	}
+	expected := SignWebhookPayload(secret, signedAt, body)
+	if !hmac.Equal([]byte(expected), []byte(fmt.Sprintf("t=%s,v1=%s", timestamp, signature))) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// webhookEventTypes the webhook event types raised by a wallet event.
+func webhookEventTypes(event WalletChangedEvent) []string {
+	types := []string{WebhookWalletChanged}
+	if event.ActionType == Withdraw.String() {
+		types = append(types, WebhookWithdrawalCompleted)
+	}
+	return types
+}
+
+func (f WebhookFilter) matches(eventType string, event WalletChangedEvent) bool {
+	contains := func(values []string, value string) bool {
+		if len(values) == 0 {
+			return true
+		}
+		for _, item := range values {
+			if item == value {
+				return true
+			}
+		}
+		return false
+	}
+	if !contains(f.EventTypes, eventType) ||
+		!contains(f.ActionTypes, event.ActionType) ||
+		!contains(f.BusinessModules, event.BusinessModule) {
+		return false
+	}
+	if len(f.Tokens) == 0 {
+		return true
+	}
+	// a token filter only matches events which moved one of the tokens
+	for _, change := range event.ERC20Changes {
+		if change.Delta != 0 && contains(f.Tokens, change.Token) {
+			return true
+		}
+	}
+	for _, change := range event.ERC1155Changes {
+		if change.Delta != 0 && contains(f.Tokens, strconv.FormatUint(change.Id, 10)) {
+			return true
+		}
+	}
+	return false
+}
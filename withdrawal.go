@@ -0,0 +1,578 @@
+package walleter
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// WithdrawalPolicy holds ERC20 withdrawals above a per-token threshold until enough operators approve them,
+// see WithWithdrawalPolicy. A held withdrawal locks its amounts: they leave Balance for Locked, so they
+// cannot be spent meanwhile, and return to Balance when the withdrawal is rejected or expires.
+type WithdrawalPolicy struct {
+	// Thresholds a withdrawal of more than the threshold of any of its tokens is held. Tokens without
+	// a threshold are never held.
+	Thresholds map[ERC20TokenEnum]float64
+	// Approvals the number of distinct operators who must approve a held withdrawal, 1 when lower.
+	Approvals int
+	// Timeout how long a withdrawal stays held before ExpireWithdrawals releases it, 0 for no timeout.
+	Timeout time.Duration
+}
+
+// holds reports whether command is a withdrawal to hold.
+func (p WithdrawalPolicy) holds(command WalletCommand) bool {
+	if command.ActionType != Withdraw || command.AssetType != ERC20AssetType {
+		return false
+	}
+	for _, token := range command.ERC20Commands {
+		if threshold, ok := p.Thresholds[token.Token]; ok && token.Value > threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// WithdrawalHoldStatus the state of a WithdrawalHold, pending until approved, rejected or expired.
+type WithdrawalHoldStatus string
+
+const (
+	WithdrawalPending  WithdrawalHoldStatus = "pending"
+	WithdrawalApproved WithdrawalHoldStatus = "approved"
+	WithdrawalRejected WithdrawalHoldStatus = "rejected"
+	WithdrawalExpired  WithdrawalHoldStatus = "expired"
+)
+
+// HeldAmounts the amounts locked by a WithdrawalHold, by token symbol.
+type HeldAmounts map[string]float64
+
+func (HeldAmounts) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (a HeldAmounts) Value() (driver.Value, error) {
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+func (a *HeldAmounts) Scan(input interface{}) error {
+	return scanJSON(input, a)
+}
+
+// WithdrawalHold a withdrawal held by the WithdrawalPolicy. Its ERC20 log stays Held until the hold is resolved:
+// Done once RequiredApprovals distinct operators approved it, Failed when it is rejected or expires.
+type WithdrawalHold struct {
+	gorm.Model        `swagger-ignore:"true"`
+	AccountId         uint64               `json:"account_id" gorm:"not null;index"`
+	LogId             uint                 `json:"log_id" gorm:"not null;uniqueIndex"`
+	Amounts           HeldAmounts          `json:"amounts"`
+	RequiredApprovals int                  `json:"required_approvals" gorm:"not null"`
+	Approvals         int                  `json:"approvals" gorm:"not null;default:0"`
+	Status            WithdrawalHoldStatus `json:"status" gorm:"type:varchar(16);not null;index"`
+	ExpiresAt         *time.Time           `json:"expires_at" gorm:"index"`
+	ResolvedBy        string               `json:"resolved_by" gorm:"type:varchar(64)"`
+	ResolvedAt        *time.Time           `json:"resolved_at"`
+	Reason            string               `json:"reason" gorm:"type:varchar(255)"`
+}
+
+// WithdrawalApproval the approval of a WithdrawalHold by one operator.
+type WithdrawalApproval struct {
+	gorm.Model `swagger-ignore:"true"`
+	HoldId     uint   `json:"hold_id" gorm:"not null;uniqueIndex:idx_withdrawal_approval_operator"`
+	Operator   string `json:"operator" gorm:"type:varchar(64);not null;uniqueIndex:idx_withdrawal_approval_operator"`
+}
+
+// WithdrawalHeldError is returned, along with the wallet locking its amounts, for a withdrawal held by the
+// WithdrawalPolicy. The command did not fail: its log is Held and the withdrawal completes once HoldId is
+// approved, see ApproveWithdrawal. It unwraps to ErrWithdrawalHeld.
+type WithdrawalHeldError struct {
+	AccountId uint64 `json:"account_id"`
+	HoldId    uint   `json:"hold_id"`
+}
+
+func (e *WithdrawalHeldError) Error() string {
+	return fmt.Sprintf("%s: withdrawal %d of account %d", ErrWithdrawalHeld, e.HoldId, e.AccountId)
+}
+
+func (e *WithdrawalHeldError) Unwrap() error {
+	return ErrWithdrawalHeld
+}
+
+// heldWithdrawalOf returns the WithdrawalHeldError in err, nil when err does not report a held withdrawal.
+func heldWithdrawalOf(err error) *WithdrawalHeldError {
+	var held *WithdrawalHeldError
+	if errors.As(err, &held) {
+		return held
+	}
+	return nil
+}
+
+// holdWithdrawal locks the amounts of command and records a WithdrawalHold instead of withdrawing them,
+// it returns the wallet with a WithdrawalHeldError. Like handleERC20Command, a failure is recorded in the log
+// of command.
+func (s *Walleter) holdWithdrawal(repo Repository, command WalletCommand) (Wallet, error) {
+	logService := newWalletLogService()
+	userWallet, err := repo.GetWallet(command.AccountId)
+	if err != nil {
+		return Wallet{}, err
+	}
+	if err := VerifyWallet(userWallet); err != nil {
+		return Wallet{}, err
+	}
+
+	erc20Log, err := logService.insertNewERC20WalletLog(repo, command, userWallet)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	var hold WithdrawalHold
+	err = repo.Transaction(func(tx Repository) error {
+		holds, err := storeOf[WithdrawalHoldStore](tx)
+		if err != nil {
+			return err
+		}
+		amounts := HeldAmounts{}
+		for _, token := range command.ERC20Commands {
+			amounts[token.Token.String()] += token.Value
+		}
+		heldWallet, err := lockERC20Amounts(tx, copyWallet(userWallet), amounts)
+		if err != nil {
+			return err
+		}
+		if _, err := updateCheckSign(tx, heldWallet); err != nil {
+			return err
+		}
+
+		hold = WithdrawalHold{
+			AccountId:         command.AccountId,
+			LogId:             erc20Log.ID,
+			Amounts:           amounts,
+			RequiredApprovals: s.withdrawalPolicy.Approvals,
+			Status:            WithdrawalPending,
+		}
+		if hold.RequiredApprovals < 1 {
+			hold.RequiredApprovals = 1
+		}
+		if s.withdrawalPolicy.Timeout > 0 {
+			expiresAt := time.Now().Add(s.withdrawalPolicy.Timeout)
+			hold.ExpiresAt = &expiresAt
+		}
+		if hold, err = holds.InsertWithdrawalHold(hold); err != nil {
+			return err
+		}
+		_, err = logService.updateERC20WalletLog(tx, erc20Log, Held, Wallet{})
+		return err
+	})
+	if err != nil {
+		_, logErr := logService.failedERC20WalletLog(repo, erc20Log, userWallet, err)
+		if logErr != nil {
+			s.logger.Error("record failed withdrawal hold failed", "account_id", command.AccountId, "error", logErr)
+		}
+		return Wallet{}, err
+	}
+	wallet, err := repo.GetWallet(command.AccountId)
+	if err != nil {
+		return Wallet{}, err
+	}
+	return wallet, &WithdrawalHeldError{AccountId: command.AccountId, HoldId: hold.ID}
+}
+
+// ApproveWithdrawal records the approval of the held withdrawal id by operator. The approval completing
+// RequiredApprovals performs the withdrawal, charging its fees, and returns the settled wallet; earlier
+// approvals return the wallet unchanged. When the withdrawal cannot be performed, e.g. because the remaining
+// balance cannot pay its fees, the approval is not recorded and the withdrawal stays held.
+func (s *Walleter) ApproveWithdrawal(ctx context.Context, id uint, operator string) (WithdrawalHold, Wallet, error) {
+	if strings.TrimSpace(operator) == "" {
+		return WithdrawalHold{}, Wallet{}, fmt.Errorf("%w: operator is required", ErrInvalidOperatorAction)
+	}
+
+	repo := s.repo.WithContext(ctx)
+	var hold WithdrawalHold
+	err := repo.Transaction(func(tx Repository) error {
+		holds, err := storeOf[WithdrawalHoldStore](tx)
+		if err != nil {
+			return err
+		}
+		current, err := pendingWithdrawalHold(holds, id, time.Now())
+		if err != nil {
+			return err
+		}
+		approvals, err := holds.ListWithdrawalApprovals(id)
+		if err != nil {
+			return err
+		}
+		for _, approval := range approvals {
+			if strings.EqualFold(strings.TrimSpace(approval.Operator), strings.TrimSpace(operator)) {
+				return fmt.Errorf("%w: %s already approved withdrawal %d", ErrDuplicateApproval, operator, id)
+			}
+		}
+		if hold, err = holds.AddWithdrawalApproval(WithdrawalApproval{HoldId: id, Operator: operator}); err != nil {
+			return err
+		}
+		if err = insertAuditLog(tx, current.auditLog(AuditApproveWithdrawal, operator, "")); err != nil {
+			return err
+		}
+		if hold.Approvals < hold.RequiredApprovals {
+			return nil
+		}
+		hold, err = s.resolveWithdrawalHold(tx, hold, WithdrawalApproved, operator, "")
+		return err
+	})
+	if err != nil {
+		return WithdrawalHold{}, Wallet{}, err
+	}
+	wallet, err := repo.GetWallet(hold.AccountId)
+	return hold, wallet, err
+}
+
+// RejectWithdrawal rejects the held withdrawal id, its amounts return to the balance and its log fails with
+// ErrWithdrawalRejected.
+func (s *Walleter) RejectWithdrawal(ctx context.Context, id uint, operator string, reason string) (WithdrawalHold, error) {
+	if err := checkOperatorAction(operator, reason); err != nil {
+		return WithdrawalHold{}, err
+	}
+	var hold WithdrawalHold
+	err := s.repo.WithContext(ctx).Transaction(func(tx Repository) error {
+		holds, err := storeOf[WithdrawalHoldStore](tx)
+		if err != nil {
+			return err
+		}
+		if hold, err = pendingWithdrawalHold(holds, id, time.Now()); err != nil {
+			return err
+		}
+		if hold, err = s.resolveWithdrawalHold(tx, hold, WithdrawalRejected, operator, reason); err != nil {
+			return err
+		}
+		return insertAuditLog(tx, hold.auditLog(AuditRejectWithdrawal, operator, reason))
+	})
+	return hold, err
+}
+
+// ExpireWithdrawals releases the held withdrawals whose timeout passed, their logs fail with ErrWithdrawalExpired.
+// It returns the number of withdrawals released, holds resolved by another instance meanwhile are skipped.
+func (s *Walleter) ExpireWithdrawals(ctx context.Context) (int, error) {
+	repo := s.repo.WithContext(ctx)
+	store, err := storeOf[WithdrawalHoldStore](repo)
+	if err != nil {
+		return 0, err
+	}
+	holds, err := store.ListExpiredWithdrawalHolds(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, hold := range holds {
+		err := repo.Transaction(func(tx Repository) error {
+			_, err := s.resolveWithdrawalHold(tx, hold, WithdrawalExpired, "", "")
+			return err
+		})
+		if errors.Is(err, ErrWithdrawalNotPending) {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// GetWithdrawalHold returns the hold id, gorm.ErrRecordNotFound when it does not exist.
+func (s *Walleter) GetWithdrawalHold(id uint) (WithdrawalHold, error) {
+	holds, err := storeOf[WithdrawalHoldStore](s.repo)
+	if err != nil {
+		return WithdrawalHold{}, err
+	}
+	return holds.GetWithdrawalHold(id)
+}
+
+// ListWithdrawalHolds returns the holds with status, every hold when empty, oldest first.
+func (s *Walleter) ListWithdrawalHolds(status WithdrawalHoldStatus) ([]WithdrawalHold, error) {
+	holds, err := storeOf[WithdrawalHoldStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return holds.ListWithdrawalHolds(status)
+}
+
+// ListWithdrawalApprovals returns the approvals recorded for the hold id, oldest first.
+func (s *Walleter) ListWithdrawalApprovals(id uint) ([]WithdrawalApproval, error) {
+	holds, err := storeOf[WithdrawalHoldStore](s.repo)
+	if err != nil {
+		return nil, err
+	}
+	return holds.ListWithdrawalApprovals(id)
+}
+
+// pendingWithdrawalHold loads the hold id, which must be pending and not expired at now.
+func pendingWithdrawalHold(holds WithdrawalHoldStore, id uint, now time.Time) (WithdrawalHold, error) {
+	hold, err := holds.GetWithdrawalHold(id)
+	if err != nil {
+		return WithdrawalHold{}, err
+	}
+	if hold.Status != WithdrawalPending {
+		return WithdrawalHold{}, fmt.Errorf("%w: withdrawal %d is %s", ErrWithdrawalNotPending, id, hold.Status)
+	}
+	if hold.ExpiresAt != nil && !now.Before(*hold.ExpiresAt) {
+		return WithdrawalHold{}, fmt.Errorf("%w: withdrawal %d expired at %s", ErrWithdrawalExpired, id, hold.ExpiresAt.Format(time.RFC3339))
+	}
+	return hold, nil
+}
+
+// resolveWithdrawalHold moves the pending hold to status and settles its funds and its log: an approved
+// withdrawal leaves Locked for TotalWithdraw and pays its fees, other statuses return the funds to Balance.
+// The resolution is traced, counted and logged as the outcome of the held command: done when approved,
+// failed with ErrWithdrawalRejected or ErrWithdrawalExpired otherwise. A hold resolved meanwhile by another
+// instance fails with ErrWithdrawalNotPending and is not observed again.
+func (s *Walleter) resolveWithdrawalHold(tx Repository, hold WithdrawalHold, status WithdrawalHoldStatus, operator string, reason string) (resolved WithdrawalHold, err error) {
+	now := time.Now()
+	hold.Status = status
+	hold.ResolvedBy = operator
+	hold.ResolvedAt = &now
+	hold.Reason = reason
+	holds, err := storeOf[WithdrawalHoldStore](tx)
+	if err != nil {
+		return WithdrawalHold{}, err
+	}
+	ok, err := holds.ResolveWithdrawalHold(hold)
+	if err != nil {
+		return WithdrawalHold{}, err
+	}
+	if !ok {
+		return WithdrawalHold{}, fmt.Errorf("%w: withdrawal %d was resolved meanwhile", ErrWithdrawalNotPending, hold.ID)
+	}
+
+	erc20Log, err := tx.GetERC20WalletLog(hold.LogId)
+	if err != nil {
+		return WithdrawalHold{}, err
+	}
+	command := erc20Log.command()
+	startedAt := time.Now()
+	tx, span := s.startCommandSpan(tx, "walleter.ResolveWithdrawalHold", command)
+	span.SetAttributes(attribute.Int64("walleter.withdrawal_hold_id", int64(hold.ID)),
+		attribute.String("walleter.withdrawal_hold_status", string(status)))
+	var failure error
+	if status != WithdrawalApproved {
+		failure = ErrWithdrawalRejected
+		if status == WithdrawalExpired {
+			failure = ErrWithdrawalExpired
+		}
+		failure = newWalletError(failure, hold.AccountId)
+	}
+	defer func() {
+		outcome := err
+		if outcome == nil {
+			outcome = failure
+		}
+		endSpan(span, outcome)
+		s.metrics.observeCommand(command, outcome, time.Since(startedAt))
+		s.logCommand(command, outcome, time.Since(startedAt))
+	}()
+
+	wallet, err := tx.GetWallet(hold.AccountId)
+	if err != nil {
+		return WithdrawalHold{}, err
+	}
+	if err := VerifyWallet(wallet); err != nil {
+		return WithdrawalHold{}, err
+	}
+
+	logService := newWalletLogService()
+	if failure != nil {
+		if wallet, err = releaseERC20Amounts(tx, wallet, hold.Amounts); err != nil {
+			return WithdrawalHold{}, err
+		}
+		if wallet, err = updateCheckSign(tx, wallet); err != nil {
+			return WithdrawalHold{}, err
+		}
+		_, err = logService.failedERC20WalletLog(tx, erc20Log, wallet, failure)
+		return hold, err
+	}
+
+	if wallet, err = chargeCommandFees(tx, command, wallet); err != nil {
+		return WithdrawalHold{}, err
+	}
+	if wallet, err = withdrawERC20Amounts(tx, wallet, hold.Amounts); err != nil {
+		return WithdrawalHold{}, err
+	}
+	if wallet, err = updateCheckSign(tx, wallet); err != nil {
+		return WithdrawalHold{}, err
+	}
+	if _, err = logService.updateERC20WalletLog(tx, erc20Log, Done, wallet); err != nil {
+		return WithdrawalHold{}, err
+	}
+	return hold, newWalletEventService().publishWalletChanged(tx, command, erc20Log.ID, wallet)
+}
+
+// lockERC20Amounts moves amounts from Balance to Locked, failing with ErrNoEnoughERC20Balance like a withdrawal.
+func lockERC20Amounts(repo Repository, userWallet Wallet, amounts HeldAmounts) (Wallet, error) {
+	return changeHeldAmounts(repo, userWallet, amounts, func(tokenWallet *ERC20TokenWallet, value float64) (float64, error) {
+		if tokenWallet.Balance < value {
+			return 0, newWalletError(ErrNoEnoughERC20Balance, userWallet.AccountId).withToken(tokenWallet.Token, value, tokenWallet.Balance)
+		}
+		tokenWallet.Balance -= value
+		tokenWallet.Locked += value
+		return -value, nil
+	})
+}
+
+// releaseERC20Amounts returns locked amounts to Balance.
+func releaseERC20Amounts(repo Repository, userWallet Wallet, amounts HeldAmounts) (Wallet, error) {
+	return changeHeldAmounts(repo, userWallet, amounts, func(tokenWallet *ERC20TokenWallet, value float64) (float64, error) {
+		tokenWallet.Locked -= value
+		tokenWallet.Balance += value
+		return value, nil
+	})
+}
+
+// withdrawERC20Amounts withdraws locked amounts, Balance already excludes them.
+func withdrawERC20Amounts(repo Repository, userWallet Wallet, amounts HeldAmounts) (Wallet, error) {
+	return changeHeldAmounts(repo, userWallet, amounts, func(tokenWallet *ERC20TokenWallet, value float64) (float64, error) {
+		tokenWallet.Locked -= value
+		tokenWallet.TotalWithdraw += value
+		return 0, nil
+	})
+}
+
+// changeHeldAmounts applies change to the token rows of amounts and stores them. change returns the change
+// of Balance, recorded in the balance history when not zero.
+func changeHeldAmounts(repo Repository, userWallet Wallet, amounts HeldAmounts,
+	change func(tokenWallet *ERC20TokenWallet, value float64) (float64, error)) (Wallet, error) {
+	historyService := newBalanceHistoryService()
+	for symbol, value := range amounts {
+		token, ok := erc20TokenOf(symbol)
+		if !ok {
+			return Wallet{}, newWalletError(ErrCannotFindERC20Wallet, userWallet.AccountId).withToken(symbol, value, 0)
+		}
+		index, tokenWallet := getUserSpecifiedERC20TokenWallet(userWallet, token)
+		if index == -1 {
+			return Wallet{}, newWalletError(ErrCannotFindERC20Wallet, userWallet.AccountId).withToken(symbol, value, 0)
+		}
+		balanceChange, err := change(&tokenWallet, value)
+		if err != nil {
+			return Wallet{}, err
+		}
+		userWallet.ERC20TokenData[index] = tokenWallet
+		if err := repo.UpdateERC20TokenWallet(tokenWallet); err != nil {
+			return Wallet{}, err
+		}
+		if balanceChange == 0 {
+			continue
+		}
+		if err := historyService.recordERC20Balance(repo, tokenWallet, balanceChange); err != nil {
+			return Wallet{}, err
+		}
+	}
+	return userWallet, nil
+}
+
+// command rebuilds the ERC20 command l was written for.
+func (l ERC20WalletLog) command() WalletCommand {
+	command := WalletCommand{
+		AccountId:      l.AccountId,
+		AssetType:      ERC20AssetType,
+		BusinessModule: l.BusinessModule,
+		Approval:       l.Approval,
+		ChainRef:       l.ChainRef,
+	}
+	for action := Initialize; action <= Deduct; action++ {
+		if action.String() == l.ActionType {
+			command.ActionType = action
+		}
+	}
+	for source := InGame; source <= BSCTestnet; source++ {
+		if source.String() == l.Source {
+			command.CommandSource = source
+		}
+	}
+	for _, item := range l.Tokens.Items {
+		if token, ok := erc20TokenOf(item.TokenType); ok {
+			command.ERC20Commands = append(command.ERC20Commands, ERC20Command{Token: token, Value: item.Amount, Decimal: item.Decimal})
+		}
+	}
+	for _, item := range l.Fees.Items {
+		if token, ok := erc20TokenOf(item.TokenType); ok {
+			command.FeeCommands = append(command.FeeCommands, ERC20Command{Token: token, Value: item.Amount, Decimal: item.Decimal})
+		}
+	}
+	return command
+}
+
+func (hold WithdrawalHold) auditLog(action AuditAction, operator string, reason string) AuditLog {
+	return AuditLog{Operator: operator, Action: action, AccountId: hold.AccountId, Reason: reason,
+		Detail: AdjustmentAmounts{Tokens: hold.Amounts}}
+}
+
+type withdrawalHoldDAO struct{}
+
+var withdrawalDAO = &withdrawalHoldDAO{}
+
+func (dao withdrawalHoldDAO) insertHold(db *gorm.DB, hold WithdrawalHold) (WithdrawalHold, error) {
+	err := db.Create(&hold).Error
+	return hold, err
+}
+
+func (dao withdrawalHoldDAO) getHold(db *gorm.DB, id uint) (hold WithdrawalHold, err error) {
+	err = db.First(&hold, id).Error
+	return hold, err
+}
+
+// addApproval records approval and counts it on its hold if the hold is still pending. The counter is
+// incremented in SQL, so concurrent approvals of different operators are all counted.
+func (dao withdrawalHoldDAO) addApproval(db *gorm.DB, approval WithdrawalApproval) (WithdrawalHold, error) {
+	if err := db.Create(&approval).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return WithdrawalHold{}, fmt.Errorf("%w: %s already approved withdrawal %d", ErrDuplicateApproval, approval.Operator, approval.HoldId)
+		}
+		return WithdrawalHold{}, err
+	}
+	result := db.Model(&WithdrawalHold{}).
+		Where("id = ? AND status = ?", approval.HoldId, WithdrawalPending).
+		Update("approvals", gorm.Expr("approvals + 1"))
+	if result.Error != nil {
+		return WithdrawalHold{}, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return WithdrawalHold{}, fmt.Errorf("%w: withdrawal %d was resolved meanwhile", ErrWithdrawalNotPending, approval.HoldId)
+	}
+	return dao.getHold(db, approval.HoldId)
+}
+
+// resolveHold saves the resolution of hold if it is still pending, reporting whether it was.
+func (dao withdrawalHoldDAO) resolveHold(db *gorm.DB, hold WithdrawalHold) (bool, error) {
+	result := db.Model(&WithdrawalHold{}).
+		Where("id = ? AND status = ?", hold.ID, WithdrawalPending).
+		Updates(map[string]interface{}{
+			"status":      hold.Status,
+			"resolved_by": hold.ResolvedBy,
+			"resolved_at": hold.ResolvedAt,
+			"reason":      hold.Reason,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (dao withdrawalHoldDAO) listHolds(db *gorm.DB, status WithdrawalHoldStatus) (result []WithdrawalHold, err error) {
+	query := db.Order("id")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err = query.Find(&result).Error
+	return result, err
+}
+
+func (dao withdrawalHoldDAO) listExpiredHolds(db *gorm.DB, now time.Time) (result []WithdrawalHold, err error) {
+	err = db.Where("status = ? AND expires_at <= ?", WithdrawalPending, now).Order("id").Find(&result).Error
+	return result, err
+}
+
+func (dao withdrawalHoldDAO) listApprovals(db *gorm.DB, holdId uint) (result []WithdrawalApproval, err error) {
+	err = db.Where("hold_id = ?", holdId).Order("id").Find(&result).Error
+	return result, err
+}